@@ -18,8 +18,10 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -36,6 +38,11 @@ type DeploymentSpec struct {
 	//+kubebuilder:validation:MinLength=1
 	Image string `json:"image"`
 
+	// ImagePullSecrets names Secrets in the CR's namespace used to pull the
+	// app image. They are added to the operator's default pull secrets.
+	//+optional
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+
 	// Port is the container port the application listens on.
 	//+kubebuilder:validation:Minimum=1
 	//+kubebuilder:validation:Maximum=65535
@@ -53,6 +60,12 @@ type DeploymentSpec struct {
 	//+optional
 	Env []corev1.EnvVar `json:"env,omitempty"`
 
+	// EnvFrom loads environment variables from ConfigMaps or Secrets in the
+	// CR's namespace. Env and injected dependency vars take precedence over
+	// keys of the same name. Editing a referenced object rolls the pods.
+	//+optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
 	// Resources defines CPU and memory requests/limits for the container.
 	//+optional
 	Resources *ResourceRequirements `json:"resources,omitempty"`
@@ -60,6 +73,179 @@ type DeploymentSpec struct {
 	// HealthCheck configures liveness and readiness probes.
 	//+optional
 	HealthCheck *HealthCheckSpec `json:"healthCheck,omitempty"`
+
+	// Sidecars are extra containers run in the app pod alongside the main
+	// container (e.g. an OTel collector or Cloud SQL proxy). Dependency
+	// connection env vars are only injected into the main container.
+	//+optional
+	Sidecars []ContainerSpec `json:"sidecars,omitempty"`
+
+	// Autoscaling creates a HorizontalPodAutoscaler for the Deployment.
+	// When set, Replicas is ignored and the HPA owns the replica count.
+	//+optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// ConfigMounts mount ConfigMaps (e.g. nginx.conf, application.yaml)
+	// into the main container.
+	//+optional
+	ConfigMounts []ConfigMount `json:"configMounts,omitempty"`
+
+	// Volumes are extra pod volumes, passed through as-is. Mount them into
+	// the main container with VolumeMounts.
+	//+kubebuilder:validation:Schemaless
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// VolumeMounts are extra mounts for the main container.
+	//+optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// TerminationGracePeriodSeconds is how long pods get to shut down after
+	// SIGTERM before they are killed. Defaults to the Kubernetes 30s.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// PreStopExec is run inside the main container before it is sent
+	// SIGTERM (e.g. ["sh", "-c", "sleep 5"] to let endpoints drain).
+	// Mutually exclusive with PreStopHTTPGet.
+	//+optional
+	PreStopExec []string `json:"preStopExec,omitempty"`
+
+	// PreStopHTTPGet is requested from the main container before it is sent
+	// SIGTERM. Mutually exclusive with PreStopExec.
+	//+optional
+	PreStopHTTPGet *PreStopHTTPGet `json:"preStopHTTPGet,omitempty"`
+
+	// NodeSelector pins app pods to nodes with these labels (e.g.
+	// {"kubernetes.io/arch": "arm64"} on a mixed-arch cluster).
+	//+optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations let app pods schedule onto tainted nodes.
+	//+optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity is the app pod's node and pod (anti-)affinity, passed
+	// through as-is.
+	//+kubebuilder:validation:Schemaless
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+kubebuilder:validation:Type=object
+	//+optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// ServiceAccountName runs app pods as this ServiceAccount, for apps
+	// that talk to the Kubernetes API. With spec.createServiceAccount it
+	// also names the account the operator creates. Empty uses the
+	// namespace's default account.
+	//+optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// RevisionHistoryLimit is how many old ReplicaSets are kept for
+	// rollback. Defaults to 2, since push and sync cycles roll often.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// Strategy is how pods are replaced on a rollout. Defaults to a
+	// RollingUpdate with the Kubernetes 25% surge and unavailability.
+	//+optional
+	Strategy *DeploymentStrategy `json:"strategy,omitempty"`
+}
+
+// DeploymentStrategyType is how a Deployment replaces its pods.
+// +kubebuilder:validation:Enum=RollingUpdate;Recreate
+type DeploymentStrategyType string
+
+const (
+	DeploymentStrategyRollingUpdate DeploymentStrategyType = "RollingUpdate"
+	DeploymentStrategyRecreate      DeploymentStrategyType = "Recreate"
+)
+
+// DeploymentStrategy configures how the app Deployment rolls out.
+type DeploymentStrategy struct {
+	// Type is RollingUpdate or Recreate. Recreate stops the old pods
+	// before starting new ones, so two versions never hold the same
+	// exclusive lock or migration at once.
+	//+kubebuilder:default=RollingUpdate
+	//+optional
+	Type DeploymentStrategyType `json:"type,omitempty"`
+
+	// MaxSurge is how many pods above the replica count a RollingUpdate
+	// may create (e.g. 1 or "25%").
+	//+kubebuilder:validation:XIntOrString
+	//+optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// MaxUnavailable is how many pods a RollingUpdate may take down at
+	// once (e.g. 0 to keep full capacity).
+	//+kubebuilder:validation:XIntOrString
+	//+optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// PreStopHTTPGet is an HTTP GET sent to the main container before shutdown.
+type PreStopHTTPGet struct {
+	// Path is the request path (e.g. "/drain").
+	//+kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// Port defaults to the container port.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+}
+
+// ConfigMount mounts a ConfigMap from the CR's namespace into the main
+// container.
+type ConfigMount struct {
+	// ConfigMap is the name of the ConfigMap.
+	//+kubebuilder:validation:MinLength=1
+	ConfigMap string `json:"configMap"`
+
+	// MountPath is the directory the ConfigMap is mounted at.
+	//+kubebuilder:validation:MinLength=1
+	MountPath string `json:"mountPath"`
+
+	// Items selects which keys to mount and the file name for each. All
+	// keys are mounted, named after the key, when empty.
+	//+optional
+	Items []corev1.KeyToPath `json:"items,omitempty"`
+}
+
+// ContainerSpec describes an extra container in the app pod.
+type ContainerSpec struct {
+	// Name is the container name. It must be unique within the pod.
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Image is the container image to run.
+	//+kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// Command overrides the container entrypoint.
+	//+optional
+	Command []string `json:"command,omitempty"`
+
+	// Args are arguments passed to the container entrypoint.
+	//+optional
+	Args []string `json:"args,omitempty"`
+
+	// Env is a list of environment variables to set in the container.
+	//+optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Port is a container port the sidecar listens on, if any.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+
+	// Resources defines CPU and memory requests/limits for the container.
+	//+optional
+	Resources *ResourceRequirements `json:"resources,omitempty"`
 }
 
 // ResourceRequirements defines compute resource requests and limits.
@@ -81,15 +267,42 @@ type ResourceRequirements struct {
 	MemoryLimit *resource.Quantity `json:"memoryLimit,omitempty"`
 }
 
+// AutoscalingSpec configures CPU-based horizontal autoscaling. CPU
+// utilization is measured against the container's CPU request, so set
+// resources.cpuRequest as well.
+type AutoscalingSpec struct {
+	// MinReplicas is the lower replica bound (default 1).
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper replica bound.
+	//+kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilization is the average CPU utilization, as a percentage
+	// of the CPU request, the HPA aims for (default 80).
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	TargetCPUUtilization *int32 `json:"targetCPUUtilization,omitempty"`
+}
+
 // HealthCheckSpec configures liveness and readiness probes.
 type HealthCheckSpec struct {
-	// Type is the probe type: "http" (default), "grpc", or "none".
+	// Type is the probe type: "http" (default), "grpc", "tcp", "exec", or "none".
 	// When "grpc", the probe uses the gRPC health checking protocol.
+	// When "tcp", the probe only checks that the port accepts connections.
+	// When "exec", the probe runs Command inside the container.
 	// When "none", no probes are attached (useful for services that don't expose health endpoints).
-	//+kubebuilder:validation:Enum=http;grpc;none
+	//+kubebuilder:validation:Enum=http;grpc;tcp;exec;none
 	//+kubebuilder:default="http"
 	Type string `json:"type,omitempty"`
 
+	// Command is run inside the container when Type is "exec".
+	// The probe succeeds when it exits 0.
+	//+optional
+	Command []string `json:"command,omitempty"`
+
 	// Path is the HTTP path for the health check endpoint (e.g. "/healthz").
 	// Only used when Type is "http".
 	//+kubebuilder:default="/healthz"
@@ -106,6 +319,48 @@ type HealthCheckSpec struct {
 	// PeriodSeconds is how often to perform the probe.
 	//+kubebuilder:default=10
 	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// TimeoutSeconds is how long a single probe may take before it fails.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures before the
+	// container is restarted (liveness) or marked unready (readiness).
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+
+	// StartupPeriodSeconds enables a startup probe that runs at this
+	// interval until the app first succeeds. Liveness and readiness are held
+	// off until then, so slow-booting apps (JVM, Rails) aren't killed early.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	StartupPeriodSeconds *int32 `json:"startupPeriodSeconds,omitempty"`
+
+	// StartupPath is the HTTP path the startup probe checks, for apps whose
+	// boot is better tracked by a different endpoint than Path. Setting it
+	// enables the startup probe. Defaults to Path.
+	//+optional
+	StartupPath string `json:"startupPath,omitempty"`
+
+	// StartupFailureThreshold is how many startup probe failures are
+	// tolerated before the container is restarted (default 30). Setting it
+	// enables the startup probe.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	StartupFailureThreshold *int32 `json:"startupFailureThreshold,omitempty"`
+
+	// ReadinessPath is the HTTP path the readiness probe checks, e.g. one
+	// that also verifies dependencies. Liveness keeps using Path.
+	//+optional
+	ReadinessPath string `json:"readinessPath,omitempty"`
+
+	// SuccessThreshold is the number of consecutive readiness successes
+	// before a pod that failed readiness receives traffic again.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
 }
 
 // ServiceSpec defines the desired state of the Service.
@@ -123,6 +378,16 @@ type ServiceSpec struct {
 	//+kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
 	//+kubebuilder:default="ClusterIP"
 	Type string `json:"type,omitempty"`
+
+	// AppProtocol is the protocol the app speaks on its port. "grpc" names
+	// the container and Service ports grpc, sets the Service port's
+	// appProtocol, probes the app over gRPC unless healthCheck.type says
+	// otherwise, and marks the Ingress backend as gRPC. "h2c" (cleartext
+	// HTTP/2) sets appProtocol kubernetes.io/h2c and marks the Ingress
+	// backend as HTTP/2.
+	//+kubebuilder:validation:Enum=http;grpc;h2c
+	//+optional
+	AppProtocol string `json:"appProtocol,omitempty"`
 }
 
 // IngressSpec defines the desired state of the Ingress.
@@ -168,7 +433,7 @@ type IngressTLSSpec struct {
 }
 
 // DependencyType represents a well-known service dependency.
-// +kubebuilder:validation:Enum=postgres;redis;mysql;mongodb;rabbitmq;minio;elasticsearch;kafka;nats;memcached;cassandra;consul;vault;influxdb;jaeger
+// +kubebuilder:validation:Enum=postgres;redis;mysql;mongodb;rabbitmq;minio;elasticsearch;kafka;nats;memcached;cassandra;consul;vault;influxdb;jaeger;clickhouse;cockroachdb;timescaledb;mailpit;qdrant;weaviate;chroma;localstack;neo4j;mariadb;sqlserver;etcd;prometheus;grafana;arangodb;couchdb;pulsar;meilisearch;typesense;otel-collector;zookeeper;surrealdb;rethinkdb;opensearch
 type DependencyType string
 
 const (
@@ -187,6 +452,40 @@ const (
 	DependencyVault         DependencyType = "vault"
 	DependencyInfluxDB      DependencyType = "influxdb"
 	DependencyJaeger        DependencyType = "jaeger"
+	DependencyClickHouse    DependencyType = "clickhouse"
+	DependencyCockroach     DependencyType = "cockroachdb"
+	DependencyTimescaleDB   DependencyType = "timescaledb"
+	DependencyMailpit       DependencyType = "mailpit"
+	DependencyQdrant        DependencyType = "qdrant"
+	DependencyWeaviate      DependencyType = "weaviate"
+	DependencyChroma        DependencyType = "chroma"
+	DependencyLocalStack    DependencyType = "localstack"
+	DependencyNeo4j         DependencyType = "neo4j"
+	DependencyMariaDB       DependencyType = "mariadb"
+	DependencySQLServer     DependencyType = "sqlserver"
+	DependencyEtcd          DependencyType = "etcd"
+	DependencyPrometheus    DependencyType = "prometheus"
+	DependencyGrafana       DependencyType = "grafana"
+	DependencyArangoDB      DependencyType = "arangodb"
+	DependencyCouchDB       DependencyType = "couchdb"
+	DependencyPulsar        DependencyType = "pulsar"
+	DependencyMeilisearch   DependencyType = "meilisearch"
+	DependencyTypesense     DependencyType = "typesense"
+	DependencyOtelCollector DependencyType = "otel-collector"
+	DependencyZookeeper     DependencyType = "zookeeper"
+	DependencySurrealDB     DependencyType = "surrealdb"
+	DependencyRethinkDB     DependencyType = "rethinkdb"
+	DependencyOpenSearch    DependencyType = "opensearch"
+)
+
+// DependencyVariant selects a protocol-compatible alternative server for a
+// dependency type.
+// +kubebuilder:validation:Enum=valkey;dragonfly
+type DependencyVariant string
+
+const (
+	DependencyVariantValkey    DependencyVariant = "valkey"
+	DependencyVariantDragonfly DependencyVariant = "dragonfly"
 )
 
 // DependencySpec declares a supporting service (database, cache, queue, etc.)
@@ -205,6 +504,13 @@ type DependencySpec struct {
 	//+optional
 	Image string `json:"image,omitempty"`
 
+	// Variant deploys a drop-in replacement instead of the stock server
+	// while keeping the same port, connection URL, and readiness check.
+	// Only redis supports variants ("valkey" or "dragonfly"). Version
+	// then selects the variant's tag.
+	//+optional
+	Variant DependencyVariant `json:"variant,omitempty"`
+
 	// Port overrides the default service port for this dependency.
 	//+optional
 	Port *int32 `json:"port,omitempty"`
@@ -219,13 +525,88 @@ type DependencySpec struct {
 	//+optional
 	EnvVarName string `json:"envVarName,omitempty"`
 
+	// Replicas is the number of dependency pods (default 1). Only stateless
+	// dependencies (e.g. redis, nats, memcached) are scaled; stateful ones
+	// share a single PVC and always run one replica.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
 	// StorageSize is the PVC size for stateful dependencies (default "1Gi").
 	//+optional
 	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
 
+	// MaxMemory caps how much data a cache dependency (redis and its
+	// variants, memcached) holds before evicting keys, so it can't grow
+	// until the node runs out of memory. Defaults to 256Mi; "0" removes
+	// the cap.
+	//+optional
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty"`
+
+	// EvictionPolicy is what a cache does at MaxMemory (default
+	// allkeys-lru). Redis and valkey take any maxmemory-policy; dragonfly
+	// evicts in cache mode unless set to noeviction; memcached always
+	// evicts LRU unless set to noeviction.
+	//+kubebuilder:validation:Enum=noeviction;allkeys-lru;allkeys-lfu;allkeys-random;volatile-lru;volatile-lfu;volatile-random;volatile-ttl
+	//+optional
+	EvictionPolicy string `json:"evictionPolicy,omitempty"`
+
+	// InitScripts are inline scripts run once when the database is first
+	// initialised, in list order. Supported for postgres, mysql, and
+	// mariadb (SQL) and mongodb (JavaScript). They are mounted into
+	// /docker-entrypoint-initdb.d and only run against an empty data dir.
+	//+optional
+	InitScripts []string `json:"initScripts,omitempty"`
+
+	// Bootstrap lists resources the operator creates inside the dependency
+	// once it is up, so apps don't fail on first publish or upload.
+	//+optional
+	Bootstrap *DependencyBootstrap `json:"bootstrap,omitempty"`
+
 	// Resources defines CPU/memory requests and limits for the dependency container.
+	// The JVM-based types (elasticsearch, opensearch, kafka, cassandra)
+	// default to memory requests and limits sized for a laptop node; setting
+	// Resources replaces those defaults entirely.
 	//+optional
 	Resources *ResourceRequirements `json:"resources,omitempty"`
+
+	// Shared provisions this dependency once per namespace, named by
+	// SharedName, and reuses it across every DevStagingEnvironment that
+	// declares the same SharedName. Consumers share its data.
+	//+optional
+	Shared bool `json:"shared,omitempty"`
+
+	// SharedName names the shared dependency's resources. Defaults to
+	// "shared-<type>". Only used when Shared is true.
+	//+kubebuilder:validation:Pattern=`^[a-z]([-a-z0-9]*[a-z0-9])?$`
+	//+kubebuilder:validation:MaxLength=40
+	//+optional
+	SharedName string `json:"sharedName,omitempty"`
+
+	// ExposeUI creates an Ingress at <name>-<type>-ui.localhost for the
+	// dependency's web UI. Supported for rabbitmq (management UI), minio
+	// (console), jaeger, influxdb, prometheus, grafana, arangodb (web UI),
+	// couchdb (Fauxton, at /_utils), meilisearch (search preview),
+	// rethinkdb (admin UI), and elasticsearch and opensearch (REST API).
+	//+optional
+	ExposeUI bool `json:"exposeUI,omitempty"`
+}
+
+// DependencyBootstrap declares resources to create inside a dependency after
+// it starts. Creation is idempotent; each field is only honoured by the
+// dependency types that support it and ignored otherwise.
+type DependencyBootstrap struct {
+	// Buckets are created in minio.
+	//+optional
+	Buckets []string `json:"buckets,omitempty"`
+
+	// Topics are created in kafka.
+	//+optional
+	Topics []string `json:"topics,omitempty"`
+
+	// Queues are declared (durable) in rabbitmq.
+	//+optional
+	Queues []string `json:"queues,omitempty"`
 }
 
 // DevStagingEnvironmentSpec defines the desired state of DevStagingEnvironment
@@ -245,6 +626,38 @@ type DevStagingEnvironmentSpec struct {
 	// Connection env vars are automatically injected into the app container.
 	//+optional
 	Dependencies []DependencySpec `json:"dependencies,omitempty"`
+
+	// NetworkIsolation creates a NetworkPolicy per dependency so only this
+	// environment's pods can reach it. Requires a CNI that enforces
+	// NetworkPolicy.
+	//+optional
+	NetworkIsolation bool `json:"networkIsolation,omitempty"`
+
+	// TTLSecondsAfterCreation deletes the environment, and with it every
+	// child resource, this many seconds after it was created. Zero or unset
+	// means it never expires.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	TTLSecondsAfterCreation *int32 `json:"ttlSecondsAfterCreation,omitempty"`
+
+	// CreateServiceAccount has the operator create the app's ServiceAccount
+	// (deployment.serviceAccountName, or the environment's name when that is
+	// unset) and delete it with the environment.
+	//+optional
+	CreateServiceAccount bool `json:"createServiceAccount,omitempty"`
+
+	// RBAC grants the app's ServiceAccount a namespaced Role. Anything
+	// broader (ClusterRoles, bindings to other accounts) is left to the user.
+	//+optional
+	RBAC *RBACSpec `json:"rbac,omitempty"`
+}
+
+// RBACSpec is a namespaced Role bound to the app's ServiceAccount.
+type RBACSpec struct {
+	// Rules are the Role's policy rules, e.g. get/list/watch on configmaps.
+	// Wildcard verbs, resources, and API groups are rejected, as are rules
+	// on rbac.authorization.k8s.io.
+	Rules []rbacv1.PolicyRule `json:"rules"`
 }
 
 // DevStagingEnvironmentStatus defines the observed state of DevStagingEnvironment
@@ -264,10 +677,27 @@ type DevStagingEnvironmentStatus struct {
 	// DependenciesReady indicates whether all declared dependencies are running.
 	DependenciesReady bool `json:"dependenciesReady,omitempty"`
 
+	// DependencyURLs maps each dependency type to the connection URL
+	// injected into the app container (e.g. "postgres" → DATABASE_URL),
+	// with any password replaced by "xxxxx".
+	// Dependencies with a web UI also get a "<type>-ui" entry: the
+	// exposed Ingress URL when exposeUI is set.
+	//+optional
+	DependencyURLs map[string]string `json:"dependencyURLs,omitempty"`
+
 	// URL is the externally reachable URL if Ingress is configured.
 	//+optional
 	URL string `json:"url,omitempty"`
 
+	// CreatedAt is when the environment was created.
+	//+optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// ExpiresAt is when the environment will be deleted, if
+	// spec.ttlSecondsAfterCreation is set.
+	//+optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
 	// Conditions represent the latest available observations of the resource's state.
 	//+optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/jeffvincent/kindling/pkg/ci"
 	"github.com/spf13/cobra"
 )
 
@@ -869,43 +870,25 @@ func dockerfileFixForLanguage(lang, repoPath string) string {
 	}
 }
 
+// checkKanikoCompat reports Dockerfile patterns that need patching under
+// Kaniko.
 func checkKanikoCompat(path, content string) []checkResult {
-	var results []checkResult
-
-	// BuildKit platform ARGs
-	if strings.Contains(content, "TARGETARCH") || strings.Contains(content, "BUILDPLATFORM") ||
-		strings.Contains(content, "TARGETPLATFORM") || strings.Contains(content, "TARGETOS") {
-		results = append(results, checkResult{
-			status:  checkWarn,
-			message: fmt.Sprintf("%s uses BuildKit platform ARGs — kindling will auto-patch for Kaniko", path),
-		})
-	}
-
-	// Poetry without --no-root
-	if strings.Contains(content, "poetry install") && !strings.Contains(content, "--no-root") {
-		results = append(results, checkResult{
-			status:  checkWarn,
-			message: fmt.Sprintf("%s has 'poetry install' without --no-root — kindling will auto-patch", path),
-		})
-	}
-
-	// npm without cache redirect
-	if (strings.Contains(content, "npm install") || strings.Contains(content, "npm ci") ||
-		strings.Contains(content, "npm run")) && !strings.Contains(content, "npm_config_cache") {
-		results = append(results, checkResult{
-			status:  checkWarn,
-			message: fmt.Sprintf("%s uses npm without cache redirect — kindling will auto-patch for Kaniko", path),
-		})
-	}
+	return findingChecks(ci.KanikoFindings(path, content))
+}
 
-	// Go build without -buildvcs=false
-	if strings.Contains(content, "go build") && !strings.Contains(content, "-buildvcs=false") {
-		results = append(results, checkResult{
-			status:  checkWarn,
-			message: fmt.Sprintf("%s has 'go build' without -buildvcs=false — kindling will auto-patch for Kaniko", path),
-		})
+// findingChecks converts analyzer findings to check results.
+func findingChecks(findings []ci.Finding) []checkResult {
+	var results []checkResult
+	for _, f := range findings {
+		status := checkInfo
+		switch f.Severity {
+		case ci.SeverityError:
+			status = checkFail
+		case ci.SeverityWarning:
+			status = checkWarn
+		}
+		results = append(results, checkResult{status: status, message: f.Message, fix: f.Fix})
 	}
-
 	return results
 }
 
@@ -999,15 +982,14 @@ func checkBuildContext(dockerfilePath, content string) []checkResult {
 
 // ── Output formatting ───────────────────────────────────────────
 
-func printChecklist(checks []checkResult) {
-	passCount, warnCount, failCount := 0, 0, 0
-
+// printCheckResults prints one line per check, with its fix when showFix is
+// set, and returns the warning and failure counts.
+func printCheckResults(checks []checkResult, showFix bool) (warnCount, failCount int) {
 	for _, c := range checks {
 		var prefix string
 		switch c.status {
 		case checkPass:
 			prefix = fmt.Sprintf("  %s✅%s", colorGreen, colorReset)
-			passCount++
 		case checkWarn:
 			prefix = fmt.Sprintf("  %s⚠️ %s", colorYellow, colorReset)
 			warnCount++
@@ -1020,10 +1002,15 @@ func printChecklist(checks []checkResult) {
 
 		fmt.Fprintf(os.Stderr, "%s %s\n", prefix, c.message)
 
-		if analyzeFix && c.fix != "" {
+		if showFix && c.fix != "" {
 			fmt.Fprintf(os.Stderr, "     %s→ %s%s\n", colorDim, c.fix, colorReset)
 		}
 	}
+	return warnCount, failCount
+}
+
+func printChecklist(checks []checkResult) {
+	warnCount, failCount := printCheckResults(checks, analyzeFix)
 
 	// Summary
 	fmt.Fprintln(os.Stderr)
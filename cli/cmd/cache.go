@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage kindling's local caches",
+	Long: `kindling keeps two caches under ~/.kindling:
+
+  build-cache/  per-project build output reused by kindling sync, so
+                repeated syncs of a compiled app rebuild incrementally
+  scan-cache/   kindling generate's repo scan results`,
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Delete the build and scan caches",
+	Long: `Deletes ~/.kindling/build-cache and ~/.kindling/scan-cache. The next
+kindling sync does a full build and the next kindling generate rescans
+the repo.
+
+Examples:
+  kindling cache clean`,
+	RunE: runCacheClean,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheCleanCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheClean(cmd *cobra.Command, args []string) error {
+	buildDir, err := buildCacheRoot()
+	if err != nil {
+		return err
+	}
+	scanDir, err := scanCacheDir()
+	if err != nil {
+		return err
+	}
+
+	header("Cleaning caches")
+	for _, dir := range []string{buildDir, scanDir} {
+		size := dirSize(dir)
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("cannot remove %s: %w", dir, err)
+		}
+		step("🧹", fmt.Sprintf("%s (%s)", dir, formatBytes(size)))
+	}
+	success("Caches cleared")
+	return nil
+}
+
+// buildCacheRoot returns ~/.kindling/build-cache.
+func buildCacheRoot() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".kindling", "build-cache"), nil
+}
+
+// buildCacheDir returns (and creates) the build cache directory for the
+// project at srcDir: ~/.kindling/build-cache/<hash of its absolute path>.
+// Build outputs written there survive between syncs, so toolchains that
+// only rebuild what changed can do so.
+func buildCacheDir(srcDir string) (string, error) {
+	root, err := buildCacheRoot()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(srcDir)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	dir := filepath.Join(root, hex.EncodeToString(sum[:])[:16])
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// dirSize returns the total size of the regular files under dir (0 if it
+// doesn't exist).
+func dirSize(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// formatBytes renders n as a short human-readable size (e.g. "12.3 MB").
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -2,13 +2,21 @@ package cmd
 
 import (
 	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jeffvincent/kindling/cli/core"
+	"github.com/jeffvincent/kindling/pkg/ci"
 )
 
 // ────────────────────────────────────────────────────────────────────────────
@@ -143,6 +151,11 @@ func TestStripKindlingTag(t *testing.T) {
 			input:    "[kindling:orders]",
 			expected: "",
 		},
+		{
+			name:     "image tag and no-deploy markers",
+			input:    "wip\n\n[kindling:orders]\n[kindling-tag:pr-42]\n[kindling-no-deploy]",
+			expected: "wip\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -155,6 +168,37 @@ func TestStripKindlingTag(t *testing.T) {
 	}
 }
 
+func TestPushMarkers(t *testing.T) {
+	got := pushMarkers([]string{"orders", "gateway"}, "pr-42", true)
+	want := []string{"[kindling:orders,gateway]", "[kindling-tag:pr-42]", "[kindling-no-deploy]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pushMarkers() = %v, want %v", got, want)
+	}
+	if got := pushMarkers(nil, "", false); got != nil {
+		t.Errorf("pushMarkers() with no options = %v, want nil", got)
+	}
+	if got := pushMarkers(nil, "", true); !reflect.DeepEqual(got, []string{"[kindling-no-deploy]"}) {
+		t.Errorf("pushMarkers(noDeploy) = %v", got)
+	}
+}
+
+func TestImageTagPattern(t *testing.T) {
+	for tag, ok := range map[string]bool{
+		"pr-42":                  true,
+		"v1.2.3":                 true,
+		"jeff_dev":               true,
+		"-leading-dash":          false,
+		".hidden":                false,
+		"has space":              false,
+		"with/slash":             false,
+		strings.Repeat("a", 129): false,
+	} {
+		if got := imageTagPattern.MatchString(tag); got != ok {
+			t.Errorf("imageTagPattern.MatchString(%q) = %v, want %v", tag, got, ok)
+		}
+	}
+}
+
 // ────────────────────────────────────────────────────────────────────────────
 // kindlingSecretName (secrets.go)
 // ────────────────────────────────────────────────────────────────────────────
@@ -344,7 +388,7 @@ func TestVersionFormat(t *testing.T) {
 // ────────────────────────────────────────────────────────────────────────────
 
 func TestCallGenAI_UnsupportedProvider(t *testing.T) {
-	_, err := callGenAI("azure", "key", "model", "sys", "usr")
+	_, err := callGenAI("cohere", "key", "model", "sys", "usr")
 	if err == nil {
 		t.Error("should return error for unsupported provider")
 	}
@@ -352,3 +396,780 @@ func TestCallGenAI_UnsupportedProvider(t *testing.T) {
 		t.Errorf("error should mention unsupported provider, got %q", err.Error())
 	}
 }
+
+func TestAzureChatURL(t *testing.T) {
+	tests := []struct {
+		base, deployment, version, want string
+	}{
+		{"https://myres.openai.azure.com", "gpt4o", "2024-10-21",
+			"https://myres.openai.azure.com/openai/deployments/gpt4o/chat/completions?api-version=2024-10-21"},
+		{"https://myres.openai.azure.com/", "gpt4o", "",
+			"https://myres.openai.azure.com/openai/deployments/gpt4o/chat/completions?api-version=" + defaultAzureAPIVersion},
+		{"https://myres.openai.azure.com/openai", "o3-mini", "2025-01-01-preview",
+			"https://myres.openai.azure.com/openai/deployments/o3-mini/chat/completions?api-version=2025-01-01-preview"},
+	}
+	for _, tt := range tests {
+		got, err := azureChatURL(tt.base, tt.deployment, tt.version)
+		if err != nil {
+			t.Fatalf("azureChatURL(%q) error: %v", tt.base, err)
+		}
+		if got != tt.want {
+			t.Errorf("azureChatURL(%q, %q, %q) = %q, want %q", tt.base, tt.deployment, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestAzureChatURL_RequiresBaseURL(t *testing.T) {
+	if _, err := azureChatURL("", "gpt4o", ""); err == nil {
+		t.Error("should require a base URL")
+	}
+}
+
+func TestCallAzureOpenAI_HeadersAndParsing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/dep1/chat/completions" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.URL.Query().Get("api-version") != "2024-10-21" {
+			t.Errorf("unexpected api-version %q", r.URL.Query().Get("api-version"))
+		}
+		if r.Header.Get("api-key") != "secret" {
+			t.Errorf("api-key header = %q", r.Header.Get("api-key"))
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("Azure requests should not send a bearer token")
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"name: dev-deploy"}}]}`)
+	}))
+	defer srv.Close()
+
+	out, err := callAzureOpenAI(srv.URL, "2024-10-21", "secret", "dep1", "sys", "usr")
+	if err != nil {
+		t.Fatalf("callAzureOpenAI error: %v", err)
+	}
+	if out != "name: dev-deploy" {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestCallAzureOpenAI_StatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, "slow down")
+	}))
+	defer srv.Close()
+
+	_, err := callAzureOpenAI(srv.URL, "", "secret", "dep1", "sys", "usr")
+	if !isTransientAPIError(err) {
+		t.Errorf("429 should be transient, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Azure OpenAI") {
+		t.Errorf("error should name the provider, got %q", err.Error())
+	}
+}
+
+func TestReadOpenAIStream(t *testing.T) {
+	stream := "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+		"data: {\"choices\":[{\"delta\":{\"content\":\"name: \"}}]}\n\n" +
+		": keep-alive\n\n" +
+		"data: {\"choices\":[{\"delta\":{\"content\":\"dev-deploy\"}}]}\n\n" +
+		"data: [DONE]\n\n"
+	var out strings.Builder
+	got, err := readOpenAIStream(strings.NewReader(stream), "OpenAI", &out)
+	if err != nil {
+		t.Fatalf("readOpenAIStream error: %v", err)
+	}
+	if got != "name: dev-deploy" {
+		t.Errorf("got %q", got)
+	}
+	if out.String() != "name: dev-deploy\n" {
+		t.Errorf("streamed %q", out.String())
+	}
+}
+
+func TestReadOpenAIStream_Error(t *testing.T) {
+	stream := "data: {\"error\":{\"message\":\"context too long\"}}\n\n"
+	if _, err := readOpenAIStream(strings.NewReader(stream), "OpenAI", io.Discard); err == nil || !strings.Contains(err.Error(), "context too long") {
+		t.Errorf("expected the stream error, got %v", err)
+	}
+	if _, err := readOpenAIStream(strings.NewReader("data: [DONE]\n\n"), "OpenAI", io.Discard); err != nil {
+		t.Errorf("an empty but finished stream is not an error, got %v", err)
+	}
+	truncated := "data: {\"choices\":[{\"delta\":{\"content\":\"name: \"}}]}\n\n"
+	if _, err := readOpenAIStream(strings.NewReader(truncated), "OpenAI", io.Discard); err == nil {
+		t.Error("a stream cut off before [DONE] should be an error")
+	}
+}
+
+func TestReadAnthropicStream(t *testing.T) {
+	stream := "event: message_start\ndata: {\"type\":\"message_start\"}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"name: \"}}\n\n" +
+		"event: ping\ndata: {\"type\":\"ping\"}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"dev-deploy\"}}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"
+	var out strings.Builder
+	got, err := readAnthropicStream(strings.NewReader(stream), &out)
+	if err != nil {
+		t.Fatalf("readAnthropicStream error: %v", err)
+	}
+	if got != "name: dev-deploy" || out.String() != "name: dev-deploy\n" {
+		t.Errorf("got %q, streamed %q", got, out.String())
+	}
+}
+
+func TestReadAnthropicStream_OverloadedIsTransient(t *testing.T) {
+	stream := "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"
+	_, err := readAnthropicStream(strings.NewReader(stream), io.Discard)
+	if !isTransientAPIError(err) {
+		t.Errorf("overloaded_error should be retried, got %v", err)
+	}
+
+	stream = "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"invalid_request_error\",\"message\":\"bad\"}}\n\n"
+	if _, err := readAnthropicStream(strings.NewReader(stream), io.Discard); err == nil || isTransientAPIError(err) {
+		t.Errorf("invalid_request_error should fail fast, got %v", err)
+	}
+}
+
+func TestCallAzureOpenAI_Streams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Stream {
+			t.Errorf("expected a streaming request, got %+v (%v)", req, err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"name: dev-deploy\"}}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	var streamed strings.Builder
+	genStreamOut = &streamed
+	defer func() { genStreamOut = nil }()
+
+	out, err := callAzureOpenAI(srv.URL, "", "secret", "dep1", "sys", "usr")
+	if err != nil {
+		t.Fatalf("callAzureOpenAI error: %v", err)
+	}
+	if out != "name: dev-deploy" || streamed.String() != "name: dev-deploy\n" {
+		t.Errorf("got %q, streamed %q", out, streamed.String())
+	}
+}
+
+func TestDefaultModel(t *testing.T) {
+	tests := map[string]string{
+		"openai":    "o3",
+		"anthropic": "claude-sonnet-4-20250514",
+		"azure":     "gpt-4o",
+		"gemini":    "gemini-2.5-pro",
+		"ollama":    "qwen2.5-coder:14b",
+		"":          "o3",
+	}
+	for provider, want := range tests {
+		if got := defaultModel(provider); got != want {
+			t.Errorf("defaultModel(%q) = %q, want %q", provider, got, want)
+		}
+	}
+}
+
+func TestParseGeminiResponse(t *testing.T) {
+	body := `{"candidates":[{"content":{"role":"model","parts":[{"text":"name: dev"},{"text":"-deploy"}]},"finishReason":"STOP"}]}`
+	got, err := parseGeminiResponse([]byte(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "name: dev-deploy" {
+		t.Errorf("got %q, want %q", got, "name: dev-deploy")
+	}
+}
+
+func TestParseGeminiResponse_Error(t *testing.T) {
+	body := `{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}`
+	_, err := parseGeminiResponse([]byte(body))
+	if err == nil {
+		t.Fatal("expected error for error envelope")
+	}
+	if !strings.Contains(err.Error(), "API key not valid") {
+		t.Errorf("error should include API message, got %q", err.Error())
+	}
+}
+
+func TestParseGeminiResponse_NoCandidates(t *testing.T) {
+	if _, err := parseGeminiResponse([]byte(`{"candidates":[]}`)); err == nil {
+		t.Error("expected error when no candidates are returned")
+	}
+}
+
+func TestProviderNeedsAPIKey(t *testing.T) {
+	for _, p := range []string{"openai", "anthropic", "gemini", ""} {
+		if !providerNeedsAPIKey(p) {
+			t.Errorf("providerNeedsAPIKey(%q) = false, want true", p)
+		}
+	}
+	if providerNeedsAPIKey("ollama") {
+		t.Error("providerNeedsAPIKey(\"ollama\") = true, want false")
+	}
+}
+
+func TestTrimSystemPromptForLocal(t *testing.T) {
+	full := ci.Default().Workflow().SystemPrompt("amd64")
+	trimmed := trimSystemPromptForLocal(full)
+
+	if len(trimmed) >= len(full) {
+		t.Fatalf("trimmed prompt (%d bytes) should be shorter than full prompt (%d bytes)", len(trimmed), len(full))
+	}
+	for _, section := range localPromptOmissions {
+		if strings.Contains(trimmed, section) {
+			t.Errorf("trimmed prompt still contains omitted section starting %q", section[:40])
+		}
+	}
+	// Core build/deploy rules must survive the trim
+	for _, keep := range []string{ci.PromptDeployInputs, ci.PromptDockerfileExistence, ci.PromptFinalValidation} {
+		if !strings.Contains(trimmed, keep) {
+			t.Errorf("trimmed prompt is missing required section starting %q", keep[:40])
+		}
+	}
+}
+
+func TestParseModelChain(t *testing.T) {
+	got := parseModelChain(" o3, gpt-4o ,,gpt-4o-mini ")
+	want := []string{"o3", "gpt-4o", "gpt-4o-mini"}
+	if len(got) != len(want) {
+		t.Fatalf("parseModelChain() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseModelChain()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if len(parseModelChain("")) != 0 {
+		t.Error("parseModelChain(\"\") should be empty")
+	}
+}
+
+func TestIsTransientAPIError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&apiStatusError{provider: "OpenAI", code: 429}, true},
+		{&apiStatusError{provider: "OpenAI", code: 503}, true},
+		{&apiStatusError{provider: "OpenAI", code: 401}, false},
+		{&apiStatusError{provider: "OpenAI", code: 400}, false},
+		{fmt.Errorf("wrapped: %w", &apiStatusError{provider: "Anthropic", code: 529}), true},
+		{fmt.Errorf("API request failed"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransientAPIError(tt.err); got != tt.want {
+			t.Errorf("isTransientAPIError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestAPIStatusError_Message(t *testing.T) {
+	err := &apiStatusError{provider: "OpenAI", code: 429, body: "rate limited"}
+	if err.Error() != "OpenAI API returned HTTP 429: rate limited" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	err := &apiStatusError{provider: "OpenAI", code: 503}
+	for attempt, want := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		if got := retryDelay(err, attempt); got != want {
+			t.Errorf("retryDelay(attempt %d) = %v, want %v", attempt, got, want)
+		}
+	}
+	if got := retryDelay(err, 40); got != genRetryMaxDelay {
+		t.Errorf("backoff should be capped at %v, got %v", genRetryMaxDelay, got)
+	}
+	err.retryAfter = 5 * time.Second
+	if got := retryDelay(err, 2); got != 5*time.Second {
+		t.Errorf("Retry-After should win over backoff, got %v", got)
+	}
+	err.retryAfter = time.Hour
+	if got := retryDelay(err, 0); got != genRetryMaxDelay {
+		t.Errorf("Retry-After should be capped at %v, got %v", genRetryMaxDelay, got)
+	}
+}
+
+// withFastRetries points callGenAI's ollama provider at url and shrinks the
+// backoff so retry tests run instantly.
+func withFastRetries(t *testing.T, url string, maxRetries int) {
+	t.Helper()
+	oldBase, oldDelay, oldMax := genBaseURL, genRetryBaseDelay, genMaxRetries
+	genBaseURL, genRetryBaseDelay, genMaxRetries = url, time.Millisecond, maxRetries
+	t.Cleanup(func() { genBaseURL, genRetryBaseDelay, genMaxRetries = oldBase, oldDelay, oldMax })
+}
+
+func TestCallGenAI_RetriesTransientErrors(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, `{"message":{"content":"name: dev-deploy"}}`)
+		}
+	}))
+	defer srv.Close()
+	withFastRetries(t, srv.URL, 3)
+
+	out, err := callGenAI("ollama", "", "m", "sys", "usr")
+	if err != nil {
+		t.Fatalf("callGenAI error: %v", err)
+	}
+	if out != "name: dev-deploy" || calls != 3 {
+		t.Errorf("got %q after %d calls, want success on the 3rd", out, calls)
+	}
+}
+
+func TestCallGenAI_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	withFastRetries(t, srv.URL, 2)
+
+	_, err := callGenAI("ollama", "", "m", "sys", "usr")
+	if !isTransientAPIError(err) {
+		t.Errorf("expected the last 502, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 1 call + 2 retries, got %d calls", calls)
+	}
+}
+
+func TestCallGenAI_FailsFastOnPermanentErrors(t *testing.T) {
+	for _, code := range []int{http.StatusBadRequest, http.StatusUnauthorized} {
+		var calls int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(code)
+		}))
+		withFastRetries(t, srv.URL, 3)
+
+		if _, err := callGenAI("ollama", "", "m", "sys", "usr"); err == nil {
+			t.Errorf("HTTP %d: expected an error", code)
+		}
+		if calls != 1 {
+			t.Errorf("HTTP %d should not be retried, got %d calls", code, calls)
+		}
+		srv.Close()
+	}
+}
+
+func TestCallGenAIWithFallback_StopsOnPermanentError(t *testing.T) {
+	_, model, err := callGenAIWithFallback("bogus", "key", []string{"a", "b"}, "sys", "usr", nil)
+	if err == nil {
+		t.Fatal("expected error for unsupported provider")
+	}
+	if model != "a" {
+		t.Errorf("chain should stop at the first model on a permanent error, stopped at %q", model)
+	}
+}
+
+func TestCallGenAIWithFallback_NoModels(t *testing.T) {
+	if _, _, err := callGenAIWithFallback("openai", "key", nil, "sys", "usr", nil); err == nil {
+		t.Error("expected error when no models are given")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Non-HTTP ingress backends (expose.go)
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestNonHTTPPortReason(t *testing.T) {
+	tests := []struct {
+		name, appProtocol, protocol string
+		port                        int32
+		want                        string
+	}{
+		{"http", "", "TCP", 8080, ""},
+		{"grpc", "", "TCP", 9000, "gRPC"},
+		{"grpc-api", "", "TCP", 9000, "gRPC"},
+		{"tcp-metrics", "", "TCP", 9000, "TCP"},
+		{"", "kubernetes.io/grpc", "TCP", 8080, "gRPC"},
+		{"grpc", "http", "TCP", 9000, ""},
+		{"", "", "UDP", 8080, "UDP"},
+		{"", "", "TCP", 50051, "gRPC"},
+		{"", "", "TCP", 5432, "PostgreSQL"},
+		{"grpcish", "", "TCP", 8080, ""},
+	}
+	for _, tt := range tests {
+		if got := nonHTTPPortReason(tt.name, tt.appProtocol, tt.protocol, tt.port); got != tt.want {
+			t.Errorf("nonHTTPPortReason(%q, %q, %q, %d) = %q, want %q",
+				tt.name, tt.appProtocol, tt.protocol, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestFindNonHTTPBackends(t *testing.T) {
+	ingresses := `{"items": [
+	  {"metadata": {"name": "orders"}, "spec": {"rules": [{"http": {"paths": [
+	    {"backend": {"service": {"name": "orders", "port": {"number": 80}}}},
+	    {"backend": {"service": {"name": "orders", "port": {"name": "grpc"}}}}
+	  ]}}]}},
+	  {"metadata": {"name": "web"}, "spec": {"defaultBackend": {"service": {"name": "web", "port": {"number": 3000}}}}}
+	]}`
+	services := `{"items": [
+	  {"metadata": {"name": "orders"}, "spec": {"ports": [
+	    {"name": "http", "port": 80, "protocol": "TCP"},
+	    {"name": "grpc", "port": 50051, "protocol": "TCP"}
+	  ]}},
+	  {"metadata": {"name": "web"}, "spec": {"ports": [{"name": "http", "port": 3000, "protocol": "TCP"}]}}
+	]}`
+
+	got, err := findNonHTTPBackends([]byte(ingresses), []byte(services), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("findNonHTTPBackends() = %+v, want one backend", got)
+	}
+	if got[0].ingress != "orders" || got[0].service != "orders" || got[0].port != 50051 || got[0].reason != "gRPC" {
+		t.Errorf("backend = %+v", got[0])
+	}
+
+	got, _ = findNonHTTPBackends([]byte(ingresses), []byte(services), "web")
+	if len(got) != 0 {
+		t.Errorf("--service web should find nothing, got %+v", got)
+	}
+
+	if _, err := findNonHTTPBackends([]byte("nope"), []byte(services), ""); err == nil {
+		t.Error("invalid JSON should fail")
+	}
+}
+
+func TestBuildDSETunnelBindOps(t *testing.T) {
+	dse := `{"metadata": {"name": "auth", "annotations": {"team": "id"}},
+	  "spec": {"ingress": {"enabled": true, "host": "auth.localhost", "tls": {"secretName": "auth-tls"}}}}`
+	ops, err := buildDSETunnelBindOps([]byte(dse), "abc.trycloudflare.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(ops)
+	want := `[{"op":"add","path":"/metadata/annotations/kindling.dev~1original-host","value":"auth.localhost"},` +
+		`{"op":"add","path":"/metadata/annotations/kindling.dev~1original-tls","value":"{\"secretName\": \"auth-tls\"}"},` +
+		`{"op":"remove","path":"/spec/ingress/tls"},` +
+		`{"op":"add","path":"/spec/ingress/host","value":"abc.trycloudflare.com"}]`
+	if string(got) != want {
+		t.Errorf("bind ops =\n%s\nwant\n%s", got, want)
+	}
+
+	// Already bound: only the host moves, the saved original is kept.
+	bound := `{"metadata": {"name": "auth", "annotations": {"kindling.dev/original-host": "auth.localhost"}},
+	  "spec": {"ingress": {"enabled": true, "host": "old.trycloudflare.com"}}}`
+	ops, _ = buildDSETunnelBindOps([]byte(bound), "new.trycloudflare.com")
+	if len(ops) != 1 || ops[0]["value"] != "new.trycloudflare.com" {
+		t.Errorf("rebind ops = %v", ops)
+	}
+
+	// No annotations yet: the map is created first.
+	bare := `{"metadata": {"name": "auth"}, "spec": {"ingress": {"enabled": true}}}`
+	ops, _ = buildDSETunnelBindOps([]byte(bare), "abc.trycloudflare.com")
+	if len(ops) != 3 || ops[0]["path"] != "/metadata/annotations" {
+		t.Errorf("bare ops = %v", ops)
+	}
+
+	if _, err := buildDSETunnelBindOps([]byte(`{"metadata": {"name": "worker"}, "spec": {}}`), "x"); err == nil ||
+		!strings.Contains(err.Error(), "spec.ingress.enabled") {
+		t.Errorf("no ingress: err = %v", err)
+	}
+}
+
+func TestBuildDSETunnelRestoreOps(t *testing.T) {
+	if ops := buildDSETunnelRestoreOps(map[string]string{"team": "id"}); ops != nil {
+		t.Errorf("unbound DSE should need no restore, got %v", ops)
+	}
+
+	ops := buildDSETunnelRestoreOps(map[string]string{
+		originalHostAnnotation: "auth.localhost",
+		originalTLSAnnotation:  `{"secretName":"auth-tls"}`,
+	})
+	got, _ := json.Marshal(ops)
+	want := `[{"op":"remove","path":"/metadata/annotations/kindling.dev~1original-host"},` +
+		`{"op":"add","path":"/spec/ingress/host","value":"auth.localhost"},` +
+		`{"op":"add","path":"/spec/ingress/tls","value":{"secretName":"auth-tls"}},` +
+		`{"op":"remove","path":"/metadata/annotations/kindling.dev~1original-tls"}]`
+	if string(got) != want {
+		t.Errorf("restore ops =\n%s\nwant\n%s", got, want)
+	}
+
+	// An ingress that had no host gets none back.
+	ops = buildDSETunnelRestoreOps(map[string]string{originalHostAnnotation: ""})
+	if len(ops) != 2 || ops[1]["op"] != "remove" || ops[1]["path"] != "/spec/ingress/host" {
+		t.Errorf("hostless restore ops = %v", ops)
+	}
+}
+
+func TestTunnelRoutedNames(t *testing.T) {
+	list := `{"items": [
+	  {"metadata": {"name": "auth", "annotations": {"kindling.dev/original-host": "auth.localhost"}}},
+	  {"metadata": {"name": "api", "annotations": {"team": "core"}}},
+	  {"metadata": {"name": "web"}}
+	]}`
+	got, err := tunnelRoutedNames([]byte(list))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "auth" {
+		t.Errorf("tunnelRoutedNames = %v, want [auth]", got)
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Dependency catalog (deps.go)
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestWriteDepsTable(t *testing.T) {
+	var buf strings.Builder
+	deps := []ci.DependencyInfo{
+		{Type: "postgres", Image: "postgres", DefaultVersion: "16", Port: 5432, EnvVars: []string{"DATABASE_URL"}},
+		{Type: "mailpit", Image: "axllent/mailpit", Port: 1025, EnvVars: []string{"SMTP_URL", "SMTP_HOST"}},
+	}
+	if err := writeDepsTable(&buf, deps); err != nil {
+		t.Fatal(err)
+	}
+	want := "TYPE      IMAGE            PORT  INJECTED ENV\n" +
+		"postgres  postgres:16      5432  DATABASE_URL\n" +
+		"mailpit   axllent/mailpit  1025  SMTP_URL, SMTP_HOST\n"
+	if buf.String() != want {
+		t.Errorf("writeDepsTable =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Environment summary (status.go)
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestParseEnvStatuses(t *testing.T) {
+	dses := `{"items": [
+	  {"metadata": {"name": "orders.api", "namespace": "default"},
+	   "spec": {"deployment": {"replicas": 2}, "dependencies": [
+	     {"type": "postgres"},
+	     {"type": "rabbitmq", "shared": true}
+	   ]},
+	   "status": {"availableReplicas": 2, "url": "http://orders.localhost",
+	     "dependencyURLs": {"postgres": "postgres://x", "rabbitmq": "amqp://y", "rabbitmq-ui": "http://ui"},
+	     "conditions": [{"type": "DeploymentReady", "status": "True"}, {"type": "Ready", "status": "True", "reason": "AllResourcesReady"}]}},
+	  {"metadata": {"name": "billing", "namespace": "default"},
+	   "spec": {"deployment": {}},
+	   "status": {"conditions": [{"type": "Ready", "status": "False", "reason": "ResourcesNotReady"}]}},
+	  {"metadata": {"name": "fresh", "namespace": "default"}, "spec": {"deployment": {}}}
+	]}`
+	deploys := `{"items": [
+	  {"metadata": {"name": "orders-api-postgres", "namespace": "default"}, "status": {"readyReplicas": 1}},
+	  {"metadata": {"name": "shared-rabbitmq", "namespace": "default"}, "status": {}}
+	]}`
+
+	envs, err := parseEnvStatuses([]byte(dses), []byte(deploys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs) != 3 || envs[0].Name != "billing" || envs[1].Name != "fresh" || envs[2].Name != "orders.api" {
+		t.Fatalf("envs not sorted by name: %+v", envs)
+	}
+
+	billing, fresh, orders := envs[0], envs[1], envs[2]
+	if billing.Ready || billing.Reason != "ResourcesNotReady" || billing.Replicas != 1 {
+		t.Errorf("billing = %+v", billing)
+	}
+	if fresh.Ready || fresh.Reason != "no status yet" {
+		t.Errorf("fresh = %+v", fresh)
+	}
+	if !orders.Ready || orders.Reason != "" || orders.Replicas != 2 || orders.AvailableReplicas != 2 || orders.URL != "http://orders.localhost" {
+		t.Errorf("orders = %+v", orders)
+	}
+	want := []envDependencyInfo{
+		{Type: "postgres", Name: "orders-api-postgres", Ready: true, URL: "postgres://x"},
+		{Type: "rabbitmq", Name: "shared-rabbitmq", Ready: false, URL: "amqp://y", UIURL: "http://ui"},
+	}
+	if len(orders.Dependencies) != len(want) {
+		t.Fatalf("dependencies = %+v", orders.Dependencies)
+	}
+	for i := range want {
+		if orders.Dependencies[i] != want[i] {
+			t.Errorf("dependency %d = %+v, want %+v", i, orders.Dependencies[i], want[i])
+		}
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Readiness polling (wait.go)
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestParseDSEProgress(t *testing.T) {
+	single := `{"kind": "DevStagingEnvironment", "metadata": {"name": "orders", "namespace": "default"},
+	  "status": {"deploymentReady": true, "serviceReady": true, "dependenciesReady": true,
+	    "conditions": [{"type": "Ready", "status": "True", "message": "all good"}]}}`
+	got, err := parseDSEProgress([]byte(single))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []dseProgress{{Name: "orders", Namespace: "default", DeploymentReady: true, ServiceReady: true, DependenciesReady: true, Ready: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("single = %+v, want %+v", got, want)
+	}
+
+	// kubectl get -f returns a List that may include other kinds.
+	list := `{"kind": "List", "items": [
+	  {"kind": "ConfigMap", "metadata": {"name": "orders-config"}},
+	  {"kind": "DevStagingEnvironment", "metadata": {"name": "gateway", "namespace": "dev"},
+	   "status": {"serviceReady": true,
+	     "conditions": [{"type": "Ready", "status": "False", "message": "One or more child resources are not yet ready"}]}},
+	  {"kind": "DevStagingEnvironment", "metadata": {"name": "fresh", "namespace": "dev"}}
+	]}`
+	got, err = parseDSEProgress([]byte(list))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "gateway" || got[1].Name != "fresh" {
+		t.Fatalf("list = %+v, want gateway and fresh only", got)
+	}
+	if got[0].Ready || got[0].Message != "One or more child resources are not yet ready" {
+		t.Errorf("gateway = %+v", got[0])
+	}
+	if pending := got[0].pendingSteps(); !reflect.DeepEqual(pending, []string{"DeploymentReady", "DependenciesReady", "Ready"}) {
+		t.Errorf("gateway pendingSteps = %v", pending)
+	}
+	if pending := got[1].pendingSteps(); len(pending) != 4 {
+		t.Errorf("fresh pendingSteps = %v, want every step", pending)
+	}
+
+	if _, err := parseDSEProgress([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Environment logs (logs.go)
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestEnvLogSources(t *testing.T) {
+	dse := `{"metadata": {"name": "orders.api"}, "spec": {"dependencies": [
+	  {"type": "postgres"},
+	  {"type": "redis", "shared": true, "sharedName": "team-redis"}
+	]}}`
+
+	sources, err := envLogSources([]byte(dse), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || sources[0].prefix != "app" ||
+		sources[0].selector != "app.kubernetes.io/instance=orders.api,app.kubernetes.io/managed-by=devstagingenvironment-operator" {
+		t.Errorf("app-only sources = %+v", sources)
+	}
+
+	sources, _ = envLogSources([]byte(dse), true)
+	want := []logSource{
+		sources[0],
+		{prefix: "postgres", selector: "app.kubernetes.io/name=orders-api-postgres"},
+		{prefix: "redis", selector: "app.kubernetes.io/name=team-redis"},
+	}
+	if len(sources) != len(want) {
+		t.Fatalf("sources = %+v", sources)
+	}
+	for i := range want {
+		if sources[i] != want[i] {
+			t.Errorf("source %d = %+v, want %+v", i, sources[i], want[i])
+		}
+	}
+}
+
+func TestFormatLogLine(t *testing.T) {
+	got := formatLogLine("app", 8, colorCyan, "listening on :8080")
+	want := colorCyan + "app     " + colorReset + " │ listening on :8080"
+	if got != want {
+		t.Errorf("formatLogLine() = %q, want %q", got, want)
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Restart (restart.go)
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestDependencyDeployment(t *testing.T) {
+	dse := []byte(`{"metadata": {"name": "orders.api"}, "spec": {"dependencies": [
+	  {"type": "postgres"},
+	  {"type": "redis", "shared": true, "sharedName": "team-redis"}
+	]}}`)
+
+	cases := map[string]string{"postgres": "orders-api-postgres", "redis": "team-redis"}
+	for depType, want := range cases {
+		got, err := dependencyDeployment(dse, depType)
+		if err != nil || got != want {
+			t.Errorf("dependencyDeployment(%q) = %q, %v; want %q", depType, got, err, want)
+		}
+	}
+	if _, err := dependencyDeployment(dse, "kafka"); err == nil {
+		t.Error("expected an error for an undeclared dependency")
+	}
+}
+
+func TestMultiNodeKindConfig(t *testing.T) {
+	base := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+  - role: control-plane
+    extraPortMappings:
+      - containerPort: 80
+        hostPort: 80
+# trailing note
+`
+	got, err := multiNodeKindConfig(base, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+  - role: control-plane
+    extraPortMappings:
+      - containerPort: 80
+        hostPort: 80
+  - role: worker
+  - role: worker
+# trailing note
+`
+	if got != want {
+		t.Errorf("multiNodeKindConfig =\n%s\nwant\n%s", got, want)
+	}
+
+	// A key after the nodes list stays after the workers.
+	got, err = multiNodeKindConfig("nodes:\n- role: control-plane\nnetworking:\n  ipFamily: ipv4\n", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "nodes:\n- role: control-plane\n- role: worker\nnetworking:\n  ipFamily: ipv4\n"; got != want {
+		t.Errorf("multiNodeKindConfig = %q, want %q", got, want)
+	}
+
+	if _, err := multiNodeKindConfig("kind: Cluster\n", 1); err == nil {
+		t.Error("expected an error for a config without nodes")
+	}
+}
@@ -140,6 +140,13 @@ export const BrowserIcon = (): ReactNode => (
   </svg>
 );
 
+export const ClickHouseIcon = (): ReactNode => (
+  <svg viewBox="0 0 256 256" style={s}>
+    <rect width="256" height="256" rx="32" fill="#FFCC01"/>
+    <path d="M56 56h20v144H56zm36 0h20v144H92zm36 0h20v144h-20zm36 0h20v144h-20zm36 56h20v32h-20z" fill="#151515"/>
+  </svg>
+);
+
 // Map depType → icon component
 export const DEP_ICONS: Record<string, () => ReactNode> = {
   postgres: PostgresIcon,
@@ -157,4 +164,5 @@ export const DEP_ICONS: Record<string, () => ReactNode> = {
   vault: VaultIcon,
   influxdb: InfluxDBIcon,
   jaeger: JaegerIcon,
+  clickhouse: ClickHouseIcon,
 };
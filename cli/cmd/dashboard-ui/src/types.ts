@@ -376,7 +376,11 @@ export interface IntelStatus {
 export const DEPENDENCY_TYPES = [
   'postgres', 'redis', 'mysql', 'mongodb', 'rabbitmq', 'minio',
   'elasticsearch', 'kafka', 'nats', 'memcached', 'cassandra',
-  'consul', 'vault', 'influxdb', 'jaeger',
+  'consul', 'vault', 'influxdb', 'jaeger', 'clickhouse', 'cockroachdb',
+  'timescaledb', 'mailpit', 'qdrant', 'weaviate', 'chroma', 'localstack',
+  'neo4j', 'mariadb', 'sqlserver', 'etcd', 'prometheus', 'grafana',
+  'arangodb', 'couchdb', 'pulsar', 'meilisearch', 'typesense',
+  'otel-collector', 'zookeeper', 'surrealdb', 'rethinkdb', 'opensearch',
 ] as const;
 
 export type DependencyType = typeof DEPENDENCY_TYPES[number];
@@ -397,6 +401,30 @@ export const DEP_META: Record<DependencyType, { icon: string; label: string; col
   vault:         { icon: '🔐', label: 'Vault',         color: '#000000', defaultPort: 8200, envVar: 'VAULT_ADDR' },
   influxdb:      { icon: '📈', label: 'InfluxDB',      color: '#22ADF6', defaultPort: 8086, envVar: 'INFLUXDB_URL' },
   jaeger:        { icon: '🔭', label: 'Jaeger',        color: '#60D0E4', defaultPort: 16686, envVar: 'JAEGER_URL' },
+  clickhouse:    { icon: '🟨', label: 'ClickHouse',    color: '#FFCC01', defaultPort: 8123, envVar: 'CLICKHOUSE_URL' },
+  cockroachdb:   { icon: '🪳', label: 'CockroachDB',   color: '#6933FF', defaultPort: 26257, envVar: 'DATABASE_URL' },
+  timescaledb:   { icon: '🐯', label: 'TimescaleDB',   color: '#FDB515', defaultPort: 5432, envVar: 'DATABASE_URL' },
+  mailpit:       { icon: '📬', label: 'Mailpit',       color: '#2C3E50', defaultPort: 1025, envVar: 'SMTP_URL' },
+  qdrant:        { icon: '🧭', label: 'Qdrant',        color: '#DC244C', defaultPort: 6333, envVar: 'QDRANT_URL' },
+  weaviate:      { icon: '🕸', label: 'Weaviate',      color: '#00A142', defaultPort: 8080, envVar: 'WEAVIATE_URL' },
+  chroma:        { icon: '🎨', label: 'Chroma',        color: '#FF6446', defaultPort: 8000, envVar: 'CHROMA_URL' },
+  localstack:    { icon: '☁️', label: 'LocalStack',    color: '#2D1A5C', defaultPort: 4566, envVar: 'AWS_ENDPOINT_URL' },
+  neo4j:         { icon: '🔗', label: 'Neo4j',         color: '#018BFF', defaultPort: 7687, envVar: 'NEO4J_URL' },
+  mariadb:       { icon: '🦭', label: 'MariaDB',       color: '#003545', defaultPort: 3306, envVar: 'DATABASE_URL' },
+  sqlserver:     { icon: '🗄', label: 'SQL Server',    color: '#CC2927', defaultPort: 1433, envVar: 'DATABASE_URL' },
+  etcd:          { icon: '🔐', label: 'etcd',          color: '#419EDA', defaultPort: 2379, envVar: 'ETCD_ENDPOINTS' },
+  prometheus:    { icon: '🔥', label: 'Prometheus',    color: '#E6522C', defaultPort: 9090, envVar: 'PROMETHEUS_URL' },
+  grafana:       { icon: '📊', label: 'Grafana',       color: '#F46800', defaultPort: 3000, envVar: 'GRAFANA_URL' },
+  arangodb:      { icon: '🥑', label: 'ArangoDB',      color: '#DDE072', defaultPort: 8529, envVar: 'ARANGO_URL' },
+  couchdb:       { icon: '🛋', label: 'CouchDB',       color: '#E42528', defaultPort: 5984, envVar: 'COUCHDB_URL' },
+  pulsar:        { icon: '🌀', label: 'Pulsar',        color: '#188FFF', defaultPort: 6650, envVar: 'PULSAR_URL' },
+  meilisearch:   { icon: '🔎', label: 'Meilisearch',   color: '#FF5CAA', defaultPort: 7700, envVar: 'MEILISEARCH_URL' },
+  typesense:     { icon: '⌨️', label: 'Typesense',     color: '#D52C71', defaultPort: 8108, envVar: 'TYPESENSE_URL' },
+  'otel-collector': { icon: '🛰', label: 'OTel Collector', color: '#425CC7', defaultPort: 4318, envVar: 'OTEL_EXPORTER_OTLP_ENDPOINT' },
+  zookeeper:     { icon: '🦓', label: 'ZooKeeper',     color: '#D22128', defaultPort: 2181, envVar: 'ZOOKEEPER_URL' },
+  surrealdb:     { icon: '🌌', label: 'SurrealDB',     color: '#FF00A0', defaultPort: 8000, envVar: 'SURREAL_URL' },
+  rethinkdb:     { icon: '🔁', label: 'RethinkDB',     color: '#5C9BA6', defaultPort: 28015, envVar: 'RETHINKDB_URL' },
+  opensearch:    { icon: '🔎', label: 'OpenSearch',    color: '#005EB8', defaultPort: 9200, envVar: 'OPENSEARCH_URL' },
 };
 
 export interface TopologyNodeData {
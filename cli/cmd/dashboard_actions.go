@@ -18,6 +18,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/jeffvincent/kindling/cli/core"
+	"github.com/jeffvincent/kindling/pkg/ci"
 )
 
 // actionResult is the standard JSON envelope for mutation endpoints.
@@ -316,7 +317,7 @@ func handleExposeAction(w http.ResponseWriter, r *http.Request) {
 		}
 		// Stale PID — clean up before starting fresh.
 		core.CleanupTunnel(clusterName)
-		restoreIngresses()
+		restoreTunnelRoutes()
 	}
 
 	// Parse optional service from body.
@@ -371,7 +372,7 @@ func handleExposeStatus(w http.ResponseWriter, r *http.Request) {
 	if !core.ProcessAlive(info.PID) {
 		// Stale — clean up.
 		core.CleanupTunnel(clusterName)
-		restoreIngresses()
+		restoreTunnelRoutes()
 		jsonResponse(w, status{})
 		return
 	}
@@ -1181,7 +1182,7 @@ func handleGenerate(w http.ResponseWriter, r *http.Request) {
 		actionErr(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
-	if body.APIKey == "" {
+	if body.APIKey == "" && providerNeedsAPIKey(body.Provider) {
 		actionErr(w, "apiKey is required", http.StatusBadRequest)
 		return
 	}
@@ -1221,12 +1222,7 @@ func handleGenerate(w http.ResponseWriter, r *http.Request) {
 	}
 	model := body.Model
 	if model == "" {
-		switch provider {
-		case "anthropic":
-			model = "claude-sonnet-4-20250514"
-		default:
-			model = "o3"
-		}
+		model = defaultModel(provider)
 	}
 
 	ciProv, err := resolveProvider(body.CIProvider)
@@ -1571,7 +1567,7 @@ func handleTopologyLogs(w http.ResponseWriter, r *http.Request) {
 		ns, podName := parts[0], parts[1]
 		podNames = append(podNames, podName)
 
-		out, err := runCapture("kubectl", "logs", podName, "-n", ns, "--tail="+tail, "--timestamps=true")
+		out, err := runCapture("kubectl", "--context", kindContext(), "logs", podName, "-n", ns, "--tail="+tail, "--timestamps=true")
 		if err != nil {
 			lines = append(lines, logEntry{Pod: podName, Line: "[error fetching logs: " + err.Error() + "]"})
 			continue
@@ -2084,18 +2080,10 @@ func handleGetTopology(w http.ResponseWriter, r *http.Request) {
 			// Determine the env var label for this dependency edge
 			depLabel := dep.EnvVarName
 			if depLabel == "" {
-				// Fall back to well-known auto-injected env var names
-				depAutoEnv := map[string]string{
-					"postgres": "DATABASE_URL", "redis": "REDIS_URL",
-					"mysql": "DATABASE_URL", "mongodb": "MONGO_URL",
-					"rabbitmq": "AMQP_URL", "minio": "S3_ENDPOINT",
-					"elasticsearch": "ELASTICSEARCH_URL", "kafka": "KAFKA_BROKER_URL",
-					"nats": "NATS_URL", "memcached": "MEMCACHED_URL",
-					"cassandra": "CASSANDRA_URL", "consul": "CONSUL_URL",
-					"vault": "VAULT_ADDR", "influxdb": "INFLUXDB_URL",
-					"jaeger": "JAEGER_URL",
+				// Fall back to the env var the operator auto-injects
+				if info, ok := ci.LookupDependency(dep.Type); ok {
+					depLabel = info.EnvVarName()
 				}
-				depLabel = depAutoEnv[dep.Type]
 			}
 			graph.Edges = append(graph.Edges, topologyEdge{
 				ID:     fmt.Sprintf("e-%s-%s", svcID, depID),
@@ -3488,7 +3476,7 @@ func handleProxy(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	pfCmd := exec.CommandContext(ctx, "kubectl", "--context", "kind-"+clusterName,
+	pfCmd := exec.CommandContext(ctx, "kubectl", "--context", kindContext(),
 		"port-forward", "-n", body.Namespace,
 		fmt.Sprintf("svc/%s", body.Service),
 		fmt.Sprintf("%d:%d", localPort, body.Port))
@@ -4149,7 +4137,7 @@ func tryFetchOpenAPI(ns, svcName string, port int) *apiSpec {
 	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
 	defer cancel()
 
-	pfCmd := exec.CommandContext(ctx, "kubectl", "--context", "kind-"+clusterName,
+	pfCmd := exec.CommandContext(ctx, "kubectl", "--context", kindContext(),
 		"port-forward", "-n", ns,
 		fmt.Sprintf("svc/%s", svcName),
 		fmt.Sprintf("%d:%d", localPort, port))
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -13,17 +14,27 @@ var deployCmd = &cobra.Command{
 	Long: `Applies one or more DevStagingEnvironment custom resources from a YAML
 file into the current cluster.
 
+With --wait, blocks until every applied environment reports Ready (see
+kindling wait) and exits non-zero if that takes longer than --timeout.
+
 Examples:
   kindling deploy -f examples/sample-app/dev-environment.yaml
-  kindling deploy -f examples/platform-api/dev-environment.yaml`,
+  kindling deploy -f examples/platform-api/dev-environment.yaml
+  kindling deploy -f dev-environment.yaml --wait --timeout 5m`,
 	RunE: runDeploy,
 }
 
-var deployFile string
+var (
+	deployFile    string
+	deployWait    bool
+	deployTimeout time.Duration
+)
 
 func init() {
 	deployCmd.Flags().StringVarP(&deployFile, "file", "f", "", "Path to DevStagingEnvironment YAML file (required)")
 	_ = deployCmd.MarkFlagRequired("file")
+	deployCmd.Flags().BoolVar(&deployWait, "wait", false, "Wait until the applied environments report Ready")
+	deployCmd.Flags().DurationVar(&deployTimeout, "timeout", 5*time.Minute, "How long --wait waits before failing")
 	rootCmd.AddCommand(deployCmd)
 }
 
@@ -35,16 +46,24 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 	header("Deploying DevStagingEnvironment")
 
 	step("📄", fmt.Sprintf("Applying %s", deployFile))
-	if err := run("kubectl", "apply", "-f", deployFile); err != nil {
+	if err := run("kubectl", "--context", kindContext(), "apply", "-f", deployFile); err != nil {
 		return fmt.Errorf("kubectl apply failed: %w", err)
 	}
 	success("Resources applied")
 
+	if deployWait {
+		fmt.Println()
+		step("⏳", fmt.Sprintf("Waiting up to %s for Ready", deployTimeout))
+		if err := waitForEnvironments([]string{"get", "-f", deployFile, "--context", kindContext(), "-o", "json"}, 0, deployTimeout); err != nil {
+			return err
+		}
+	}
+
 	// ── Show what was created ───────────────────────────────────
 	fmt.Println()
 	step("📋", "Current DevStagingEnvironments:")
 	fmt.Println()
-	if err := run("kubectl", "get", "devstagingenvironments", "-o", "wide"); err != nil {
+	if err := run("kubectl", "--context", kindContext(), "get", "devstagingenvironments", "-o", "wide"); err != nil {
 		warn("Could not list DevStagingEnvironments (CRD may not be installed)")
 	}
 
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jeffvincent/kindling/pkg/ci"
+	"github.com/spf13/cobra"
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "List the dependency types a DevStagingEnvironment can declare",
+	Long: `Prints every supported dependency type with its default image, port, and
+the env vars the operator injects into the app. This is the same catalog
+the operator provisions from and the generate prompt describes.
+
+Examples:
+  kindling deps
+  kindling deps --format json | jq '.[] | select(.type == "postgres")'`,
+	Args: cobra.NoArgs,
+	RunE: runDeps,
+}
+
+var depsFormat string
+
+func init() {
+	depsCmd.Flags().StringVar(&depsFormat, "format", "table", "Output format: table or json")
+	rootCmd.AddCommand(depsCmd)
+}
+
+func runDeps(cmd *cobra.Command, args []string) error {
+	switch depsFormat {
+	case "table":
+		return writeDepsTable(os.Stdout, ci.Dependencies)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		// Keep the <name> and <password> placeholders readable.
+		enc.SetEscapeHTML(false)
+		return enc.Encode(ci.Dependencies)
+	}
+	return fmt.Errorf("unknown --format %q (want table or json)", depsFormat)
+}
+
+// writeDepsTable writes deps as an aligned table, one type per row.
+func writeDepsTable(w io.Writer, deps []ci.DependencyInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tIMAGE\tPORT\tINJECTED ENV")
+	for _, d := range deps {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", d.Type, d.DefaultImage(), d.Port, strings.Join(d.EnvVars, ", "))
+	}
+	return tw.Flush()
+}
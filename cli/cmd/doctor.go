@@ -0,0 +1,430 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jeffvincent/kindling/pkg/ci"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [path]",
+	Short: "Statically validate a repo's kindling workflow before pushing",
+	Long: `Checks a repo and its dev-deploy workflow for the problems that otherwise
+only surface after a full build and deploy. No cluster or API key required.
+
+For every build step, the Dockerfile must exist in the build context and
+avoid Kaniko-incompatible patterns (BuildKit platform ARGs, 'poetry install'
+without --no-root, 'go build' without -buildvcs=false, npm without a
+writable cache). For every deploy step, the health check must point at a
+route the code actually serves. Each problem is printed with its fix.
+
+Exits non-zero when a blocker is found, so it works as a pre-push hook.
+
+--format json prints the problems as a JSON array of findings (rule ID,
+severity, service, file, message, fix) and --format sarif as a SARIF 2.1.0
+log for GitHub code scanning. Both go to stdout; passing checks are omitted.
+
+Examples:
+  kindling doctor                          # current directory
+  kindling doctor ../orders-service
+  kindling doctor -w ci/dev-deploy.yml     # check a specific workflow file
+  kindling doctor --format sarif > doctor.sarif`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDoctor,
+}
+
+var (
+	doctorWorkflow string
+	doctorFormat   string
+)
+
+func init() {
+	doctorCmd.Flags().StringVarP(&doctorWorkflow, "workflow", "w", "", "Workflow file to check (default: .github/workflows/dev-deploy.yml or .gitlab-ci.yml)")
+	doctorCmd.Flags().StringVar(&doctorFormat, "format", "text", "Output format: text, json, or sarif")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	repoPath := "."
+	if len(args) == 1 {
+		repoPath = args[0]
+	}
+	repoPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return fmt.Errorf("invalid repo path: %w", err)
+	}
+	if info, err := os.Stat(repoPath); err != nil || !info.IsDir() {
+		return fmt.Errorf("repo path does not exist or is not a directory: %s", repoPath)
+	}
+	switch doctorFormat {
+	case "text", "json", "sarif":
+	default:
+		return fmt.Errorf("unknown --format %q (want text, json, or sarif)", doctorFormat)
+	}
+
+	if doctorFormat == "text" {
+		fmt.Fprintf(os.Stderr, "\n  %s%s kindling doctor %s— %s%s\n\n",
+			colorBold, colorCyan, colorReset, repoPath, colorReset)
+	}
+
+	wfPath, err := findDoctorWorkflow(repoPath, doctorWorkflow)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(wfPath)
+	if err != nil {
+		return fmt.Errorf("cannot read workflow: %w", err)
+	}
+	builds, deploys := parseKindlingSteps(string(data))
+	if len(builds) == 0 && len(deploys) == 0 {
+		return fmt.Errorf("%s has no kindling build or deploy steps", wfPath)
+	}
+
+	// Reuse the generate pipeline's repo scanner for health route detection
+	repoCtx, err := scanRepo(repoPath)
+	if err != nil {
+		return fmt.Errorf("repo scan failed: %w", err)
+	}
+
+	rel, _ := filepath.Rel(repoPath, wfPath)
+	if doctorFormat != "text" {
+		findings := doctorAnalyzer(repoPath, filepath.ToSlash(rel), repoCtx).Analyze(buildSteps(builds), deploySteps(deploys))
+		return printFindings(findings, doctorFormat)
+	}
+
+	checks := []checkResult{{
+		status:  checkInfo,
+		message: fmt.Sprintf("%s: %d build step(s), %d deploy step(s)", rel, len(builds), len(deploys)),
+	}}
+	checks = append(checks, checkBuildSteps(repoPath, builds, repoCtx)...)
+	checks = append(checks, checkDeployHealth(builds, deploys, repoCtx)...)
+
+	warnCount, failCount := printCheckResults(checks, true)
+
+	fmt.Fprintln(os.Stderr)
+	if warnCount > 0 {
+		fmt.Fprintf(os.Stderr, "  %s%d warning(s)%s to review\n", colorYellow, warnCount, colorReset)
+	}
+	if failCount > 0 {
+		fmt.Fprintln(os.Stderr)
+		return fmt.Errorf("%d blocker(s) found — fix them before pushing", failCount)
+	}
+	fmt.Fprintf(os.Stderr, "  %s✅ Ready to push%s\n\n", colorGreen, colorReset)
+	return nil
+}
+
+// printFindings writes findings to stdout as JSON or SARIF, and fails when
+// any of them is a blocker.
+func printFindings(findings []ci.Finding, format string) error {
+	var out any = findings
+	if findings == nil {
+		out = []ci.Finding{}
+	}
+	if format == "sarif" {
+		out = ci.ToSARIF(findings, "kindling doctor", Version)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+
+	blockers := 0
+	for _, f := range findings {
+		if f.Severity == ci.SeverityError {
+			blockers++
+		}
+	}
+	if blockers > 0 {
+		return fmt.Errorf("%d blocker(s) found — fix them before pushing", blockers)
+	}
+	return nil
+}
+
+// findDoctorWorkflow returns the workflow file to check: override if set,
+// otherwise the first dev-deploy workflow found in the repo.
+func findDoctorWorkflow(repoPath, override string) (string, error) {
+	if override != "" {
+		if _, err := os.Stat(override); err != nil {
+			return "", fmt.Errorf("workflow not found: %s", override)
+		}
+		return override, nil
+	}
+	for _, p := range []string{
+		filepath.Join(repoPath, ".github", "workflows", "dev-deploy.yml"),
+		filepath.Join(repoPath, ".github", "workflows", "dev-deploy.yaml"),
+		filepath.Join(repoPath, ".gitlab-ci.yml"),
+	} {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no dev-deploy workflow found in %s — run 'kindling generate' first or pass --workflow", repoPath)
+}
+
+// ── Workflow parsing ────────────────────────────────────────────
+
+// workflowBuild is a kindling-build step, or a GitLab job that hands a
+// tarball to the Kaniko sidecar directly.
+type workflowBuild struct {
+	name       string
+	context    string
+	dockerfile string
+	image      string
+}
+
+// workflowDeploy is a kindling-deploy step, or a DevStagingEnvironment
+// written inline (GitLab).
+type workflowDeploy struct {
+	name       string
+	image      string
+	healthPath string
+	healthType string
+	inline     bool // inline DSE — no /healthz default applies
+}
+
+var (
+	gitlabBuildTarRe  = regexp.MustCompile(`tar\s+-czf\s+/builds/(\S+)\.tar\.gz\s+-C\s+("[^"]+"|\S+)`)
+	gitlabBuildDestRe = regexp.MustCompile(`echo\s+("[^"]+"|\S+)\s*>\s*/builds/(\S+)\.dest`)
+)
+
+// parseKindlingSteps extracts the build and deploy steps from a GitHub
+// Actions or GitLab CI workflow. It reads the YAML line by line, like the
+// rest of the CLI, so it tolerates templated values YAML parsers reject.
+func parseKindlingSteps(content string) ([]workflowBuild, []workflowDeploy) {
+	var builds []workflowBuild
+	var deploys []workflowDeploy
+	dests := make(map[string]string)
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimPrefix(strings.TrimSpace(line), "- ")
+		switch {
+		case strings.HasPrefix(trimmed, "uses:") && strings.Contains(trimmed, "kindling-build@"):
+			in := stepInputs(lines, i)
+			builds = append(builds, workflowBuild{
+				name:       in["name"],
+				context:    in["context"],
+				dockerfile: in["dockerfile"],
+				image:      in["image"],
+			})
+		case strings.HasPrefix(trimmed, "uses:") && strings.Contains(trimmed, "kindling-deploy@"):
+			in := stepInputs(lines, i)
+			deploys = append(deploys, workflowDeploy{
+				name:       in["name"],
+				image:      in["image"],
+				healthPath: in["health-check-path"],
+				healthType: in["health-check-type"],
+			})
+		case trimmed == "kind: DevStagingEnvironment":
+			deploys = append(deploys, parseInlineDSE(lines, i))
+		}
+		if m := gitlabBuildTarRe.FindStringSubmatch(line); m != nil {
+			builds = append(builds, workflowBuild{name: m[1], context: unquoteYAML(m[2])})
+		}
+		if m := gitlabBuildDestRe.FindStringSubmatch(line); m != nil {
+			dests[m[2]] = unquoteYAML(m[1])
+		}
+	}
+	for i := range builds {
+		if builds[i].image == "" {
+			builds[i].image = dests[builds[i].name]
+		}
+	}
+	return builds, deploys
+}
+
+// stepInputs returns the scalar `with:` inputs of the step whose `uses:`
+// key is on lines[i]. Block scalars (labels, env, ...) map to "".
+func stepInputs(lines []string, i int) map[string]string {
+	keyIndent := indentOf(lines[i])
+	if strings.HasPrefix(strings.TrimSpace(lines[i]), "- ") {
+		keyIndent += 2
+	}
+	for j := i + 1; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		ind := indentOf(lines[j])
+		if ind < keyIndent {
+			break
+		}
+		if ind == keyIndent && trimmed == "with:" {
+			return yamlChildScalars(lines, j)
+		}
+	}
+	return map[string]string{}
+}
+
+// yamlChildScalars returns the direct key: value children of the mapping
+// key on lines[i].
+func yamlChildScalars(lines []string, i int) map[string]string {
+	parentIndent := indentOf(lines[i])
+	out := make(map[string]string)
+	childIndent := -1
+	for j := i + 1; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		ind := indentOf(lines[j])
+		if ind <= parentIndent {
+			break
+		}
+		if childIndent < 0 {
+			childIndent = ind
+		}
+		if ind != childIndent {
+			continue
+		}
+		if key, value, ok := strings.Cut(trimmed, ":"); ok {
+			out[strings.TrimSpace(key)] = unquoteYAML(strings.TrimSpace(value))
+		}
+	}
+	return out
+}
+
+// parseInlineDSE reads metadata.name, the image and the health check from
+// the DevStagingEnvironment whose `kind:` is on lines[i].
+func parseInlineDSE(lines []string, i int) workflowDeploy {
+	base := indentOf(lines[i])
+	d := workflowDeploy{inline: true}
+	inHealth, healthIndent := false, 0
+	for j := i + 1; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if trimmed == "" {
+			continue
+		}
+		ind := indentOf(lines[j])
+		if ind < base || trimmed == "---" || trimmed == "EOF" {
+			break
+		}
+		if inHealth && ind <= healthIndent {
+			inHealth = false
+		}
+		key, value, _ := strings.Cut(trimmed, ":")
+		value = unquoteYAML(strings.TrimSpace(value))
+		switch {
+		case trimmed == "healthCheck:":
+			inHealth, healthIndent = true, ind
+		case inHealth && key == "type":
+			d.healthType = value
+		case inHealth && key == "path":
+			d.healthPath = value
+		case key == "name" && d.name == "" && ind == base+2:
+			d.name = value
+		case key == "image" && d.image == "":
+			d.image = value
+		}
+	}
+	return d
+}
+
+// indentOf returns the number of leading spaces on line.
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// unquoteYAML strips quotes and trailing comments from a scalar. Block
+// scalar indicators yield "".
+func unquoteYAML(v string) string {
+	if strings.HasPrefix(v, "|") || strings.HasPrefix(v, ">") {
+		return ""
+	}
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	if before, _, ok := strings.Cut(v, " #"); ok {
+		v = strings.TrimSpace(before)
+	}
+	return v
+}
+
+// ── Checks ──────────────────────────────────────────────────────
+
+func (b workflowBuild) step() ci.BuildStep {
+	return ci.BuildStep{Name: b.name, Context: b.context, Dockerfile: b.dockerfile, Image: b.image}
+}
+
+func (d workflowDeploy) step() ci.DeployStep {
+	return ci.DeployStep{Name: d.name, Image: d.image, HealthPath: d.healthPath, HealthType: d.healthType, Inline: d.inline}
+}
+
+func buildSteps(builds []workflowBuild) []ci.BuildStep {
+	steps := make([]ci.BuildStep, len(builds))
+	for i, b := range builds {
+		steps[i] = b.step()
+	}
+	return steps
+}
+
+func deploySteps(deploys []workflowDeploy) []ci.DeployStep {
+	steps := make([]ci.DeployStep, len(deploys))
+	for i, d := range deploys {
+		steps[i] = d.step()
+	}
+	return steps
+}
+
+// doctorAnalyzer returns the shared static analyzer, wired to the generate
+// pipeline's repo scan for health routes and Dockerfile suggestions.
+func doctorAnalyzer(repoPath, workflow string, ctx *repoContext) *ci.Analyzer {
+	return &ci.Analyzer{
+		RepoPath:     repoPath,
+		Workflow:     workflow,
+		HealthRoutes: func(dir string) []string { return detectHealthRoutes(ctx, dir) },
+		DockerfileFix: func(dir string) string {
+			return dockerfileFixForLanguage(detectPrimaryLanguage(ctx), dir)
+		},
+	}
+}
+
+// checkBuildSteps verifies every build step has a Dockerfile in its context
+// and runs the Kaniko compatibility checks against it.
+func checkBuildSteps(repoPath string, builds []workflowBuild, ctx *repoContext) []checkResult {
+	a := doctorAnalyzer(repoPath, "", ctx)
+	var results []checkResult
+	for _, b := range builds {
+		dockerfile, findings := a.CheckBuild(b.step())
+		if dockerfile != "" {
+			results = append(results, checkResult{
+				status:  checkPass,
+				message: fmt.Sprintf("build %s: %s", b.name, dockerfile),
+			})
+		}
+		results = append(results, findingChecks(findings)...)
+	}
+	return results
+}
+
+// checkDeployHealth verifies each deploy step's health check targets a
+// route the code serves.
+func checkDeployHealth(builds []workflowBuild, deploys []workflowDeploy, ctx *repoContext) []checkResult {
+	a := doctorAnalyzer("", "", ctx)
+	steps := buildSteps(builds)
+	var results []checkResult
+	for _, d := range deploys {
+		if findings := a.CheckDeploy(steps, d.step()); len(findings) > 0 {
+			results = append(results, findingChecks(findings)...)
+			continue
+		}
+		var message string
+		switch {
+		case d.healthType != "" && d.healthType != "http":
+			message = fmt.Sprintf("deploy %s: %s health check", d.name, d.healthType)
+		case d.healthPath != "":
+			message = fmt.Sprintf("deploy %s: health check %s", d.name, d.healthPath)
+		default:
+			message = fmt.Sprintf("deploy %s: default health check /healthz found in source", d.name)
+		}
+		results = append(results, checkResult{status: checkPass, message: message})
+	}
+	return results
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const doctorGitHubWorkflow = `jobs:
+  build-and-deploy:
+    steps:
+      - name: Checkout code
+        uses: actions/checkout@v4
+
+      - name: Build API image
+        uses: kindling-sh/kindling/.github/actions/kindling-build@main
+        with:
+          name: api
+          context: ${{ github.workspace }}
+          image: "${{ env.REGISTRY }}/api:${{ env.TAG }}"
+          exclude: "./ui"
+
+      - uses: kindling-sh/kindling/.github/actions/kindling-build@main
+        with:
+          name: ui
+          context: "${{ github.workspace }}/ui"
+          dockerfile: docker/Dockerfile.prod   # prod image
+          image: "${{ env.REGISTRY }}/ui:${{ env.TAG }}"
+
+      - name: Deploy API
+        uses: kindling-sh/kindling/.github/actions/kindling-deploy@main
+        with:
+          name: "${{ github.actor }}-api"
+          image: "${{ env.REGISTRY }}/api:${{ env.TAG }}"
+          port: "8080"
+          labels: |
+            app.kubernetes.io/part-of: my-app
+          env: |
+            - name: health-check-path
+              value: "/nope"
+
+      - name: Deploy UI
+        uses: kindling-sh/kindling/.github/actions/kindling-deploy@main
+        with:
+          name: "${{ github.actor }}-ui"
+          image: "${{ env.REGISTRY }}/ui:${{ env.TAG }}"
+          health-check-path: "/"
+`
+
+const doctorGitLabWorkflow = `build-api:
+  script:
+    - tar -czf /builds/api.tar.gz -C ${CI_PROJECT_DIR}/api .
+    - echo "${REGISTRY}/api:${TAG}" > /builds/api.dest
+
+deploy-api:
+  script:
+    - |
+      cat > /builds/${KINDLING_USER}-api-dse.yaml <<EOF
+      apiVersion: apps.example.com/v1alpha1
+      kind: DevStagingEnvironment
+      metadata:
+        name: ${KINDLING_USER}-api
+        labels:
+          app.kubernetes.io/name: ${KINDLING_USER}-api
+      spec:
+        deployment:
+          image: ${REGISTRY}/api:${TAG}
+          port: 8080
+          healthCheck:
+            type: http
+            path: /health
+        service:
+          port: 8080
+      EOF
+`
+
+// ────────────────────────────────────────────────────────────────────────────
+// parseKindlingSteps
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestParseKindlingSteps_GitHub(t *testing.T) {
+	builds, deploys := parseKindlingSteps(doctorGitHubWorkflow)
+
+	wantBuilds := []workflowBuild{
+		{name: "api", context: "${{ github.workspace }}", image: "${{ env.REGISTRY }}/api:${{ env.TAG }}"},
+		{name: "ui", context: "${{ github.workspace }}/ui", dockerfile: "docker/Dockerfile.prod", image: "${{ env.REGISTRY }}/ui:${{ env.TAG }}"},
+	}
+	if !reflect.DeepEqual(builds, wantBuilds) {
+		t.Errorf("builds = %+v\nwant %+v", builds, wantBuilds)
+	}
+
+	wantDeploys := []workflowDeploy{
+		{name: "${{ github.actor }}-api", image: "${{ env.REGISTRY }}/api:${{ env.TAG }}"},
+		{name: "${{ github.actor }}-ui", image: "${{ env.REGISTRY }}/ui:${{ env.TAG }}", healthPath: "/"},
+	}
+	if !reflect.DeepEqual(deploys, wantDeploys) {
+		t.Errorf("deploys = %+v\nwant %+v", deploys, wantDeploys)
+	}
+}
+
+func TestParseKindlingSteps_GitLab(t *testing.T) {
+	builds, deploys := parseKindlingSteps(doctorGitLabWorkflow)
+
+	wantBuilds := []workflowBuild{{name: "api", context: "${CI_PROJECT_DIR}/api", image: "${REGISTRY}/api:${TAG}"}}
+	if !reflect.DeepEqual(builds, wantBuilds) {
+		t.Errorf("builds = %+v\nwant %+v", builds, wantBuilds)
+	}
+	wantDeploys := []workflowDeploy{{
+		name: "${KINDLING_USER}-api", image: "${REGISTRY}/api:${TAG}",
+		healthType: "http", healthPath: "/health", inline: true,
+	}}
+	if !reflect.DeepEqual(deploys, wantDeploys) {
+		t.Errorf("deploys = %+v\nwant %+v", deploys, wantDeploys)
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// checkBuildSteps / checkDeployHealth
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestCheckBuildSteps(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "api"), 0755)
+	os.WriteFile(filepath.Join(dir, "api", "Dockerfile"),
+		[]byte("FROM golang:1.22\nRUN go build -o /server .\n"), 0644)
+
+	builds := []workflowBuild{
+		{name: "api", context: "${{ github.workspace }}/api"},
+		{name: "ui", context: "${{ github.workspace }}/ui"},
+	}
+	results := checkBuildSteps(dir, builds, &repoContext{depFiles: map[string]string{}})
+
+	var sawPass, sawKaniko, sawMissing bool
+	for _, r := range results {
+		switch {
+		case r.status == checkPass && strings.Contains(r.message, "api/Dockerfile"):
+			sawPass = true
+		case r.status == checkWarn && strings.Contains(r.message, "-buildvcs=false"):
+			sawKaniko = r.fix != ""
+		case r.status == checkFail && strings.Contains(r.message, "build ui: no Dockerfile at ui/Dockerfile"):
+			sawMissing = r.fix != ""
+		}
+	}
+	if !sawPass {
+		t.Error("expected api's Dockerfile to pass")
+	}
+	if !sawKaniko {
+		t.Error("expected a go build -buildvcs=false warning with a fix")
+	}
+	if !sawMissing {
+		t.Error("expected ui's missing Dockerfile to be a blocker with a fix")
+	}
+}
+
+func TestCheckDeployHealth(t *testing.T) {
+	ctx := &repoContext{sourceSnippets: map[string]string{
+		"api/main.go":    `mux.HandleFunc("/healthz", health)`,
+		"worker/main.py": `@app.get("/health")`,
+	}}
+	builds := []workflowBuild{
+		{name: "api", context: "./api", image: "reg/api:1"},
+		{name: "worker", context: "./worker", image: "reg/worker:1"},
+		{name: "ui", context: "./ui", image: "reg/ui:1"},
+	}
+	deploys := []workflowDeploy{
+		{name: "api", image: "reg/api:1"},
+		{name: "worker", image: "reg/worker:1"},
+		{name: "ui", image: "reg/ui:1"},
+		{name: "grpc", image: "reg/grpc:1", healthType: "grpc"},
+		{name: "web", image: "reg/web:1", healthPath: "/"},
+	}
+	results := checkDeployHealth(builds, deploys, ctx)
+
+	want := []checkStatus{checkPass, checkWarn, checkFail, checkPass, checkPass}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, r := range results {
+		if r.status != want[i] {
+			t.Errorf("deploy %s: status %d, want %d (%s)", deploys[i].name, r.status, want[i], r.message)
+		}
+	}
+	if results[1].fix != `health-check-path: "/health"` {
+		t.Errorf("worker fix = %q", results[1].fix)
+	}
+}
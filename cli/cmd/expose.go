@@ -12,7 +12,7 @@ import (
 
 var exposeCmd = &cobra.Command{
 	Use:   "expose",
-	Short: "Expose the local cluster via a public HTTPS tunnel",
+	Short: "Expose the local cluster via a public HTTPS or TCP tunnel",
 	Long: `Creates a secure tunnel from a public HTTPS URL to the Kind cluster's
 ingress controller, enabling external OAuth/OIDC providers (Auth0, Okta,
 Firebase Auth, etc.) to call back into local services.
@@ -23,12 +23,24 @@ Supported providers:
   cloudflared  — Cloudflare Tunnel (free, no account required for quick tunnels)
   ngrok        — ngrok tunnel (requires free account + auth token)
 
+gRPC and other non-HTTP services can't ride an HTTPS tunnel. For those, use
+--protocol tcp with the local port of the service (e.g. from kubectl
+port-forward); raw TCP tunnels require ngrok.
+
 Examples:
   kindling expose                          # auto-detect tunnel, expose port 80
   kindling expose --tunnel cloudflared     # use cloudflared explicitly
   kindling expose --port 443               # expose a different port
+  kindling expose --protocol tcp --port 50051   # raw TCP tunnel (ngrok)
+  kindling expose --bind my-app            # point my-app's ingress host at the tunnel
+  kindling expose list                     # show the tunnel and what it routes to
   kindling expose --stop                   # stop a running tunnel
 
+By default the first Ingress (or --service) is patched to the tunnel
+hostname. The operator rewrites that Ingress whenever the CR changes, so
+for an OAuth app use --bind to set the DevStagingEnvironment's
+spec.ingress.host instead; --stop restores the original host.
+
 The public URL is saved to .kindling/tunnel.yaml so that other commands
 (kindling generate) can reference it.`,
 	RunE: runExpose,
@@ -39,13 +51,25 @@ var (
 	exposePort     int
 	exposeStop     bool
 	exposeService  string
+	exposeProtocol string
+	exposeBind     string
 )
 
+var exposeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the running tunnel and the ingresses or environments routed to it",
+	Args:  cobra.NoArgs,
+	RunE:  runExposeList,
+}
+
 func init() {
 	exposeCmd.Flags().StringVar(&exposeProvider, "tunnel", "", "Tunnel provider: cloudflared or ngrok (auto-detected if omitted)")
 	exposeCmd.Flags().IntVar(&exposePort, "port", 80, "Local port to expose (default: 80, the ingress controller)")
 	exposeCmd.Flags().BoolVar(&exposeStop, "stop", false, "Stop a running tunnel")
 	exposeCmd.Flags().StringVar(&exposeService, "service", "", "Ingress name to route tunnel traffic to (default: first ingress found)")
+	exposeCmd.Flags().StringVar(&exposeProtocol, "protocol", "http", "Tunnel protocol: http or tcp (tcp requires ngrok and an explicit --port)")
+	exposeCmd.Flags().StringVar(&exposeBind, "bind", "", "DevStagingEnvironment whose spec.ingress.host is set to the tunnel hostname (restored by --stop)")
+	exposeCmd.AddCommand(exposeListCmd)
 	rootCmd.AddCommand(exposeCmd)
 }
 
@@ -55,12 +79,28 @@ func runExpose(cmd *cobra.Command, args []string) error {
 		return stopTunnel()
 	}
 
+	switch exposeProtocol {
+	case "http":
+	case "tcp":
+		if exposeBind != "" {
+			return fmt.Errorf("--bind needs an HTTP tunnel: TCP traffic never passes through the ingress")
+		}
+		return runExposeTCP(cmd)
+	default:
+		return fmt.Errorf("unsupported protocol %q (use http or tcp)", exposeProtocol)
+	}
+
 	header("Public HTTPS tunnel")
 
 	// ── Check for already-running tunnel ────────────────────────
 	if info, _ := core.ReadTunnelInfo(); info != nil && info.PID > 0 {
 		if core.ProcessAlive(info.PID) {
 			success(fmt.Sprintf("Tunnel already running → %s%s%s (pid %d)", colorBold, info.URL, colorReset, info.PID))
+			if exposeBind != "" {
+				if err := routeTunnel(info.URL); err != nil {
+					return err
+				}
+			}
 			fmt.Println()
 			fmt.Printf("  Stop with: %skindling expose --stop%s\n", colorCyan, colorReset)
 			fmt.Println()
@@ -90,6 +130,16 @@ func runExpose(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("Kind cluster %q not found — run 'kindling init' first", clusterName)
 	}
 
+	// Check the bind target before starting anything, so a typo doesn't
+	// leave a tunnel running that routes nowhere.
+	if exposeBind != "" {
+		if _, err := dseTunnelBindOps(exposeBind, ""); err != nil {
+			return err
+		}
+	}
+
+	warnNonHTTPBackends()
+
 	// ── Start tunnel ────────────────────────────────────────────
 	switch provider {
 	case "cloudflared":
@@ -112,7 +162,9 @@ func runCloudflaredTunnel() error {
 	}
 
 	core.SaveTunnelInfo(clusterName, result.PublicURL, "cloudflared", result.PID)
-	patchIngressesForTunnel(result.PublicURL)
+	if err := routeTunnel(result.PublicURL); err != nil {
+		return err
+	}
 	printTunnelRunning(result.PublicURL, result.PID)
 
 	if !result.DNSOK {
@@ -133,12 +185,231 @@ func runNgrokTunnel() error {
 	}
 
 	core.SaveTunnelInfo(clusterName, result.PublicURL, "ngrok", result.PID)
-	patchIngressesForTunnel(result.PublicURL)
+	if err := routeTunnel(result.PublicURL); err != nil {
+		return err
+	}
 	printTunnelRunning(result.PublicURL, result.PID)
 
 	return nil
 }
 
+// ── Raw TCP ─────────────────────────────────────────────────────
+
+// runExposeTCP opens a raw TCP tunnel to a local port. Ingresses are left
+// alone: TCP traffic never passes through the ingress controller.
+func runExposeTCP(cmd *cobra.Command) error {
+	header("Public TCP tunnel")
+
+	if !cmd.Flags().Changed("port") {
+		fmt.Println()
+		fmt.Println("  Forward the service to a local port first, e.g.:")
+		fmt.Printf("    %skubectl port-forward svc/<service> 50051:50051%s\n", colorCyan, colorReset)
+		fmt.Println()
+		return fmt.Errorf("--protocol tcp needs --port: the local port to tunnel")
+	}
+	if exposeProvider == "cloudflared" {
+		return fmt.Errorf("cloudflared quick tunnels are HTTP-only — use --tunnel ngrok for TCP")
+	}
+	if !commandExists("ngrok") {
+		fail("ngrok not found")
+		fmt.Println()
+		fmt.Printf("    brew install ngrok/ngrok/ngrok\n")
+		fmt.Println()
+		return fmt.Errorf("raw TCP tunnels require ngrok")
+	}
+
+	if info, _ := core.ReadTunnelInfo(); info != nil && info.PID > 0 {
+		if core.ProcessAlive(info.PID) {
+			return fmt.Errorf("a tunnel is already running → %s (pid %d); stop it with 'kindling expose --stop'", info.URL, info.PID)
+		}
+		core.CleanupTunnel(clusterName)
+	}
+
+	step("⏳", fmt.Sprintf("Starting ngrok TCP tunnel to localhost:%d...", exposePort))
+	result, err := core.StartNgrokTCPTunnel(exposePort)
+	if err != nil {
+		return err
+	}
+	core.SaveTCPTunnelInfo(result.PublicURL, "ngrok", result.PID)
+	printTunnelRunning(result.PublicURL, result.PID)
+	return nil
+}
+
+// ── Non-HTTP backend detection ──────────────────────────────────
+
+// nonHTTPBackend is an Ingress backend whose Service port doesn't speak
+// plain HTTP.
+type nonHTTPBackend struct {
+	ingress string
+	service string
+	port    int32
+	reason  string
+}
+
+// wellKnownNonHTTPPorts are ports whose protocol is unambiguous.
+var wellKnownNonHTTPPorts = map[int32]string{
+	50051: "gRPC",
+	4317:  "OTLP gRPC",
+	5432:  "PostgreSQL",
+	3306:  "MySQL",
+	1433:  "SQL Server",
+	6379:  "Redis",
+	27017: "MongoDB",
+	5672:  "AMQP",
+	9092:  "Kafka",
+}
+
+// nonHTTPPortReason explains why a Service port looks like it isn't HTTP,
+// or returns "" if it looks like HTTP. appProtocol wins, then the port
+// name's protocol prefix (grpc-web, tcp-metrics, ...), then the number.
+func nonHTTPPortReason(name, appProtocol, protocol string, port int32) string {
+	if strings.EqualFold(protocol, "UDP") || strings.EqualFold(protocol, "SCTP") {
+		return strings.ToUpper(protocol)
+	}
+	switch ap := strings.ToLower(appProtocol); {
+	case strings.Contains(ap, "grpc"):
+		return "gRPC"
+	case ap == "tcp", ap == "udp":
+		return strings.ToUpper(ap)
+	case ap != "":
+		return ""
+	}
+	lname := strings.ToLower(name)
+	for _, prefix := range []string{"grpc", "tcp", "udp"} {
+		if lname == prefix || strings.HasPrefix(lname, prefix+"-") {
+			if prefix == "grpc" {
+				return "gRPC"
+			}
+			return strings.ToUpper(prefix)
+		}
+	}
+	return wellKnownNonHTTPPorts[port]
+}
+
+// findNonHTTPBackends cross-references Ingress backends with Service
+// ports (kubectl -o json lists). If only is non-empty, just that Ingress
+// is considered.
+func findNonHTTPBackends(ingressJSON, serviceJSON []byte, only string) ([]nonHTTPBackend, error) {
+	type backend struct {
+		Service *struct {
+			Name string `json:"name"`
+			Port struct {
+				Name   string `json:"name"`
+				Number int32  `json:"number"`
+			} `json:"port"`
+		} `json:"service"`
+	}
+	var ingresses struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				DefaultBackend *backend `json:"defaultBackend"`
+				Rules          []struct {
+					HTTP *struct {
+						Paths []struct {
+							Backend backend `json:"backend"`
+						} `json:"paths"`
+					} `json:"http"`
+				} `json:"rules"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	var services struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				Ports []struct {
+					Name        string `json:"name"`
+					Port        int32  `json:"port"`
+					Protocol    string `json:"protocol"`
+					AppProtocol string `json:"appProtocol"`
+				} `json:"ports"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(ingressJSON, &ingresses); err != nil {
+		return nil, fmt.Errorf("parse ingresses: %w", err)
+	}
+	if err := json.Unmarshal(serviceJSON, &services); err != nil {
+		return nil, fmt.Errorf("parse services: %w", err)
+	}
+
+	var found []nonHTTPBackend
+	seen := make(map[string]bool)
+	for _, ing := range ingresses.Items {
+		if only != "" && ing.Metadata.Name != only {
+			continue
+		}
+		var backends []backend
+		if ing.Spec.DefaultBackend != nil {
+			backends = append(backends, *ing.Spec.DefaultBackend)
+		}
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, p := range rule.HTTP.Paths {
+				backends = append(backends, p.Backend)
+			}
+		}
+
+		for _, b := range backends {
+			if b.Service == nil {
+				continue
+			}
+			for _, svc := range services.Items {
+				if svc.Metadata.Name != b.Service.Name {
+					continue
+				}
+				for _, sp := range svc.Spec.Ports {
+					if sp.Port != b.Service.Port.Number && (b.Service.Port.Name == "" || sp.Name != b.Service.Port.Name) {
+						continue
+					}
+					reason := nonHTTPPortReason(sp.Name, sp.AppProtocol, sp.Protocol, sp.Port)
+					key := fmt.Sprintf("%s/%s/%d", ing.Metadata.Name, svc.Metadata.Name, sp.Port)
+					if reason == "" || seen[key] {
+						continue
+					}
+					seen[key] = true
+					found = append(found, nonHTTPBackend{
+						ingress: ing.Metadata.Name,
+						service: svc.Metadata.Name,
+						port:    sp.Port,
+						reason:  reason,
+					})
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+// warnNonHTTPBackends warns about Ingress backends an HTTPS tunnel can't
+// carry and points at --protocol tcp. Lookup failures are ignored.
+func warnNonHTTPBackends() {
+	ingJSON, err := runSilent("kubectl", "--context", kindContext(), "get", "ingress", "-o", "json")
+	if err != nil {
+		return
+	}
+	svcJSON, err := runSilent("kubectl", "--context", kindContext(), "get", "service", "-o", "json")
+	if err != nil {
+		return
+	}
+	backends, err := findNonHTTPBackends([]byte(ingJSON), []byte(svcJSON), exposeService)
+	if err != nil {
+		return
+	}
+	for _, b := range backends {
+		warn(fmt.Sprintf("ingress/%s routes to svc/%s:%d (%s) — HTTPS tunneling won't work for it", b.ingress, b.service, b.port, b.reason))
+		fmt.Printf("    Use a raw TCP tunnel instead: %skubectl port-forward svc/%s %d:%d%s, then %skindling expose --protocol tcp --port %d%s\n",
+			colorCyan, b.service, b.port, b.port, colorReset, colorCyan, b.port, colorReset)
+	}
+}
+
 // ── Shared helpers ──────────────────────────────────────────────
 
 // printTunnelRunning shows the success output after backgrounding.
@@ -161,7 +432,7 @@ func stopTunnel() error {
 
 	if !core.ProcessAlive(info.PID) {
 		core.CleanupTunnel(clusterName)
-		restoreIngresses()
+		restoreTunnelRoutes()
 		fmt.Println("  Tunnel process already exited — cleaned up.")
 		return nil
 	}
@@ -169,11 +440,76 @@ func stopTunnel() error {
 	step("🛑", fmt.Sprintf("Stopping %s tunnel (pid %d)...", info.Provider, info.PID))
 	core.StopTunnelProcess()
 	core.CleanupTunnel(clusterName)
-	restoreIngresses()
+	restoreTunnelRoutes()
 	success("Tunnel stopped")
 	return nil
 }
 
+// ── Listing ─────────────────────────────────────────────────────
+
+// runExposeList prints the tunnel recorded in .kindling/tunnel.yaml and
+// the Ingresses and DevStagingEnvironments currently routed to it.
+func runExposeList(cmd *cobra.Command, args []string) error {
+	info, err := core.ReadTunnelInfo()
+	if err != nil || info == nil || info.PID == 0 {
+		fmt.Println("  No tunnel is currently running.")
+		return nil
+	}
+
+	header("Tunnels")
+	if core.ProcessAlive(info.PID) {
+		success(fmt.Sprintf("%s  %s%s%s  %s(pid %d)%s", info.Provider, colorBold, info.URL, colorReset, colorDim, info.PID, colorReset))
+	} else {
+		warn(fmt.Sprintf("%s  %s  (pid %d exited — run 'kindling expose --stop' to clean up)", info.Provider, info.URL, info.PID))
+	}
+
+	var routes []string
+	if out, err := runSilent("kubectl", "--context", kindContext(), "get", "ingress", "-o", "json"); err == nil {
+		names, _ := tunnelRoutedNames([]byte(out))
+		for _, n := range names {
+			routes = append(routes, "ingress/"+n)
+		}
+	}
+	if out, err := runSilent("kubectl", "--context", kindContext(), "get", "devstagingenvironments", "-n", "default", "-o", "json"); err == nil {
+		names, _ := tunnelRoutedNames([]byte(out))
+		for _, n := range names {
+			routes = append(routes, "devstagingenvironment/"+n)
+		}
+	}
+	for _, r := range routes {
+		fmt.Printf("     → %s\n", r)
+	}
+	if len(routes) == 0 && strings.HasPrefix(info.URL, "https://") {
+		fmt.Printf("     %sNothing routed to it — rerun with --bind <name> or --service <ingress>%s\n", colorDim, colorReset)
+	}
+	fmt.Println()
+	return nil
+}
+
+// tunnelRoutedNames returns the names of the objects in a kubectl -o json
+// list that carry the original-host annotation, i.e. that were rerouted
+// to a tunnel.
+func tunnelRoutedNames(listJSON []byte) ([]string, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name        string            `json:"name"`
+				Annotations map[string]string `json:"annotations"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(listJSON, &list); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, item := range list.Items {
+		if _, ok := item.Metadata.Annotations[originalHostAnnotation]; ok {
+			names = append(names, item.Metadata.Name)
+		}
+	}
+	return names, nil
+}
+
 // ── Ingress patching ──────────────────────────────────────────
 
 const originalHostAnnotation = "kindling.dev/original-host"
@@ -216,7 +552,7 @@ func patchIngressesForTunnel(publicURL string) {
 	patched := 0
 	for _, name := range names {
 		// Read current host
-		currentHost, err := runSilent("kubectl", "get", "ingress", name,
+		currentHost, err := runSilent("kubectl", "--context", kindContext(), "get", "ingress", name,
 			"-o", "jsonpath={.spec.rules[0].host}")
 		if err != nil || strings.TrimSpace(currentHost) == "" {
 			continue
@@ -238,7 +574,7 @@ func patchIngressesForTunnel(publicURL string) {
 
 		// 3. If the ingress has a TLS block (cert-manager, etc.), save it as
 		//    an annotation and remove it — cloudflared terminates TLS at the edge.
-		tlsJSON, _ := runSilent("kubectl", "get", "ingress", name,
+		tlsJSON, _ := runSilent("kubectl", "--context", kindContext(), "get", "ingress", name,
 			"-o", "jsonpath={.spec.tls}")
 		tlsJSON = strings.TrimSpace(tlsJSON)
 		if tlsJSON != "" && tlsJSON != "[]" {
@@ -249,7 +585,7 @@ func patchIngressesForTunnel(publicURL string) {
 		}
 
 		patchBytes, _ := json.Marshal(ops)
-		if _, err := runSilent("kubectl", "patch", "ingress", name,
+		if _, err := runSilent("kubectl", "--context", kindContext(), "patch", "ingress", name,
 			"--type=json", "-p="+string(patchBytes)); err == nil {
 			step("🔀", fmt.Sprintf("Routing tunnel → ingress/%s", name))
 			patched++
@@ -270,7 +606,7 @@ func restoreIngresses() {
 
 	restored := 0
 	for _, name := range names {
-		originalHost, err := runSilent("kubectl", "get", "ingress", name,
+		originalHost, err := runSilent("kubectl", "--context", kindContext(), "get", "ingress", name,
 			"-o", `go-template={{index .metadata.annotations "kindling.dev/original-host"}}`,
 		)
 		if err != nil {
@@ -290,7 +626,7 @@ func restoreIngresses() {
 		}
 
 		// 3. If a saved TLS block exists, restore it and remove the annotation
-		tlsJSON, _ := runSilent("kubectl", "get", "ingress", name,
+		tlsJSON, _ := runSilent("kubectl", "--context", kindContext(), "get", "ingress", name,
 			"-o", `go-template={{index .metadata.annotations "kindling.dev/original-tls"}}`,
 		)
 		tlsJSON = strings.TrimSpace(tlsJSON)
@@ -305,7 +641,7 @@ func restoreIngresses() {
 		}
 
 		patchBytes, _ := json.Marshal(ops)
-		if _, err := runSilent("kubectl", "patch", "ingress", name,
+		if _, err := runSilent("kubectl", "--context", kindContext(), "patch", "ingress", name,
 			"--type=json", "-p="+string(patchBytes)); err == nil {
 			restored++
 		}
@@ -316,9 +652,156 @@ func restoreIngresses() {
 	}
 }
 
+// routeTunnel points the tunnel hostname at the app: the --bind
+// DevStagingEnvironment when set, otherwise the first Ingress.
+func routeTunnel(publicURL string) error {
+	if exposeBind == "" {
+		patchIngressesForTunnel(publicURL)
+		return nil
+	}
+	// Self-heal a bind left behind by a tunnel that died without cleanup.
+	restoreBoundDSEs()
+
+	hostname := publicURL
+	if u, err := url.Parse(publicURL); err == nil && u.Host != "" {
+		hostname = u.Host
+	}
+	ops, err := dseTunnelBindOps(exposeBind, hostname)
+	if err != nil {
+		return err
+	}
+	patchBytes, _ := json.Marshal(ops)
+	if _, err := core.Kubectl(clusterName, "patch", "devstagingenvironment", exposeBind,
+		"-n", "default", "--type=json", "-p", string(patchBytes)); err != nil {
+		return fmt.Errorf("bind %s to the tunnel: %w", exposeBind, err)
+	}
+	step("🔀", fmt.Sprintf("Routing tunnel → devstagingenvironment/%s (spec.ingress.host = %s)", exposeBind, hostname))
+	return nil
+}
+
+// dseTunnelBindOps reads DevStagingEnvironment name and returns the JSON
+// patch that sets spec.ingress.host to hostname, saving the original host
+// (and TLS block, since the tunnel terminates TLS) as annotations. The
+// environment must have its ingress enabled.
+func dseTunnelBindOps(name, hostname string) ([]map[string]interface{}, error) {
+	out, err := kubectlJSON("get", "devstagingenvironment", name, "-n", "default", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("devstagingenvironment %q not found in namespace default", name)
+	}
+	return buildDSETunnelBindOps([]byte(out), hostname)
+}
+
+// buildDSETunnelBindOps is dseTunnelBindOps on an already-fetched
+// DevStagingEnvironment.
+func buildDSETunnelBindOps(dseJSON []byte, hostname string) ([]map[string]interface{}, error) {
+	var dse struct {
+		Metadata struct {
+			Name        string            `json:"name"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+		Spec struct {
+			Ingress *struct {
+				Enabled bool            `json:"enabled"`
+				Host    string          `json:"host"`
+				TLS     json.RawMessage `json:"tls"`
+			} `json:"ingress"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(dseJSON, &dse); err != nil {
+		return nil, fmt.Errorf("parse devstagingenvironment: %w", err)
+	}
+	ing := dse.Spec.Ingress
+	if ing == nil || !ing.Enabled {
+		return nil, fmt.Errorf("devstagingenvironment %q has no ingress — set spec.ingress.enabled: true to bind it to a tunnel", dse.Metadata.Name)
+	}
+
+	var ops []map[string]interface{}
+	if dse.Metadata.Annotations == nil {
+		ops = append(ops, map[string]interface{}{"op": "add", "path": "/metadata/annotations", "value": map[string]string{}})
+	}
+	// Keep the first saved host if it is already bound, so rebinding
+	// never records a tunnel hostname as the original.
+	if _, bound := dse.Metadata.Annotations[originalHostAnnotation]; !bound {
+		ops = append(ops, map[string]interface{}{"op": "add", "path": "/metadata/annotations/" + strings.ReplaceAll(originalHostAnnotation, "/", "~1"), "value": ing.Host})
+		if len(ing.TLS) > 0 && string(ing.TLS) != "null" {
+			ops = append(ops,
+				map[string]interface{}{"op": "add", "path": "/metadata/annotations/" + strings.ReplaceAll(originalTLSAnnotation, "/", "~1"), "value": string(ing.TLS)},
+				map[string]interface{}{"op": "remove", "path": "/spec/ingress/tls"},
+			)
+		}
+	}
+	ops = append(ops, map[string]interface{}{"op": "add", "path": "/spec/ingress/host", "value": hostname})
+	return ops, nil
+}
+
+// buildDSETunnelRestoreOps returns the JSON patch that undoes
+// buildDSETunnelBindOps, or nil if the DevStagingEnvironment isn't bound.
+func buildDSETunnelRestoreOps(annotations map[string]string) []map[string]interface{} {
+	originalHost, bound := annotations[originalHostAnnotation]
+	if !bound {
+		return nil
+	}
+	ops := []map[string]interface{}{
+		{"op": "remove", "path": "/metadata/annotations/" + strings.ReplaceAll(originalHostAnnotation, "/", "~1")},
+	}
+	if originalHost == "" {
+		ops = append(ops, map[string]interface{}{"op": "remove", "path": "/spec/ingress/host"})
+	} else {
+		ops = append(ops, map[string]interface{}{"op": "add", "path": "/spec/ingress/host", "value": originalHost})
+	}
+	if tlsJSON, ok := annotations[originalTLSAnnotation]; ok {
+		var tlsBlock interface{}
+		if json.Unmarshal([]byte(tlsJSON), &tlsBlock) == nil {
+			ops = append(ops,
+				map[string]interface{}{"op": "add", "path": "/spec/ingress/tls", "value": tlsBlock},
+				map[string]interface{}{"op": "remove", "path": "/metadata/annotations/" + strings.ReplaceAll(originalTLSAnnotation, "/", "~1")},
+			)
+		}
+	}
+	return ops
+}
+
+// restoreBoundDSEs reverts every DevStagingEnvironment bound to a tunnel
+// with --bind. The operator then puts its Ingress back on the original host.
+func restoreBoundDSEs() {
+	out, err := runSilent("kubectl", "--context", kindContext(), "get", "devstagingenvironments", "-n", "default", "-o", "json")
+	if err != nil {
+		return
+	}
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name        string            `json:"name"`
+				Annotations map[string]string `json:"annotations"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if json.Unmarshal([]byte(out), &list) != nil {
+		return
+	}
+	for _, item := range list.Items {
+		ops := buildDSETunnelRestoreOps(item.Metadata.Annotations)
+		if ops == nil {
+			continue
+		}
+		patchBytes, _ := json.Marshal(ops)
+		if _, err := runSilent("kubectl", "--context", kindContext(), "patch", "devstagingenvironment", item.Metadata.Name,
+			"-n", "default", "--type=json", "-p="+string(patchBytes)); err == nil {
+			step("🔀", fmt.Sprintf("Restored devstagingenvironment/%s to its original host", item.Metadata.Name))
+		}
+	}
+}
+
+// restoreTunnelRoutes undoes both kinds of tunnel routing: patched
+// Ingresses and --bind DevStagingEnvironments.
+func restoreTunnelRoutes() {
+	restoreIngresses()
+	restoreBoundDSEs()
+}
+
 // getIngressNames returns the names of all Ingresses in the default namespace.
 func getIngressNames() ([]string, error) {
-	out, err := runSilent("kubectl", "get", "ingress",
+	out, err := runSilent("kubectl", "--context", kindContext(), "get", "ingress",
 		"-o", "jsonpath={.items[*].metadata.name}")
 	if err != nil {
 		return nil, err
@@ -1,25 +1,206 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/jeffvincent/kindling/pkg/ci"
 )
 
 // callGenAI dispatches to the appropriate provider and returns the model's
-// text response. It supports OpenAI-compatible and Anthropic APIs.
+// text response. It supports OpenAI-compatible, Azure OpenAI, Anthropic,
+// Gemini, and local Ollama APIs. Rate limits and server errors are retried
+// up to --max-retries times with exponential backoff, honouring
+// Retry-After; any other error is returned at once.
 func callGenAI(provider, apiKey, model, systemPrompt, userPrompt string) (string, error) {
+	for attempt := 0; ; attempt++ {
+		out, err := callGenAIOnce(provider, apiKey, model, systemPrompt, userPrompt)
+		if err == nil || !isTransientAPIError(err) || attempt >= genMaxRetries {
+			return out, err
+		}
+		delay := retryDelay(err, attempt)
+		var se *apiStatusError
+		errors.As(err, &se)
+		warn(fmt.Sprintf("%s API returned HTTP %d; retrying in %s (retry %d/%d)",
+			se.provider, se.code, delay, attempt+1, genMaxRetries))
+		time.Sleep(delay)
+	}
+}
+
+// genRetryBaseDelay is the wait before the first retry; it doubles on each
+// subsequent one up to genRetryMaxDelay, which also caps Retry-After.
+var (
+	genRetryBaseDelay = 2 * time.Second
+	genRetryMaxDelay  = 60 * time.Second
+)
+
+// retryDelay returns how long to wait before retrying after err: the
+// server's Retry-After when it sent one, exponential backoff otherwise.
+func retryDelay(err error, attempt int) time.Duration {
+	var se *apiStatusError
+	if errors.As(err, &se) && se.retryAfter > 0 {
+		return min(se.retryAfter, genRetryMaxDelay)
+	}
+	delay := genRetryBaseDelay << attempt
+	if delay <= 0 || delay > genRetryMaxDelay {
+		return genRetryMaxDelay
+	}
+	return delay
+}
+
+// genStreamOut receives the response text as it arrives when non-nil.
+// Only providers in streamingProviders honour it; the others still return
+// the whole response at once.
+var genStreamOut io.Writer
+
+// streamingProviders are the providers whose APIs support SSE streaming.
+var streamingProviders = map[string]bool{"openai": true, "azure": true, "anthropic": true}
+
+// callGenAIOnce makes a single request to the provider's API.
+func callGenAIOnce(provider, apiKey, model, systemPrompt, userPrompt string) (string, error) {
 	switch provider {
 	case "openai":
 		return callOpenAI(apiKey, model, systemPrompt, userPrompt)
+	case "azure":
+		return callAzureOpenAI(genBaseURL, genAPIVersion, apiKey, model, systemPrompt, userPrompt)
 	case "anthropic":
 		return callAnthropic(apiKey, model, systemPrompt, userPrompt)
+	case "gemini":
+		return callGemini(apiKey, model, systemPrompt, userPrompt)
+	case "ollama":
+		return callOllama(genBaseURL, model, trimSystemPromptForLocal(systemPrompt), userPrompt)
 	default:
-		return "", fmt.Errorf("unsupported provider %q (use \"openai\" or \"anthropic\")", provider)
+		return "", fmt.Errorf("unsupported provider %q (use \"openai\", \"azure\", \"anthropic\", \"gemini\", or \"ollama\")", provider)
+	}
+}
+
+// apiStatusError is returned when a provider API answers with a non-200
+// status. Keeping the status code lets callers tell transient failures
+// (rate limits, server errors) from permanent ones (bad key, bad model).
+type apiStatusError struct {
+	provider   string
+	code       int
+	body       string
+	retryAfter time.Duration // from the Retry-After header; 0 if absent
+}
+
+// newAPIStatusError builds the error for a non-200 response.
+func newAPIStatusError(provider string, resp *http.Response, body []byte) *apiStatusError {
+	return &apiStatusError{
+		provider:   provider,
+		code:       resp.StatusCode,
+		body:       string(body),
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter parses a Retry-After value, either delay-seconds or an
+// HTTP date, into a wait from now. It returns 0 for empty or invalid
+// values and for dates in the past.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func (e *apiStatusError) Error() string {
+	return fmt.Sprintf("%s API returned HTTP %d: %s", e.provider, e.code, e.body)
+}
+
+// isTransientAPIError reports whether err is an HTTP 429 or 5xx response.
+func isTransientAPIError(err error) bool {
+	var se *apiStatusError
+	if !errors.As(err, &se) {
+		return false
+	}
+	return se.code == http.StatusTooManyRequests || se.code >= 500
+}
+
+// parseModelChain splits a comma-separated --model value into an ordered
+// fallback chain, dropping empty entries.
+func parseModelChain(s string) []string {
+	var models []string
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// callGenAIWithFallback tries each model in order with the same provider.
+// It moves on to the next model when the API returns a transient error or
+// when validate rejects the response; any other error stops the chain.
+// Returns the raw response and the model that produced it.
+func callGenAIWithFallback(provider, apiKey string, models []string, systemPrompt, userPrompt string, validate func(string) error) (string, string, error) {
+	if len(models) == 0 {
+		return "", "", fmt.Errorf("no model specified")
+	}
+	var lastErr error
+	for i, model := range models {
+		if i > 0 {
+			step("↪️", fmt.Sprintf("Falling back to %s", model))
+		}
+		out, err := callGenAI(provider, apiKey, model, systemPrompt, userPrompt)
+		if err != nil {
+			if !isTransientAPIError(err) {
+				return "", model, err
+			}
+			warn(fmt.Sprintf("%s: %v", model, err))
+			lastErr = err
+			continue
+		}
+		if validate != nil {
+			if verr := validate(out); verr != nil {
+				warn(fmt.Sprintf("%s produced an invalid workflow: %v", model, verr))
+				lastErr = verr
+				continue
+			}
+		}
+		return out, model, nil
+	}
+	return "", models[len(models)-1], fmt.Errorf("all models failed (%s): %w", strings.Join(models, ", "), lastErr)
+}
+
+// providerNeedsAPIKey reports whether the provider requires an API key.
+// Local providers (Ollama) run unauthenticated.
+func providerNeedsAPIKey(provider string) bool {
+	return provider != "ollama"
+}
+
+// defaultModel returns the model used when --model is not set.
+func defaultModel(provider string) string {
+	switch provider {
+	case "anthropic":
+		return "claude-sonnet-4-20250514"
+	case "gemini":
+		return "gemini-2.5-pro"
+	case "ollama":
+		return "qwen2.5-coder:14b"
+	case "azure":
+		return "gpt-4o"
+	default:
+		return "o3"
 	}
 }
 
@@ -33,6 +214,19 @@ type openAIRequest struct {
 	Temperature         *float64        `json:"temperature,omitempty"`
 	MaxTokens           int             `json:"max_tokens,omitempty"`
 	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Stream              bool            `json:"stream,omitempty"`
+}
+
+// openAIStreamChunk is one SSE event of a streamed chat completion.
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
 }
 
 type openAIMessage struct {
@@ -57,13 +251,13 @@ func isReasoningModel(model string) bool {
 	return strings.HasPrefix(model, "o1") || strings.HasPrefix(model, "o3")
 }
 
-func callOpenAI(apiKey, model, systemPrompt, userPrompt string) (string, error) {
-	var reqBody openAIRequest
-
+// newOpenAIRequest builds a chat completions body, adjusting parameters for
+// reasoning models.
+func newOpenAIRequest(model, systemPrompt, userPrompt string) openAIRequest {
 	if isReasoningModel(model) {
 		// Reasoning models: use "developer" role, max_completion_tokens,
 		// and no temperature parameter.
-		reqBody = openAIRequest{
+		return openAIRequest{
 			Model: model,
 			Messages: []openAIMessage{
 				{Role: "developer", Content: systemPrompt},
@@ -71,19 +265,31 @@ func callOpenAI(apiKey, model, systemPrompt, userPrompt string) (string, error)
 			},
 			MaxCompletionTokens: 32768,
 		}
-	} else {
-		temp := 0.2
-		reqBody = openAIRequest{
-			Model: model,
-			Messages: []openAIMessage{
-				{Role: "system", Content: systemPrompt},
-				{Role: "user", Content: userPrompt},
-			},
-			Temperature: &temp,
-			MaxTokens:   8192,
-		}
 	}
+	temp := 0.2
+	return openAIRequest{
+		Model: model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Temperature: &temp,
+		MaxTokens:   8192,
+	}
+}
+
+// openAITimeout returns the HTTP timeout for a model. Reasoning models can
+// take longer to think.
+func openAITimeout(model string) time.Duration {
+	if isReasoningModel(model) {
+		return 300 * time.Second
+	}
+	return 120 * time.Second
+}
 
+func callOpenAI(apiKey, model, systemPrompt, userPrompt string) (string, error) {
+	reqBody := newOpenAIRequest(model, systemPrompt, userPrompt)
+	reqBody.Stream = genStreamOut != nil
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("marshal request: %w", err)
@@ -96,11 +302,12 @@ func callOpenAI(apiKey, model, systemPrompt, userPrompt string) (string, error)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 
-	// Reasoning models can take longer to think.
-	timeout := 120 * time.Second
-	if isReasoningModel(model) {
-		timeout = 300 * time.Second
-	}
+	return doOpenAIRequest(req, "OpenAI", openAITimeout(model))
+}
+
+// doOpenAIRequest sends a chat completions request and extracts the first
+// choice. Shared by OpenAI and Azure OpenAI, which use the same body schema.
+func doOpenAIRequest(req *http.Request, provider string, timeout time.Duration) (string, error) {
 	client := &http.Client{Timeout: timeout}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -108,13 +315,17 @@ func callOpenAI(apiKey, model, systemPrompt, userPrompt string) (string, error)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusOK && genStreamOut != nil {
+		return readOpenAIStream(resp.Body, provider, genStreamOut)
+	}
+
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("OpenAI API returned HTTP %d: %s", resp.StatusCode, string(respBody))
+		return "", newAPIStatusError(provider, resp, respBody)
 	}
 
 	var result openAIResponse
@@ -123,16 +334,124 @@ func callOpenAI(apiKey, model, systemPrompt, userPrompt string) (string, error)
 	}
 
 	if result.Error != nil {
-		return "", fmt.Errorf("OpenAI API error: %s", result.Error.Message)
+		return "", fmt.Errorf("%s API error: %s", provider, result.Error.Message)
 	}
 
 	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("OpenAI API returned no choices")
+		return "", fmt.Errorf("%s API returned no choices", provider)
 	}
 
 	return result.Choices[0].Message.Content, nil
 }
 
+// readOpenAIStream collects a streamed chat completion, copying each delta
+// to out as it arrives.
+func readOpenAIStream(r io.Reader, provider string, out io.Writer) (string, error) {
+	return readSSE(r, out, func(data string) (string, bool, error) {
+		if data == "[DONE]" {
+			return "", true, nil
+		}
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", false, fmt.Errorf("parse stream event: %w", err)
+		}
+		if chunk.Error != nil {
+			return "", false, fmt.Errorf("%s API error: %s", provider, chunk.Error.Message)
+		}
+		if len(chunk.Choices) == 0 {
+			return "", false, nil
+		}
+		return chunk.Choices[0].Delta.Content, false, nil
+	})
+}
+
+// readSSE reads a server-sent event stream, passing the payload of each
+// data line to event, which returns the text it carries and whether the
+// stream is done. The text is copied to out and accumulated into the
+// result; a stream that ends before done is an error. A newline is written
+// to out after any text so later output starts on a fresh line.
+func readSSE(r io.Reader, out io.Writer, event func(data string) (text string, done bool, err error)) (string, error) {
+	var sb strings.Builder
+	defer func() {
+		if sb.Len() > 0 {
+			fmt.Fprintln(out)
+		}
+	}()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // event names, comments, and blank separators
+		}
+		text, done, err := event(strings.TrimSpace(data))
+		if err != nil {
+			return "", err
+		}
+		if text != "" {
+			sb.WriteString(text)
+			fmt.Fprint(out, text)
+		}
+		if done {
+			return sb.String(), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("read stream: %w", err)
+	}
+	return "", fmt.Errorf("API stream ended before the response was complete")
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Azure OpenAI
+// ────────────────────────────────────────────────────────────────────────────
+
+// defaultAzureAPIVersion is the Azure OpenAI data-plane API version used
+// when --api-version is not set.
+const defaultAzureAPIVersion = "2024-10-21"
+
+// azureChatURL builds the chat completions URL for an Azure OpenAI
+// deployment: <base>/openai/deployments/<deployment>/chat/completions?api-version=<v>.
+func azureChatURL(baseURL, deployment, apiVersion string) (string, error) {
+	if baseURL == "" {
+		return "", fmt.Errorf("--base-url is required for azure (e.g. https://<resource>.openai.azure.com)")
+	}
+	if apiVersion == "" {
+		apiVersion = defaultAzureAPIVersion
+	}
+	base := strings.TrimRight(baseURL, "/")
+	base = strings.TrimSuffix(base, "/openai")
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		base, url.PathEscape(deployment), url.QueryEscape(apiVersion)), nil
+}
+
+// callAzureOpenAI calls an Azure OpenAI deployment. The model argument is
+// the deployment name; Azure authenticates with an api-key header instead
+// of a bearer token.
+func callAzureOpenAI(baseURL, apiVersion, apiKey, deployment, systemPrompt, userPrompt string) (string, error) {
+	endpoint, err := azureChatURL(baseURL, deployment, apiVersion)
+	if err != nil {
+		return "", err
+	}
+
+	reqBody := newOpenAIRequest(deployment, systemPrompt, userPrompt)
+	reqBody.Stream = genStreamOut != nil
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("api-key", apiKey)
+
+	return doOpenAIRequest(req, "Azure OpenAI", openAITimeout(deployment))
+}
+
 // ────────────────────────────────────────────────────────────────────────────
 // Anthropic
 // ────────────────────────────────────────────────────────────────────────────
@@ -143,6 +462,21 @@ type anthropicRequest struct {
 	System      string             `json:"system,omitempty"`
 	Messages    []anthropicMessage `json:"messages"`
 	Temperature float64            `json:"temperature"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+// anthropicStreamEvent is one SSE event of a streamed message. Only text
+// deltas and errors matter; other event types are skipped.
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
 }
 
 type anthropicMessage struct {
@@ -170,6 +504,7 @@ func callAnthropic(apiKey, model, systemPrompt, userPrompt string) (string, erro
 			{Role: "user", Content: userPrompt},
 		},
 		Temperature: 0.2,
+		Stream:      genStreamOut != nil,
 	}
 
 	body, err := json.Marshal(reqBody)
@@ -192,13 +527,17 @@ func callAnthropic(apiKey, model, systemPrompt, userPrompt string) (string, erro
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusOK && genStreamOut != nil {
+		return readAnthropicStream(resp.Body, genStreamOut)
+	}
+
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("Anthropic API returned HTTP %d: %s", resp.StatusCode, string(respBody))
+		return "", newAPIStatusError("Anthropic", resp, respBody)
 	}
 
 	var result anthropicResponse
@@ -224,3 +563,249 @@ func callAnthropic(apiKey, model, systemPrompt, userPrompt string) (string, erro
 
 	return sb.String(), nil
 }
+
+// readAnthropicStream collects a streamed message, copying each text delta
+// to out as it arrives.
+func readAnthropicStream(r io.Reader, out io.Writer) (string, error) {
+	return readSSE(r, out, func(data string) (string, bool, error) {
+		var ev anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return "", false, fmt.Errorf("parse stream event: %w", err)
+		}
+		switch ev.Type {
+		case "error":
+			if ev.Error == nil {
+				return "", false, fmt.Errorf("Anthropic API error")
+			}
+			if ev.Error.Type == "overloaded_error" {
+				// Retryable, as the equivalent HTTP 529 would be.
+				return "", false, &apiStatusError{provider: "Anthropic", code: 529, body: ev.Error.Message}
+			}
+			return "", false, fmt.Errorf("Anthropic API error: %s: %s", ev.Error.Type, ev.Error.Message)
+		case "content_block_delta":
+			if ev.Delta.Type == "text_delta" {
+				return ev.Delta.Text, false, nil
+			}
+		case "message_stop":
+			return "", true, nil
+		}
+		return "", false, nil
+	})
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Google Gemini
+// ────────────────────────────────────────────────────────────────────────────
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent         `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent        `json:"contents"`
+	GenerationConfig  geminiGenerationConfig `json:"generationConfig"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature     float64 `json:"temperature"`
+	MaxOutputTokens int     `json:"maxOutputTokens"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error,omitempty"`
+}
+
+func callGemini(apiKey, model, systemPrompt, userPrompt string) (string, error) {
+	reqBody := geminiRequest{
+		SystemInstruction: &geminiContent{
+			Parts: []geminiPart{{Text: systemPrompt}},
+		},
+		Contents: []geminiContent{
+			{Role: "user", Parts: []geminiPart{{Text: userPrompt}}},
+		},
+		GenerationConfig: geminiGenerationConfig{
+			Temperature:     0.2,
+			MaxOutputTokens: 8192,
+		},
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent", model)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", apiKey)
+
+	// Gemini 2.5 models think before answering, so allow extra time.
+	client := &http.Client{Timeout: 300 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIStatusError("Gemini", resp, respBody)
+	}
+
+	return parseGeminiResponse(respBody)
+}
+
+// parseGeminiResponse extracts the text from a generateContent response.
+func parseGeminiResponse(respBody []byte) (string, error) {
+	var result geminiResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+
+	if result.Error != nil {
+		return "", fmt.Errorf("Gemini API error: %s: %s", result.Error.Status, result.Error.Message)
+	}
+
+	if len(result.Candidates) == 0 {
+		return "", fmt.Errorf("Gemini API returned no candidates")
+	}
+
+	// Concatenate all text parts of the first candidate
+	var sb strings.Builder
+	for _, part := range result.Candidates[0].Content.Parts {
+		sb.WriteString(part.Text)
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("Gemini API returned an empty candidate (finish reason: %s)",
+			result.Candidates[0].FinishReason)
+	}
+
+	return sb.String(), nil
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Ollama (local)
+// ────────────────────────────────────────────────────────────────────────────
+
+// defaultOllamaURL is where `ollama serve` listens by default.
+const defaultOllamaURL = "http://localhost:11434"
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  *ollamaOptions  `json:"options,omitempty"`
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumCtx      int     `json:"num_ctx,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	Error string `json:"error,omitempty"`
+}
+
+// localPromptOmissions are system-prompt sections dropped for local models.
+// Smaller models lose track of the core build/deploy rules when the prompt
+// is padded with guidance for edge cases they rarely handle well anyway.
+var localPromptOmissions = []string{
+	ci.PromptMultiAgentArchitecture,
+	ci.PromptOAuth,
+	ci.PromptBuildTimeout,
+}
+
+// trimSystemPromptForLocal removes the optional sections listed in
+// localPromptOmissions from a provider's system prompt.
+func trimSystemPromptForLocal(systemPrompt string) string {
+	for _, section := range localPromptOmissions {
+		systemPrompt = strings.Replace(systemPrompt, section, "", 1)
+	}
+	return systemPrompt
+}
+
+func callOllama(baseURL, model, systemPrompt, userPrompt string) (string, error) {
+	if baseURL == "" {
+		baseURL = defaultOllamaURL
+	}
+
+	reqBody := ollamaRequest{
+		Model: model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Stream: false,
+		Options: &ollamaOptions{
+			Temperature: 0.2,
+			NumCtx:      32768,
+		},
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimRight(baseURL, "/")+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Local inference on a laptop is slow, especially on first model load.
+	client := &http.Client{Timeout: 600 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("API request failed (is `ollama serve` running at %s?): %w", baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIStatusError("Ollama", resp, respBody)
+	}
+
+	var result ollamaResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+
+	if result.Error != "" {
+		return "", fmt.Errorf("Ollama API error: %s", result.Error)
+	}
+
+	if result.Message.Content == "" {
+		return "", fmt.Errorf("Ollama API returned an empty message")
+	}
+
+	return result.Message.Content, nil
+}
@@ -6,8 +6,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/jeffvincent/kindling/pkg/ci"
 	"github.com/spf13/cobra"
@@ -24,15 +28,24 @@ to detect services and backing dependencies, then produces a
 dev-deploy workflow that uses the reusable kindling-build and
 kindling-deploy composite actions.
 
-Supports OpenAI-compatible and Anthropic APIs.
+Supports OpenAI-compatible, Azure OpenAI, Anthropic, and Google Gemini
+APIs, plus a local Ollama server for offline use (no API key needed).
 Supports GitHub Actions and GitLab CI via --ci-provider.
 
 Examples:
   kindling generate --api-key sk-... --repo-path /path/to/my-app
   kindling generate -k sk-... -r . --ai-provider openai --model o3
+  kindling generate -k sk-... -r . --model o3,gpt-4o,gpt-4o-mini
   kindling generate -k sk-... -r . --ci-provider gitlab
   kindling generate -k sk-ant-... -r . --ai-provider anthropic
-  kindling generate -k sk-... -r . --dry-run`,
+  kindling generate -k AIza... -r . --ai-provider gemini
+  kindling generate -k <key> -r . --ai-provider azure --base-url https://myres.openai.azure.com --model my-gpt4o
+  kindling generate -r . --ai-provider ollama --model qwen2.5-coder:14b
+  kindling generate -k sk-... -r . --dry-run
+  kindling generate -k sk-... -r . --explain
+  kindling generate -k sk-... -r . --update
+  kindling generate -k sk-... -r . --services services/api,services/web
+  kindling generate -k sk-... -r . --prompt-file .kindling/prompt.tmpl`,
 	RunE: runGenerate,
 }
 
@@ -45,18 +58,37 @@ var (
 	genBranch     string
 	genDryRun     bool
 	genCIProvider string
+	genBaseURL    string
+	genAPIVersion string
+	genExplain    bool
+	genMaxTokens  int
+	genNoCache    bool
+	genUpdate     bool
+	genPromptFile string
+	genMaxRetries int
+	genStream     bool
+	genServices   string
 )
 
 func init() {
-	generateCmd.Flags().StringVarP(&genAPIKey, "api-key", "k", "", "GenAI API key (required)")
+	generateCmd.Flags().StringVarP(&genAPIKey, "api-key", "k", "", "GenAI API key (required except for ollama)")
 	generateCmd.Flags().StringVarP(&genRepoPath, "repo-path", "r", ".", "Path to the local repository to analyze")
-	generateCmd.Flags().StringVar(&genProvider, "ai-provider", "openai", "AI provider: openai or anthropic")
-	generateCmd.Flags().StringVar(&genModel, "model", "", "Model name (default: o3 for openai, claude-sonnet-4-20250514 for anthropic)")
+	generateCmd.Flags().StringVar(&genProvider, "ai-provider", "openai", "AI provider: openai, azure, anthropic, gemini, or ollama")
+	generateCmd.Flags().StringVar(&genModel, "model", "", "Model name, or a comma-separated fallback chain (default: o3 for openai, claude-sonnet-4-20250514 for anthropic, gemini-2.5-pro for gemini, qwen2.5-coder:14b for ollama; the deployment name for azure)")
 	generateCmd.Flags().StringVarP(&genOutput, "output", "o", "", "Output path (default: <repo-path>/.github/workflows/dev-deploy.yml)")
 	generateCmd.Flags().StringVarP(&genBranch, "branch", "b", "", "Branch to trigger on (default: auto-detect from git, fallback to 'main')")
 	generateCmd.Flags().BoolVar(&genDryRun, "dry-run", false, "Print the generated workflow to stdout instead of writing a file")
 	generateCmd.Flags().StringVar(&genCIProvider, "ci-provider", "", "CI platform to generate for (github, gitlab; default: github)")
-	_ = generateCmd.MarkFlagRequired("api-key")
+	generateCmd.Flags().IntVar(&genMaxTokens, "max-context-tokens", 0, "Approximate token budget for the prompt; lowest-priority context is dropped to fit (default: based on model)")
+	generateCmd.Flags().StringVar(&genPromptFile, "prompt-file", "", "Go text/template that renders the system prompt (.Repo, .CI, and .Default are available)")
+	generateCmd.Flags().BoolVar(&genUpdate, "update", false, "Merge into the existing workflow, preserving hand edits, and print a diff before writing")
+	generateCmd.Flags().BoolVar(&genNoCache, "no-scan-cache", false, "Always rescan the repo instead of reusing ~/.kindling/scan-cache")
+	generateCmd.Flags().BoolVar(&genExplain, "explain", false, "Also write <workflow>.explain.md summarizing why the AI chose each dependency, secret, and port")
+	generateCmd.Flags().StringVar(&genBaseURL, "base-url", "", "Base URL of the model server (ollama default: "+defaultOllamaURL+"; azure: https://<resource>.openai.azure.com)")
+	generateCmd.Flags().StringVar(&genAPIVersion, "api-version", defaultAzureAPIVersion, "Azure OpenAI API version (azure only)")
+	generateCmd.Flags().BoolVar(&genStream, "stream", false, "Stream the model's output to stderr as it arrives (openai, azure, and anthropic)")
+	generateCmd.Flags().StringVar(&genServices, "services", "", "Comma-separated service directories to generate for (e.g. services/api,services/web); the rest of the repo is ignored")
+	generateCmd.Flags().IntVar(&genMaxRetries, "max-retries", 3, "Retries per model for rate limits (429) and server errors (5xx), with exponential backoff")
 	rootCmd.AddCommand(generateCmd)
 }
 
@@ -70,15 +102,29 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("repo path does not exist or is not a directory: %s", repoPath)
 	}
 
+	if genAPIKey == "" && providerNeedsAPIKey(genProvider) {
+		return fmt.Errorf("--api-key is required for provider %q", genProvider)
+	}
+	if genProvider == "azure" && genBaseURL == "" {
+		return fmt.Errorf("--base-url is required for provider \"azure\" (e.g. https://<resource>.openai.azure.com)")
+	}
+
 	if genModel == "" {
-		switch genProvider {
-		case "anthropic":
-			genModel = "claude-sonnet-4-20250514"
-		default:
-			genModel = "o3"
+		genModel = defaultModel(genProvider)
+	}
+
+	var promptTmpl *template.Template
+	if genPromptFile != "" {
+		if promptTmpl, err = loadPromptTemplate(genPromptFile); err != nil {
+			return err
 		}
 	}
 
+	services, err := parseServicePaths(repoPath, genServices)
+	if err != nil {
+		return err
+	}
+
 	// ── Resolve CI provider ──────────────────────────────────────
 	ciProv, err := resolveProvider(genCIProvider)
 	if err != nil {
@@ -104,11 +150,30 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	header("Analyzing repository")
 	step("📂", repoPath)
 
-	repoCtx, err := scanRepo(repoPath)
+	if len(services) > 0 {
+		step("🎯", fmt.Sprintf("Scoped to %d service(s): %s", len(services), strings.Join(services, ", ")))
+	}
+
+	repoCtx, cached, err := scanRepoCached(repoPath, !genNoCache, services...)
 	if err != nil {
 		return fmt.Errorf("repo scan failed: %w", err)
 	}
 	repoCtx.branch = genBranch
+	if genUpdate {
+		existing, err := os.ReadFile(genOutput)
+		switch {
+		case err == nil:
+			repoCtx.existingWorkflow = string(existing)
+			step("🔁", fmt.Sprintf("Updating existing workflow %s", genOutput))
+		case os.IsNotExist(err):
+			warn(fmt.Sprintf("--update: %s does not exist yet — generating from scratch", genOutput))
+		default:
+			return fmt.Errorf("cannot read existing workflow: %w", err)
+		}
+	}
+	if cached {
+		step("⚡", "Repo unchanged since last scan — using cached results (--no-scan-cache to rescan)")
+	}
 
 	success(fmt.Sprintf("Found %d Dockerfile(s), %d dependency manifest(s), %d source file(s)",
 		repoCtx.dockerfileCount, repoCtx.depFileCount, len(repoCtx.sourceSnippets)))
@@ -131,7 +196,7 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(os.Stderr, "       • %s\n", hint)
 		}
 		fmt.Fprintln(os.Stderr)
-		step("💡", fmt.Sprintf("Run %skindling expose%s to create a public HTTPS tunnel for OAuth callbacks",
+		step("💡", fmt.Sprintf("Run %skindling expose --bind <name>%s to route a public HTTPS tunnel to the app handling OAuth callbacks",
 			colorCyan, colorReset))
 	}
 
@@ -167,6 +232,37 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if len(repoCtx.scheduledJobs) > 0 {
+		fmt.Fprintln(os.Stderr)
+		step("⏰", fmt.Sprintf("%sDetected scheduled jobs:%s", colorBold, colorReset))
+		for _, j := range repoCtx.scheduledJobs {
+			fmt.Fprintf(os.Stderr, "       • %s\n", j)
+		}
+	}
+
+	if len(repoCtx.embeddedDBs) > 0 {
+		step("🗃️", fmt.Sprintf("Embedded database(s): %s (no database pod needed for these)",
+			strings.Join(repoCtx.embeddedDBs, ", ")))
+	}
+
+	if len(repoCtx.temporalHints) > 0 {
+		step("⏱️", fmt.Sprintf("Temporal workflows: %s", strings.Join(repoCtx.temporalHints, ", ")))
+	}
+	if len(repoCtx.daprHints) > 0 {
+		warn(fmt.Sprintf("Dapr detected (%s) — sidecar injection isn't supported yet",
+			strings.Join(repoCtx.daprHints, ", ")))
+	}
+	if repoCtx.healthEndpoint != "" {
+		step("🩺", fmt.Sprintf("Health check endpoint: %s", repoCtx.healthEndpoint))
+	}
+	if len(repoCtx.webSockets) > 0 {
+		step("🔌", fmt.Sprintf("WebSocket endpoints: %s", strings.Join(repoCtx.webSockets, ", ")))
+	}
+	if len(repoCtx.gpuWorkloads) > 0 {
+		step("🧠", fmt.Sprintf("ML/GPU workloads: %s (memory requests raised; Kind has no GPU)",
+			strings.Join(repoCtx.gpuWorkloads, ", ")))
+	}
+
 	// Dockerfile build-context warnings
 	if len(repoCtx.dockerfileWarnings) > 0 {
 		fmt.Fprintln(os.Stderr)
@@ -183,23 +279,80 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 
 	// ── Call the AI ──────────────────────────────────────────────
 	header("Generating workflow with AI")
-	step("🤖", fmt.Sprintf("Provider: %s, Model: %s", genProvider, genModel))
+	models := parseModelChain(genModel)
+	step("🤖", fmt.Sprintf("Provider: %s, Model: %s", genProvider, strings.Join(models, " → ")))
+
+	maxTokens := genMaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxContextTokens(genProvider, models)
+	}
+	dropped, err := trimContextToBudget(repoCtx, ciProv, promptTmpl, maxTokens)
+	if err != nil {
+		return err
+	}
+	if len(dropped) > 0 {
+		warn(fmt.Sprintf("Prompt exceeded ~%d tokens — dropped %d item(s) to fit (generation uses partial context):", maxTokens, len(dropped)))
+		for _, d := range dropped {
+			fmt.Fprintf(os.Stderr, "       • %s\n", d)
+		}
+	}
 
-	systemPrompt, userPrompt := buildGeneratePrompt(repoCtx, ciProv)
+	systemPrompt, userPrompt, err := generatePrompts(promptTmpl, repoCtx, ciProv)
+	if err != nil {
+		return err
+	}
+	if promptTmpl != nil {
+		step("📝", fmt.Sprintf("Using system prompt template %s", genPromptFile))
+	}
 
+	if genStream && !streamingProviders[genProvider] {
+		warn(fmt.Sprintf("--stream is not supported for %s; waiting for the full response", genProvider))
+	} else if genStream {
+		genStreamOut = os.Stderr
+	}
 	step("⏳", "Calling API (this may take a moment)...")
-	workflow, err := callGenAI(genProvider, genAPIKey, genModel, systemPrompt, userPrompt)
+	workflow, usedModel, err := callGenAIWithFallback(genProvider, genAPIKey, models, systemPrompt, userPrompt,
+		func(out string) error { return validateWorkflow(cleanYAMLResponse(out)) })
+	genStreamOut = nil // the --explain call is not streamed
 	if err != nil {
 		return fmt.Errorf("AI generation failed: %w", err)
 	}
+	if len(models) > 1 {
+		success(fmt.Sprintf("Workflow generated by %s", usedModel))
+	}
 
 	// Strip markdown fences if the model wrapped the output
 	workflow = cleanYAMLResponse(workflow)
 
+	if repoCtx.existingWorkflow != "" {
+		header("Changes to existing workflow")
+		name, err := filepath.Rel(repoPath, genOutput)
+		if err != nil {
+			name = genOutput
+		}
+		diff := unifiedDiff("a/"+name, "b/"+name, repoCtx.existingWorkflow, workflow+"\n")
+		if diff == "" {
+			success("Workflow is already up to date — nothing to change")
+			return nil
+		}
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprint(os.Stderr, colorizeDiff(diff))
+	}
+
+	var explanation string
+	if genExplain {
+		explanation = generateExplanation(repoCtx, workflow, usedModel)
+	}
+
 	if genDryRun {
 		header("Generated workflow (dry-run)")
 		fmt.Fprintln(os.Stderr)
 		fmt.Println(workflow)
+		if explanation != "" {
+			header("Explanation")
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintln(os.Stderr, explanation)
+		}
 		return nil
 	}
 
@@ -221,6 +374,16 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 	success(fmt.Sprintf("Workflow written to %s", relPath))
 
+	if explanation != "" {
+		explainPath := explainOutputPath(genOutput)
+		if err := os.WriteFile(explainPath, []byte(explanation+"\n"), 0644); err != nil {
+			warn(fmt.Sprintf("cannot write explanation: %v", err))
+		} else {
+			explainRel, _ := filepath.Rel(repoPath, explainPath)
+			step("📝", fmt.Sprintf("Wrote %s%s%s (review notes)", colorCyan, explainRel, colorReset))
+		}
+	}
+
 	// ── Write canonical agent context ───────────────────────────
 	contextDoc := buildContextDocument(repoPath)
 	kindlingDir := filepath.Join(repoPath, ".kindling")
@@ -256,6 +419,7 @@ type repoContext struct {
 	depFiles          map[string]string // relative path → content
 	composeFile       string            // docker-compose.yml content (if found)
 	sourceSnippets    map[string]string // relative path → truncated content
+	sourceOrder       []string          // sourceSnippets keys, highest priority first
 	dockerfileCount   int
 	depFileCount      int
 	externalSecrets   []string // detected external credential env var names
@@ -269,9 +433,30 @@ type repoContext struct {
 	vectorStores      []string // detected vector store dependencies
 	workerProcesses   []string // detected background worker patterns
 	interServiceCalls []string // detected inter-service HTTP/gRPC calls
+	scheduledJobs     []string // detected cron/scheduler patterns
+	embeddedDBs       []string // detected in-process databases (SQLite, DuckDB, ...)
+	temporalHints     []string // detected Temporal SDK usage
+	daprHints         []string // detected Dapr SDK/annotations/components
+	webSockets        []string // detected WebSocket server libraries
+	gpuWorkloads      []string // detected ML frameworks that load models (torch, ...)
+	healthEndpoint    string   // detected health route ("" = none found)
 
 	// Dockerfile build-context issues
 	dockerfileWarnings []string // Dockerfiles that need repo-root context
+
+	existingWorkflow string // current workflow content (--update mode)
+
+	services []string // --services scope, relative to the repo root (nil = whole repo)
+
+	exposedPorts map[string]int32 // Dockerfile path → first EXPOSEd port
+	composePorts map[string]int32 // compose service → first container-side port
+	procEntries  []procEntry      // process types from the root Procfile
+}
+
+// procEntry is one "<type>: <command>" line from a Procfile.
+type procEntry struct {
+	name    string // process type (web, worker, release, ...)
+	command string // command line to run
 }
 
 // Directories to skip during scanning (built from the shared skip list).
@@ -426,18 +611,23 @@ func hasEnvVarPatterns(path string) bool {
 	return false
 }
 
-func scanRepo(repoPath string) (*repoContext, error) {
+func scanRepo(repoPath string, services ...string) (*repoContext, error) {
 	ctx := &repoContext{
 		name:           filepath.Base(repoPath),
+		services:       services,
 		dockerfiles:    make(map[string]string),
 		depFiles:       make(map[string]string),
 		sourceSnippets: make(map[string]string),
+		exposedPorts:   make(map[string]int32),
 		hostArch:       "amd64", // always target amd64 for production compatibility
 	}
 
 	var treeLines []string
 	var sourceFiles []string
 
+	// Honour .dockerignore so the tree and samples match Kaniko's build context
+	ignore := loadDockerignore(repoPath)
+
 	err := filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil // skip inaccessible entries
@@ -448,6 +638,13 @@ func scanRepo(repoPath string) (*repoContext, error) {
 			return nil
 		}
 
+		// Excluded directories are still walked: the generator needs a few
+		// files whether or not Kaniko sees them (see scanIgnoreExempt).
+		excluded := ignore.excludes(rel)
+		if excluded && !d.IsDir() && !scanIgnoreExempt(d.Name()) {
+			return nil
+		}
+
 		// Skip ignored directories
 		if d.IsDir() {
 			if scanSkipDirs[d.Name()] {
@@ -461,19 +658,34 @@ func scanRepo(repoPath string) (*repoContext, error) {
 		}
 
 		depth := strings.Count(rel, string(filepath.Separator))
-		if depth <= 3 {
+		if depth <= 3 && (!excluded || isDockerfileName(d.Name())) {
 			treeLines = append(treeLines, rel)
 		}
 
+		// With --services, only files under a listed service are read;
+		// source depth then counts from the service directory.
+		srcDepth := depth
+		if len(services) > 0 {
+			svc, ok := serviceForPath(rel, services)
+			if !ok {
+				return nil
+			}
+			if svc != "." {
+				srcDepth = depth - strings.Count(svc, string(filepath.Separator)) - 1
+			}
+		}
+
 		name := d.Name()
-		nameLower := strings.ToLower(name)
 
 		// Collect Dockerfiles
-		if nameLower == "dockerfile" || strings.HasPrefix(nameLower, "dockerfile.") {
+		if isDockerfileName(name) {
 			content, err := readFileCapped(path, 80)
 			if err == nil {
 				ctx.dockerfiles[rel] = content
 				ctx.dockerfileCount++
+				if ports := parseExposedPorts(content); len(ports) > 0 {
+					ctx.exposedPorts[rel] = ports[0]
+				}
 			}
 		}
 
@@ -488,16 +700,16 @@ func scanRepo(repoPath string) (*repoContext, error) {
 		}
 
 		// Collect docker-compose
-		if nameLower == "docker-compose.yml" || nameLower == "docker-compose.yaml" ||
-			nameLower == "compose.yml" || nameLower == "compose.yaml" {
+		if isComposeFileName(name) {
 			content, err := readFileCapped(path, 150)
 			if err == nil {
 				ctx.composeFile = content
+				ctx.composePorts = parseComposePorts(content)
 			}
 		}
 
 		// Collect source files for analysis (top 2 levels only)
-		if scanSourceExts[ext] && depth <= 2 {
+		if scanSourceExts[ext] && srcDepth <= 2 && !excluded {
 			sourceFiles = append(sourceFiles, path)
 		}
 
@@ -535,9 +747,15 @@ func scanRepo(repoPath string) (*repoContext, error) {
 		if err == nil {
 			rel, _ := filepath.Rel(repoPath, path)
 			ctx.sourceSnippets[rel] = content
+			ctx.sourceOrder = append(ctx.sourceOrder, rel)
 		}
 	}
 
+	// Split the root Procfile into process types
+	if content, ok := ctx.depFiles["Procfile"]; ok {
+		ctx.procEntries = parseProcfile(content)
+	}
+
 	// Detect external credential references
 	ctx.externalSecrets = detectExternalSecrets(repoPath, ctx)
 
@@ -550,6 +768,13 @@ func scanRepo(repoPath string) (*repoContext, error) {
 	ctx.vectorStores = detectVectorStores(ctx)
 	ctx.workerProcesses = detectWorkerProcesses(ctx)
 	ctx.interServiceCalls = detectInterServiceCalls(ctx)
+	ctx.scheduledJobs = detectScheduledJobs(ctx)
+	ctx.embeddedDBs = detectEmbeddedDatabases(ctx)
+	ctx.temporalHints = detectTemporal(ctx)
+	ctx.daprHints = detectDapr(ctx)
+	ctx.webSockets = detectWebSockets(ctx)
+	ctx.gpuWorkloads = detectGPUWorkloads(ctx)
+	ctx.healthEndpoint, _ = detectHealthEndpoint(ctx)
 
 	// Detect Dockerfiles that reference their own directory name in COPY/ADD,
 	// meaning they expect the repo root as build context instead of being
@@ -559,6 +784,173 @@ func scanRepo(repoPath string) (*repoContext, error) {
 	return ctx, nil
 }
 
+// parseServicePaths splits a comma-separated --services value into
+// directories relative to repoPath. Each must stay inside the repo and
+// contain a Dockerfile, so a typo fails before any tokens are spent.
+func parseServicePaths(repoPath, raw string) ([]string, error) {
+	var services []string
+	seen := make(map[string]bool)
+	for _, s := range strings.Split(raw, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		rel := filepath.Clean(s)
+		if filepath.IsAbs(rel) {
+			r, err := filepath.Rel(repoPath, rel)
+			if err != nil {
+				return nil, fmt.Errorf("--services: %s is not inside %s", s, repoPath)
+			}
+			rel = r
+		}
+		if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("--services: %s is not inside %s", s, repoPath)
+		}
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		entries, err := os.ReadDir(filepath.Join(repoPath, rel))
+		if err != nil {
+			return nil, fmt.Errorf("--services: %s is not a directory in %s", s, repoPath)
+		}
+		if !slices.ContainsFunc(entries, func(e fs.DirEntry) bool { return !e.IsDir() && isDockerfileName(e.Name()) }) {
+			return nil, fmt.Errorf("--services: no Dockerfile in %s", s)
+		}
+		services = append(services, rel)
+	}
+	return services, nil
+}
+
+// serviceForPath returns the service directory containing rel.
+func serviceForPath(rel string, services []string) (string, bool) {
+	for _, s := range services {
+		if s == "." || rel == s || strings.HasPrefix(rel, s+string(filepath.Separator)) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// isDockerfileName reports whether a file name is a Dockerfile
+// ("Dockerfile" or "Dockerfile.<variant>", case-insensitive).
+func isDockerfileName(name string) bool {
+	lower := strings.ToLower(name)
+	return lower == "dockerfile" || strings.HasPrefix(lower, "dockerfile.")
+}
+
+// isComposeFileName reports whether a file name is a docker-compose file.
+func isComposeFileName(name string) bool {
+	switch strings.ToLower(name) {
+	case "docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml":
+		return true
+	}
+	return false
+}
+
+// scanIgnoreExempt reports whether a file is read even when .dockerignore
+// excludes it. Docker always sends Dockerfiles, and compose files and
+// dependency manifests are the generator's main signal for services,
+// ports, and dependencies, whatever Kaniko ends up seeing.
+func scanIgnoreExempt(name string) bool {
+	return isDockerfileName(name) || isComposeFileName(name) ||
+		scanDepFiles[name] || scanDepExts[strings.ToLower(filepath.Ext(name))]
+}
+
+// ── .dockerignore matching ──────────────────────────────────────
+
+// dockerignoreRule is one pattern line from a .dockerignore file.
+type dockerignoreRule struct {
+	re     *regexp.Regexp
+	negate bool // "!pattern" re-includes matching paths
+}
+
+// dockerignore is the parsed rule list. A nil/empty value ignores nothing.
+type dockerignore []dockerignoreRule
+
+// loadDockerignore reads <repoPath>/.dockerignore. A missing or unreadable
+// file yields an empty rule set.
+func loadDockerignore(repoPath string) dockerignore {
+	data, err := os.ReadFile(filepath.Join(repoPath, ".dockerignore"))
+	if err != nil {
+		return nil
+	}
+	return parseDockerignore(string(data))
+}
+
+// parseDockerignore parses .dockerignore content using Docker's rules:
+// patterns are relative to the context root, "#" starts a comment, "!"
+// negates, "*" and "?" don't cross "/", and "**" matches any number of
+// directories.
+func parseDockerignore(content string) dockerignore {
+	var rules dockerignore
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		negate := false
+		if strings.HasPrefix(line, "!") {
+			negate = true
+			line = strings.TrimSpace(line[1:])
+		}
+		line = filepath.ToSlash(filepath.Clean(line))
+		line = strings.TrimPrefix(line, "/")
+		if line == "" || line == "." {
+			continue
+		}
+		re, err := regexp.Compile(dockerignorePatternToRegexp(line))
+		if err != nil {
+			continue
+		}
+		rules = append(rules, dockerignoreRule{re: re, negate: negate})
+	}
+	return rules
+}
+
+// dockerignorePatternToRegexp converts a cleaned .dockerignore pattern into
+// an anchored regular expression. A pattern also matches everything below a
+// matching directory, so "assets" excludes "assets/img/logo.png".
+func dockerignorePatternToRegexp(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				i++
+				sb.WriteString("(.*/)?")
+			} else {
+				sb.WriteString(".*")
+			}
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("(/.*)?$")
+	return sb.String()
+}
+
+// excludes reports whether the relative path is excluded from the build
+// context. The last matching rule wins, as in Docker.
+func (d dockerignore) excludes(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	excluded := false
+	for _, r := range d {
+		if r.re.MatchString(rel) {
+			excluded = !r.negate
+		}
+	}
+	return excluded
+}
+
 // readFileCapped reads up to maxLines lines from a file and truncates with a
 // note if the file is longer.
 func readFileCapped(path string, maxLines int) (string, error) {
@@ -655,6 +1047,17 @@ func buildGeneratePrompt(ctx *repoContext, provider ci.Provider) (system, user s
 	b.WriteString(fmt.Sprintf("Default branch: %s (use this in the 'on: push: branches:' trigger)\n\n", ctx.branch))
 	b.WriteString(fmt.Sprintf("Target architecture: %s (use this in all Kaniko Dockerfile patches)\n\n", ctx.hostArch))
 
+	if len(ctx.services) > 0 {
+		b.WriteString("## Service scope\n\n")
+		b.WriteString("Only these service directories are in scope:\n\n")
+		for _, s := range ctx.services {
+			b.WriteString(fmt.Sprintf("- %s\n", s))
+		}
+		b.WriteString("\n**DIRECTIVE:** Emit build and deploy steps ONLY for the services listed above. ")
+		b.WriteString("Other Dockerfiles and directories in the tree belong to services that are not onboarded yet — do not build or deploy them. ")
+		b.WriteString("Still declare any backing dependencies (databases, caches, queues) the in-scope services need.\n\n")
+	}
+
 	// Directory tree
 	b.WriteString("## Repository structure\n```\n")
 	b.WriteString(ctx.tree)
@@ -663,16 +1066,74 @@ func buildGeneratePrompt(ctx *repoContext, provider ci.Provider) (system, user s
 	// Dockerfiles
 	if len(ctx.dockerfiles) > 0 {
 		b.WriteString("## Dockerfiles\n\n")
-		for path, content := range ctx.dockerfiles {
-			b.WriteString(fmt.Sprintf("### %s\n```dockerfile\n%s\n```\n\n", path, content))
+		paths := make([]string, 0, len(ctx.dockerfiles))
+		for path := range ctx.dockerfiles {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			b.WriteString(fmt.Sprintf("### %s\n```dockerfile\n%s\n```\n\n", path, ctx.dockerfiles[path]))
+		}
+	}
+
+	// Ports declared via EXPOSE and docker-compose
+	if len(ctx.exposedPorts) > 0 || len(ctx.composePorts) > 0 {
+		b.WriteString("## Detected container ports\n\n")
+		if len(ctx.exposedPorts) > 0 {
+			b.WriteString("These ports come from EXPOSE directives in the Dockerfiles:\n\n")
+			paths := make([]string, 0, len(ctx.exposedPorts))
+			for p := range ctx.exposedPorts {
+				paths = append(paths, p)
+			}
+			sort.Strings(paths)
+			for _, p := range paths {
+				b.WriteString(fmt.Sprintf("- %s: %d\n", p, ctx.exposedPorts[p]))
+			}
+			b.WriteString("\n")
+		}
+		if len(ctx.composePorts) > 0 {
+			b.WriteString("These container-side ports come from `ports:` in docker-compose (service: port):\n\n")
+			services := make([]string, 0, len(ctx.composePorts))
+			for s := range ctx.composePorts {
+				services = append(services, s)
+			}
+			sort.Strings(services)
+			for _, s := range services {
+				b.WriteString(fmt.Sprintf("- %s: %d\n", s, ctx.composePorts[s]))
+			}
+			b.WriteString("\n")
 		}
+		b.WriteString("**DIRECTIVE:** Use the detected port as the `port:` input for the service built from that Dockerfile, ")
+		b.WriteString("and make sure its health check targets the same port. When a compose service builds that Dockerfile ")
+		b.WriteString("(same name or build context), prefer the compose port — it is what the app actually listens on. ")
+		b.WriteString("Do not fall back to 8080 when a port is listed here.\n\n")
+	}
+
+	// Health check endpoint
+	if ctx.healthEndpoint != "" {
+		b.WriteString("## Detected health check endpoint\n\n")
+		b.WriteString(fmt.Sprintf("- `%s`\n", ctx.healthEndpoint))
+		b.WriteString(fmt.Sprintf("\n**DIRECTIVE:** Set `health-check-path: \"%s\"` on every HTTP deploy step for a service that serves it. ", ctx.healthEndpoint))
+		b.WriteString("Only use a different path if the source shows that service serves a different one.\n\n")
+	} else {
+		b.WriteString("## Health check endpoint\n\n")
+		b.WriteString("No health route was found in the sampled source.\n\n")
+		b.WriteString("**DIRECTIVE:** Do NOT omit health-check-path — its default, /healthz, does not exist in this app. ")
+		b.WriteString("Set `health-check-path: \"/\"` on every HTTP deploy step and add a YAML comment above it: ")
+		b.WriteString("`# HEALTH: no health route found — probing / ; add a /healthz endpoint for a real readiness check`. ")
+		b.WriteString("Steps with health-check-type \"grpc\" or \"none\" are unaffected.\n\n")
 	}
 
 	// Dependency manifests
 	if len(ctx.depFiles) > 0 {
 		b.WriteString("## Dependency manifests\n\n")
-		for path, content := range ctx.depFiles {
-			b.WriteString(fmt.Sprintf("### %s\n```\n%s\n```\n\n", path, content))
+		paths := make([]string, 0, len(ctx.depFiles))
+		for path := range ctx.depFiles {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			b.WriteString(fmt.Sprintf("### %s\n```\n%s\n```\n\n", path, ctx.depFiles[path]))
 		}
 	}
 
@@ -703,7 +1164,9 @@ func buildGeneratePrompt(ctx *repoContext, provider ci.Provider) (system, user s
 		b.WriteString("The following environment variables were detected in source code.\n")
 		b.WriteString("Apply the dev staging philosophy from the system prompt to decide how to handle each:\n")
 		b.WriteString("- If it is an app-level secret (SECRET_KEY, JWT_SECRET, etc.), set a random hex dev value\n")
-		b.WriteString("- If it is an optional integration (AWS, Datadog, SMTP, OAuth), OMIT it entirely\n")
+		b.WriteString("- If it is an optional integration (Datadog, OAuth), OMIT it entirely\n")
+		b.WriteString("- If it is an AWS credential and the app uses an AWS SDK, declare a localstack dependency instead (it injects test credentials)\n")
+		b.WriteString("- If it is an SMTP setting, declare a mailpit dependency and point it at SMTP_HOST/SMTP_PORT\n")
 		b.WriteString("- If it is truly required AND external, use secretKeyRef with name kindling-secret-<name>\n\n")
 		for _, name := range ctx.externalSecrets {
 			b.WriteString(fmt.Sprintf("- %s\n", name))
@@ -750,9 +1213,25 @@ func buildGeneratePrompt(ctx *repoContext, provider ci.Provider) (system, user s
 
 		if len(ctx.vectorStores) > 0 {
 			b.WriteString("### Vector stores: " + strings.Join(ctx.vectorStores, ", ") + "\n\n")
-			b.WriteString("**DIRECTIVE:** Default to respecting external services — do NOT auto-add local dependencies for vector stores. ")
-			b.WriteString("Surface API keys (PINECONE_API_KEY, WEAVIATE_API_KEY, QDRANT_API_KEY, etc.) as secretKeyRef. ")
-			b.WriteString("Add a YAML comment noting the vector store and that the user can add a local dependency if they want a dev replica.\n\n")
+			var local, hosted []string
+			for _, s := range ctx.vectorStores {
+				if dep, ok := localVectorStoreDeps[s]; ok {
+					local = append(local, fmt.Sprintf("%s → `%s`", s, dep))
+				} else {
+					hosted = append(hosted, s)
+				}
+			}
+			if len(local) > 0 {
+				b.WriteString("**DIRECTIVE:** These vector stores run locally — add them as dependencies: " + strings.Join(local, ", ") + ". ")
+				b.WriteString("The operator injects QDRANT_URL / WEAVIATE_URL / CHROMA_URL (plus CHROMA_HOST and CHROMA_PORT); map the app's own env var names onto them with $(VAR) expansion. ")
+				b.WriteString("The local instances run without auth, so do NOT add their API keys as secretKeyRef. ")
+				b.WriteString("ChromaDB is the exception when it is only used embedded (chromadb.PersistentClient / EphemeralClient) — then it needs no dependency.\n\n")
+			}
+			if len(hosted) > 0 {
+				b.WriteString("**DIRECTIVE:** " + strings.Join(hosted, ", ") + ": default to respecting external services — do NOT auto-add local dependencies for these. ")
+				b.WriteString("Surface API keys (PINECONE_API_KEY, MILVUS_API_KEY, etc.) as secretKeyRef. ")
+				b.WriteString("Add a YAML comment noting the vector store and that the user can add a local dependency if they want a dev replica.\n\n")
+			}
 		}
 
 		if len(ctx.workerProcesses) > 0 {
@@ -762,7 +1241,7 @@ func buildGeneratePrompt(ctx *repoContext, provider ci.Provider) (system, user s
 			}
 			b.WriteString("\n**DIRECTIVE:** Each background worker MUST be a separate deploy step (not just a dependency). ")
 			b.WriteString("Workers typically share the same Dockerfile as the main service but with a different command/entrypoint. ")
-			b.WriteString("Wire up the correct broker dependency (redis for Celery/BullMQ, rabbitmq for AMQP, kafka for Kafka consumers).\n\n")
+			b.WriteString("Wire up the correct broker dependency (redis for Celery/BullMQ, rabbitmq for AMQP, kafka for Kafka consumers, pulsar for Pulsar consumers).\n\n")
 		}
 
 		if len(ctx.interServiceCalls) > 0 {
@@ -773,7 +1252,105 @@ func buildGeneratePrompt(ctx *repoContext, provider ci.Provider) (system, user s
 			b.WriteString("\n**DIRECTIVE:** Wire up env vars for service discovery using Kubernetes DNS: ")
 			b.WriteString("$ACTOR-<service-name>:<port>. Check source code for env vars ending in _URL, _ADDR, _ENDPOINT, ")
 			b.WriteString("_SERVICE_HOST that reference other services, and set them to the correct K8s DNS name.\n\n")
+
+			if grpc := grpcInterServiceCalls(ctx.interServiceCalls); len(grpc) > 0 {
+				b.WriteString("### gRPC calls detected:\n")
+				for _, c := range grpc {
+					b.WriteString(fmt.Sprintf("- %s\n", c))
+				}
+				b.WriteString("\n**DIRECTIVE:** For every service that is the target of a gRPC dial, set `health-check-type: \"grpc\"` ")
+				b.WriteString("and omit health-check-path. Set the calling service's address env var (e.g. *_GRPC_ADDR, *_ADDR, *_TARGET) ")
+				b.WriteString("to $ACTOR-<service-name>:<port> using the gRPC port (often 50051) and NO http:// or https:// scheme — ")
+				b.WriteString("gRPC clients dial a bare host:port and fail to resolve a URL.\n\n")
+			}
+		}
+	}
+
+	// Scheduled jobs
+	if len(ctx.scheduledJobs) > 0 {
+		b.WriteString("## Detected scheduled jobs\n\n")
+		for _, j := range ctx.scheduledJobs {
+			b.WriteString(fmt.Sprintf("- %s\n", j))
+		}
+		b.WriteString("\n**DIRECTIVE:** kindling-deploy has no CronJob mode yet. Run the scheduler as a separate always-on worker deploy ")
+		b.WriteString("(same image, scheduler command, replicas: 1, no ingress, health-check-type: \"none\") so jobs don't fire once per replica of the web service. ")
+		b.WriteString("Add a YAML comment above that deploy step: `# SCHEDULED: runs <scheduler> in-process — convert to a CronJob when supported`.\n\n")
+	}
+
+	// Embedded databases
+	if len(ctx.embeddedDBs) > 0 {
+		b.WriteString("## Detected embedded databases\n\n")
+		for _, d := range ctx.embeddedDBs {
+			b.WriteString(fmt.Sprintf("- %s\n", d))
+		}
+		b.WriteString("\n**DIRECTIVE:** These databases run in-process and store data in a local file — they need NO dependency. ")
+		b.WriteString("Do NOT add a postgres or mysql dependency just because the code uses `database/sql`, an ORM, or a DATABASE_URL variable. ")
+		b.WriteString("Only add a server database if there is separate evidence for it (a postgres/mysql driver such as lib/pq, pgx, psycopg2, pg, mysql2, ")
+		b.WriteString("or a postgres/mysql service in docker-compose).\n\n")
+	}
+
+	// Temporal
+	if len(ctx.temporalHints) > 0 {
+		b.WriteString("## Detected Temporal workflows\n\n")
+		for _, h := range ctx.temporalHints {
+			b.WriteString(fmt.Sprintf("- %s\n", h))
+		}
+		b.WriteString("\n**DIRECTIVE:** This app needs a Temporal server. kindling has no `temporal` dependency type yet, so do NOT invent one. ")
+		b.WriteString("Deploy any Temporal worker processes as separate deploy steps (no ingress, health-check-type: \"none\"), ")
+		b.WriteString("and add a YAML comment above the first deploy step: `# TEMPORAL: needs a Temporal server — set TEMPORAL_ADDRESS once one is available`.\n\n")
+	}
+
+	// Dapr
+	if len(ctx.daprHints) > 0 {
+		b.WriteString("## Detected Dapr usage\n\n")
+		for _, h := range ctx.daprHints {
+			b.WriteString(fmt.Sprintf("- %s\n", h))
+		}
+		b.WriteString("\n**DIRECTIVE:** kindling does not support Dapr sidecar injection yet. Do NOT add `dapr.io/*` annotations or a Dapr dependency. ")
+		b.WriteString("Deploy the services normally and add a YAML comment above the first deploy step: ")
+		b.WriteString("`# DAPR: sidecar injection isn't supported — Dapr building blocks (state, pub/sub, invoke) will not work in this environment`.\n\n")
+	}
+
+	// WebSockets
+	if len(ctx.webSockets) > 0 {
+		b.WriteString("## Detected WebSocket endpoints\n\n")
+		for _, w := range ctx.webSockets {
+			b.WriteString(fmt.Sprintf("- %s\n", w))
+		}
+		b.WriteString("\n**DIRECTIVE:** WebSocket connections stay open far longer than the default ingress timeouts. ")
+		b.WriteString("For each service that serves WebSockets and has an ingress-host, add these ingress annotations ")
+		b.WriteString("(the `ingress-annotations` input on kindling-deploy, or `spec.ingress.annotations` in an inline DSE):\n")
+		b.WriteString("```yaml\nnginx.ingress.kubernetes.io/proxy-read-timeout: \"3600\"\nnginx.ingress.kubernetes.io/proxy-send-timeout: \"3600\"\n```\n")
+		b.WriteString("Traefik (the default ingress class) upgrades WebSocket connections without extra configuration and ignores these. ")
+		b.WriteString("Set health-check-path to a plain HTTP route such as `/healthz` — NEVER the WebSocket route, which rejects requests without an Upgrade header.\n\n")
+	}
+
+	// GPU / ML workloads
+	if len(ctx.gpuWorkloads) > 0 {
+		b.WriteString("## Detected ML / GPU workloads\n\n")
+		for _, g := range ctx.gpuWorkloads {
+			b.WriteString(fmt.Sprintf("- %s\n", g))
 		}
+		b.WriteString("\n**DIRECTIVE:** Kind clusters have no GPUs, so a pod requesting `nvidia.com/gpu` would never schedule. ")
+		b.WriteString("Do NOT request a GPU. Above the deploy step of each service that loads these models, add this commented-out block for when it moves to a GPU cluster:\n")
+		b.WriteString("```yaml\n# GPU: uncomment on a cluster with the NVIDIA device plugin — Kind has no GPU, so this runs on CPU\n# resources:\n#   limits:\n#     nvidia.com/gpu: 1\n```\n")
+		b.WriteString("Loading model weights needs far more memory than a typical web service. For those services set ")
+		b.WriteString("`spec.deployment.resources.memoryRequest: 2Gi` and `memoryLimit: 4Gi` in an inline DSE ")
+		b.WriteString("(kindling-deploy has no resources input — if you use it, add a YAML comment: `# ML: needs ~2Gi+ memory to load models`). ")
+		b.WriteString("Do not raise memory for services that don't import these libraries.\n\n")
+	}
+
+	// Procfile process types
+	if len(ctx.procEntries) > 0 {
+		b.WriteString("## Procfile process types\n\n")
+		for _, e := range ctx.procEntries {
+			b.WriteString(fmt.Sprintf("- %s: `%s`\n", e.name, e.command))
+		}
+		b.WriteString("\n**DIRECTIVE:** Emit a separate kindling-deploy step for each process type except `release`. ")
+		b.WriteString("All of them share the single built image — do NOT add extra build steps — and each deploy overrides the container `command` with the Procfile command. ")
+		b.WriteString("Only the `web` process gets an ingress-host and HTTP health check; `worker`, `clock`, `consumer` and other non-web processes get no ingress and health-check-type: \"none\". ")
+		b.WriteString("Name each deploy <actor>-<app>-<process> (use <actor>-<app> for `web`). ")
+		b.WriteString("Skip `release` (one-off migrations) — add a YAML comment with its command instead.\n\n")
 	}
 
 	// Dockerfile build-context issues
@@ -800,12 +1377,283 @@ func buildGeneratePrompt(ctx *repoContext, provider ci.Provider) (system, user s
 	b.WriteString(multiExample)
 	b.WriteString("\n```\n\n")
 
-	b.WriteString("Now generate the dev-deploy.yml workflow YAML for this repository. Return ONLY the YAML.\n")
+	if ctx.existingWorkflow != "" {
+		b.WriteString("## Current workflow (preserve user edits)\n```yaml\n")
+		b.WriteString(strings.TrimRight(ctx.existingWorkflow, "\n"))
+		b.WriteString("\n```\n\n")
+		b.WriteString("**DIRECTIVE:** This workflow already exists and may contain hand edits. Start from it rather than from the examples. ")
+		b.WriteString("Only add or adjust what the repository now needs (a new service, a new dependency, a changed port or Dockerfile path). ")
+		b.WriteString("Keep every existing comment, step, env var, secret reference, and manual tweak exactly as written unless it is clearly wrong for the current repo. ")
+		b.WriteString("Do not reorder, reformat, or rename existing steps.\n\n")
+		b.WriteString("Now return the updated workflow YAML. Return ONLY the YAML.\n")
+	} else {
+		b.WriteString("Now generate the dev-deploy.yml workflow YAML for this repository. Return ONLY the YAML.\n")
+	}
 
 	user = b.String()
 	return system, user
 }
 
+// ────────────────────────────────────────────────────────────────────────────
+// Context budget
+// ────────────────────────────────────────────────────────────────────────────
+
+// omittedDepFileBody replaces a dependency manifest body that was dropped to
+// fit the context budget. The path stays in the prompt so the model still
+// knows the manifest exists.
+const omittedDepFileBody = "(contents omitted to fit the context budget)"
+
+// estimateTokens approximates the token count of s. ~4 bytes per token is
+// close enough for English prose, YAML, and source code across tokenizers.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// defaultMaxContextTokens returns a prompt budget that leaves headroom for
+// the response within each model's context window. For a fallback chain the
+// smallest budget wins so every model in the chain can take the prompt.
+func defaultMaxContextTokens(provider string, models []string) int {
+	budget := 0
+	for _, model := range models {
+		var b int
+		switch provider {
+		case "ollama":
+			b = 24000 // num_ctx is 32k; keep room for the answer
+		case "gemini":
+			b = 500000
+		case "anthropic":
+			b = 150000
+		default:
+			if isReasoningModel(model) {
+				b = 150000
+			} else {
+				b = 100000
+			}
+		}
+		if budget == 0 || b < budget {
+			budget = b
+		}
+	}
+	if budget == 0 {
+		budget = 100000
+	}
+	return budget
+}
+
+// trimContextToBudget drops the lowest-priority context from ctx until the
+// prompt that will be sent fits in maxTokens: first source snippets (lowest
+// priority first), then dependency manifest bodies (largest first).
+// Dockerfiles and docker-compose are always kept. tmpl is the --prompt-file
+// template, if any, since it can render a much larger system prompt than the
+// built-in one. Returns a description of each dropped item.
+func trimContextToBudget(ctx *repoContext, provider ci.Provider, tmpl *template.Template, maxTokens int) ([]string, error) {
+	var dropped []string
+	order := snippetOrder(ctx)
+
+	for {
+		system, user, err := generatePrompts(tmpl, ctx, provider)
+		if err != nil {
+			return dropped, err
+		}
+		if estimateTokens(system)+estimateTokens(user) <= maxTokens {
+			return dropped, nil
+		}
+
+		if len(order) > 0 {
+			last := order[len(order)-1]
+			order = order[:len(order)-1]
+			delete(ctx.sourceSnippets, last)
+			dropped = append(dropped, "source file "+last)
+			continue
+		}
+
+		largest := ""
+		for path, content := range ctx.depFiles {
+			if content == omittedDepFileBody {
+				continue
+			}
+			if largest == "" || len(content) > len(ctx.depFiles[largest]) ||
+				(len(content) == len(ctx.depFiles[largest]) && path < largest) {
+				largest = path
+			}
+		}
+		if largest == "" {
+			dropped = append(dropped, "nothing left to drop — Dockerfiles and docker-compose alone exceed the budget")
+			return dropped, nil
+		}
+		ctx.depFiles[largest] = omittedDepFileBody
+		dropped = append(dropped, "dependency manifest body "+largest)
+	}
+}
+
+// snippetOrder returns the source snippet paths from highest to lowest
+// priority, falling back to sorted order for contexts built without a scan.
+func snippetOrder(ctx *repoContext) []string {
+	var order []string
+	for _, p := range ctx.sourceOrder {
+		if _, ok := ctx.sourceSnippets[p]; ok {
+			order = append(order, p)
+		}
+	}
+	if len(order) == len(ctx.sourceSnippets) {
+		return order
+	}
+	order = order[:0]
+	for p := range ctx.sourceSnippets {
+		order = append(order, p)
+	}
+	sort.Strings(order)
+	return order
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Explanation (--explain)
+// ────────────────────────────────────────────────────────────────────────────
+
+// explainSystemPrompt instructs the model to justify, not regenerate.
+const explainSystemPrompt = `You are reviewing a kindling CI workflow that another model generated.
+Explain its decisions to a human reviewer in concise Markdown.
+
+Ground every claim in the detection results and the workflow you are given.
+Do not invent evidence. If a decision is not supported by the detection
+results, say so explicitly and flag it for review.
+
+Use these sections:
+## Services — which services are built/deployed and from which Dockerfile
+## Dependencies — each dependency, and the import/manifest evidence for it
+## Secrets — which credentials use secretKeyRef, which were stubbed or omitted, and why
+## Ports and health checks — the port chosen per service and where it came from
+## Review items — anything the reviewer should double-check, ordered by risk`
+
+// generateExplanation makes a second, cheap model call summarising the
+// decisions behind the workflow. Failures are reported as warnings and
+// return "" — the explanation is a review aid, not part of the output.
+func generateExplanation(ctx *repoContext, workflow, model string) string {
+	explainModel := explainModelFor(genProvider, model)
+	step("📝", fmt.Sprintf("Generating explanation with %s...", explainModel))
+	out, err := callGenAI(genProvider, genAPIKey, explainModel, explainSystemPrompt, buildExplainPrompt(ctx, workflow))
+	if err != nil {
+		warn(fmt.Sprintf("Explanation failed: %v", err))
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// explainModelFor returns a cheaper model from the same provider for the
+// explanation call. Local models are used as-is.
+func explainModelFor(provider, model string) string {
+	switch provider {
+	case "openai":
+		return "gpt-4o-mini"
+	case "anthropic":
+		return "claude-3-5-haiku-20241022"
+	case "gemini":
+		return "gemini-2.5-flash"
+	default:
+		return model
+	}
+}
+
+// explainOutputPath returns the sidecar path for a workflow file:
+// dev-deploy.yml → dev-deploy.explain.md.
+func explainOutputPath(workflowPath string) string {
+	return strings.TrimSuffix(workflowPath, filepath.Ext(workflowPath)) + ".explain.md"
+}
+
+// detectionList is one list-valued scan finding.
+type detectionList struct {
+	title string // human title with the repoContext field name
+	items []string
+}
+
+// detectionLists returns every list-valued detector result on ctx. The
+// explain prompt walks it, so a detector added to repoContext must be
+// added here for the explanation to mention what it found.
+func (ctx *repoContext) detectionLists() []detectionList {
+	return []detectionList{
+		{"External credentials (externalSecrets)", ctx.externalSecrets},
+		{"OAuth/OIDC indicators (oauthHints)", ctx.oauthHints},
+		{"Agent frameworks (agentFrameworks)", ctx.agentFrameworks},
+		{"MCP servers (mcpServers)", ctx.mcpServers},
+		{"Vector stores (vectorStores)", ctx.vectorStores},
+		{"Background workers (workerProcesses)", ctx.workerProcesses},
+		{"Inter-service calls (interServiceCalls)", ctx.interServiceCalls},
+		{"Scheduled jobs (scheduledJobs)", ctx.scheduledJobs},
+		{"Embedded databases (embeddedDBs)", ctx.embeddedDBs},
+		{"Temporal usage (temporalHints)", ctx.temporalHints},
+		{"Dapr usage (daprHints)", ctx.daprHints},
+		{"WebSocket servers (webSockets)", ctx.webSockets},
+		{"ML / GPU workloads (gpuWorkloads)", ctx.gpuWorkloads},
+		{"Dockerfile build-context issues (dockerfileWarnings)", ctx.dockerfileWarnings},
+	}
+}
+
+// buildExplainPrompt assembles the user prompt for the explanation call from
+// the same detection results that shaped the generation prompt.
+func buildExplainPrompt(ctx *repoContext, workflow string) string {
+	var b strings.Builder
+
+	list := func(title string, items []string) {
+		b.WriteString("### " + title + "\n")
+		if len(items) == 0 {
+			b.WriteString("- (none detected)\n\n")
+			return
+		}
+		for _, item := range items {
+			b.WriteString("- " + item + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(fmt.Sprintf("Explain the workflow generated for the repository %q.\n\n", ctx.name))
+	b.WriteString("## Detection results\n\n")
+
+	dockerfiles := make([]string, 0, len(ctx.dockerfiles))
+	for path := range ctx.dockerfiles {
+		if port, ok := ctx.exposedPorts[path]; ok {
+			path = fmt.Sprintf("%s (EXPOSE %d)", path, port)
+		}
+		dockerfiles = append(dockerfiles, path)
+	}
+	sort.Strings(dockerfiles)
+	list("Dockerfiles", dockerfiles)
+
+	manifests := make([]string, 0, len(ctx.depFiles))
+	for path := range ctx.depFiles {
+		manifests = append(manifests, path)
+	}
+	sort.Strings(manifests)
+	list("Dependency manifests", manifests)
+
+	services := make([]string, 0, len(ctx.composePorts))
+	for svc, port := range ctx.composePorts {
+		services = append(services, fmt.Sprintf("%s: %d", svc, port))
+	}
+	sort.Strings(services)
+	list("docker-compose container ports (composePorts)", services)
+
+	var health []string
+	if ctx.healthEndpoint != "" {
+		health = []string{ctx.healthEndpoint}
+	}
+	list("Health check endpoint (healthEndpoint)", health)
+
+	for _, d := range ctx.detectionLists() {
+		list(d.title, d.items)
+	}
+
+	procs := make([]string, 0, len(ctx.procEntries))
+	for _, e := range ctx.procEntries {
+		procs = append(procs, fmt.Sprintf("%s: %s", e.name, e.command))
+	}
+	list("Procfile process types (procEntries)", procs)
+
+	b.WriteString("## Generated workflow\n```yaml\n")
+	b.WriteString(workflow)
+	b.WriteString("\n```\n")
+	return b.String()
+}
+
 // ────────────────────────────────────────────────────────────────────────────
 // Helpers
 // ────────────────────────────────────────────────────────────────────────────
@@ -832,6 +1680,35 @@ func cleanYAMLResponse(s string) string {
 	return strings.TrimSpace(s)
 }
 
+// validateWorkflow runs cheap sanity checks on a cleaned model response so
+// an obviously broken answer can trigger a model fallback. It does not
+// parse YAML — it catches prose replies, truncated output, and workflows
+// that never deploy anything.
+func validateWorkflow(workflow string) error {
+	if strings.TrimSpace(workflow) == "" {
+		return fmt.Errorf("empty response")
+	}
+	for _, line := range strings.Split(workflow, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.Contains(trimmed, ":") {
+			return fmt.Errorf("response does not start with YAML: %q", trimmed)
+		}
+		break
+	}
+	for i, line := range strings.Split(workflow, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			return fmt.Errorf("line %d is indented with a tab", i+1)
+		}
+	}
+	if !strings.Contains(workflow, "kindling-deploy") && !strings.Contains(workflow, "DevStagingEnvironment") {
+		return fmt.Errorf("workflow has no deploy step")
+	}
+	return nil
+}
+
 // ── External credential detection ───────────────────────────────
 
 // credentialPatterns are suffixes that indicate an env var is an external credential.
@@ -866,7 +1743,40 @@ var dependencyManagedNames = map[string]bool{
 	"CONSUL_HTTP_ADDR":  true,
 	"VAULT_ADDR":        true,
 	"INFLUXDB_URL":      true,
+	"INFLUXDB_TOKEN":    true,
+	"INFLUXDB_ORG":      true,
+	"INFLUXDB_BUCKET":   true,
 	"JAEGER_ENDPOINT":   true,
+	"CLICKHOUSE_URL":    true,
+	"SMTP_URL":          true,
+	"SMTP_HOST":         true,
+	"SMTP_PORT":         true,
+	"QDRANT_URL":        true,
+	"WEAVIATE_URL":      true,
+	"WEAVIATE_GRPC_URL": true,
+	"CHROMA_URL":        true,
+	"CHROMA_HOST":       true,
+	"CHROMA_PORT":       true,
+	"AWS_ENDPOINT_URL":  true,
+	"NEO4J_URL":         true,
+	"ETCD_ENDPOINTS":    true,
+	"PROMETHEUS_URL":    true,
+	"GRAFANA_URL":       true,
+	"ARANGO_URL":        true,
+	"COUCHDB_URL":       true,
+	"PULSAR_URL":        true,
+	"PULSAR_ADMIN_URL":  true,
+	"MEILISEARCH_URL":   true,
+	"TYPESENSE_URL":     true,
+
+	"OTEL_EXPORTER_OTLP_ENDPOINT": true,
+	"OTEL_EXPORTER_OTLP_PROTOCOL": true,
+	"ZOOKEEPER_URL":               true,
+	"SURREAL_URL":                 true,
+	"SURREAL_USER":                true,
+	"SURREAL_PASS":                true,
+	"RETHINKDB_URL":               true,
+	"OPENSEARCH_URL":              true,
 	// Dependency credentials (managed by operator defaults)
 	"POSTGRES_PASSWORD":          true,
 	"POSTGRES_USER":              true,
@@ -876,9 +1786,20 @@ var dependencyManagedNames = map[string]bool{
 	"MYSQL_ROOT_PASSWORD":        true,
 	"MYSQL_USER":                 true,
 	"MYSQL_DATABASE":             true,
+	"MARIADB_PASSWORD":           true,
+	"MARIADB_ROOT_PASSWORD":      true,
+	"MARIADB_USER":               true,
+	"MARIADB_DATABASE":           true,
+	"MSSQL_SA_PASSWORD":          true,
 	"REDIS_PASSWORD":             true,
 	"MONGO_INITDB_ROOT_USERNAME": true,
 	"MONGO_INITDB_ROOT_PASSWORD": true,
+	"ARANGO_ROOT_PASSWORD":       true,
+	"COUCHDB_USER":               true,
+	"COUCHDB_PASSWORD":           true,
+	"MEILI_MASTER_KEY":           true,
+	"MEILISEARCH_API_KEY":        true,
+	"TYPESENSE_API_KEY":          true,
 }
 
 // credentialExactNames are full env var names that indicate external credentials.
@@ -956,6 +1877,133 @@ func detectDockerfileContextIssues(ctx *repoContext) []string {
 	return warnings
 }
 
+// parseProcfile parses Procfile content into process entries, preserving
+// file order. Blank lines, comments, and malformed lines are ignored.
+func parseProcfile(content string) []procEntry {
+	var entries []procEntry
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx := strings.Index(line, ":")
+		if idx <= 0 {
+			continue
+		}
+		name := strings.TrimSpace(line[:idx])
+		command := strings.TrimSpace(line[idx+1:])
+		if command == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		entries = append(entries, procEntry{name: name, command: command})
+	}
+	return entries
+}
+
+// parseExposedPorts returns the ports declared by EXPOSE directives in a
+// Dockerfile, in order of appearance. It accepts multiple ports per line and
+// protocol suffixes ("EXPOSE 8080/tcp 9090"); variable references like
+// $PORT and port ranges are skipped because they can't be resolved statically.
+func parseExposedPorts(content string) []int32 {
+	var ports []int32
+	seen := make(map[int32]bool)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(strings.TrimSpace(line))
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "EXPOSE") {
+			continue
+		}
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "#") {
+				break
+			}
+			if idx := strings.Index(f, "/"); idx >= 0 {
+				f = f[:idx]
+			}
+			n, err := strconv.ParseInt(f, 10, 32)
+			if err != nil || n <= 0 || n > 65535 {
+				continue
+			}
+			if port := int32(n); !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+	return ports
+}
+
+// parseComposePorts maps each docker-compose service to the container side of
+// its first published port. It reads the short syntax ("8080", "3000:3000",
+// "127.0.0.1:5000:5000", optionally with "/tcp") in block or flow lists, and
+// the long syntax's target: key. Port ranges and variable references are
+// skipped because they can't be resolved statically.
+func parseComposePorts(content string) map[string]int32 {
+	ports := make(map[string]int32)
+	record := func(service, spec string) {
+		if _, ok := ports[service]; ok || service == "" {
+			return
+		}
+		spec, _, _ = strings.Cut(unquoteYAML(strings.TrimSpace(spec)), "/")
+		if idx := strings.LastIndex(spec, ":"); idx >= 0 {
+			spec = spec[idx+1:]
+		}
+		if n, err := strconv.ParseInt(spec, 10, 32); err == nil && n > 0 && n <= 65535 {
+			ports[service] = int32(n)
+		}
+	}
+
+	servicesIndent, serviceIndent, portsIndent := -1, -1, -1
+	service := ""
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := indentOf(line)
+		if servicesIndent < 0 {
+			if trimmed == "services:" {
+				servicesIndent = indent
+			}
+			continue
+		}
+		if indent <= servicesIndent {
+			break // the next top-level key ends the services block
+		}
+		if serviceIndent < 0 {
+			serviceIndent = indent
+		}
+		if indent == serviceIndent {
+			name, _, _ := strings.Cut(trimmed, ":")
+			service, portsIndent = unquoteYAML(name), -1
+			continue
+		}
+
+		// Block sequences may sit at the same indent as their key.
+		inPorts := portsIndent >= 0 && (indent > portsIndent || (indent == portsIndent && strings.HasPrefix(trimmed, "- ")))
+		if !inPorts {
+			portsIndent = -1
+			if value, ok := strings.CutPrefix(trimmed, "ports:"); ok {
+				portsIndent = indent
+				if value = strings.TrimSpace(value); strings.HasPrefix(value, "[") {
+					value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+					if first, _, _ := strings.Cut(value, ","); first != "" {
+						record(service, first)
+					}
+				}
+			}
+			continue
+		}
+
+		item := strings.TrimPrefix(trimmed, "- ")
+		if target, ok := strings.CutPrefix(item, "target:"); ok {
+			record(service, target)
+		} else if item != trimmed && !strings.Contains(item, ": ") {
+			record(service, item)
+		}
+	}
+	return ports
+}
+
 // detectExternalSecrets scans source files, Dockerfiles, compose files, and .env
 // files for references to external credentials.
 func detectExternalSecrets(repoPath string, ctx *repoContext) []string {
@@ -1276,6 +2324,14 @@ func detectMCPServers(repoPath string, ctx *repoContext) []string {
 	return result
 }
 
+// localVectorStoreDeps maps detected vector stores that kindling can run
+// locally to their dependency type.
+var localVectorStoreDeps = map[string]string{
+	"Qdrant":   "qdrant",
+	"Weaviate": "weaviate",
+	"ChromaDB": "chroma",
+}
+
 // vectorStorePatterns maps import patterns to vector store names.
 var vectorStorePatterns = []struct {
 	pattern string
@@ -1354,6 +2410,8 @@ var workerPatterns = []struct {
 	{"consumer.subscribe", "Kafka/message consumer subscription"},
 	{"confluent_kafka", "Confluent Kafka (Python)"},
 	{"segmentio/kafka-go", "Kafka consumer (Go)"},
+	// Pulsar consumers
+	{"pulsar-client", "Pulsar consumer"},
 	// RabbitMQ / AMQP
 	{"basic_consume", "RabbitMQ consumer (basic_consume)"},
 	{"channel.consume", "AMQP channel consumer"},
@@ -1408,6 +2466,388 @@ func detectWorkerProcesses(ctx *repoContext) []string {
 	return result
 }
 
+// scheduledJobPatterns maps code/config patterns to scheduler descriptions.
+var scheduledJobPatterns = []struct {
+	pattern string
+	desc    string
+}{
+	// Python
+	{"APScheduler", "APScheduler (Python)"},
+	{"apscheduler", "APScheduler (Python)"},
+	{"celery beat", "Celery beat scheduler"},
+	{"beat_schedule", "Celery beat scheduler"},
+	{"schedule.every(", "schedule library (Python)"},
+	// Node.js
+	{"node-cron", "node-cron (Node.js)"},
+	{"cron.schedule(", "cron.schedule() (Node.js)"},
+	{"node-schedule", "node-schedule (Node.js)"},
+	{"@nestjs/schedule", "NestJS @nestjs/schedule"},
+	// Java / Kotlin
+	{"@Scheduled", "Spring @Scheduled"},
+	{"@EnableScheduling", "Spring @Scheduled"},
+	// Ruby
+	{"sidekiq-scheduler", "sidekiq-scheduler (Ruby)"},
+	{"sidekiq-cron", "sidekiq-cron (Ruby)"},
+	{`gem "whenever"`, "whenever gem (Ruby)"},
+	{`gem 'whenever'`, "whenever gem (Ruby)"},
+	// Go
+	{"robfig/cron", "robfig/cron (Go)"},
+	{"go-co-op/gocron", "gocron (Go)"},
+}
+
+// detectScheduledJobs scans all collected content for in-process schedulers
+// and cron libraries.
+func detectScheduledJobs(ctx *repoContext) []string {
+	allContent := mergeAllContent(ctx)
+
+	seen := make(map[string]bool)
+	for _, content := range allContent {
+		for _, p := range scheduledJobPatterns {
+			if seen[p.desc] {
+				continue
+			}
+			if strings.Contains(content, p.pattern) {
+				seen[p.desc] = true
+			}
+		}
+	}
+
+	var result []string
+	for desc := range seen {
+		result = append(result, desc)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// embeddedDBPatterns maps imports/dependencies to in-process databases.
+var embeddedDBPatterns = []struct {
+	pattern string
+	desc    string
+}{
+	// SQLite
+	{"mattn/go-sqlite3", "SQLite"},
+	{"modernc.org/sqlite", "SQLite"},
+	{"glebarez/sqlite", "SQLite"},
+	{"import sqlite3", "SQLite"},
+	{"sqlite:///", "SQLite"},
+	{"aiosqlite", "SQLite"},
+	{`gem 'sqlite3'`, "SQLite"},
+	{`gem "sqlite3"`, "SQLite"},
+	{"better-sqlite3", "SQLite"},
+	{`"sqlite3":`, "SQLite"},
+	{"rusqlite", "SQLite"},
+	{"org.xerial:sqlite-jdbc", "SQLite"},
+	{"Microsoft.Data.Sqlite", "SQLite"},
+	// DuckDB
+	{"duckdb", "DuckDB"},
+	{"DuckDB", "DuckDB"},
+	// Key-value stores
+	{"dgraph-io/badger", "BadgerDB"},
+	{"go.etcd.io/bbolt", "bbolt"},
+	{"boltdb/bolt", "bbolt"},
+	{"cockroachdb/pebble", "Pebble"},
+	{"syndtr/goleveldb", "LevelDB"},
+	{"rocksdb", "RocksDB"},
+	{"lmdb", "LMDB"},
+}
+
+// detectEmbeddedDatabases scans all collected content for in-process
+// databases, which need no backing-service dependency.
+func detectEmbeddedDatabases(ctx *repoContext) []string {
+	allContent := mergeAllContent(ctx)
+
+	seen := make(map[string]bool)
+	for _, content := range allContent {
+		for _, p := range embeddedDBPatterns {
+			if seen[p.desc] {
+				continue
+			}
+			if strings.Contains(content, p.pattern) {
+				seen[p.desc] = true
+			}
+		}
+	}
+
+	var result []string
+	for desc := range seen {
+		result = append(result, desc)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// temporalPatterns maps imports/dependencies to Temporal SDKs.
+var temporalPatterns = []struct {
+	pattern string
+	desc    string
+}{
+	{"go.temporal.io/sdk", "Temporal Go SDK"},
+	{"@temporalio/client", "Temporal TypeScript SDK"},
+	{"@temporalio/worker", "Temporal TypeScript SDK"},
+	{"from temporalio", "Temporal Python SDK"},
+	{"import temporalio", "Temporal Python SDK"},
+	{"temporalio", "Temporal Python SDK"},
+	{"io.temporal:temporal-sdk", "Temporal Java SDK"},
+	{"Temporalio", "Temporal .NET SDK"},
+}
+
+// detectTemporal scans all collected content for Temporal SDK usage.
+func detectTemporal(ctx *repoContext) []string {
+	allContent := mergeAllContent(ctx)
+
+	seen := make(map[string]bool)
+	for path, content := range allContent {
+		for _, p := range temporalPatterns {
+			if seen[p.desc] {
+				continue
+			}
+			// The bare "temporalio" package name is only meaningful in
+			// Python manifests; elsewhere it matches @temporalio/*.
+			if p.pattern == "temporalio" && !isPythonDepFile(path) {
+				continue
+			}
+			if strings.Contains(content, p.pattern) {
+				seen[p.desc] = true
+			}
+		}
+	}
+
+	var result []string
+	for desc := range seen {
+		result = append(result, desc)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// isPythonDepFile reports whether path is a Python dependency manifest.
+func isPythonDepFile(path string) bool {
+	switch filepath.Base(path) {
+	case "requirements.txt", "pyproject.toml", "Pipfile", "setup.py", "setup.cfg":
+		return true
+	}
+	return false
+}
+
+// webSocketPatterns maps imports/dependencies to WebSocket server libraries.
+var webSocketPatterns = []struct {
+	pattern string
+	desc    string
+}{
+	{"github.com/gorilla/websocket", "gorilla/websocket (Go)"},
+	{"nhooyr.io/websocket", "nhooyr.io/websocket (Go)"},
+	{"github.com/coder/websocket", "coder/websocket (Go)"},
+	{"socket.io", "Socket.IO"},
+	{"flask_socketio", "Flask-SocketIO (Python)"},
+	{"python-socketio", "python-socketio"},
+	{"require('ws')", "ws (Node.js)"},
+	{"require(\"ws\")", "ws (Node.js)"},
+	{"from 'ws'", "ws (Node.js)"},
+	{"from \"ws\"", "ws (Node.js)"},
+	{"\"ws\":", "ws (Node.js)"},
+	{"import websockets", "websockets (Python)"},
+	{"from websockets", "websockets (Python)"},
+	{"websockets", "websockets (Python)"},
+	{"@app.websocket(", "FastAPI WebSocket routes"},
+	{"ActionCable", "Rails ActionCable"},
+	{"action_cable", "Rails ActionCable"},
+	{"Phoenix.Socket", "Phoenix channels"},
+}
+
+// detectWebSockets scans all collected content for WebSocket server
+// libraries, whose long-lived connections need ingress timeouts raised.
+func detectWebSockets(ctx *repoContext) []string {
+	allContent := mergeAllContent(ctx)
+
+	seen := make(map[string]bool)
+	for path, content := range allContent {
+		for _, p := range webSocketPatterns {
+			if seen[p.desc] {
+				continue
+			}
+			// The bare "websockets" package name is only meaningful in
+			// Python manifests; elsewhere it matches prose and identifiers.
+			if p.pattern == "websockets" && !isPythonDepFile(path) {
+				continue
+			}
+			if strings.Contains(content, p.pattern) {
+				seen[p.desc] = true
+			}
+		}
+	}
+
+	var result []string
+	for desc := range seen {
+		result = append(result, desc)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// gpuWorkloadPatterns maps imports/dependencies to ML frameworks that load
+// models into memory and usually expect a GPU.
+var gpuWorkloadPatterns = []struct {
+	pattern string
+	desc    string
+}{
+	{"import torch", "PyTorch"},
+	{"from torch", "PyTorch"},
+	{"torch", "PyTorch"},
+	{"import tensorflow", "TensorFlow"},
+	{"from tensorflow", "TensorFlow"},
+	{"tensorflow", "TensorFlow"},
+	{"@tensorflow/tfjs-node-gpu", "TensorFlow.js (GPU)"},
+	{"from transformers", "Hugging Face Transformers"},
+	{"import transformers", "Hugging Face Transformers"},
+	{"transformers", "Hugging Face Transformers"},
+	{"onnxruntime-gpu", "ONNX Runtime (GPU)"},
+}
+
+// gpuBarePatterns are package names only meaningful in Python manifests.
+var gpuBarePatterns = map[string]bool{"torch": true, "tensorflow": true, "transformers": true}
+
+// detectGPUWorkloads scans all collected content for ML frameworks that
+// need large memory requests (and a GPU outside Kind).
+func detectGPUWorkloads(ctx *repoContext) []string {
+	allContent := mergeAllContent(ctx)
+
+	seen := make(map[string]bool)
+	for path, content := range allContent {
+		for _, p := range gpuWorkloadPatterns {
+			if seen[p.desc] {
+				continue
+			}
+			// Bare package names match prose and unrelated identifiers
+			// outside Python dependency manifests.
+			if gpuBarePatterns[p.pattern] && !isPythonDepFile(path) {
+				continue
+			}
+			if strings.Contains(content, p.pattern) {
+				seen[p.desc] = true
+			}
+		}
+	}
+
+	var result []string
+	for desc := range seen {
+		result = append(result, desc)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// healthRouteCandidates are the probe routes looked for in source,
+// readiness endpoints first.
+var healthRouteCandidates = []string{
+	"/readyz", "/ready", "/healthz", "/health", "/_health", "/healthcheck",
+	"/api/health", "/actuator/health", "/livez", "/ping", "/status",
+}
+
+// frameworkHealthDefaults maps dependencies that serve a health route out
+// of the box to that route.
+var frameworkHealthDefaults = []struct {
+	pattern string
+	path    string
+}{
+	{"spring-boot-starter-actuator", "/actuator/health"},
+	{"quarkus-smallrye-health", "/q/health/ready"},
+	{"micronaut-management", "/health"},
+	{"rails/health#show", "/up"},
+}
+
+// detectHealthRoutes returns the health routes that appear as string
+// literals in the sampled source under dir ("." for the whole repo).
+func detectHealthRoutes(ctx *repoContext, dir string) []string {
+	var routes []string
+	for _, route := range healthRouteCandidates {
+		found := false
+		for path, content := range ctx.sourceSnippets {
+			if dir != "." && !strings.HasPrefix(path, dir+"/") {
+				continue
+			}
+			for _, q := range []string{`"`, `'`, "`"} {
+				if strings.Contains(content, q+route+q) {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if found {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// detectHealthEndpoint picks the route to probe: a health route declared in
+// source, else the default of a framework that serves one automatically.
+func detectHealthEndpoint(ctx *repoContext) (path string, found bool) {
+	if routes := detectHealthRoutes(ctx, "."); len(routes) > 0 {
+		return routes[0], true
+	}
+	allContent := mergeAllContent(ctx)
+	for _, f := range frameworkHealthDefaults {
+		for _, content := range allContent {
+			if strings.Contains(content, f.pattern) {
+				return f.path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// daprPatterns maps imports and annotations to Dapr indicators.
+var daprPatterns = []struct {
+	pattern string
+	desc    string
+}{
+	{"dapr.io/", "dapr.io annotations"},
+	{"github.com/dapr/go-sdk", "Dapr Go SDK"},
+	{"@dapr/dapr", "Dapr JavaScript SDK"},
+	{"from dapr", "Dapr Python SDK"},
+	{"dapr-ext-", "Dapr Python SDK"},
+	{"io.dapr:dapr-sdk", "Dapr Java SDK"},
+	{"Dapr.Client", "Dapr .NET SDK"},
+	{"dapr run", "dapr run command"},
+}
+
+// detectDapr scans collected content for Dapr SDKs and annotations, and the
+// tree for a Dapr components/ directory of YAML files.
+func detectDapr(ctx *repoContext) []string {
+	allContent := mergeAllContent(ctx)
+
+	seen := make(map[string]bool)
+	for _, content := range allContent {
+		for _, p := range daprPatterns {
+			if seen[p.desc] {
+				continue
+			}
+			if strings.Contains(content, p.pattern) {
+				seen[p.desc] = true
+			}
+		}
+	}
+
+	for _, line := range strings.Split(ctx.tree, "\n") {
+		line = strings.TrimSpace(line)
+		ext := strings.ToLower(filepath.Ext(line))
+		if (ext == ".yaml" || ext == ".yml") && filepath.Base(filepath.Dir(line)) == "components" {
+			seen[fmt.Sprintf("Dapr components directory: %s", filepath.Dir(line))] = true
+		}
+	}
+
+	var result []string
+	for desc := range seen {
+		result = append(result, desc)
+	}
+	sort.Strings(result)
+	return result
+}
+
 // mergeAllContent combines all scanned content into a single map for pattern matching.
 func mergeAllContent(ctx *repoContext) map[string]string {
 	all := make(map[string]string)
@@ -1449,12 +2889,6 @@ var interServiceCallPatterns = []struct {
 	{`http.Get(`, "Go http.Get (potential inter-service call)"},
 	{`http.Post(`, "Go http.Post (potential inter-service call)"},
 	{`http.NewRequest(`, "Go http.NewRequest (potential inter-service call)"},
-	// gRPC channels
-	{`grpc.Dial(`, "Go gRPC dial (inter-service)"},
-	{`grpc.NewClient(`, "Go gRPC client (inter-service)"},
-	{`grpc.insecure_channel(`, "Python gRPC channel (inter-service)"},
-	{`new grpc.Client(`, "Node gRPC client (inter-service)"},
-	{`ManagedChannelBuilder.forAddress(`, "Java gRPC channel (inter-service)"},
 	// Service URL env vars
 	{`_SERVICE_URL`, "Service URL env var reference"},
 	{`_SERVICE_HOST`, "Service host env var reference"},
@@ -1462,13 +2896,46 @@ var interServiceCallPatterns = []struct {
 	{`_ENDPOINT`, "Endpoint env var reference"},
 }
 
+// grpcCallPatterns are the gRPC dials among the inter-service patterns.
+// They get their own DIRECTIVE: gRPC targets need a grpc health check and
+// a scheme-less host:port address.
+var grpcCallPatterns = []struct {
+	pattern string
+	desc    string
+}{
+	{`grpc.Dial(`, "Go gRPC dial (inter-service)"},
+	{`grpc.NewClient(`, "Go gRPC client (inter-service)"},
+	{`grpc.insecure_channel(`, "Python gRPC channel (inter-service)"},
+	{`grpc.aio.insecure_channel(`, "Python gRPC channel (inter-service)"},
+	{`new grpc.Client(`, "Node gRPC client (inter-service)"},
+	{`ManagedChannelBuilder.forAddress(`, "Java gRPC channel (inter-service)"},
+	{`GrpcChannel.ForAddress(`, "C# gRPC channel (inter-service)"},
+}
+
+// grpcInterServiceCalls returns the calls in calls that came from a gRPC
+// pattern.
+func grpcInterServiceCalls(calls []string) []string {
+	grpcDescs := make(map[string]bool)
+	for _, p := range grpcCallPatterns {
+		grpcDescs[p.desc] = true
+	}
+	var out []string
+	for _, c := range calls {
+		if grpcDescs[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
 // detectInterServiceCalls scans source code for patterns indicating
 // one service calls another over HTTP or gRPC.
 func detectInterServiceCalls(ctx *repoContext) []string {
 	seen := make(map[string]bool)
 
+	patterns := append(append(interServiceCallPatterns[:0:0], interServiceCallPatterns...), grpcCallPatterns...)
 	for _, content := range ctx.sourceSnippets {
-		for _, p := range interServiceCallPatterns {
+		for _, p := range patterns {
 			if seen[p.desc] {
 				continue
 			}
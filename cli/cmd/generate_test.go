@@ -3,9 +3,12 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jeffvincent/kindling/pkg/ci"
 )
@@ -691,6 +694,121 @@ func main() { _ = os.Getenv("PORT") }`), 0644)
 	}
 }
 
+func TestScanRepo_Services(t *testing.T) {
+	dir := t.TempDir()
+	for _, svc := range []string{"services/api", "services/web"} {
+		os.MkdirAll(filepath.Join(dir, svc, "cmd"), 0755)
+		os.WriteFile(filepath.Join(dir, svc, "Dockerfile"), []byte("FROM golang:1.22"), 0644)
+		os.WriteFile(filepath.Join(dir, svc, "go.mod"), []byte("module "+svc), 0644)
+		os.WriteFile(filepath.Join(dir, svc, "cmd", "main.go"), []byte("package main"), 0644)
+	}
+
+	ctx, err := scanRepo(dir, filepath.Join("services", "api"))
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+	if ctx.dockerfileCount != 1 || ctx.dockerfiles[filepath.Join("services", "api", "Dockerfile")] == "" {
+		t.Errorf("dockerfiles = %v, want only services/api", ctx.dockerfiles)
+	}
+	if ctx.depFileCount != 1 {
+		t.Errorf("depFileCount = %d, want 1", ctx.depFileCount)
+	}
+	if _, ok := ctx.sourceSnippets[filepath.Join("services", "api", "cmd", "main.go")]; !ok || len(ctx.sourceSnippets) != 1 {
+		t.Errorf("sourceSnippets = %v, want only services/api/cmd/main.go", ctx.sourceOrder)
+	}
+
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+	if !strings.Contains(user, "## Service scope") || !strings.Contains(user, "- "+filepath.Join("services", "api")+"\n") {
+		t.Error("user prompt should list the in-scope services")
+	}
+}
+
+func TestParseServicePaths(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "api"), 0755)
+	os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+	os.WriteFile(filepath.Join(dir, "api", "Dockerfile.dev"), []byte("FROM alpine"), 0644)
+
+	got, err := parseServicePaths(dir, " ./api/, api,"+filepath.Join(dir, "api"))
+	if err != nil || len(got) != 1 || got[0] != "api" {
+		t.Errorf("parseServicePaths() = %v, %v; want [api]", got, err)
+	}
+	if got, err := parseServicePaths(dir, ""); err != nil || got != nil {
+		t.Errorf("empty --services = %v, %v; want nil", got, err)
+	}
+	for _, bad := range []string{"docs", "missing", "../api"} {
+		if _, err := parseServicePaths(dir, bad); err == nil {
+			t.Errorf("parseServicePaths(%q) should fail", bad)
+		}
+	}
+}
+
+func TestScanRepoWithCache_HitAndMiss(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM node:20\nEXPOSE 3000"), 0644)
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"dependencies":{"node-cron":"3"}}`), 0644)
+	os.WriteFile(filepath.Join(dir, "Procfile"), []byte("web: node server.js\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "server.js"), []byte(`const url = process.env.DATABASE_URL;`), 0644)
+
+	fresh, hit, err := scanRepoWithCache(dir, cacheDir)
+	if err != nil {
+		t.Fatalf("scanRepoWithCache() error = %v", err)
+	}
+	if hit {
+		t.Fatal("first scan should miss the cache")
+	}
+
+	cached, hit, err := scanRepoWithCache(dir, cacheDir)
+	if err != nil {
+		t.Fatalf("scanRepoWithCache() error = %v", err)
+	}
+	if !hit {
+		t.Fatal("second scan of an unchanged repo should hit the cache")
+	}
+
+	// The cache must not change prompt content.
+	fresh.branch, cached.branch = "main", "main"
+	_, wantUser := buildGeneratePrompt(fresh, ci.Default())
+	_, gotUser := buildGeneratePrompt(cached, ci.Default())
+	if gotUser != wantUser {
+		t.Error("cached repoContext should produce the same prompt as a fresh scan")
+	}
+
+	// Changing a file changes the fingerprint.
+	os.WriteFile(filepath.Join(dir, "server.js"), []byte(`const url = process.env.REDIS_URL; // changed`), 0644)
+	_, hit, err = scanRepoWithCache(dir, cacheDir)
+	if err != nil {
+		t.Fatalf("scanRepoWithCache() error = %v", err)
+	}
+	if hit {
+		t.Error("modified repo should miss the cache")
+	}
+}
+
+func TestScanRepoWithCache_NewerFileBypasses(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\ngo 1.21"), 0644)
+
+	if _, _, err := scanRepoWithCache(dir, cacheDir); err != nil {
+		t.Fatalf("scanRepoWithCache() error = %v", err)
+	}
+
+	// Age the cache entry so the repo's files look newer than it.
+	entries, _ := os.ReadDir(cacheDir)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 cache entry, got %d", len(entries))
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(cacheDir, entries[0].Name()), old, old)
+
+	if _, hit, _ := scanRepoWithCache(dir, cacheDir); hit {
+		t.Error("cache entry older than a tracked file should be bypassed")
+	}
+}
+
 func TestScanRepo_SkipsDirs(t *testing.T) {
 	dir := t.TempDir()
 
@@ -1233,6 +1351,20 @@ consumer = KafkaConsumer('my-topic')`,
 	}
 }
 
+func TestDetectWorkerProcesses_Pulsar(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: make(map[string]string),
+		depFiles: map[string]string{
+			"requirements.txt": "pulsar-client==3.5.0\n",
+		},
+		dockerfiles: make(map[string]string),
+	}
+	workers := detectWorkerProcesses(ctx)
+	if !slices.Contains(workers, "Pulsar consumer") {
+		t.Errorf("should detect Pulsar consumer, got %v", workers)
+	}
+}
+
 func TestDetectWorkerProcesses_RabbitMQ(t *testing.T) {
 	ctx := &repoContext{
 		sourceSnippets: map[string]string{
@@ -1340,546 +1472,1848 @@ func TestDetectWorkerProcesses_None(t *testing.T) {
 // mergeAllContent
 // ────────────────────────────────────────────────────────────────────────────
 
-func TestMergeAllContent(t *testing.T) {
+// ────────────────────────────────────────────────────────────────────────────
+// detectScheduledJobs
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestDetectScheduledJobs_APScheduler(t *testing.T) {
 	ctx := &repoContext{
-		dockerfiles: map[string]string{"Dockerfile": "FROM node:18"},
-		depFiles:    map[string]string{"package.json": `{"name":"app"}`},
 		sourceSnippets: map[string]string{
-			"app.js": "console.log('hello')",
+			"jobs.py": `from apscheduler.schedulers.blocking import BlockingScheduler
+
+sched = BlockingScheduler()`,
 		},
-		composeFile: "services:\n  web:\n    build: .",
-	}
-	merged := mergeAllContent(ctx)
-	if len(merged) != 4 {
-		t.Errorf("expected 4 entries, got %d", len(merged))
-	}
-	if merged["Dockerfile"] != "FROM node:18" {
-		t.Error("should include Dockerfile content")
+		depFiles:    map[string]string{"requirements.txt": "APScheduler==3.10.4\n"},
+		dockerfiles: make(map[string]string),
 	}
-	if merged["docker-compose.yml"] != ctx.composeFile {
-		t.Error("should include compose file")
+	jobs := detectScheduledJobs(ctx)
+	if len(jobs) != 1 || jobs[0] != "APScheduler (Python)" {
+		t.Errorf("should detect APScheduler once, got %v", jobs)
 	}
 }
 
-func TestMergeAllContent_NoCompose(t *testing.T) {
+func TestDetectScheduledJobs_NodeCron(t *testing.T) {
 	ctx := &repoContext{
-		dockerfiles:    map[string]string{"Dockerfile": "FROM go:1.21"},
-		depFiles:       make(map[string]string),
-		sourceSnippets: make(map[string]string),
+		sourceSnippets: map[string]string{
+			"cron.js": `const cron = require('node-cron');
+cron.schedule('*/5 * * * *', () => sync());`,
+		},
+		depFiles:    make(map[string]string),
+		dockerfiles: make(map[string]string),
 	}
-	merged := mergeAllContent(ctx)
-	if _, ok := merged["docker-compose.yml"]; ok {
-		t.Error("should not include compose key when no compose file")
+	jobs := detectScheduledJobs(ctx)
+	found := false
+	for _, j := range jobs {
+		if strings.Contains(j, "node-cron") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("should detect node-cron, got %v", jobs)
 	}
 }
 
-// ────────────────────────────────────────────────────────────────────────────
-// buildGeneratePrompt with multi-agent context
-// ────────────────────────────────────────────────────────────────────────────
-
-func TestBuildGeneratePrompt_WithAgentFrameworks(t *testing.T) {
+func TestDetectScheduledJobs_SpringScheduled(t *testing.T) {
 	ctx := &repoContext{
-		name:            "agent-app",
-		branch:          "main",
-		tree:            "main.py\nDockerfile\n",
-		agentFrameworks: []string{"CrewAI", "LangChain"},
-		vectorStores:    []string{"ChromaDB", "pgvector"},
-		mcpServers:      []string{"MCP config file: mcp.json"},
-		workerProcesses: []string{"Celery worker"},
-		dockerfiles:     make(map[string]string),
-		depFiles:        make(map[string]string),
-		sourceSnippets:  make(map[string]string),
-	}
-
-	system, user := buildGeneratePrompt(ctx, ci.Default())
-
-	// System prompt should contain multi-agent guidance
-	if !strings.Contains(system, "Multi-agent architecture") {
-		t.Error("system prompt should contain multi-agent architecture guidance")
+		sourceSnippets: map[string]string{
+			"Report.java": `@Scheduled(cron = "0 0 * * * *")
+public void nightly() {}`,
+		},
+		depFiles:    make(map[string]string),
+		dockerfiles: make(map[string]string),
 	}
-	if !strings.Contains(system, "MCP") {
-		t.Error("system prompt should mention MCP")
+	jobs := detectScheduledJobs(ctx)
+	if len(jobs) == 0 || jobs[0] != "Spring @Scheduled" {
+		t.Errorf("should detect Spring @Scheduled, got %v", jobs)
 	}
+}
 
-	// User prompt should contain all detected patterns
-	if !strings.Contains(user, "multi-agent architecture") {
-		t.Error("user prompt should contain multi-agent section")
-	}
-	if !strings.Contains(user, "CrewAI") {
-		t.Error("user prompt should list CrewAI")
-	}
-	if !strings.Contains(user, "LangChain") {
-		t.Error("user prompt should list LangChain")
-	}
-	if !strings.Contains(user, "ChromaDB") {
-		t.Error("user prompt should list ChromaDB")
-	}
-	if !strings.Contains(user, "pgvector") {
-		t.Error("user prompt should list pgvector")
-	}
-	if !strings.Contains(user, "mcp.json") {
-		t.Error("user prompt should list MCP config")
+func TestDetectScheduledJobs_RubyWheneverAndSidekiq(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: make(map[string]string),
+		depFiles: map[string]string{
+			"Gemfile": "gem 'whenever', require: false\ngem 'sidekiq-scheduler'\n",
+		},
+		dockerfiles: make(map[string]string),
 	}
-	if !strings.Contains(user, "Celery") {
-		t.Error("user prompt should list Celery worker")
+	jobs := detectScheduledJobs(ctx)
+	if len(jobs) != 2 {
+		t.Errorf("should detect whenever and sidekiq-scheduler, got %v", jobs)
 	}
 }
 
-func TestBuildGeneratePrompt_NoAgentArch(t *testing.T) {
+func TestDetectScheduledJobs_CeleryBeat(t *testing.T) {
 	ctx := &repoContext{
-		name:           "plain-app",
-		branch:         "main",
-		tree:           "main.go\n",
-		dockerfiles:    make(map[string]string),
-		depFiles:       make(map[string]string),
 		sourceSnippets: make(map[string]string),
+		depFiles:       make(map[string]string),
+		dockerfiles:    make(map[string]string),
+		composeFile: `services:
+  beat:
+    command: celery beat -A app`,
 	}
+	jobs := detectScheduledJobs(ctx)
+	if len(jobs) != 1 || jobs[0] != "Celery beat scheduler" {
+		t.Errorf("should detect Celery beat, got %v", jobs)
+	}
+}
 
-	_, user := buildGeneratePrompt(ctx, ci.Default())
-
-	if strings.Contains(user, "multi-agent architecture") {
-		t.Error("user prompt should NOT contain multi-agent section for plain apps")
+func TestDetectScheduledJobs_None(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"main.go": `package main; func main() { http.ListenAndServe(":8080", nil) }`,
+		},
+		depFiles:    make(map[string]string),
+		dockerfiles: make(map[string]string),
+	}
+	if jobs := detectScheduledJobs(ctx); len(jobs) != 0 {
+		t.Errorf("should detect no scheduled jobs, got %v", jobs)
 	}
 }
 
 // ────────────────────────────────────────────────────────────────────────────
-// scanRepo integration: multi-agent detection
+// --prompt-file templates
 // ────────────────────────────────────────────────────────────────────────────
 
-func TestScanRepo_DetectsAgentFrameworks(t *testing.T) {
-	dir := t.TempDir()
-
-	os.WriteFile(filepath.Join(dir, "main.py"), []byte(`from crewai import Agent, Task, Crew
-from langchain.llms import OpenAI`), 0644)
-	os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("crewai\nlangchain"), 0644)
-
-	ctx, err := scanRepo(dir)
+func TestRenderSystemPrompt_DefaultMatchesBuiltIn(t *testing.T) {
+	ctx := &repoContext{name: "app", branch: "main", hostArch: "amd64"}
+	tmpl, err := loadPromptTemplate("")
 	if err != nil {
-		t.Fatalf("scanRepo() error = %v", err)
+		t.Fatalf("loadPromptTemplate() error = %v", err)
 	}
-
-	if !contains(ctx.agentFrameworks, "CrewAI") {
-		t.Errorf("should detect CrewAI, got %v", ctx.agentFrameworks)
+	got, err := renderSystemPrompt(tmpl, ctx, ci.Default())
+	if err != nil {
+		t.Fatalf("renderSystemPrompt() error = %v", err)
+	}
+	want, _ := buildGeneratePrompt(ctx, ci.Default())
+	if got != want {
+		t.Error("default template should reproduce the built-in system prompt")
 	}
 }
 
-func TestScanRepo_DetectsMCPServer(t *testing.T) {
-	dir := t.TempDir()
-
-	os.WriteFile(filepath.Join(dir, "mcp.json"), []byte(`{"servers":{"search":{}}}`), 0644)
-	os.WriteFile(filepath.Join(dir, "server.py"), []byte(`from mcp.server import FastMCP
-app = FastMCP("search")
-@app.tool()
-def search(q: str): pass`), 0644)
+func TestRenderSystemPrompt_CustomTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	os.WriteFile(path, []byte(`{{.Default}}
+## House rules
+- Repo {{.Repo.Name}} on {{.Repo.Branch}} targets {{.CI.PlatformName}}
+{{range $p, $_ := .Repo.Dockerfiles}}- push {{$p}} to registry.internal
+{{end}}`), 0644)
 
-	ctx, err := scanRepo(dir)
+	ctx := &repoContext{
+		name:        "shop",
+		branch:      "develop",
+		hostArch:    "amd64",
+		dockerfiles: map[string]string{"api/Dockerfile": "FROM go"},
+	}
+	tmpl, err := loadPromptTemplate(path)
 	if err != nil {
-		t.Fatalf("scanRepo() error = %v", err)
+		t.Fatalf("loadPromptTemplate() error = %v", err)
 	}
-
-	if len(ctx.mcpServers) == 0 {
-		t.Error("should detect MCP servers")
+	got, err := renderSystemPrompt(tmpl, ctx, ci.Default())
+	if err != nil {
+		t.Fatalf("renderSystemPrompt() error = %v", err)
+	}
+	if !strings.Contains(got, "Repo shop on develop targets GitHub Actions") {
+		t.Errorf("template should see repo and CI fields, got tail %q", got[len(got)-120:])
+	}
+	if !strings.Contains(got, "- push api/Dockerfile to registry.internal") {
+		t.Error("template should be able to range over Dockerfiles")
+	}
+	if !strings.HasPrefix(got, "You are an expert") {
+		t.Error("{{.Default}} should expand to the built-in prompt")
 	}
 }
 
-func TestScanRepo_DetectsVectorStores(t *testing.T) {
+func TestLoadPromptTemplate_Errors(t *testing.T) {
 	dir := t.TempDir()
 
-	os.WriteFile(filepath.Join(dir, "main.py"), []byte(`import chromadb
-client = chromadb.Client()`), 0644)
-	os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("chromadb"), 0644)
+	if _, err := loadPromptTemplate(filepath.Join(dir, "missing.tmpl")); err == nil {
+		t.Error("missing prompt file should fail")
+	}
 
-	ctx, err := scanRepo(dir)
+	bad := filepath.Join(dir, "bad.tmpl")
+	os.WriteFile(bad, []byte("{{.Default"), 0644)
+	if _, err := loadPromptTemplate(bad); err == nil || !strings.Contains(err.Error(), "invalid prompt template") {
+		t.Errorf("unparseable template should fail to load, got %v", err)
+	}
+
+	unknown := filepath.Join(dir, "unknown.tmpl")
+	os.WriteFile(unknown, []byte("{{.Repo.Nope}}"), 0644)
+	tmpl, err := loadPromptTemplate(unknown)
 	if err != nil {
-		t.Fatalf("scanRepo() error = %v", err)
+		t.Fatalf("loadPromptTemplate() error = %v", err)
+	}
+	if _, err := renderSystemPrompt(tmpl, &repoContext{name: "x"}, ci.Default()); err == nil {
+		t.Error("unknown field should fail at render time")
 	}
 
-	if !contains(ctx.vectorStores, "ChromaDB") {
-		t.Errorf("should detect ChromaDB, got %v", ctx.vectorStores)
+	empty := filepath.Join(dir, "empty.tmpl")
+	os.WriteFile(empty, []byte("{{/* nothing */}}\n"), 0644)
+	tmpl, _ = loadPromptTemplate(empty)
+	if _, err := renderSystemPrompt(tmpl, &repoContext{name: "x"}, ci.Default()); err == nil {
+		t.Error("empty rendered prompt should be rejected")
 	}
 }
 
-func TestScanRepo_DetectsWorkerProcesses(t *testing.T) {
-	dir := t.TempDir()
-
-	os.WriteFile(filepath.Join(dir, "tasks.py"), []byte(`from celery import Celery
-app = celery.Celery('myapp')
-@app.task
-def add(x, y): return x + y`), 0644)
+func TestBuildGeneratePrompt_UpdateMode(t *testing.T) {
+	ctx := &repoContext{
+		name:             "app",
+		branch:           "main",
+		existingWorkflow: "name: dev-deploy\n# hand-tuned timeout\n",
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
 
-	ctx, err := scanRepo(dir)
-	if err != nil {
-		t.Fatalf("scanRepo() error = %v", err)
+	if !strings.Contains(user, "## Current workflow (preserve user edits)") {
+		t.Error("update mode should include the current workflow section")
+	}
+	if !strings.Contains(user, "# hand-tuned timeout") {
+		t.Error("current workflow content should be passed through verbatim")
+	}
+	if strings.Contains(user, "Now generate the dev-deploy.yml") {
+		t.Error("update mode should ask for the updated workflow, not a fresh one")
 	}
 
-	if len(ctx.workerProcesses) == 0 {
-		t.Error("should detect Celery worker processes")
+	ctx.existingWorkflow = ""
+	_, user = buildGeneratePrompt(ctx, ci.Default())
+	if strings.Contains(user, "Current workflow") {
+		t.Error("fresh generation should not mention a current workflow")
 	}
 }
 
-// ────────────────────────────────────────────────────────────────────────────
-// scanDepFiles includes MCP config files
-// ────────────────────────────────────────────────────────────────────────────
+func TestBuildGeneratePrompt_DirectiveScheduledJobs(t *testing.T) {
+	ctx := &repoContext{
+		name:          "cron-app",
+		branch:        "main",
+		scheduledJobs: []string{"node-cron (Node.js)"},
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
 
-func TestScanDepFiles_IncludesMCPConfig(t *testing.T) {
-	if !scanDepFiles["mcp.json"] {
-		t.Error("scanDepFiles should include mcp.json")
+	if !strings.Contains(user, "## Detected scheduled jobs") {
+		t.Error("user prompt should list scheduled jobs")
 	}
-	if !scanDepFiles["mcp.config.json"] {
-		t.Error("scanDepFiles should include mcp.config.json")
+	if !strings.Contains(user, "no CronJob mode") {
+		t.Error("user prompt should explain the lack of a CronJob mode")
+	}
+	if !strings.Contains(user, "# SCHEDULED:") {
+		t.Error("user prompt should ask for a SCHEDULED YAML comment")
 	}
 }
 
 // ────────────────────────────────────────────────────────────────────────────
-// scanDepFiles includes Procfile
+// detectEmbeddedDatabases
 // ────────────────────────────────────────────────────────────────────────────
 
-func TestScanDepFiles_IncludesProcfile(t *testing.T) {
-	if !scanDepFiles["Procfile"] {
-		t.Error("scanDepFiles should include Procfile")
+func TestDetectEmbeddedDatabases_GoSQLite(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"main.go": `import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+)`,
+		},
+		depFiles:    map[string]string{"go.mod": "require github.com/mattn/go-sqlite3 v1.14.22"},
+		dockerfiles: make(map[string]string),
+	}
+	dbs := detectEmbeddedDatabases(ctx)
+	if len(dbs) != 1 || dbs[0] != "SQLite" {
+		t.Errorf("should detect SQLite once, got %v", dbs)
+	}
+}
+
+func TestDetectEmbeddedDatabases_PythonAndRails(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{"db.py": "import sqlite3\nimport duckdb\n"},
+		depFiles:       map[string]string{"Gemfile": "gem 'sqlite3', '~> 1.4'\n"},
+		dockerfiles:    make(map[string]string),
+	}
+	dbs := detectEmbeddedDatabases(ctx)
+	want := []string{"DuckDB", "SQLite"}
+	if !reflect.DeepEqual(dbs, want) {
+		t.Errorf("detectEmbeddedDatabases() = %v, want %v", dbs, want)
+	}
+}
+
+func TestDetectEmbeddedDatabases_Badger(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{"store.go": `import badger "github.com/dgraph-io/badger/v4"`},
+		depFiles:       make(map[string]string),
+		dockerfiles:    make(map[string]string),
+	}
+	dbs := detectEmbeddedDatabases(ctx)
+	if len(dbs) != 1 || dbs[0] != "BadgerDB" {
+		t.Errorf("should detect BadgerDB, got %v", dbs)
+	}
+}
+
+func TestDetectEmbeddedDatabases_None(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"main.go": `import (
+	"database/sql"
+	_ "github.com/lib/pq"
+)`,
+		},
+		depFiles:    make(map[string]string),
+		dockerfiles: make(map[string]string),
+	}
+	if dbs := detectEmbeddedDatabases(ctx); len(dbs) != 0 {
+		t.Errorf("postgres-only app should have no embedded databases, got %v", dbs)
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveEmbeddedDatabases(t *testing.T) {
+	ctx := &repoContext{
+		name:        "sqlite-app",
+		branch:      "main",
+		embeddedDBs: []string{"SQLite"},
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	if !strings.Contains(user, "## Detected embedded databases") {
+		t.Error("user prompt should list embedded databases")
+	}
+	if !strings.Contains(user, "Do NOT add a postgres or mysql dependency") {
+		t.Error("user prompt should forbid a spurious server database")
 	}
 }
 
 // ────────────────────────────────────────────────────────────────────────────
-// Inter-service call detection
+// detectTemporal / detectDapr
 // ────────────────────────────────────────────────────────────────────────────
 
-func TestDetectInterServiceCalls_HTTPLocalhost(t *testing.T) {
+func TestDetectTemporal_GoSDK(t *testing.T) {
 	ctx := &repoContext{
 		sourceSnippets: map[string]string{
-			"app.py": `resp = requests.get("http://localhost:8080/api/orders")`,
+			"worker.go": `import "go.temporal.io/sdk/worker"`,
 		},
+		depFiles:    map[string]string{"go.mod": "require go.temporal.io/sdk v1.26.0"},
+		dockerfiles: make(map[string]string),
 	}
-	result := detectInterServiceCalls(ctx)
-	if len(result) == 0 {
-		t.Error("should detect HTTP localhost call")
+	hints := detectTemporal(ctx)
+	if len(hints) != 1 || hints[0] != "Temporal Go SDK" {
+		t.Errorf("should detect Temporal Go SDK, got %v", hints)
 	}
-	found := false
-	for _, r := range result {
-		if strings.Contains(r, "localhost") || strings.Contains(r, "requests.get") {
-			found = true
-		}
+}
+
+func TestDetectTemporal_TypeScriptSDK(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: make(map[string]string),
+		depFiles: map[string]string{
+			"package.json": `{"dependencies":{"@temporalio/client":"^1.9.0","@temporalio/worker":"^1.9.0"}}`,
+		},
+		dockerfiles: make(map[string]string),
 	}
-	if !found {
-		t.Errorf("should detect localhost or requests.get pattern, got %v", result)
+	hints := detectTemporal(ctx)
+	if len(hints) != 1 || hints[0] != "Temporal TypeScript SDK" {
+		t.Errorf("should detect only the TypeScript SDK, got %v", hints)
 	}
 }
 
-func TestDetectInterServiceCalls_GoHTTP(t *testing.T) {
+func TestDetectTemporal_PythonSDK(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: make(map[string]string),
+		depFiles:       map[string]string{"requirements.txt": "temporalio==1.5.0\n"},
+		dockerfiles:    make(map[string]string),
+	}
+	hints := detectTemporal(ctx)
+	if len(hints) != 1 || hints[0] != "Temporal Python SDK" {
+		t.Errorf("should detect Temporal Python SDK, got %v", hints)
+	}
+}
+
+func TestDetectTemporal_None(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{"app.py": "import flask\n"},
+		depFiles:       make(map[string]string),
+		dockerfiles:    make(map[string]string),
+	}
+	if hints := detectTemporal(ctx); len(hints) != 0 {
+		t.Errorf("should detect no Temporal usage, got %v", hints)
+	}
+}
+
+func TestDetectDapr_Annotations(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: make(map[string]string),
+		depFiles:       make(map[string]string),
+		dockerfiles:    make(map[string]string),
+		composeFile: `services:
+  api:
+    labels:
+      dapr.io/app-id: api`,
+	}
+	hints := detectDapr(ctx)
+	if len(hints) != 1 || hints[0] != "dapr.io annotations" {
+		t.Errorf("should detect dapr.io annotations, got %v", hints)
+	}
+}
+
+func TestDetectDapr_ComponentsDir(t *testing.T) {
+	ctx := &repoContext{
+		tree:           "components/pubsub.yaml\ncomponents/statestore.yaml\nmain.go\n",
+		sourceSnippets: map[string]string{"main.go": `import dapr "github.com/dapr/go-sdk/client"`},
+		depFiles:       make(map[string]string),
+		dockerfiles:    make(map[string]string),
+	}
+	hints := detectDapr(ctx)
+	want := []string{"Dapr Go SDK", "Dapr components directory: components"}
+	if !reflect.DeepEqual(hints, want) {
+		t.Errorf("detectDapr() = %v, want %v", hints, want)
+	}
+}
+
+func TestDetectDapr_None(t *testing.T) {
+	ctx := &repoContext{
+		tree:           "src/components/Button.tsx\n",
+		sourceSnippets: make(map[string]string),
+		depFiles:       make(map[string]string),
+		dockerfiles:    make(map[string]string),
+	}
+	if hints := detectDapr(ctx); len(hints) != 0 {
+		t.Errorf("should detect no Dapr usage, got %v", hints)
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveTemporalAndDapr(t *testing.T) {
+	ctx := &repoContext{
+		name:          "wf-app",
+		branch:        "main",
+		temporalHints: []string{"Temporal Go SDK"},
+		daprHints:     []string{"dapr.io annotations"},
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	if !strings.Contains(user, "## Detected Temporal workflows") {
+		t.Error("user prompt should include the Temporal section")
+	}
+	if !strings.Contains(user, "no `temporal` dependency type yet") {
+		t.Error("Temporal directive should say the dependency type doesn't exist")
+	}
+	if !strings.Contains(user, "## Detected Dapr usage") {
+		t.Error("user prompt should include the Dapr section")
+	}
+	if !strings.Contains(user, "does not support Dapr sidecar injection") {
+		t.Error("Dapr directive should say sidecar injection isn't supported")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// detectWebSockets
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestDetectWebSockets(t *testing.T) {
 	ctx := &repoContext{
 		sourceSnippets: map[string]string{
-			"main.go": `resp, err := http.Get("http://inventory:3000/items")`,
+			"hub.go":    `import "github.com/gorilla/websocket"`,
+			"server.js": `const { WebSocketServer } = require('ws');`,
+		},
+		depFiles: map[string]string{
+			"package.json":     `{"dependencies":{"socket.io":"^4.7.0","ws":"^8.16.0"}}`,
+			"requirements.txt": "websockets==12.0\n",
 		},
+		dockerfiles: make(map[string]string),
 	}
-	result := detectInterServiceCalls(ctx)
-	found := false
-	for _, r := range result {
-		if strings.Contains(r, "http.Get") {
-			found = true
-		}
+	got := detectWebSockets(ctx)
+	want := []string{"Socket.IO", "gorilla/websocket (Go)", "websockets (Python)", "ws (Node.js)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("detectWebSockets() = %v, want %v", got, want)
 	}
-	if !found {
-		t.Errorf("should detect Go http.Get, got %v", result)
+}
+
+func TestDetectWebSockets_RailsAndPhoenix(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"app/channels/chat_channel.rb": "class ChatChannel < ApplicationCable::Channel\nend\n# ActionCable.server.broadcast",
+			"lib/app_web/user_socket.ex":   "defmodule AppWeb.UserSocket do\n  use Phoenix.Socket\nend",
+		},
+		depFiles:    make(map[string]string),
+		dockerfiles: make(map[string]string),
+	}
+	got := detectWebSockets(ctx)
+	want := []string{"Phoenix channels", "Rails ActionCable"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("detectWebSockets() = %v, want %v", got, want)
 	}
 }
 
-func TestDetectInterServiceCalls_GRPCDial(t *testing.T) {
+func TestDetectWebSockets_None(t *testing.T) {
 	ctx := &repoContext{
 		sourceSnippets: map[string]string{
-			"client.go": `conn, err := grpc.Dial("orders:50051", grpc.WithInsecure())`,
+			"README.md": "Talks to clients over websockets.",
+			"app.js":    `const jwt = require("jsonwebtoken");`,
 		},
+		depFiles:    map[string]string{"package.json": `{"dependencies":{"express":"^4.18.0"}}`},
+		dockerfiles: make(map[string]string),
 	}
-	result := detectInterServiceCalls(ctx)
-	found := false
-	for _, r := range result {
-		if strings.Contains(r, "gRPC") {
-			found = true
+	if got := detectWebSockets(ctx); len(got) != 0 {
+		t.Errorf("should detect no WebSocket libraries, got %v", got)
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveWebSockets(t *testing.T) {
+	ctx := &repoContext{
+		name:       "chat",
+		branch:     "main",
+		webSockets: []string{"Socket.IO"},
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	for _, want := range []string{
+		"## Detected WebSocket endpoints",
+		"- Socket.IO",
+		"`ingress-annotations`",
+		`nginx.ingress.kubernetes.io/proxy-read-timeout: "3600"`,
+		`nginx.ingress.kubernetes.io/proxy-send-timeout: "3600"`,
+		"NEVER the WebSocket route",
+	} {
+		if !strings.Contains(user, want) {
+			t.Errorf("user prompt missing %q", want)
 		}
 	}
-	if !found {
-		t.Errorf("should detect gRPC dial, got %v", result)
+
+	ctx.webSockets = nil
+	_, user = buildGeneratePrompt(ctx, ci.Default())
+	if strings.Contains(user, "## Detected WebSocket endpoints") {
+		t.Error("WebSocket section should be omitted when nothing was detected")
 	}
 }
 
-func TestDetectInterServiceCalls_PythonGRPC(t *testing.T) {
+// ────────────────────────────────────────────────────────────────────────────
+// detectGPUWorkloads
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestDetectGPUWorkloads(t *testing.T) {
 	ctx := &repoContext{
 		sourceSnippets: map[string]string{
-			"client.py": `channel = grpc.insecure_channel("mcp-server:50051")`,
+			"model.py":  "import torch\nfrom transformers import AutoModel\n",
+			"infer.js":  `const tf = require("@tensorflow/tfjs-node-gpu");`,
+			"README.md": "Uses tensorflow under the hood.",
 		},
+		depFiles: map[string]string{
+			"requirements.txt": "onnxruntime-gpu==1.18.0\ntensorflow==2.16.1\n",
+		},
+		dockerfiles: make(map[string]string),
 	}
-	result := detectInterServiceCalls(ctx)
-	found := false
-	for _, r := range result {
-		if strings.Contains(r, "gRPC") {
-			found = true
+	got := detectGPUWorkloads(ctx)
+	want := []string{"Hugging Face Transformers", "ONNX Runtime (GPU)", "PyTorch", "TensorFlow", "TensorFlow.js (GPU)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("detectGPUWorkloads() = %v, want %v", got, want)
+	}
+}
+
+func TestDetectGPUWorkloads_None(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"README.md": "No torch, tensorflow, or transformers here.",
+			"app.py":    "import onnxruntime\n",
+		},
+		depFiles:    map[string]string{"requirements.txt": "flask==3.0.0\nonnxruntime==1.18.0\n"},
+		dockerfiles: make(map[string]string),
+	}
+	if got := detectGPUWorkloads(ctx); len(got) != 0 {
+		t.Errorf("should detect no GPU workloads, got %v", got)
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveGPUWorkloads(t *testing.T) {
+	ctx := &repoContext{
+		name:         "ml-api",
+		branch:       "main",
+		gpuWorkloads: []string{"PyTorch"},
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	for _, want := range []string{
+		"## Detected ML / GPU workloads",
+		"- PyTorch",
+		"Do NOT request a GPU",
+		"#     nvidia.com/gpu: 1",
+		"memoryRequest: 2Gi",
+	} {
+		if !strings.Contains(user, want) {
+			t.Errorf("user prompt missing %q", want)
 		}
 	}
-	if !found {
-		t.Errorf("should detect Python gRPC channel, got %v", result)
+
+	ctx.gpuWorkloads = nil
+	_, user = buildGeneratePrompt(ctx, ci.Default())
+	if strings.Contains(user, "## Detected ML / GPU workloads") {
+		t.Error("GPU section should be omitted when nothing was detected")
 	}
 }
 
-func TestDetectInterServiceCalls_ServiceEnvVar(t *testing.T) {
+// ────────────────────────────────────────────────────────────────────────────
+// detectHealthEndpoint
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestDetectHealthEndpoint(t *testing.T) {
+	tests := []struct {
+		name      string
+		snippets  map[string]string
+		depFiles  map[string]string
+		wantPath  string
+		wantFound bool
+	}{
+		{
+			name:      "source route",
+			snippets:  map[string]string{"main.go": `mux.HandleFunc("/health", healthHandler)`},
+			wantPath:  "/health",
+			wantFound: true,
+		},
+		{
+			name: "readiness route preferred",
+			snippets: map[string]string{
+				"app.py": "@app.get('/healthz')\ndef live(): ...\n@app.get('/ready')\ndef ready(): ...",
+			},
+			wantPath:  "/ready",
+			wantFound: true,
+		},
+		{
+			name:      "spring actuator default",
+			depFiles:  map[string]string{"pom.xml": "<artifactId>spring-boot-starter-actuator</artifactId>"},
+			wantPath:  "/actuator/health",
+			wantFound: true,
+		},
+		{
+			name:      "rails health controller",
+			snippets:  map[string]string{"config/routes.rb": `get "up" => "rails/health#show", as: :rails_health_check`},
+			wantPath:  "/up",
+			wantFound: true,
+		},
+		{
+			name:     "none",
+			snippets: map[string]string{"server.js": `app.get("/api/orders", list)`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &repoContext{
+				sourceSnippets: tt.snippets,
+				depFiles:       tt.depFiles,
+				dockerfiles:    make(map[string]string),
+			}
+			path, found := detectHealthEndpoint(ctx)
+			if path != tt.wantPath || found != tt.wantFound {
+				t.Errorf("detectHealthEndpoint() = (%q, %v), want (%q, %v)", path, found, tt.wantPath, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveHealthEndpoint(t *testing.T) {
+	ctx := &repoContext{name: "api", branch: "main", healthEndpoint: "/actuator/health"}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+	if !strings.Contains(user, "## Detected health check endpoint") {
+		t.Error("user prompt should list the detected health endpoint")
+	}
+	if !strings.Contains(user, `Set `+"`"+`health-check-path: "/actuator/health"`+"`") {
+		t.Error("directive should set health-check-path to the detected endpoint")
+	}
+
+	ctx.healthEndpoint = ""
+	_, user = buildGeneratePrompt(ctx, ci.Default())
+	if !strings.Contains(user, "No health route was found") {
+		t.Error("user prompt should say no health route was found")
+	}
+	if !strings.Contains(user, `Set `+"`"+`health-check-path: "/"`+"`") {
+		t.Error("directive should fall back to probing /")
+	}
+	if !strings.Contains(user, "# HEALTH: no health route found") {
+		t.Error("directive should ask for a YAML note explaining the / probe")
+	}
+}
+
+func TestMergeAllContent(t *testing.T) {
 	ctx := &repoContext{
+		dockerfiles: map[string]string{"Dockerfile": "FROM node:18"},
+		depFiles:    map[string]string{"package.json": `{"name":"app"}`},
 		sourceSnippets: map[string]string{
-			"config.py": `ORDERS_SERVICE_URL = os.getenv("ORDERS_SERVICE_URL")`,
+			"app.js": "console.log('hello')",
 		},
+		composeFile: "services:\n  web:\n    build: .",
 	}
-	result := detectInterServiceCalls(ctx)
-	found := false
-	for _, r := range result {
-		if strings.Contains(r, "Service URL") || strings.Contains(r, "Endpoint") {
-			found = true
-		}
+	merged := mergeAllContent(ctx)
+	if len(merged) != 4 {
+		t.Errorf("expected 4 entries, got %d", len(merged))
 	}
-	if !found {
-		t.Errorf("should detect service URL env var, got %v", result)
+	if merged["Dockerfile"] != "FROM node:18" {
+		t.Error("should include Dockerfile content")
+	}
+	if merged["docker-compose.yml"] != ctx.composeFile {
+		t.Error("should include compose file")
 	}
 }
 
-func TestDetectInterServiceCalls_ComposeDepends(t *testing.T) {
+func TestMergeAllContent_NoCompose(t *testing.T) {
 	ctx := &repoContext{
+		dockerfiles:    map[string]string{"Dockerfile": "FROM go:1.21"},
+		depFiles:       make(map[string]string),
 		sourceSnippets: make(map[string]string),
-		composeFile: `services:
-  api:
-    depends_on:
-      - orders
-      - inventory`,
 	}
-	result := detectInterServiceCalls(ctx)
-	found := false
-	for _, r := range result {
-		if strings.Contains(r, "depends_on") {
-			found = true
-		}
+	merged := mergeAllContent(ctx)
+	if _, ok := merged["docker-compose.yml"]; ok {
+		t.Error("should not include compose key when no compose file")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// buildGeneratePrompt with multi-agent context
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestBuildGeneratePrompt_WithAgentFrameworks(t *testing.T) {
+	ctx := &repoContext{
+		name:            "agent-app",
+		branch:          "main",
+		tree:            "main.py\nDockerfile\n",
+		agentFrameworks: []string{"CrewAI", "LangChain"},
+		vectorStores:    []string{"ChromaDB", "pgvector"},
+		mcpServers:      []string{"MCP config file: mcp.json"},
+		workerProcesses: []string{"Celery worker"},
+		dockerfiles:     make(map[string]string),
+		depFiles:        make(map[string]string),
+		sourceSnippets:  make(map[string]string),
+	}
+
+	system, user := buildGeneratePrompt(ctx, ci.Default())
+
+	// System prompt should contain multi-agent guidance
+	if !strings.Contains(system, "Multi-agent architecture") {
+		t.Error("system prompt should contain multi-agent architecture guidance")
+	}
+	if !strings.Contains(system, "MCP") {
+		t.Error("system prompt should mention MCP")
+	}
+
+	// User prompt should contain all detected patterns
+	if !strings.Contains(user, "multi-agent architecture") {
+		t.Error("user prompt should contain multi-agent section")
+	}
+	if !strings.Contains(user, "CrewAI") {
+		t.Error("user prompt should list CrewAI")
+	}
+	if !strings.Contains(user, "LangChain") {
+		t.Error("user prompt should list LangChain")
+	}
+	if !strings.Contains(user, "ChromaDB") {
+		t.Error("user prompt should list ChromaDB")
+	}
+	if !strings.Contains(user, "pgvector") {
+		t.Error("user prompt should list pgvector")
+	}
+	if !strings.Contains(user, "mcp.json") {
+		t.Error("user prompt should list MCP config")
+	}
+	if !strings.Contains(user, "Celery") {
+		t.Error("user prompt should list Celery worker")
+	}
+}
+
+func TestBuildGeneratePrompt_NoAgentArch(t *testing.T) {
+	ctx := &repoContext{
+		name:           "plain-app",
+		branch:         "main",
+		tree:           "main.go\n",
+		dockerfiles:    make(map[string]string),
+		depFiles:       make(map[string]string),
+		sourceSnippets: make(map[string]string),
+	}
+
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	if strings.Contains(user, "multi-agent architecture") {
+		t.Error("user prompt should NOT contain multi-agent section for plain apps")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// scanRepo integration: multi-agent detection
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestScanRepo_DetectsAgentFrameworks(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "main.py"), []byte(`from crewai import Agent, Task, Crew
+from langchain.llms import OpenAI`), 0644)
+	os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("crewai\nlangchain"), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+
+	if !contains(ctx.agentFrameworks, "CrewAI") {
+		t.Errorf("should detect CrewAI, got %v", ctx.agentFrameworks)
+	}
+}
+
+func TestScanRepo_DetectsMCPServer(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "mcp.json"), []byte(`{"servers":{"search":{}}}`), 0644)
+	os.WriteFile(filepath.Join(dir, "server.py"), []byte(`from mcp.server import FastMCP
+app = FastMCP("search")
+@app.tool()
+def search(q: str): pass`), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+
+	if len(ctx.mcpServers) == 0 {
+		t.Error("should detect MCP servers")
+	}
+}
+
+func TestScanRepo_DetectsVectorStores(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "main.py"), []byte(`import chromadb
+client = chromadb.Client()`), 0644)
+	os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("chromadb"), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+
+	if !contains(ctx.vectorStores, "ChromaDB") {
+		t.Errorf("should detect ChromaDB, got %v", ctx.vectorStores)
+	}
+}
+
+func TestScanRepo_DetectsWorkerProcesses(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "tasks.py"), []byte(`from celery import Celery
+app = celery.Celery('myapp')
+@app.task
+def add(x, y): return x + y`), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+
+	if len(ctx.workerProcesses) == 0 {
+		t.Error("should detect Celery worker processes")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// scanDepFiles includes MCP config files
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestScanDepFiles_IncludesMCPConfig(t *testing.T) {
+	if !scanDepFiles["mcp.json"] {
+		t.Error("scanDepFiles should include mcp.json")
+	}
+	if !scanDepFiles["mcp.config.json"] {
+		t.Error("scanDepFiles should include mcp.config.json")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// scanDepFiles includes Procfile
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestScanDepFiles_IncludesProcfile(t *testing.T) {
+	if !scanDepFiles["Procfile"] {
+		t.Error("scanDepFiles should include Procfile")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Inter-service call detection
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestDetectInterServiceCalls_HTTPLocalhost(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"app.py": `resp = requests.get("http://localhost:8080/api/orders")`,
+		},
+	}
+	result := detectInterServiceCalls(ctx)
+	if len(result) == 0 {
+		t.Error("should detect HTTP localhost call")
+	}
+	found := false
+	for _, r := range result {
+		if strings.Contains(r, "localhost") || strings.Contains(r, "requests.get") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("should detect localhost or requests.get pattern, got %v", result)
+	}
+}
+
+func TestDetectInterServiceCalls_GoHTTP(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"main.go": `resp, err := http.Get("http://inventory:3000/items")`,
+		},
+	}
+	result := detectInterServiceCalls(ctx)
+	found := false
+	for _, r := range result {
+		if strings.Contains(r, "http.Get") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("should detect Go http.Get, got %v", result)
+	}
+}
+
+func TestDetectInterServiceCalls_GRPCDial(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"client.go": `conn, err := grpc.Dial("orders:50051", grpc.WithInsecure())`,
+		},
+	}
+	result := detectInterServiceCalls(ctx)
+	found := false
+	for _, r := range result {
+		if strings.Contains(r, "gRPC") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("should detect gRPC dial, got %v", result)
+	}
+}
+
+func TestDetectInterServiceCalls_PythonGRPC(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"client.py": `channel = grpc.insecure_channel("mcp-server:50051")`,
+		},
+	}
+	result := detectInterServiceCalls(ctx)
+	found := false
+	for _, r := range result {
+		if strings.Contains(r, "gRPC") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("should detect Python gRPC channel, got %v", result)
+	}
+}
+
+func TestDetectInterServiceCalls_ServiceEnvVar(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"config.py": `ORDERS_SERVICE_URL = os.getenv("ORDERS_SERVICE_URL")`,
+		},
+	}
+	result := detectInterServiceCalls(ctx)
+	found := false
+	for _, r := range result {
+		if strings.Contains(r, "Service URL") || strings.Contains(r, "Endpoint") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("should detect service URL env var, got %v", result)
+	}
+}
+
+func TestDetectInterServiceCalls_ComposeDepends(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: make(map[string]string),
+		composeFile: `services:
+  api:
+    depends_on:
+      - orders
+      - inventory`,
+	}
+	result := detectInterServiceCalls(ctx)
+	found := false
+	for _, r := range result {
+		if strings.Contains(r, "depends_on") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("should detect compose depends_on, got %v", result)
+	}
+}
+
+func TestDetectInterServiceCalls_None(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"main.go": `func main() { fmt.Println("hello") }`,
+		},
+	}
+	result := detectInterServiceCalls(ctx)
+	if len(result) != 0 {
+		t.Errorf("should detect no inter-service calls, got %v", result)
+	}
+}
+
+func TestDetectInterServiceCalls_FetchJS(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"api.ts": `const res = await fetch("http://mcp-server:8080/tools")`,
+		},
+	}
+	result := detectInterServiceCalls(ctx)
+	found := false
+	for _, r := range result {
+		if strings.Contains(r, "fetch") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("should detect JS fetch, got %v", result)
+	}
+}
+
+func TestDetectInterServiceCalls_AxiosPost(t *testing.T) {
+	ctx := &repoContext{
+		sourceSnippets: map[string]string{
+			"service.ts": `await axios.post("http://worker:3001/enqueue", data)`,
+		},
+	}
+	result := detectInterServiceCalls(ctx)
+	found := false
+	for _, r := range result {
+		if strings.Contains(r, "Axios") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("should detect Axios POST, got %v", result)
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Vector store API key credential detection
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestCredentialExactNames_VectorStoreKeys(t *testing.T) {
+	keys := []string{
+		"PINECONE_API_KEY", "WEAVIATE_API_KEY", "QDRANT_API_KEY",
+		"MILVUS_API_KEY", "COHERE_API_KEY", "HUGGINGFACE_API_KEY",
+		"HF_TOKEN", "GROQ_API_KEY", "MISTRAL_API_KEY",
+		"TOGETHER_API_KEY", "REPLICATE_API_TOKEN",
+	}
+	for _, k := range keys {
+		if !credentialExactNames[k] {
+			t.Errorf("credentialExactNames should include %s", k)
+		}
+	}
+}
+
+func TestIsExternalCredential_VectorStoreKeys(t *testing.T) {
+	keys := []string{
+		"PINECONE_API_KEY", "WEAVIATE_API_KEY", "QDRANT_API_KEY",
+		"COHERE_API_KEY", "GROQ_API_KEY",
+	}
+	for _, k := range keys {
+		if !isExternalCredential(k) {
+			t.Errorf("isExternalCredential(%q) should return true", k)
+		}
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Directive prompt output
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestBuildGeneratePrompt_DirectiveMCP(t *testing.T) {
+	ctx := &repoContext{
+		name:           "mcp-app",
+		branch:         "main",
+		tree:           "server.py\nDockerfile\nmcp.json\n",
+		mcpServers:     []string{"MCP config file: mcp.json", "MCP tool decorator (@mcp.tool)"},
+		dockerfiles:    make(map[string]string),
+		depFiles:       make(map[string]string),
+		sourceSnippets: make(map[string]string),
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	if !strings.Contains(user, "DIRECTIVE") {
+		t.Error("user prompt should contain DIRECTIVE for MCP servers")
+	}
+	if !strings.Contains(user, "separate build+deploy") {
+		t.Error("user prompt should instruct separate build+deploy for MCP servers")
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveWorkers(t *testing.T) {
+	ctx := &repoContext{
+		name:            "celery-app",
+		branch:          "main",
+		tree:            "app.py\nDockerfile\n",
+		workerProcesses: []string{"Celery worker (celery -A)"},
+		dockerfiles:     make(map[string]string),
+		depFiles:        make(map[string]string),
+		sourceSnippets:  make(map[string]string),
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	if !strings.Contains(user, "DIRECTIVE") {
+		t.Error("user prompt should contain DIRECTIVE for workers")
+	}
+	if !strings.Contains(user, "separate deploy step") {
+		t.Error("user prompt should instruct separate deploy for workers")
+	}
+	if !strings.Contains(user, "broker dependency") {
+		t.Error("user prompt should mention broker dependency wiring")
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveVectorStores(t *testing.T) {
+	ctx := &repoContext{
+		name:           "rag-app",
+		branch:         "main",
+		tree:           "main.py\nDockerfile\n",
+		vectorStores:   []string{"Pinecone", "pgvector"},
+		dockerfiles:    make(map[string]string),
+		depFiles:       make(map[string]string),
+		sourceSnippets: make(map[string]string),
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	if !strings.Contains(user, "DIRECTIVE") {
+		t.Error("user prompt should contain DIRECTIVE for vector stores")
+	}
+	if !strings.Contains(user, "do NOT auto-add local dependencies") {
+		t.Error("user prompt should instruct not to auto-add local deps")
+	}
+	if !strings.Contains(user, "secretKeyRef") {
+		t.Error("user prompt should mention secretKeyRef for API keys")
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveLocalVectorStores(t *testing.T) {
+	ctx := &repoContext{
+		name:           "rag-app",
+		branch:         "main",
+		tree:           "main.py\nDockerfile\n",
+		vectorStores:   []string{"Pinecone", "Qdrant", "Weaviate"},
+		dockerfiles:    make(map[string]string),
+		depFiles:       make(map[string]string),
+		sourceSnippets: make(map[string]string),
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	for _, want := range []string{"Qdrant → `qdrant`", "Weaviate → `weaviate`", "QDRANT_URL"} {
+		if !strings.Contains(user, want) {
+			t.Errorf("user prompt should contain %q", want)
+		}
+	}
+	if !strings.Contains(user, "Pinecone: default to respecting external services") {
+		t.Error("hosted-only vector stores should still be treated as external")
+	}
+	if strings.Contains(user, "Pinecone → ") {
+		t.Error("Pinecone has no local dependency type")
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveInterService(t *testing.T) {
+	ctx := &repoContext{
+		name:              "multi-svc",
+		branch:            "main",
+		tree:              "api/main.go\nworker/main.go\n",
+		interServiceCalls: []string{"Go http.Get (potential inter-service call)"},
+		dockerfiles:       make(map[string]string),
+		depFiles:          make(map[string]string),
+		sourceSnippets:    make(map[string]string),
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	if !strings.Contains(user, "DIRECTIVE") {
+		t.Error("user prompt should contain DIRECTIVE for inter-service calls")
+	}
+	if !strings.Contains(user, "Kubernetes DNS") {
+		t.Error("user prompt should mention Kubernetes DNS for inter-service calls")
+	}
+}
+
+func TestBuildGeneratePrompt_DirectiveGRPCInterService(t *testing.T) {
+	newCtx := func(calls ...string) *repoContext {
+		return &repoContext{
+			name:              "multi-svc",
+			branch:            "main",
+			tree:              "api/main.go\norders/main.go\n",
+			interServiceCalls: calls,
+			dockerfiles:       make(map[string]string),
+			depFiles:          make(map[string]string),
+			sourceSnippets:    make(map[string]string),
+		}
+	}
+
+	_, user := buildGeneratePrompt(newCtx("Go gRPC dial (inter-service)", "Go http.Get (potential inter-service call)"), ci.Default())
+	if !strings.Contains(user, "### gRPC calls detected") {
+		t.Error("user prompt should list detected gRPC calls")
+	}
+	if !strings.Contains(user, `health-check-type: "grpc"`) {
+		t.Error("gRPC DIRECTIVE should set the target's health-check-type to grpc")
+	}
+	if !strings.Contains(user, "NO http:// or https:// scheme") {
+		t.Error("gRPC DIRECTIVE should forbid a URL scheme on the address")
+	}
+
+	_, user = buildGeneratePrompt(newCtx("Go http.Get (potential inter-service call)"), ci.Default())
+	if strings.Contains(user, "gRPC calls detected") || strings.Contains(user, `health-check-type: "grpc"`) {
+		t.Error("gRPC DIRECTIVE should not appear without a gRPC dial")
+	}
+}
+
+func TestDetectInterServiceCalls_GRPCCategory(t *testing.T) {
+	ctx := &repoContext{sourceSnippets: map[string]string{
+		"client/Program.cs": `var channel = GrpcChannel.ForAddress(Environment.GetEnvironmentVariable("ORDERS_ADDR"));`,
+		"web/app.py":        `requests.get(os.environ["CATALOG_SERVICE_URL"])`,
+	}}
+	calls := detectInterServiceCalls(ctx)
+	grpc := grpcInterServiceCalls(calls)
+	if len(grpc) != 1 || grpc[0] != "C# gRPC channel (inter-service)" {
+		t.Errorf("grpcInterServiceCalls(%v) = %v", calls, grpc)
+	}
+}
+
+func TestBuildGeneratePrompt_NoInterServiceWithoutDetection(t *testing.T) {
+	ctx := &repoContext{
+		name:           "simple-app",
+		branch:         "main",
+		tree:           "main.go\n",
+		dockerfiles:    make(map[string]string),
+		depFiles:       make(map[string]string),
+		sourceSnippets: make(map[string]string),
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	if strings.Contains(user, "Inter-service communication") {
+		t.Error("user prompt should NOT contain inter-service section when nothing detected")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// scanRepo integration: inter-service calls
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestScanRepo_DetectsInterServiceCalls(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
+import "net/http"
+func main() {
+	resp, _ := http.Get("http://orders:3000/api")
+	_ = resp
+}`), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+
+	if len(ctx.interServiceCalls) == 0 {
+		t.Error("should detect inter-service HTTP call")
+	}
+}
+
+func TestScanRepo_DetectsProcfile(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "Procfile"), []byte(`web: gunicorn app:app
+worker: celery -A app worker
+`), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+
+	if _, ok := ctx.depFiles["Procfile"]; !ok {
+		t.Error("scanRepo should collect Procfile as a dependency manifest")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// .dockerignore matching
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestDockerignore_Excludes(t *testing.T) {
+	rules := parseDockerignore(`# assets are served from a CDN
+assets
+*.log
+docs/**/*.pdf
+/fixtures
+**/testdata
+!assets/keep.css
+`)
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"assets", true},
+		{"assets/img/logo.png", true},
+		{"assets/keep.css", false},
+		{"server.log", true},
+		{"logs/server.log", false}, // "*" does not cross directories
+		{"docs/guide.pdf", true},
+		{"docs/a/b/guide.pdf", true},
+		{"docs/guide.md", false},
+		{"fixtures/users.json", true},
+		{"pkg/api/testdata/x.json", true},
+		{"main.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := rules.excludes(tt.path); got != tt.want {
+				t.Errorf("excludes(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerignore_Empty(t *testing.T) {
+	var rules dockerignore
+	if rules.excludes("anything/at/all") {
+		t.Error("empty rule set should exclude nothing")
+	}
+	if len(parseDockerignore("# only comments\n\n")) != 0 {
+		t.Error("comments and blank lines should produce no rules")
+	}
+}
+
+func TestScanRepo_RespectsDockerignore(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("static\nDockerfile\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM node:20\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "server.js"), []byte("require('http')"), 0644)
+	os.MkdirAll(filepath.Join(dir, "static", "vendor"), 0755)
+	os.WriteFile(filepath.Join(dir, "static", "vendor", "huge.js"), []byte("var x = 1;"), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+
+	if strings.Contains(ctx.tree, "static") {
+		t.Errorf("tree should not contain .dockerignored paths:\n%s", ctx.tree)
+	}
+	if !strings.Contains(ctx.tree, "server.js") {
+		t.Error("tree should still contain non-ignored files")
+	}
+	if ctx.dockerfileCount != 1 {
+		t.Errorf("Dockerfile should be collected even when .dockerignored, got %d", ctx.dockerfileCount)
+	}
+}
+
+func TestScanRepo_DockerignoreKeepsComposeAndManifests(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("docker-compose*.yml\n*.txt\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM python:3.12\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services:\n  api:\n    ports:\n      - \"8000:8000\"\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("flask\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("scratch"), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+	if ctx.composeFile == "" || ctx.composePorts["api"] != 8000 {
+		t.Errorf("ignored compose file should still be read, got ports %v", ctx.composePorts)
+	}
+	if _, ok := ctx.depFiles["requirements.txt"]; !ok {
+		t.Error("ignored dependency manifest should still be read")
+	}
+	if strings.Contains(ctx.tree, "notes.txt") || strings.Contains(ctx.tree, "docker-compose.yml") {
+		t.Errorf("tree should still leave out .dockerignored files:\n%s", ctx.tree)
+	}
+}
+
+func TestScanRepo_DockerfileInIgnoredDir(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("deploy\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "deploy", "worker"), 0755)
+	os.WriteFile(filepath.Join(dir, "deploy", "worker", "Dockerfile"), []byte("FROM golang:1.22\nEXPOSE 9090\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "deploy", "worker", "main.go"), []byte("package main"), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+	if _, ok := ctx.dockerfiles[filepath.Join("deploy", "worker", "Dockerfile")]; !ok {
+		t.Errorf("Dockerfile under an ignored directory should be collected, got %v", ctx.dockerfiles)
 	}
-	if !found {
-		t.Errorf("should detect compose depends_on, got %v", result)
+	if _, ok := ctx.sourceSnippets[filepath.Join("deploy", "worker", "main.go")]; ok {
+		t.Error("source under an ignored directory should not be sampled")
 	}
 }
 
-func TestDetectInterServiceCalls_None(t *testing.T) {
-	ctx := &repoContext{
-		sourceSnippets: map[string]string{
-			"main.go": `func main() { fmt.Println("hello") }`,
-		},
+// ────────────────────────────────────────────────────────────────────────────
+// Context budget
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestEstimateTokens(t *testing.T) {
+	if got := estimateTokens(""); got != 0 {
+		t.Errorf("estimateTokens(\"\") = %d, want 0", got)
 	}
-	result := detectInterServiceCalls(ctx)
-	if len(result) != 0 {
-		t.Errorf("should detect no inter-service calls, got %v", result)
+	if got := estimateTokens(strings.Repeat("a", 400)); got != 100 {
+		t.Errorf("estimateTokens(400 bytes) = %d, want 100", got)
 	}
 }
 
-func TestDetectInterServiceCalls_FetchJS(t *testing.T) {
-	ctx := &repoContext{
-		sourceSnippets: map[string]string{
-			"api.ts": `const res = await fetch("http://mcp-server:8080/tools")`,
-		},
+func TestDefaultMaxContextTokens(t *testing.T) {
+	if got := defaultMaxContextTokens("ollama", []string{"qwen2.5-coder:14b"}); got != 24000 {
+		t.Errorf("ollama budget = %d, want 24000", got)
 	}
-	result := detectInterServiceCalls(ctx)
-	found := false
-	for _, r := range result {
-		if strings.Contains(r, "fetch") {
-			found = true
-		}
+	// A fallback chain uses the smallest budget
+	if got := defaultMaxContextTokens("openai", []string{"o3", "gpt-4o"}); got != 100000 {
+		t.Errorf("o3,gpt-4o budget = %d, want 100000", got)
 	}
-	if !found {
-		t.Errorf("should detect JS fetch, got %v", result)
+	if got := defaultMaxContextTokens("openai", nil); got != 100000 {
+		t.Errorf("empty chain budget = %d, want 100000", got)
 	}
 }
 
-func TestDetectInterServiceCalls_AxiosPost(t *testing.T) {
-	ctx := &repoContext{
+func budgetTestContext() *repoContext {
+	return &repoContext{
+		name:        "big-repo",
+		branch:      "main",
+		dockerfiles: map[string]string{"Dockerfile": "FROM golang:1.22\nCOPY . .\nRUN go build"},
+		composeFile: "services:\n  web:\n    build: .",
+		depFiles: map[string]string{
+			"go.mod":       "module big\n" + strings.Repeat("require example.com/dep v1.0.0\n", 400),
+			"package.json": `{"name":"ui"}`,
+		},
 		sourceSnippets: map[string]string{
-			"service.ts": `await axios.post("http://worker:3001/enqueue", data)`,
+			"main.go":   "package main\n" + strings.Repeat("// entry\n", 400),
+			"server.go": "package main\n" + strings.Repeat("// server\n", 400),
+			"util.go":   "package main\n" + strings.Repeat("// util\n", 400),
 		},
+		sourceOrder: []string{"main.go", "server.go", "util.go"},
 	}
-	result := detectInterServiceCalls(ctx)
-	found := false
-	for _, r := range result {
-		if strings.Contains(r, "Axios") {
-			found = true
-		}
+}
+
+func TestTrimContextToBudget_FitsWithoutDropping(t *testing.T) {
+	ctx := budgetTestContext()
+	if dropped, _ := trimContextToBudget(ctx, ci.Default(), nil, 10000000); len(dropped) != 0 {
+		t.Errorf("nothing should be dropped under a huge budget, got %v", dropped)
 	}
-	if !found {
-		t.Errorf("should detect Axios POST, got %v", result)
+	if len(ctx.sourceSnippets) != 3 {
+		t.Errorf("sourceSnippets should be untouched, got %d", len(ctx.sourceSnippets))
 	}
 }
 
-// ────────────────────────────────────────────────────────────────────────────
-// Vector store API key credential detection
-// ────────────────────────────────────────────────────────────────────────────
+func TestTrimContextToBudget_DropsLowestPriorityFirst(t *testing.T) {
+	ctx := budgetTestContext()
+	system, user := buildGeneratePrompt(ctx, ci.Default())
+	full := estimateTokens(system) + estimateTokens(user)
 
-func TestCredentialExactNames_VectorStoreKeys(t *testing.T) {
-	keys := []string{
-		"PINECONE_API_KEY", "WEAVIATE_API_KEY", "QDRANT_API_KEY",
-		"MILVUS_API_KEY", "COHERE_API_KEY", "HUGGINGFACE_API_KEY",
-		"HF_TOKEN", "GROQ_API_KEY", "MISTRAL_API_KEY",
-		"TOGETHER_API_KEY", "REPLICATE_API_TOKEN",
+	// Budget that requires dropping exactly one snippet
+	dropped, _ := trimContextToBudget(ctx, ci.Default(), nil, full-100)
+	if len(dropped) != 1 || dropped[0] != "source file util.go" {
+		t.Fatalf("dropped = %v, want only util.go", dropped)
 	}
-	for _, k := range keys {
-		if !credentialExactNames[k] {
-			t.Errorf("credentialExactNames should include %s", k)
-		}
+	if _, ok := ctx.sourceSnippets["main.go"]; !ok {
+		t.Error("highest-priority snippet main.go should be kept")
 	}
 }
 
-func TestIsExternalCredential_VectorStoreKeys(t *testing.T) {
-	keys := []string{
-		"PINECONE_API_KEY", "WEAVIATE_API_KEY", "QDRANT_API_KEY",
-		"COHERE_API_KEY", "GROQ_API_KEY",
+func TestTrimContextToBudget_KeepsDockerfilesAndCompose(t *testing.T) {
+	ctx := budgetTestContext()
+	dropped, _ := trimContextToBudget(ctx, ci.Default(), nil, 1)
+
+	if len(ctx.sourceSnippets) != 0 {
+		t.Errorf("all source snippets should be dropped, %d left", len(ctx.sourceSnippets))
 	}
-	for _, k := range keys {
-		if !isExternalCredential(k) {
-			t.Errorf("isExternalCredential(%q) should return true", k)
-		}
+	if ctx.depFiles["go.mod"] != omittedDepFileBody {
+		t.Error("go.mod body should be omitted")
+	}
+	if ctx.dockerfiles["Dockerfile"] == "" || ctx.composeFile == "" {
+		t.Error("Dockerfiles and docker-compose must always be kept")
+	}
+	// Snippets go before dep files, and the largest dep file goes first
+	if dropped[3] != "dependency manifest body go.mod" {
+		t.Errorf("dropped[3] = %q, want go.mod body after the three snippets", dropped[3])
+	}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+	if !strings.Contains(user, "### go.mod") {
+		t.Error("omitted manifest should still be listed by path")
+	}
+}
+
+func TestTrimContextToBudget_MeasuresPromptTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	os.WriteFile(path, []byte("{{.Default}}\n"+strings.Repeat("## House rule: pin every image digest.\n", 200)), 0644)
+	tmpl, err := loadPromptTemplate(path)
+	if err != nil {
+		t.Fatalf("loadPromptTemplate() error = %v", err)
+	}
+
+	ctx := budgetTestContext()
+	system, user := buildGeneratePrompt(ctx, ci.Default())
+	budget := estimateTokens(system) + estimateTokens(user)
+
+	if dropped, _ := trimContextToBudget(ctx, ci.Default(), nil, budget); len(dropped) != 0 {
+		t.Fatalf("built-in prompt fits the budget, but dropped %v", dropped)
+	}
+	dropped, err := trimContextToBudget(ctx, ci.Default(), tmpl, budget)
+	if err != nil {
+		t.Fatalf("trimContextToBudget() error = %v", err)
+	}
+	if len(dropped) == 0 {
+		t.Error("the larger templated system prompt should force context to be dropped")
+	}
+	system, user, _ = generatePrompts(tmpl, ctx, ci.Default())
+	if got := estimateTokens(system) + estimateTokens(user); got > budget {
+		t.Errorf("rendered prompt is ~%d tokens, want <= %d", got, budget)
 	}
 }
 
 // ────────────────────────────────────────────────────────────────────────────
-// Directive prompt output
+// Explanation (--explain)
 // ────────────────────────────────────────────────────────────────────────────
 
-func TestBuildGeneratePrompt_DirectiveMCP(t *testing.T) {
-	ctx := &repoContext{
-		name:           "mcp-app",
-		branch:         "main",
-		tree:           "server.py\nDockerfile\nmcp.json\n",
-		mcpServers:     []string{"MCP config file: mcp.json", "MCP tool decorator (@mcp.tool)"},
-		dockerfiles:    make(map[string]string),
-		depFiles:       make(map[string]string),
-		sourceSnippets: make(map[string]string),
+func TestExplainOutputPath(t *testing.T) {
+	tests := map[string]string{
+		"/repo/.github/workflows/dev-deploy.yml": "/repo/.github/workflows/dev-deploy.explain.md",
+		"/repo/.gitlab-ci.yml":                   "/repo/.gitlab-ci.explain.md",
+		"out/workflow":                           "out/workflow.explain.md",
 	}
-	_, user := buildGeneratePrompt(ctx, ci.Default())
+	for in, want := range tests {
+		if got := explainOutputPath(in); got != want {
+			t.Errorf("explainOutputPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
 
-	if !strings.Contains(user, "DIRECTIVE") {
-		t.Error("user prompt should contain DIRECTIVE for MCP servers")
+func TestExplainModelFor(t *testing.T) {
+	if got := explainModelFor("openai", "o3"); got != "gpt-4o-mini" {
+		t.Errorf("openai explain model = %q, want gpt-4o-mini", got)
 	}
-	if !strings.Contains(user, "separate build+deploy") {
-		t.Error("user prompt should instruct separate build+deploy for MCP servers")
+	if got := explainModelFor("ollama", "qwen2.5-coder:14b"); got != "qwen2.5-coder:14b" {
+		t.Errorf("ollama should reuse the generation model, got %q", got)
 	}
 }
 
-func TestBuildGeneratePrompt_DirectiveWorkers(t *testing.T) {
+func TestBuildExplainPrompt(t *testing.T) {
 	ctx := &repoContext{
-		name:            "celery-app",
-		branch:          "main",
-		tree:            "app.py\nDockerfile\n",
-		workerProcesses: []string{"Celery worker (celery -A)"},
-		dockerfiles:     make(map[string]string),
-		depFiles:        make(map[string]string),
-		sourceSnippets:  make(map[string]string),
+		name:            "explain-app",
+		dockerfiles:     map[string]string{"Dockerfile": "FROM python:3.12\nEXPOSE 8000"},
+		exposedPorts:    map[string]int32{"Dockerfile": 8000},
+		depFiles:        map[string]string{"requirements.txt": "psycopg2\n"},
+		externalSecrets: []string{"STRIPE_API_KEY"},
+		agentFrameworks: []string{"LangGraph"},
+		vectorStores:    []string{"Qdrant"},
+		scheduledJobs:   []string{"node-cron"},
+		embeddedDBs:     []string{"SQLite"},
+		temporalHints:   []string{"temporalio"},
+		daprHints:       []string{"dapr sidecar annotations"},
+		webSockets:      []string{"socket.io"},
+		gpuWorkloads:    []string{"torch"},
+		healthEndpoint:  "/healthz",
+		composePorts:    map[string]int32{"api": 8000},
+	}
+	prompt := buildExplainPrompt(ctx, "name: dev-deploy")
+
+	for _, want := range []string{
+		"explain-app",
+		"Dockerfile (EXPOSE 8000)",
+		"requirements.txt",
+		"STRIPE_API_KEY",
+		"LangGraph",
+		"Qdrant",
+		"### MCP servers (mcpServers)\n- (none detected)",
+		"node-cron",
+		"SQLite",
+		"temporalio",
+		"dapr sidecar annotations",
+		"socket.io",
+		"torch",
+		"/healthz",
+		"api: 8000",
+		"name: dev-deploy",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("explain prompt should contain %q", want)
+		}
 	}
-	_, user := buildGeneratePrompt(ctx, ci.Default())
+}
 
-	if !strings.Contains(user, "DIRECTIVE") {
-		t.Error("user prompt should contain DIRECTIVE for workers")
-	}
-	if !strings.Contains(user, "separate deploy step") {
-		t.Error("user prompt should instruct separate deploy for workers")
+func TestDetectionLists_CoverEveryDetector(t *testing.T) {
+	// []string fields that hold options or ordering rather than findings.
+	notDetectors := map[string]bool{"sourceOrder": true, "services": true}
+
+	var titles []string
+	for _, d := range (&repoContext{}).detectionLists() {
+		titles = append(titles, d.title)
 	}
-	if !strings.Contains(user, "broker dependency") {
-		t.Error("user prompt should mention broker dependency wiring")
+	all := strings.Join(titles, "\n")
+
+	typ := reflect.TypeOf(repoContext{})
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Type != reflect.TypeOf([]string(nil)) || notDetectors[f.Name] {
+			continue
+		}
+		if !strings.Contains(all, "("+f.Name+")") {
+			t.Errorf("repoContext.%s is not in detectionLists, so the explain prompt never sees it", f.Name)
+		}
 	}
 }
 
-func TestBuildGeneratePrompt_DirectiveVectorStores(t *testing.T) {
-	ctx := &repoContext{
-		name:           "rag-app",
-		branch:         "main",
-		tree:           "main.py\nDockerfile\n",
-		vectorStores:   []string{"Pinecone", "pgvector"},
-		dockerfiles:    make(map[string]string),
-		depFiles:       make(map[string]string),
-		sourceSnippets: make(map[string]string),
+// ────────────────────────────────────────────────────────────────────────────
+// validateWorkflow
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestValidateWorkflow(t *testing.T) {
+	valid := `# generated by kindling
+name: dev-deploy
+jobs:
+  deploy:
+    steps:
+      - uses: kindling-sh/kindling/.github/actions/kindling-deploy@main`
+	if err := validateWorkflow(valid); err != nil {
+		t.Errorf("validateWorkflow(valid) = %v, want nil", err)
 	}
-	_, user := buildGeneratePrompt(ctx, ci.Default())
 
-	if !strings.Contains(user, "DIRECTIVE") {
-		t.Error("user prompt should contain DIRECTIVE for vector stores")
+	gitlab := "deploy-app:\n  script:\n    - |\n      kind: DevStagingEnvironment"
+	if err := validateWorkflow(gitlab); err != nil {
+		t.Errorf("validateWorkflow(gitlab) = %v, want nil", err)
 	}
-	if !strings.Contains(user, "do NOT auto-add local dependencies") {
-		t.Error("user prompt should instruct not to auto-add local deps")
+
+	invalid := map[string]string{
+		"empty":     "  ",
+		"prose":     "Sure! Here is your workflow\nname: dev-deploy\nkindling-deploy",
+		"tabs":      "name: x\njobs:\n\tdeploy: kindling-deploy",
+		"no_deploy": "name: dev-deploy\njobs:\n  build:\n    steps: []",
 	}
-	if !strings.Contains(user, "secretKeyRef") {
-		t.Error("user prompt should mention secretKeyRef for API keys")
+	for name, wf := range invalid {
+		if err := validateWorkflow(wf); err == nil {
+			t.Errorf("validateWorkflow(%s) = nil, want error", name)
+		}
 	}
 }
 
-func TestBuildGeneratePrompt_DirectiveInterService(t *testing.T) {
-	ctx := &repoContext{
-		name:              "multi-svc",
-		branch:            "main",
-		tree:              "api/main.go\nworker/main.go\n",
-		interServiceCalls: []string{"Go http.Get (potential inter-service call)"},
-		dockerfiles:       make(map[string]string),
-		depFiles:          make(map[string]string),
-		sourceSnippets:    make(map[string]string),
+// ────────────────────────────────────────────────────────────────────────────
+// parseProcfile
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestParseProcfile_MultiProcess(t *testing.T) {
+	content := `# Heroku-style Procfile
+web: gunicorn app:app --bind 0.0.0.0:$PORT
+worker: celery -A app worker --loglevel=info
+clock: python clock.py
+
+release: python manage.py migrate
+`
+	got := parseProcfile(content)
+	want := []procEntry{
+		{name: "web", command: "gunicorn app:app --bind 0.0.0.0:$PORT"},
+		{name: "worker", command: "celery -A app worker --loglevel=info"},
+		{name: "clock", command: "python clock.py"},
+		{name: "release", command: "python manage.py migrate"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseProcfile() returned %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
 	}
-	_, user := buildGeneratePrompt(ctx, ci.Default())
+}
 
-	if !strings.Contains(user, "DIRECTIVE") {
-		t.Error("user prompt should contain DIRECTIVE for inter-service calls")
+func TestParseProcfile_SkipsMalformedLines(t *testing.T) {
+	content := "web:\nno colon here\nbad name: cmd\nconsumer: node consumer.js"
+	got := parseProcfile(content)
+	if len(got) != 1 || got[0].name != "consumer" {
+		t.Errorf("parseProcfile() = %+v, want only the consumer entry", got)
 	}
-	if !strings.Contains(user, "Kubernetes DNS") {
-		t.Error("user prompt should mention Kubernetes DNS for inter-service calls")
+}
+
+func TestScanRepo_ParsesProcfile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "Procfile"), []byte("web: node server.js\nworker: node worker.js\n"), 0644)
+
+	ctx, err := scanRepo(dir)
+	if err != nil {
+		t.Fatalf("scanRepo() error = %v", err)
+	}
+	if len(ctx.procEntries) != 2 {
+		t.Fatalf("procEntries = %+v, want 2 entries", ctx.procEntries)
+	}
+	if ctx.procEntries[1].name != "worker" || ctx.procEntries[1].command != "node worker.js" {
+		t.Errorf("procEntries[1] = %+v, want worker: node worker.js", ctx.procEntries[1])
 	}
 }
 
-func TestBuildGeneratePrompt_NoInterServiceWithoutDetection(t *testing.T) {
+func TestBuildGeneratePrompt_ProcfileDirective(t *testing.T) {
 	ctx := &repoContext{
-		name:           "simple-app",
-		branch:         "main",
-		tree:           "main.go\n",
-		dockerfiles:    make(map[string]string),
-		depFiles:       make(map[string]string),
-		sourceSnippets: make(map[string]string),
+		name:   "proc-app",
+		branch: "main",
+		procEntries: []procEntry{
+			{name: "web", command: "bundle exec puma"},
+			{name: "worker", command: "bundle exec sidekiq"},
+			{name: "release", command: "rails db:migrate"},
+		},
 	}
+
 	_, user := buildGeneratePrompt(ctx, ci.Default())
 
-	if strings.Contains(user, "Inter-service communication") {
-		t.Error("user prompt should NOT contain inter-service section when nothing detected")
+	for _, want := range []string{
+		"## Procfile process types",
+		"- web: `bundle exec puma`",
+		"- worker: `bundle exec sidekiq`",
+		"separate kindling-deploy step for each process type except `release`",
+		"overrides the container `command`",
+	} {
+		if !strings.Contains(user, want) {
+			t.Errorf("user prompt should contain %q", want)
+		}
+	}
+}
+
+func TestBuildGeneratePrompt_NoProcfileSection(t *testing.T) {
+	ctx := &repoContext{name: "plain-app", branch: "main"}
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+	if strings.Contains(user, "## Procfile process types") {
+		t.Error("user prompt should omit the Procfile section without a Procfile")
 	}
 }
 
 // ────────────────────────────────────────────────────────────────────────────
-// scanRepo integration: inter-service calls
+// parseExposedPorts
 // ────────────────────────────────────────────────────────────────────────────
 
-func TestScanRepo_DetectsInterServiceCalls(t *testing.T) {
+func TestParseExposedPorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []int32
+	}{
+		{"single", "FROM node:20\nEXPOSE 3000\nCMD [\"node\", \"server.js\"]", []int32{3000}},
+		{"protocol_suffix", "EXPOSE 8080/tcp", []int32{8080}},
+		{"multiple_lines", "EXPOSE 9000\nEXPOSE 9090/udp", []int32{9000, 9090}},
+		{"multiple_per_line", "EXPOSE 5000 5001/tcp", []int32{5000, 5001}},
+		{"lowercase", "expose 4000", []int32{4000}},
+		{"variable_skipped", "ARG PORT=8000\nEXPOSE $PORT", nil},
+		{"duplicates", "EXPOSE 80\nEXPOSE 80/tcp", []int32{80}},
+		{"trailing_comment", "EXPOSE 7000 # api", []int32{7000}},
+		{"none", "FROM alpine\nRUN echo hi", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseExposedPorts(tt.content)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseExposedPorts() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseExposedPorts()[%d] = %d, want %d", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScanRepo_DetectsExposedPorts(t *testing.T) {
 	dir := t.TempDir()
 
-	os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
-import "net/http"
-func main() {
-	resp, _ := http.Get("http://orders:3000/api")
-	_ = resp
-}`), 0644)
+	os.MkdirAll(filepath.Join(dir, "api"), 0755)
+	os.WriteFile(filepath.Join(dir, "api", "Dockerfile"), []byte("FROM node:20\nEXPOSE 3000/tcp\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM golang:1.22\n"), 0644)
 
 	ctx, err := scanRepo(dir)
 	if err != nil {
 		t.Fatalf("scanRepo() error = %v", err)
 	}
 
-	if len(ctx.interServiceCalls) == 0 {
-		t.Error("should detect inter-service HTTP call")
+	if got := ctx.exposedPorts[filepath.Join("api", "Dockerfile")]; got != 3000 {
+		t.Errorf("exposedPorts[api/Dockerfile] = %d, want 3000", got)
+	}
+	if _, ok := ctx.exposedPorts["Dockerfile"]; ok {
+		t.Error("Dockerfile without EXPOSE should not have a detected port")
 	}
 }
 
-func TestScanRepo_DetectsProcfile(t *testing.T) {
-	dir := t.TempDir()
+func TestBuildGeneratePrompt_DetectedPorts(t *testing.T) {
+	ctx := &repoContext{
+		name:         "ports-app",
+		branch:       "main",
+		dockerfiles:  map[string]string{"api/Dockerfile": "FROM node:20\nEXPOSE 3000"},
+		exposedPorts: map[string]int32{"api/Dockerfile": 3000},
+	}
 
-	os.WriteFile(filepath.Join(dir, "Procfile"), []byte(`web: gunicorn app:app
-worker: celery -A app worker
-`), 0644)
+	_, user := buildGeneratePrompt(ctx, ci.Default())
 
-	ctx, err := scanRepo(dir)
-	if err != nil {
-		t.Fatalf("scanRepo() error = %v", err)
+	if !strings.Contains(user, "## Detected container ports") {
+		t.Error("user prompt should include the detected container ports section")
+	}
+	if !strings.Contains(user, "- api/Dockerfile: 3000") {
+		t.Error("user prompt should list the port for api/Dockerfile")
 	}
 
-	if _, ok := ctx.depFiles["Procfile"]; !ok {
-		t.Error("scanRepo should collect Procfile as a dependency manifest")
+	ctx.exposedPorts = nil
+	_, user = buildGeneratePrompt(ctx, ci.Default())
+	if strings.Contains(user, "## Detected container ports") {
+		t.Error("user prompt should omit the ports section when nothing was detected")
+	}
+}
+
+func TestParseComposePorts(t *testing.T) {
+	compose := `version: "3.9"
+services:
+  web:
+    build: ./web
+    ports:
+      - "3000:3000"
+      - "9229:9229"
+  api:
+    build:
+      context: ./api
+    ports:
+    - "127.0.0.1:5000:5001/tcp"
+  worker:
+    ports: ["8080"]
+  admin:
+    ports:
+      - target: 4000
+        published: 14000
+  ranged:
+    ports:
+      - "7000-7005:7000-7005"
+  templated:
+    ports:
+      - "${PORT}:${PORT}"
+  db:
+    image: postgres:16
+volumes:
+  data:
+    ports: ["1234"]
+`
+	got := parseComposePorts(compose)
+	want := map[string]int32{"web": 3000, "api": 5001, "worker": 8080, "admin": 4000}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseComposePorts() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildGeneratePrompt_ComposePorts(t *testing.T) {
+	ctx := &repoContext{
+		name:         "compose-app",
+		branch:       "main",
+		composeFile:  "services:\n  web:\n    ports:\n      - \"3000:3000\"\n",
+		composePorts: map[string]int32{"web": 3000},
+	}
+
+	_, user := buildGeneratePrompt(ctx, ci.Default())
+
+	if !strings.Contains(user, "## Detected container ports") || !strings.Contains(user, "- web: 3000") {
+		t.Error("user prompt should list the compose service port")
+	}
+	if !strings.Contains(user, "prefer the compose port") {
+		t.Error("directive should prefer the compose port")
 	}
 }
 
@@ -1968,3 +3402,31 @@ CMD ["node", "dist/index.js"]`,
 		t.Errorf("expected no warnings for multi-stage COPY --from, got: %v", warnings)
 	}
 }
+
+func TestBuildGeneratePrompt_SortsManifests(t *testing.T) {
+	ctx := &repoContext{
+		name:   "app",
+		branch: "main",
+		dockerfiles: map[string]string{
+			"web/Dockerfile": "FROM node:20",
+			"api/Dockerfile": "FROM golang:1.22",
+		},
+		depFiles: map[string]string{
+			"web/package.json": "{}",
+			"api/go.mod":       "module api",
+			"requirements.txt": "flask",
+		},
+	}
+	_, first := buildGeneratePrompt(ctx, ci.Default())
+	for i := 0; i < 20; i++ {
+		if _, user := buildGeneratePrompt(ctx, ci.Default()); user != first {
+			t.Fatal("prompt should be identical across builds of the same repoContext")
+		}
+	}
+	if strings.Index(first, "### api/go.mod") > strings.Index(first, "### web/package.json") {
+		t.Error("dependency manifests should be listed in path order")
+	}
+	if strings.Index(first, "### api/Dockerfile") > strings.Index(first, "### web/Dockerfile") {
+		t.Error("Dockerfiles should be listed in path order")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/jeffvincent/kindling/cli/core"
 	"github.com/jeffvincent/kindling/pkg/ci"
 )
 
@@ -25,9 +26,42 @@ const (
 
 // ── Shared helpers ──────────────────────────────────────────────
 
-// kindContext returns the kubectl context string for the active Kind cluster.
+// kindContext returns the kubectl context for the active cluster: the
+// --kube-context override when set, otherwise the Kind cluster's context.
 func kindContext() string {
-	return "kind-" + clusterName
+	return core.ClusterContext(clusterName)
+}
+
+// resolveKubeContext replaces --kube-context=current with the name of the
+// current-context from $KUBECONFIG (or ~/.kube/config).
+func resolveKubeContext() error {
+	if kubeContext != "current" {
+		return nil
+	}
+	out, err := runCapture("kubectl", "config", "current-context")
+	if err != nil || strings.TrimSpace(out) == "" {
+		return fmt.Errorf("--kube-context=current: kubectl has no current-context")
+	}
+	kubeContext = strings.TrimSpace(out)
+	return nil
+}
+
+// applyKubeContext resolves --kube-context and hands it to the core
+// package, so its kubectl helpers (secrets, env, runners, expose, the
+// dashboard) target the same cluster as the commands' own calls.
+func applyKubeContext() error {
+	if err := resolveKubeContext(); err != nil {
+		return err
+	}
+	core.KubeContext = kubeContext
+	return nil
+}
+
+// usingKindCluster reports whether commands target the local Kind cluster
+// rather than a --kube-context override, so Kind-only checks (and docker
+// exec into the node) make sense.
+func usingKindCluster() bool {
+	return kubeContext == ""
 }
 
 // labelSession labels a deployment with kindling.dev/mode and kindling.dev/runtime
@@ -272,3 +306,140 @@ func ensureKustomize(dir string) (string, error) {
 
 	return kustomizePath, nil
 }
+
+// ── Unified diff ────────────────────────────────────────────────
+
+// unifiedDiff returns a unified diff (3 lines of context) turning a into b,
+// or "" when they are identical. Meant for small files like workflows —
+// it uses an O(n·m) LCS table.
+func unifiedDiff(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	if a == "" {
+		x = nil
+	}
+	if b == "" {
+		y = nil
+	}
+
+	// lcs[i][j] = length of the LCS of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type diffLine struct {
+		op   byte // ' ', '-', '+'
+		text string
+		ai   int // line index in a (for ' ' and '-')
+		bi   int // line index in b (for ' ' and '+')
+	}
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, diffLine{' ', x[i], i, j})
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', x[i], i, j})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', y[j], i, j})
+			j++
+		}
+	}
+
+	const context = 3
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(lines); {
+		// Find the next change
+		for start < len(lines) && lines[start].op == ' ' {
+			start++
+		}
+		if start == len(lines) {
+			break
+		}
+		from := start - context
+		if from < 0 {
+			from = 0
+		}
+		// Extend the hunk while changes are within 2*context of each other
+		end := start
+		for k := start; k < len(lines); k++ {
+			if lines[k].op != ' ' {
+				end = k
+			} else if k-end > 2*context {
+				break
+			}
+		}
+		to := end + context + 1
+		if to > len(lines) {
+			to = len(lines)
+		}
+
+		aStart, bStart := lines[from].ai, lines[from].bi
+		aLen, bLen := 0, 0
+		for _, l := range lines[from:to] {
+			if l.op != '+' {
+				aLen++
+			}
+			if l.op != '-' {
+				bLen++
+			}
+		}
+		if aLen > 0 {
+			aStart++
+		}
+		if bLen > 0 {
+			bStart++
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+		for _, l := range lines[from:to] {
+			out.WriteByte(l.op)
+			out.WriteString(l.text)
+			out.WriteByte('\n')
+		}
+		start = to
+	}
+	return out.String()
+}
+
+// colorizeDiff adds ANSI colours to a unified diff for terminal output.
+func colorizeDiff(diff string) string {
+	var out strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		nl := line[len(text):]
+		switch {
+		case strings.HasPrefix(text, "+++"), strings.HasPrefix(text, "---"):
+			out.WriteString(colorBold + text + colorReset + nl)
+		case strings.HasPrefix(text, "@@"):
+			out.WriteString(colorCyan + text + colorReset + nl)
+		case strings.HasPrefix(text, "+"):
+			out.WriteString(colorGreen + text + colorReset + nl)
+		case strings.HasPrefix(text, "-"):
+			out.WriteString(colorRed + text + colorReset + nl)
+		default:
+			out.WriteString(line)
+		}
+	}
+	return out.String()
+}
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/jeffvincent/kindling/cli/core"
 )
 
 // ────────────────────────────────────────────────────────────────────────────
@@ -162,3 +166,92 @@ func TestRuntimeTableNoneRuntimes(t *testing.T) {
 		}
 	}
 }
+
+// ────────────────────────────────────────────────────────────────────────────
+// unifiedDiff
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestUnifiedDiff_Identical(t *testing.T) {
+	if d := unifiedDiff("a", "b", "x\ny\n", "x\ny\n"); d != "" {
+		t.Errorf("identical input should give empty diff, got %q", d)
+	}
+}
+
+func TestUnifiedDiff_SingleChange(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	b := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n"
+	want := `--- a/f
++++ b/f
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`
+	if got := unifiedDiff("a/f", "b/f", a, b); got != want {
+		t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiff_Insertion(t *testing.T) {
+	a := "name: dev\njobs:\n  build:\n"
+	b := "name: dev\n# keep me\njobs:\n  build:\n  deploy:\n"
+	got := unifiedDiff("old", "new", a, b)
+	if !strings.Contains(got, "+# keep me\n") || !strings.Contains(got, "+  deploy:\n") {
+		t.Errorf("diff should show both insertions, got:\n%s", got)
+	}
+	if strings.Contains(got, "-") && strings.Contains(got, "\n-") {
+		t.Errorf("pure insertion should have no removed lines, got:\n%s", got)
+	}
+	if !strings.Contains(got, "@@ -1,3 +1,5 @@") {
+		t.Errorf("hunk header should cover both changes, got:\n%s", got)
+	}
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 20; i++ {
+		a = append(a, "line")
+		b = append(b, "line")
+	}
+	b[1] = "first"
+	b[18] = "second"
+	got := unifiedDiff("a", "b", strings.Join(a, "\n"), strings.Join(b, "\n"))
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Errorf("distant changes should produce 2 hunks, got %d:\n%s", n, got)
+	}
+}
+
+func TestUnifiedDiff_FromEmpty(t *testing.T) {
+	got := unifiedDiff("a", "b", "", "x\n")
+	if !strings.Contains(got, "@@ -0,0 +1,1 @@\n+x\n") {
+		t.Errorf("diff from empty = %q", got)
+	}
+}
+
+func TestApplyKubeContext_CoreHelpers(t *testing.T) {
+	defer func() {
+		kubeContext, core.KubeContext = "", ""
+		rootCmd.PersistentFlags().Lookup("kube-context").Changed = false
+	}()
+	if err := rootCmd.PersistentFlags().Set("kube-context", "staging"); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyKubeContext(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Core helpers (secrets, env, runners, expose, dashboard) build their
+	// kubectl calls with the override, not kind-<cluster>.
+	got := core.KubectlArgs(clusterName, "get", "secrets")
+	if want := []string{"--context", "staging", "get", "secrets"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("core.KubectlArgs = %v, want %v", got, want)
+	}
+	if got := kindContext(); got != "staging" {
+		t.Errorf("kindContext() = %q, want staging", got)
+	}
+}
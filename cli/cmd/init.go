@@ -21,6 +21,10 @@ By default the operator image is pulled from:
 
 Use --build to build from source instead (requires Go and Make).
 Use --operator-image to specify a different pre-built image.
+Use --nodes to create workers alongside the control plane, for testing
+affinity, NetworkPolicy, and HA behaviour. Each node is a container with
+its own kubelet and runtime, so budget roughly 1 GB of Docker memory per
+extra node on top of your workloads.
 
 Optional flags are passed through to "kind create cluster":
   --image        Node image to use (e.g. kindest/node:v1.29.0)
@@ -41,6 +45,7 @@ var (
 	kindKubeconfig string
 	kindWait       string
 	kindRetain     bool
+	kindNodes      int
 	initExpose     bool
 	buildOperator  bool
 	operatorImage  string
@@ -49,6 +54,7 @@ var (
 func init() {
 	initCmd.Flags().BoolVar(&skipCluster, "skip-cluster", false, "Skip Kind cluster creation (use existing cluster)")
 	initCmd.Flags().StringVar(&kindNodeImage, "image", "", "Node Docker image for Kind (e.g. kindest/node:v1.29.0)")
+	initCmd.Flags().IntVar(&kindNodes, "nodes", 1, "Number of Kind nodes: 1 control plane plus N-1 workers")
 	initCmd.Flags().StringVar(&kindKubeconfig, "kubeconfig", "", "Path to write kubeconfig instead of default location")
 	initCmd.Flags().StringVar(&kindWait, "wait", "", "Wait for control plane to be ready (e.g. 60s, 5m)")
 	initCmd.Flags().BoolVar(&kindRetain, "retain", false, "Retain cluster nodes for debugging on creation failure")
@@ -59,6 +65,10 @@ func init() {
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
+	if kindNodes < 1 {
+		return fmt.Errorf("--nodes must be at least 1, got %d", kindNodes)
+	}
+
 	dir, err := resolveProjectDir()
 	if err != nil {
 		return err
@@ -98,7 +108,35 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 		if clusterExists(clusterName) {
 			warn(fmt.Sprintf("Cluster %q already exists — skipping creation", clusterName))
+			if kindNodes > 1 {
+				warn("--nodes only applies to new clusters — run kindling destroy first to resize")
+			}
 		} else {
+			if kindNodes > 1 {
+				base, err := os.ReadFile(configPath)
+				if err != nil {
+					return fmt.Errorf("cannot read %s: %w", configPath, err)
+				}
+				cfg, err := multiNodeKindConfig(string(base), kindNodes-1)
+				if err != nil {
+					return err
+				}
+				f, err := os.CreateTemp("", "kindling-kind-config-*.yaml")
+				if err != nil {
+					return err
+				}
+				defer os.Remove(f.Name())
+				if _, err := f.WriteString(cfg); err != nil {
+					f.Close()
+					return err
+				}
+				if err := f.Close(); err != nil {
+					return err
+				}
+				configPath = f.Name()
+				step("🖥️ ", fmt.Sprintf("1 control-plane + %d worker node(s)", kindNodes-1))
+			}
+
 			kindArgs := []string{
 				"create", "cluster",
 				"--name", clusterName,
@@ -197,7 +235,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	step("📜", "Applying CRDs")
 	crdDir := filepath.Join(dir, "config", "crd", "bases")
-	if err := run("kubectl", "apply", "-f", crdDir); err != nil {
+	if err := run("kubectl", "--context", kindContext(), "apply", "-f", crdDir); err != nil {
 		return fmt.Errorf("CRD installation failed: %w", err)
 	}
 	success("CRDs installed")
@@ -216,14 +254,14 @@ func runInit(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("kustomize build failed: %w", err)
 	}
-	if err := runStdin(kustomizeOut, "kubectl", "apply", "-f", "-"); err != nil {
+	if err := runStdin(kustomizeOut, "kubectl", "--context", kindContext(), "apply", "-f", "-"); err != nil {
 		return fmt.Errorf("operator deployment failed: %w", err)
 	}
 	success("Operator deployed")
 
 	// ── Wait for operator ───────────────────────────────────────
 	step("⏳", "Waiting for controller-manager rollout")
-	if err := run("kubectl", "rollout", "status",
+	if err := run("kubectl", "--context", kindContext(), "rollout", "status",
 		"deployment/kindling-controller-manager",
 		"-n", "kindling-system",
 		"--timeout=120s",
@@ -250,3 +288,56 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// multiNodeKindConfig appends workers worker nodes to the nodes list of the
+// Kind config base. The control plane keeps its ingress-ready label and
+// port mappings, so Traefik and the registry stay on it; workers inherit
+// the cluster-wide containerd registry mirror.
+func multiNodeKindConfig(base string, workers int) (string, error) {
+	lines := strings.Split(strings.TrimRight(base, "\n"), "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.TrimRight(line, " ") == "nodes:" {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", fmt.Errorf("kind-config.yaml has no top-level nodes list")
+	}
+
+	// The list ends at the next top-level key; trailing comments and
+	// blank lines stay after the inserted workers.
+	end, itemIndent := len(lines), -1
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indentOf(lines[i]) == 0 && !strings.HasPrefix(trimmed, "- ") {
+			end = i
+			break
+		}
+		if itemIndent < 0 && strings.HasPrefix(trimmed, "- ") {
+			itemIndent = indentOf(lines[i])
+		}
+	}
+	if itemIndent < 0 {
+		itemIndent = 2
+	}
+	for end > start+1 {
+		trimmed := strings.TrimSpace(lines[end-1])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		end--
+	}
+
+	worker := strings.Repeat(" ", itemIndent) + "- role: worker"
+	out := append([]string{}, lines[:end]...)
+	for i := 0; i < workers; i++ {
+		out = append(out, worker)
+	}
+	out = append(out, lines[end:]...)
+	return strings.Join(out, "\n") + "\n", nil
+}
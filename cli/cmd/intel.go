@@ -100,7 +100,7 @@ func init() {
 	rootCmd.AddCommand(intelCmd)
 }
 
-// ── Auto-lifecycle (called by PersistentPreRun) ─────────────────
+// ── Auto-lifecycle (called by PersistentPreRunE) ────────────────
 
 // ensureIntel is called before every kindling command. It manages the
 // intel lifecycle automatically:
@@ -1,41 +1,72 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 )
 
 var logsCmd = &cobra.Command{
-	Use:   "logs",
-	Short: "Tail the kindling controller logs",
-	Long: `Streams logs from the kindling controller-manager pod. Press Ctrl+C to stop.
+	Use:   "logs [environment]",
+	Short: "Tail the kindling controller logs, or an environment's app and dependencies",
+	Long: `Without arguments, streams logs from the kindling controller-manager pod.
 
-Use --all to see logs from all containers in the pod (including kube-rbac-proxy).`,
+With the name of a DevStagingEnvironment, streams its app pod logs instead.
+--deps interleaves the logs of every dependency pod, each line prefixed by
+its source. Press Ctrl+C to stop.
+
+Use --all to see logs from all containers in the pod (including kube-rbac-proxy).
+
+Examples:
+  kindling logs                         # controller
+  kindling logs orders-api              # app pods
+  kindling logs orders-api --deps       # app + postgres, redis, ...
+  kindling logs orders-api --tail 50 -f=false`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runLogs,
 }
 
 var (
-	logsAll    bool
-	logsSince  string
-	logsFollow bool
+	logsAll       bool
+	logsSince     string
+	logsFollow    bool
+	logsTail      int
+	logsDeps      bool
+	logsNamespace string
 )
 
 func init() {
 	logsCmd.Flags().BoolVar(&logsAll, "all", false, "Show logs from all containers")
 	logsCmd.Flags().StringVar(&logsSince, "since", "5m", "Show logs since duration (e.g. 5m, 1h)")
 	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", true, "Follow log output (stream)")
+	logsCmd.Flags().IntVar(&logsTail, "tail", -1, "Lines of recent log to show per pod (-1 for all)")
+	logsCmd.Flags().BoolVar(&logsDeps, "deps", false, "Also stream dependency pod logs (environment only)")
+	logsCmd.Flags().StringVarP(&logsNamespace, "namespace", "n", "default", "Namespace of the environment")
 	rootCmd.AddCommand(logsCmd)
 }
 
 func runLogs(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		return runEnvLogs(args[0])
+	}
+
 	header("Controller logs")
 
 	kubectlArgs := []string{
 		"logs",
 		"-n", "kindling-system",
+		"--context", kindContext(),
 		"-l", "control-plane=controller-manager",
 		"--since=" + logsSince,
+		"--tail=" + strconv.Itoa(logsTail),
 	}
 
 	if logsAll {
@@ -51,3 +82,147 @@ func runLogs(cmd *cobra.Command, args []string) error {
 
 	return run("kubectl", kubectlArgs...)
 }
+
+// ── Environment logs ────────────────────────────────────────────
+
+// logSource is one stream of pods multiplexed by runEnvLogs.
+type logSource struct {
+	prefix   string // "app" or the dependency type
+	selector string // pod label selector
+}
+
+// logPrefixColors cycles across sources so adjacent streams differ.
+var logPrefixColors = []string{colorCyan, colorGreen, colorYellow, colorRed, colorBold}
+
+func runEnvLogs(name string) error {
+	header(fmt.Sprintf("Logs for %s", name))
+
+	dseJSON, err := runCapture("kubectl", "--context", kindContext(), "get", "devstagingenvironment", name, "-n", logsNamespace, "-o", "json")
+	if err != nil {
+		return fmt.Errorf("DevStagingEnvironment %q not found in namespace %s: %w", name, logsNamespace, err)
+	}
+	sources, err := envLogSources([]byte(dseJSON), logsDeps)
+	if err != nil {
+		return err
+	}
+
+	baseArgs := []string{"logs", "-n", logsNamespace, "--context", kindContext(),
+		"--since=" + logsSince,
+		"--tail=" + strconv.Itoa(logsTail),
+		"--max-log-requests=20",
+	}
+	if logsAll {
+		baseArgs = append(baseArgs, "--all-containers=true")
+	}
+	if logsFollow {
+		baseArgs = append(baseArgs, "-f")
+		fmt.Printf("  %sStreaming %d source(s) (Ctrl+C to stop)...%s\n\n", colorDim, len(sources), colorReset)
+	}
+
+	width := 0
+	for _, src := range sources {
+		width = max(width, len(src.prefix))
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make([]error, len(sources))
+	)
+	for i, src := range sources {
+		wg.Add(1)
+		go func(i int, src logSource) {
+			defer wg.Done()
+			color := logPrefixColors[i%len(logPrefixColors)]
+			errs[i] = streamLogSource(append(append([]string{}, baseArgs...), "-l", src.selector), func(line string) {
+				mu.Lock()
+				defer mu.Unlock()
+				fmt.Println(formatLogLine(src.prefix, width, color, line))
+			})
+		}(i, src)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil && i > 0 {
+			warn(fmt.Sprintf("%s: %v", sources[i].prefix, err))
+		}
+	}
+	return errs[0]
+}
+
+// envLogSources returns the app source followed, if withDeps is set, by
+// one source per declared dependency, from a DevStagingEnvironment's JSON.
+func envLogSources(dseJSON []byte, withDeps bool) ([]logSource, error) {
+	var dse struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Dependencies []struct {
+				Type       string `json:"type"`
+				Shared     bool   `json:"shared"`
+				SharedName string `json:"sharedName"`
+			} `json:"dependencies"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(dseJSON, &dse); err != nil {
+		return nil, fmt.Errorf("parse DevStagingEnvironment: %w", err)
+	}
+
+	sources := []logSource{{
+		prefix:   "app",
+		selector: "app.kubernetes.io/instance=" + dse.Metadata.Name + ",app.kubernetes.io/managed-by=devstagingenvironment-operator",
+	}}
+	if !withDeps {
+		return sources, nil
+	}
+	for _, dep := range dse.Spec.Dependencies {
+		sources = append(sources, logSource{
+			prefix:   dep.Type,
+			selector: "app.kubernetes.io/name=" + dependencyResourceName(dse.Metadata.Name, dep.Type, dep.Shared, dep.SharedName),
+		})
+	}
+	return sources, nil
+}
+
+// streamLogSource runs kubectl logs with args and hands each output line to
+// emit. kubectl's stderr is folded into the returned error.
+func streamLogSource(args []string, emit func(string)) error {
+	cmd := exec.Command("kubectl", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cannot start kubectl: %w", err)
+	}
+	scanLines(stdout, emit)
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	if strings.Contains(stderr.String(), "No resources found") {
+		emit(colorDim + "(no pods)" + colorReset)
+	}
+	return nil
+}
+
+// scanLines calls emit for each line read from r, allowing long lines.
+func scanLines(r io.Reader, emit func(string)) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		emit(scanner.Text())
+	}
+}
+
+// formatLogLine pads prefix to width and colors it so multiplexed streams
+// line up.
+func formatLogLine(prefix string, width int, color, line string) string {
+	return fmt.Sprintf("%s%-*s%s │ %s", color, width, prefix, colorReset, line)
+}
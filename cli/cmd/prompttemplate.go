@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/jeffvincent/kindling/pkg/ci"
+)
+
+// ────────────────────────────────────────────────────────────────────────────
+// --prompt-file system prompt templates
+//
+// A prompt file is a Go text/template rendered into the system prompt.
+// Templates see the scanned repo as .Repo, the CI platform details as .CI,
+// and the built-in system prompt as .Default, so teams can either replace
+// the prompt outright or wrap it with house conventions:
+//
+//	{{.Default}}
+//
+//	## House rules
+//	- Images go to registry.internal/{{.Repo.Name}}
+// ────────────────────────────────────────────────────────────────────────────
+
+// defaultPromptTemplate reproduces the built-in system prompt.
+const defaultPromptTemplate = "{{.Default}}"
+
+// promptRepo is the template view of a repoContext.
+type promptRepo struct {
+	scanCacheEntry
+	Branch string
+}
+
+// promptTemplateData is the execution context for a prompt template.
+type promptTemplateData struct {
+	Repo    promptRepo
+	CI      ci.PromptContext
+	Default string // built-in system prompt for this CI provider
+}
+
+// loadPromptTemplate reads and parses a prompt template. An empty path
+// yields the default template.
+func loadPromptTemplate(path string) (*template.Template, error) {
+	text := defaultPromptTemplate
+	name := "default"
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read prompt file: %w", err)
+		}
+		text = string(data)
+		name = path
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// generatePrompts returns the system and user prompts for ctx. The system
+// prompt comes from tmpl when one is given, else from the built-in prompt.
+func generatePrompts(tmpl *template.Template, ctx *repoContext, provider ci.Provider) (string, string, error) {
+	system, user := buildGeneratePrompt(ctx, provider)
+	if tmpl == nil {
+		return system, user, nil
+	}
+	system, err := renderSystemPrompt(tmpl, ctx, provider)
+	if err != nil {
+		return "", "", err
+	}
+	return system, user, nil
+}
+
+// renderSystemPrompt executes tmpl against the repo and CI provider.
+func renderSystemPrompt(tmpl *template.Template, ctx *repoContext, provider ci.Provider) (string, error) {
+	wfGen := provider.Workflow()
+	data := promptTemplateData{
+		Repo:    promptRepo{scanCacheEntry: newScanCacheEntry(ctx), Branch: ctx.branch},
+		CI:      wfGen.PromptContext(),
+		Default: wfGen.SystemPrompt(ctx.hostArch),
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("render prompt template: %w", err)
+	}
+	if strings.TrimSpace(b.String()) == "" {
+		return "", fmt.Errorf("prompt template rendered an empty system prompt")
+	}
+	return b.String(), nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -20,24 +21,42 @@ and redeploys the named services.
 
 Without --service the full pipeline runs (all services).
 
+--tag and --no-deploy add [kindling-tag:<tag>] and [kindling-no-deploy]
+markers the same way: the workflow builds with that image tag instead of
+<actor>-<sha>, and skips the DevStagingEnvironment apply, so images can be
+pre-built or tagged for manual testing.
+
 Examples:
   kindling push                              # push + rebuild everything
   kindling push --service orders             # push + rebuild orders only
   kindling push -s orders -s gateway         # push + rebuild orders & gateway
+  kindling push -s orders --tag pr-42        # build + deploy registry:5000/...:pr-42
+  kindling push --tag nightly --no-deploy    # build + push images only
   kindling push -s ui -- origin my-branch    # extra git push args after --`,
 	RunE:               runPush,
 	DisableFlagParsing: false,
 }
 
-var pushServices []string
+var (
+	pushServices []string
+	pushTag      string
+	pushNoDeploy bool
+)
 
 func init() {
 	pushCmd.Flags().StringArrayVarP(&pushServices, "service", "s", nil,
 		`Service(s) to rebuild (repeatable, or comma-separated).
 Omit to rebuild all services.`)
+	pushCmd.Flags().StringVar(&pushTag, "tag", "",
+		"Image tag to build and deploy (default: <actor>-<sha>)")
+	pushCmd.Flags().BoolVar(&pushNoDeploy, "no-deploy", false,
+		"Build and push images to registry:5000 only — skip the DSE apply")
 	rootCmd.AddCommand(pushCmd)
 }
 
+// imageTagPattern is the Docker image tag grammar.
+var imageTagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
 func runPush(cmd *cobra.Command, args []string) error {
 	// ── Pre-flight: check for missing secrets ───────────────────
 	missing := checkWorkflowSecrets()
@@ -64,22 +83,35 @@ func runPush(cmd *cobra.Command, args []string) error {
 	// ── Normalise service list (allow -s "orders,gateway") ──────
 	services := normaliseServices(pushServices)
 
-	// ── If services specified, amend HEAD commit message ────────
-	if len(services) > 0 {
-		tag := "[kindling:" + strings.Join(services, ",") + "]"
+	if pushTag != "" && !imageTagPattern.MatchString(pushTag) {
+		return fmt.Errorf("invalid --tag %q: use letters, digits, '_', '.', and '-' (max 128 chars, not starting with '.' or '-')", pushTag)
+	}
 
+	// ── If any markers apply, amend HEAD commit message ─────────
+	markers := pushMarkers(services, pushTag, pushNoDeploy)
+	if len(markers) > 0 {
 		// Read current HEAD message
 		msg, err := runCapture("git", "log", "-1", "--format=%B")
 		if err != nil {
 			return fmt.Errorf("cannot read HEAD commit message: %w", err)
 		}
 
-		// Strip any existing [kindling:...] tag so we don't stack them
+		// Strip any existing markers so we don't stack them
 		cleaned := stripKindlingTag(msg)
 
-		newMsg := strings.TrimRight(cleaned, "\n") + "\n\n" + tag
-		header("Selective push")
-		step("🏷️ ", fmt.Sprintf("Tagging commit for: %s", strings.Join(services, ", ")))
+		newMsg := strings.TrimRight(cleaned, "\n") + "\n\n" + strings.Join(markers, "\n")
+		if len(services) > 0 {
+			header("Selective push")
+			step("🏷️ ", fmt.Sprintf("Tagging commit for: %s", strings.Join(services, ", ")))
+		} else {
+			header("Pushing (full rebuild)")
+		}
+		if pushTag != "" {
+			step("🏷️ ", fmt.Sprintf("Image tag: %s", pushTag))
+		}
+		if pushNoDeploy {
+			step("⏭ ", "Skipping deploy — images are only pushed to registry:5000")
+		}
 
 		if err := runGit("commit", "--amend", "-m", newMsg); err != nil {
 			return fmt.Errorf("failed to amend commit: %w", err)
@@ -95,14 +127,36 @@ func runPush(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("git push failed: %w", err)
 	}
 
-	if len(services) > 0 {
+	switch {
+	case pushNoDeploy && len(services) > 0:
+		success(fmt.Sprintf("Pushed — only %s will rebuild (no deploy)", strings.Join(services, ", ")))
+	case pushNoDeploy:
+		success("Pushed — all images will rebuild (no deploy)")
+	case len(services) > 0:
 		success(fmt.Sprintf("Pushed — only %s will rebuild", strings.Join(services, ", ")))
-	} else {
+	default:
 		success("Pushed — full pipeline will run")
 	}
 	return nil
 }
 
+// pushMarkers returns the commit-message markers the CI workflow reads:
+// [kindling:svc,...] for a selective rebuild, [kindling-tag:<tag>] to
+// override the image tag, and [kindling-no-deploy] to skip the DSE apply.
+func pushMarkers(services []string, tag string, noDeploy bool) []string {
+	var markers []string
+	if len(services) > 0 {
+		markers = append(markers, "[kindling:"+strings.Join(services, ",")+"]")
+	}
+	if tag != "" {
+		markers = append(markers, "[kindling-tag:"+tag+"]")
+	}
+	if noDeploy {
+		markers = append(markers, "[kindling-no-deploy]")
+	}
+	return markers
+}
+
 // normaliseServices splits comma-separated values and deduplicates.
 func normaliseServices(raw []string) []string {
 	seen := map[string]bool{}
@@ -119,13 +173,18 @@ func normaliseServices(raw []string) []string {
 	return out
 }
 
-// stripKindlingTag removes any existing [kindling:...] marker.
+// stripKindlingTag removes any existing [kindling:...], [kindling-tag:...],
+// or [kindling-no-deploy] marker.
 func stripKindlingTag(msg string) string {
 	lines := strings.Split(msg, "\n")
 	var out []string
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "[kindling:") && strings.HasSuffix(trimmed, "]") {
+		if trimmed == "[kindling-no-deploy]" {
+			continue
+		}
+		if (strings.HasPrefix(trimmed, "[kindling:") || strings.HasPrefix(trimmed, "[kindling-tag:")) &&
+			strings.HasSuffix(trimmed, "]") {
 			continue
 		}
 		out = append(out, line)
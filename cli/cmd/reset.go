@@ -36,7 +36,7 @@ func init() {
 func runReset(cmd *cobra.Command, args []string) error {
 	header("Resetting runner pool")
 
-	if !clusterExists(clusterName) {
+	if usingKindCluster() && !clusterExists(clusterName) {
 		fail(fmt.Sprintf("Kind cluster %q not found", clusterName))
 		return nil
 	}
@@ -87,7 +87,7 @@ func runReset(cmd *cobra.Command, args []string) error {
 	// ── Wait for runner pods to terminate ────────────────────────
 	step("⏳", "Waiting for runner pods to terminate...")
 	for i := 0; i < 30; i++ {
-		out, err := runSilent("kubectl", "get", "pods",
+		out, err := runSilent("kubectl", "--context", kindContext(), "get", "pods",
 			"-l", "app.kubernetes.io/component="+labels.RunnerComponent,
 			"--no-headers", "--ignore-not-found")
 		if err != nil || strings.TrimSpace(out) == "" {
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var restartCmd = &cobra.Command{
+	Use:   "restart <deployment>",
+	Short: "Roll a deployment (or one of its dependencies) without syncing",
+	Long: `Runs kubectl rollout restart on a deployment, waits for the rollout to
+finish, and reports the new pod. Nothing is synced, rebuilt, or patched —
+the pod is simply recreated from its current spec.
+
+With --dep, the argument is a DevStagingEnvironment name and the dependency
+of that type is restarted instead (shared dependencies resolve to their
+shared deployment).
+
+Examples:
+  kindling restart orders-api
+  kindling restart orders-api --dep postgres
+  kindling restart orders-api -n staging --timeout 5m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestart,
+}
+
+var (
+	restartDep       string
+	restartNamespace string
+	restartTimeout   string
+)
+
+func init() {
+	restartCmd.Flags().StringVar(&restartDep, "dep", "", "Restart the environment's dependency of this type instead (e.g. postgres)")
+	restartCmd.Flags().StringVarP(&restartNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	restartCmd.Flags().StringVar(&restartTimeout, "timeout", "120s", "How long to wait for the rollout")
+	rootCmd.AddCommand(restartCmd)
+}
+
+func runRestart(cmd *cobra.Command, args []string) error {
+	deployment := args[0]
+	if restartDep != "" {
+		dseJSON, err := runCapture("kubectl", "get", "devstagingenvironment", args[0],
+			"-n", restartNamespace, "--context", kindContext(), "-o", "json")
+		if err != nil {
+			return fmt.Errorf("DevStagingEnvironment %q not found in namespace %s: %w", args[0], restartNamespace, err)
+		}
+		if deployment, err = dependencyDeployment([]byte(dseJSON), restartDep); err != nil {
+			return err
+		}
+	}
+
+	header(fmt.Sprintf("Restarting %s", deployment))
+
+	if !deploymentExists(deployment, restartNamespace) {
+		return fmt.Errorf("deployment/%s not found in namespace %s", deployment, restartNamespace)
+	}
+
+	step("♻️", fmt.Sprintf("kubectl rollout restart deployment/%s", deployment))
+	if err := run("kubectl", "rollout", "restart", fmt.Sprintf("deployment/%s", deployment),
+		"-n", restartNamespace, "--context", kindContext()); err != nil {
+		return fmt.Errorf("rollout restart failed: %w", err)
+	}
+
+	step("⏳", "Waiting for rollout")
+	if err := run("kubectl", "rollout", "status", fmt.Sprintf("deployment/%s", deployment),
+		"-n", restartNamespace, "--context", kindContext(), "--timeout="+restartTimeout); err != nil {
+		return fmt.Errorf("rollout did not finish within %s: %w", restartTimeout, err)
+	}
+
+	pod, err := findPodForDeployment(deployment, restartNamespace)
+	if err != nil {
+		warn(fmt.Sprintf("Rollout finished but no running pod was found: %v", err))
+		return nil
+	}
+	success(fmt.Sprintf("deployment/%s restarted — pod %s", deployment, pod))
+	return nil
+}
+
+// dependencyDeployment returns the Deployment name of the dependency of
+// type depType declared in a DevStagingEnvironment's JSON.
+func dependencyDeployment(dseJSON []byte, depType string) (string, error) {
+	var dse struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Dependencies []struct {
+				Type       string `json:"type"`
+				Shared     bool   `json:"shared"`
+				SharedName string `json:"sharedName"`
+			} `json:"dependencies"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(dseJSON, &dse); err != nil {
+		return "", fmt.Errorf("parse DevStagingEnvironment: %w", err)
+	}
+	for _, dep := range dse.Spec.Dependencies {
+		if dep.Type == depType {
+			return dependencyResourceName(dse.Metadata.Name, dep.Type, dep.Shared, dep.SharedName), nil
+		}
+	}
+	return "", fmt.Errorf("%s declares no %s dependency", dse.Metadata.Name, depType)
+}
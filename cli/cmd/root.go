@@ -12,13 +12,20 @@ var (
 
 	// projectDir is the root of the kindling project (defaults to cwd).
 	projectDir string
+
+	// kubeContext overrides the kubectl context (default "kind-<cluster>").
+	kubeContext string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "kindling",
 	Short: "kindling — set up CI in minutes, stay for everything else",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyKubeContext(); err != nil {
+			return err
+		}
 		ensureIntel(cmd)
+		return nil
 	},
 	Long: `kindling is a development engine that wires up your CI pipeline
 in minutes — then keeps working for you. It bootstraps a local Kind
@@ -48,6 +55,8 @@ Common workflow:
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "dev", "Kind cluster name")
 	rootCmd.PersistentFlags().StringVarP(&projectDir, "project-dir", "p", "", "Path to kindling project root (default: current directory)")
+	rootCmd.PersistentFlags().StringVar(&kubeContext, "kube-context", "",
+		`kubectl context to use instead of "kind-<cluster>" ("current" = current-context of $KUBECONFIG)`)
 }
 
 // Execute runs the root command.
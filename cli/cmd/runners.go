@@ -91,7 +91,7 @@ func runRunners(cmd *cobra.Command, args []string) error {
 
 	found := false
 	for i := 0; i < 30; i++ {
-		if _, err := runSilent("kubectl", "get", deployName); err == nil {
+		if _, err := runSilent("kubectl", "--context", kindContext(), "get", deployName); err == nil {
 			found = true
 			break
 		}
@@ -105,7 +105,7 @@ func runRunners(cmd *cobra.Command, args []string) error {
 	}
 
 	step("⏳", "Waiting for rollout to complete...")
-	if err := run("kubectl", "rollout", "status", deployName, "--timeout=120s"); err != nil {
+	if err := run("kubectl", "--context", kindContext(), "rollout", "status", deployName, "--timeout=120s"); err != nil {
 		return fmt.Errorf("runner rollout failed: %w", err)
 	}
 
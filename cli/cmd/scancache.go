@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ────────────────────────────────────────────────────────────────────────────
+// scanRepo cache
+//
+// Re-running `kindling generate --dry-run` while iterating on prompts walks
+// and reads the whole repo every time. The cache stores the repoContext
+// under ~/.kindling/scan-cache/<fingerprint>.json, where the fingerprint
+// hashes every file's path, size, and mtime. It only saves time; the cached
+// context is identical to a fresh scan.
+// ────────────────────────────────────────────────────────────────────────────
+
+// scanCacheVersion is bumped whenever repoContext or the detectors change
+// shape so stale entries from older binaries are never reused.
+const scanCacheVersion = 9
+
+// scanCacheEntry is the on-disk form of a repoContext.
+type scanCacheEntry struct {
+	Version            int               `json:"version"`
+	Name               string            `json:"name"`
+	Tree               string            `json:"tree"`
+	Dockerfiles        map[string]string `json:"dockerfiles"`
+	DepFiles           map[string]string `json:"depFiles"`
+	ComposeFile        string            `json:"composeFile"`
+	SourceSnippets     map[string]string `json:"sourceSnippets"`
+	SourceOrder        []string          `json:"sourceOrder"`
+	DockerfileCount    int               `json:"dockerfileCount"`
+	DepFileCount       int               `json:"depFileCount"`
+	ExternalSecrets    []string          `json:"externalSecrets"`
+	NeedsPublicExpose  bool              `json:"needsPublicExpose"`
+	OAuthHints         []string          `json:"oauthHints"`
+	HostArch           string            `json:"hostArch"`
+	AgentFrameworks    []string          `json:"agentFrameworks"`
+	MCPServers         []string          `json:"mcpServers"`
+	VectorStores       []string          `json:"vectorStores"`
+	WorkerProcesses    []string          `json:"workerProcesses"`
+	InterServiceCalls  []string          `json:"interServiceCalls"`
+	ScheduledJobs      []string          `json:"scheduledJobs"`
+	EmbeddedDBs        []string          `json:"embeddedDBs"`
+	TemporalHints      []string          `json:"temporalHints"`
+	DaprHints          []string          `json:"daprHints"`
+	WebSockets         []string          `json:"webSockets"`
+	GPUWorkloads       []string          `json:"gpuWorkloads"`
+	HealthEndpoint     string            `json:"healthEndpoint"`
+	DockerfileWarnings []string          `json:"dockerfileWarnings"`
+	ExposedPorts       map[string]int32  `json:"exposedPorts"`
+	ComposePorts       map[string]int32  `json:"composePorts"`
+	ProcEntries        [][2]string       `json:"procEntries"`
+	Services           []string          `json:"services"`
+}
+
+func newScanCacheEntry(ctx *repoContext) scanCacheEntry {
+	e := scanCacheEntry{
+		Version:            scanCacheVersion,
+		Name:               ctx.name,
+		Tree:               ctx.tree,
+		Dockerfiles:        ctx.dockerfiles,
+		DepFiles:           ctx.depFiles,
+		ComposeFile:        ctx.composeFile,
+		SourceSnippets:     ctx.sourceSnippets,
+		SourceOrder:        ctx.sourceOrder,
+		DockerfileCount:    ctx.dockerfileCount,
+		DepFileCount:       ctx.depFileCount,
+		ExternalSecrets:    ctx.externalSecrets,
+		NeedsPublicExpose:  ctx.needsPublicExpose,
+		OAuthHints:         ctx.oauthHints,
+		HostArch:           ctx.hostArch,
+		AgentFrameworks:    ctx.agentFrameworks,
+		MCPServers:         ctx.mcpServers,
+		VectorStores:       ctx.vectorStores,
+		WorkerProcesses:    ctx.workerProcesses,
+		InterServiceCalls:  ctx.interServiceCalls,
+		ScheduledJobs:      ctx.scheduledJobs,
+		EmbeddedDBs:        ctx.embeddedDBs,
+		TemporalHints:      ctx.temporalHints,
+		DaprHints:          ctx.daprHints,
+		WebSockets:         ctx.webSockets,
+		GPUWorkloads:       ctx.gpuWorkloads,
+		HealthEndpoint:     ctx.healthEndpoint,
+		DockerfileWarnings: ctx.dockerfileWarnings,
+		ExposedPorts:       ctx.exposedPorts,
+		ComposePorts:       ctx.composePorts,
+		Services:           ctx.services,
+	}
+	for _, p := range ctx.procEntries {
+		e.ProcEntries = append(e.ProcEntries, [2]string{p.name, p.command})
+	}
+	return e
+}
+
+func (e scanCacheEntry) repoContext() *repoContext {
+	ctx := &repoContext{
+		name:               e.Name,
+		tree:               e.Tree,
+		dockerfiles:        e.Dockerfiles,
+		depFiles:           e.DepFiles,
+		composeFile:        e.ComposeFile,
+		sourceSnippets:     e.SourceSnippets,
+		sourceOrder:        e.SourceOrder,
+		dockerfileCount:    e.DockerfileCount,
+		depFileCount:       e.DepFileCount,
+		externalSecrets:    e.ExternalSecrets,
+		needsPublicExpose:  e.NeedsPublicExpose,
+		oauthHints:         e.OAuthHints,
+		hostArch:           e.HostArch,
+		agentFrameworks:    e.AgentFrameworks,
+		mcpServers:         e.MCPServers,
+		vectorStores:       e.VectorStores,
+		workerProcesses:    e.WorkerProcesses,
+		interServiceCalls:  e.InterServiceCalls,
+		scheduledJobs:      e.ScheduledJobs,
+		embeddedDBs:        e.EmbeddedDBs,
+		temporalHints:      e.TemporalHints,
+		daprHints:          e.DaprHints,
+		webSockets:         e.WebSockets,
+		gpuWorkloads:       e.GPUWorkloads,
+		healthEndpoint:     e.HealthEndpoint,
+		dockerfileWarnings: e.DockerfileWarnings,
+		exposedPorts:       e.ExposedPorts,
+		composePorts:       e.ComposePorts,
+		services:           e.Services,
+	}
+	for _, p := range e.ProcEntries {
+		ctx.procEntries = append(ctx.procEntries, procEntry{name: p[0], command: p[1]})
+	}
+	// Keep maps non-nil, as scanRepo does.
+	if ctx.dockerfiles == nil {
+		ctx.dockerfiles = make(map[string]string)
+	}
+	if ctx.depFiles == nil {
+		ctx.depFiles = make(map[string]string)
+	}
+	if ctx.sourceSnippets == nil {
+		ctx.sourceSnippets = make(map[string]string)
+	}
+	if ctx.exposedPorts == nil {
+		ctx.exposedPorts = make(map[string]int32)
+	}
+	return ctx
+}
+
+// scanCacheDir returns ~/.kindling/scan-cache.
+func scanCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".kindling", "scan-cache"), nil
+}
+
+// repoFingerprint hashes the path, size, and mtime of every file scanRepo
+// could read (skip-listed directories excluded), plus the --services scope.
+// It also returns the newest mtime seen so callers can reject cache entries
+// older than any file.
+func repoFingerprint(repoPath string, services []string) (string, time.Time, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d\x00%s\x00%s\n", scanCacheVersion, repoPath, strings.Join(services, ","))
+	var newest time.Time
+
+	err := filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(repoPath, path)
+		if d.IsDir() {
+			if rel != "." && scanSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", rel, info.Size(), info.ModTime().UnixNano())
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), newest, nil
+}
+
+// scanRepoCached returns scanRepo(repoPath, services...), reusing a cached result when
+// the repo is unchanged. Cache failures are never fatal: the repo is simply
+// scanned again. The bool reports whether the cache was hit.
+func scanRepoCached(repoPath string, useCache bool, services ...string) (*repoContext, bool, error) {
+	if !useCache {
+		ctx, err := scanRepo(repoPath, services...)
+		return ctx, false, err
+	}
+
+	dir, err := scanCacheDir()
+	if err != nil {
+		ctx, err := scanRepo(repoPath, services...)
+		return ctx, false, err
+	}
+	return scanRepoWithCache(repoPath, dir, services...)
+}
+
+// scanRepoWithCache implements scanRepoCached against an explicit cache
+// directory.
+func scanRepoWithCache(repoPath, cacheDir string, services ...string) (*repoContext, bool, error) {
+	key, newest, err := repoFingerprint(repoPath, services)
+	if err != nil {
+		ctx, err := scanRepo(repoPath, services...)
+		return ctx, false, err
+	}
+	cachePath := filepath.Join(cacheDir, key+".json")
+
+	// A file touched after the entry was written means the fingerprint
+	// can't be trusted (e.g. mtime granularity); rescan.
+	if info, err := os.Stat(cachePath); err == nil && !newest.After(info.ModTime()) {
+		if data, err := os.ReadFile(cachePath); err == nil {
+			var e scanCacheEntry
+			if json.Unmarshal(data, &e) == nil && e.Version == scanCacheVersion {
+				return e.repoContext(), true, nil
+			}
+		}
+	}
+
+	ctx, err := scanRepo(repoPath, services...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if data, err := json.Marshal(newScanCacheEntry(ctx)); err == nil {
+		if os.MkdirAll(cacheDir, 0755) == nil {
+			_ = os.WriteFile(cachePath, data, 0644)
+		}
+	}
+	return ctx, false, nil
+}
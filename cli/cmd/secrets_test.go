@@ -83,3 +83,83 @@ func parseSecretsContent(content string) map[string]string {
 	}
 	return secrets
 }
+
+// ────────────────────────────────────────────────────────────────────────────
+// secrets import: .env parsing and filtering
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestParseDotEnv(t *testing.T) {
+	input := `# Stripe
+export STRIPE_API_KEY=sk_test_123
+SENTRY_DSN = https://abc@sentry.io/1  # prod project
+QUOTED="a \"b\" c\nd"
+LITERAL='raw \n #kept'
+MULTI="line one
+line two"
+EMPTY=
+STRIPE_API_KEY=sk_test_456
+`
+	got, err := parseDotEnv(input)
+	if err != nil {
+		t.Fatalf("parseDotEnv() error: %v", err)
+	}
+	want := []dotEnvEntry{
+		{"STRIPE_API_KEY", "sk_test_456"},
+		{"SENTRY_DSN", "https://abc@sentry.io/1"},
+		{"QUOTED", "a \"b\" c\nd"},
+		{"LITERAL", `raw \n #kept`},
+		{"MULTI", "line one\nline two"},
+		{"EMPTY", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseDotEnv() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseDotEnv_Errors(t *testing.T) {
+	for _, input := range []string{
+		"NOEQUALS\n",
+		"BAD KEY=x\n",
+		"OPEN=\"never closed\n",
+		"OPEN='never closed\n",
+	} {
+		if _, err := parseDotEnv(input); err == nil {
+			t.Errorf("parseDotEnv(%q) should fail", input)
+		}
+	}
+}
+
+func TestPlanSecretImport(t *testing.T) {
+	entries := []dotEnvEntry{
+		{"STRIPE_API_KEY", "sk"},
+		{"STRIPE_WEBHOOK_SECRET", "wh"},
+		{"DATABASE_URL", "postgres://"},
+		{"AWS_ACCESS_KEY_ID", "AKIA"},
+		{"SENTRY_DSN", ""},
+	}
+
+	imports, skipped, err := planSecretImport(entries, nil, []string{"AWS_*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imports) != 2 || imports[0].key != "STRIPE_API_KEY" || imports[1].key != "STRIPE_WEBHOOK_SECRET" {
+		t.Errorf("imports = %v", imports)
+	}
+	if len(skipped) != 2 || !strings.HasPrefix(skipped[0], "DATABASE_URL") || !strings.HasPrefix(skipped[1], "SENTRY_DSN") {
+		t.Errorf("skipped = %v", skipped)
+	}
+
+	imports, _, _ = planSecretImport(entries, []string{"STRIPE_API_KEY", "AWS_*"}, nil)
+	if len(imports) != 2 || imports[0].key != "STRIPE_API_KEY" || imports[1].key != "AWS_ACCESS_KEY_ID" {
+		t.Errorf("--only imports = %v", imports)
+	}
+
+	if _, _, err := planSecretImport(entries, []string{"[bad"}, nil); err == nil {
+		t.Error("invalid pattern should fail")
+	}
+}
@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -16,28 +21,63 @@ var statusCmd = &cobra.Command{
   • Cluster info and node status
   • kindling operator health
   • CI runner pools
-  • Dev staging environments and their dependencies`,
+  • Dev staging environments and their dependencies
+
+With -o json or --watch, only the environments are shown: each
+DevStagingEnvironment's Ready condition, available replicas, URL, and
+per-dependency readiness.
+
+Examples:
+  kindling status
+  kindling status -o json
+  kindling status --watch`,
 	RunE: runStatus,
 }
 
-var statusProvider string
+var (
+	statusProvider string
+	statusOutput   string
+	statusWatch    bool
+	statusInterval time.Duration
+)
 
 func init() {
 	statusCmd.Flags().StringVar(&statusProvider, "ci-provider", "", "CI provider (github, gitlab)")
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", "", "Output format for environments: json")
+	statusCmd.Flags().BoolVarP(&statusWatch, "watch", "w", false, "Refresh the environment summary until interrupted")
+	statusCmd.Flags().DurationVar(&statusInterval, "interval", 2*time.Second, "Refresh interval for --watch")
 	rootCmd.AddCommand(statusCmd)
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
+	if statusOutput != "" && statusOutput != "json" {
+		return fmt.Errorf("unsupported output format %q (use json)", statusOutput)
+	}
+	if statusWatch {
+		return watchEnvironments()
+	}
+	if statusOutput == "json" {
+		envs, err := fetchEnvStatuses()
+		if err != nil {
+			return err
+		}
+		return printEnvStatusesJSON(envs)
+	}
+
 	// ── Cluster ─────────────────────────────────────────────────
 	header("Cluster")
 
-	if !clusterExists(clusterName) {
-		fail(fmt.Sprintf("Kind cluster %q not found. Run: kindling init", clusterName))
-		return nil
+	if usingKindCluster() {
+		if !clusterExists(clusterName) {
+			fail(fmt.Sprintf("Kind cluster %q not found. Run: kindling init", clusterName))
+			return nil
+		}
+		success(fmt.Sprintf("Kind cluster %q exists", clusterName))
+	} else {
+		success(fmt.Sprintf("Using kube context %q", kindContext()))
 	}
-	success(fmt.Sprintf("Kind cluster %q exists", clusterName))
 
-	nodesOut, err := runCapture("kubectl", "get", "nodes",
+	nodesOut, err := runCapture("kubectl", "--context", kindContext(), "get", "nodes",
 		"-o", "custom-columns=NAME:.metadata.name,STATUS:.status.conditions[-1].type,VERSION:.status.nodeInfo.kubeletVersion",
 		"--no-headers")
 	if err == nil && nodesOut != "" {
@@ -49,7 +89,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// ── Operator ────────────────────────────────────────────────
 	header("Operator")
 
-	operatorOut, err := runCapture("kubectl", "get", "deployment",
+	operatorOut, err := runCapture("kubectl", "--context", kindContext(), "get", "deployment",
 		"-n", "kindling-system",
 		"-o", "custom-columns=NAME:.metadata.name,READY:.status.readyReplicas,DESIRED:.spec.replicas,AGE:.metadata.creationTimestamp",
 		"--no-headers")
@@ -72,7 +112,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// ── Registry ────────────────────────────────────────────────
 	header("Registry")
 
-	regOut, err := runCapture("kubectl", "get", "deployment/registry",
+	regOut, err := runCapture("kubectl", "--context", kindContext(), "get", "deployment/registry",
 		"-o", "custom-columns=READY:.status.readyReplicas,DESIRED:.spec.replicas",
 		"--no-headers")
 	if err != nil {
@@ -84,7 +124,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// ── Ingress ─────────────────────────────────────────────────
 	header("Ingress Controller")
 
-	ingOut, err := runCapture("kubectl", "get", "pods",
+	ingOut, err := runCapture("kubectl", "--context", kindContext(), "get", "pods",
 		"-n", "traefik",
 		"-l", "app.kubernetes.io/name=traefik",
 		"-o", "custom-columns=NAME:.metadata.name,STATUS:.status.phase,RESTARTS:.status.containerStatuses[0].restartCount",
@@ -102,7 +142,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	labels := statusProviderObj.CLILabels()
 	header(labels.CRDListHeader)
 
-	rpOut, err := runCapture("kubectl", "get", labels.CRDPlural,
+	rpOut, err := runCapture("kubectl", "--context", kindContext(), "get", labels.CRDPlural,
 		"-o", "custom-columns=NAME:.metadata.name,USERNAME:.spec.githubUsername,REPO:.spec.repository",
 		"--no-headers")
 	if err != nil || rpOut == "" || strings.Contains(rpOut, "No resources") {
@@ -117,7 +157,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 		// Show runner deployment status
 		fmt.Println()
-		runnerDeploys, _ := runCapture("kubectl", "get", "deployments",
+		runnerDeploys, _ := runCapture("kubectl", "--context", kindContext(), "get", "deployments",
 			"-l", "app.kubernetes.io/managed-by=kindling",
 			"-o", "custom-columns=NAME:.metadata.name,READY:.status.readyReplicas,DESIRED:.spec.replicas",
 			"--no-headers")
@@ -134,19 +174,11 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// ── Dev Staging Environments ────────────────────────────────
 	header("Dev Staging Environments")
 
-	dseOut, err := runCapture("kubectl", "get", "devstagingenvironments",
-		"-o", "custom-columns=NAME:.metadata.name,IMAGE:.spec.deployment.image,PORT:.spec.deployment.port,INGRESS:.spec.ingress.host",
-		"--no-headers")
-	if err != nil || dseOut == "" || strings.Contains(dseOut, "No resources") {
+	envs, err := fetchEnvStatuses()
+	if err != nil || len(envs) == 0 {
 		fmt.Printf("    %sNone — run:%s kindling deploy -f <file.yaml>\n", colorDim, colorReset)
 	} else {
-		for _, line := range strings.Split(dseOut, "\n") {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-			fmt.Printf("    📦 %s\n", line)
-		}
+		printEnvStatuses(envs)
 	}
 
 	// ── Active Dev Sessions ─────────────────────────────────────
@@ -180,7 +212,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// ── Deployments ─────────────────────────────────────────────
 	header("All Deployments")
 
-	depOut, _ := runCapture("kubectl", "get", "deployments",
+	depOut, _ := runCapture("kubectl", "--context", kindContext(), "get", "deployments",
 		"-o", "custom-columns=NAME:.metadata.name,READY:.status.readyReplicas,UP-TO-DATE:.status.updatedReplicas,AVAILABLE:.status.availableReplicas",
 		"--no-headers")
 	if depOut != "" {
@@ -195,7 +227,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// ── Unhealthy Pods ──────────────────────────────────────────
 	// Show CrashLoopBackOff / Error pods with their last log lines
 	// so the developer doesn't have to manually run kubectl logs.
-	crashPods, _ := runCapture("kubectl", "get", "pods",
+	crashPods, _ := runCapture("kubectl", "--context", kindContext(), "get", "pods",
 		"--field-selector=status.phase!=Running,status.phase!=Succeeded",
 		"-o", "custom-columns=NAME:.metadata.name,STATUS:.status.phase,REASON:.status.containerStatuses[0].state.waiting.reason",
 		"--no-headers")
@@ -215,7 +247,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			fmt.Printf("    %s❌ %s%s\n", colorRed, line, colorReset)
 
 			// Show last few log lines for this pod
-			logs, _ := runCapture("kubectl", "logs", podName, "--tail=10")
+			logs, _ := runCapture("kubectl", "--context", kindContext(), "logs", podName, "--tail=10")
 			if logs != "" {
 				for _, logLine := range strings.Split(logs, "\n") {
 					logLine = strings.TrimSpace(logLine)
@@ -231,7 +263,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// ── Ingress Routes ──────────────────────────────────────────
 	header("Ingress Routes")
 
-	ingRoutes, err := runCapture("kubectl", "get", "ingress",
+	ingRoutes, err := runCapture("kubectl", "--context", kindContext(), "get", "ingress",
 		"-o", "custom-columns=NAME:.metadata.name,HOST:.spec.rules[*].host,SERVICE:.spec.rules[*].http.paths[*].backend.service.name",
 		"--no-headers")
 	if err != nil || ingRoutes == "" || strings.Contains(ingRoutes, "No resources") {
@@ -277,3 +309,242 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	return nil
 }
+
+// ────────────────────────────────────────────────────────────────────────────
+// Environment summary (-o json, --watch)
+// ────────────────────────────────────────────────────────────────────────────
+
+// envStatus summarizes one DevStagingEnvironment.
+type envStatus struct {
+	Name              string              `json:"name"`
+	Namespace         string              `json:"namespace"`
+	Ready             bool                `json:"ready"`
+	Reason            string              `json:"reason,omitempty"`
+	Replicas          int32               `json:"replicas"`
+	AvailableReplicas int32               `json:"availableReplicas"`
+	URL               string              `json:"url,omitempty"`
+	Dependencies      []envDependencyInfo `json:"dependencies,omitempty"`
+}
+
+// envDependencyInfo is the readiness of one declared dependency.
+type envDependencyInfo struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	URL   string `json:"url,omitempty"`
+	UIURL string `json:"uiURL,omitempty"`
+}
+
+// fetchEnvStatuses reads every DevStagingEnvironment in the cluster along
+// with the operator-managed dependency Deployments.
+func fetchEnvStatuses() ([]envStatus, error) {
+	dseJSON, err := runCapture("kubectl", "--context", kindContext(), "get", "devstagingenvironments", "-A", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("cannot list DevStagingEnvironments: %w", err)
+	}
+	depJSON, err := runCapture("kubectl", "--context", kindContext(), "get", "deployments", "-A",
+		"-l", "app.kubernetes.io/managed-by=devstagingenvironment-operator", "-o", "json")
+	if err != nil {
+		depJSON = `{"items": []}`
+	}
+	return parseEnvStatuses([]byte(dseJSON), []byte(depJSON))
+}
+
+// parseEnvStatuses builds environment summaries from kubectl -o json lists
+// of DevStagingEnvironments and dependency Deployments. A dependency is
+// ready when its Deployment has at least one ready replica.
+func parseEnvStatuses(dseJSON, depJSON []byte) ([]envStatus, error) {
+	var dses struct {
+		Items []struct {
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Spec struct {
+				Deployment struct {
+					Replicas *int32 `json:"replicas"`
+				} `json:"deployment"`
+				Dependencies []struct {
+					Type       string `json:"type"`
+					Shared     bool   `json:"shared"`
+					SharedName string `json:"sharedName"`
+				} `json:"dependencies"`
+			} `json:"spec"`
+			Status struct {
+				AvailableReplicas int32             `json:"availableReplicas"`
+				DependencyURLs    map[string]string `json:"dependencyURLs"`
+				URL               string            `json:"url"`
+				Conditions        []struct {
+					Type    string `json:"type"`
+					Status  string `json:"status"`
+					Reason  string `json:"reason"`
+					Message string `json:"message"`
+				} `json:"conditions"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	var deploys struct {
+		Items []struct {
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Status struct {
+				ReadyReplicas int32 `json:"readyReplicas"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(dseJSON, &dses); err != nil {
+		return nil, fmt.Errorf("parse DevStagingEnvironments: %w", err)
+	}
+	if err := json.Unmarshal(depJSON, &deploys); err != nil {
+		return nil, fmt.Errorf("parse dependency Deployments: %w", err)
+	}
+
+	ready := make(map[string]bool)
+	for _, d := range deploys.Items {
+		ready[d.Metadata.Namespace+"/"+d.Metadata.Name] = d.Status.ReadyReplicas > 0
+	}
+
+	envs := make([]envStatus, 0, len(dses.Items))
+	for _, item := range dses.Items {
+		env := envStatus{
+			Name:              item.Metadata.Name,
+			Namespace:         item.Metadata.Namespace,
+			Replicas:          1,
+			AvailableReplicas: item.Status.AvailableReplicas,
+			URL:               item.Status.URL,
+			Reason:            "no status yet",
+		}
+		if r := item.Spec.Deployment.Replicas; r != nil {
+			env.Replicas = *r
+		}
+		for _, c := range item.Status.Conditions {
+			if c.Type != "Ready" {
+				continue
+			}
+			env.Ready = c.Status == "True"
+			env.Reason = ""
+			if !env.Ready {
+				env.Reason = c.Reason
+			}
+		}
+
+		for _, dep := range item.Spec.Dependencies {
+			name := dependencyResourceName(item.Metadata.Name, dep.Type, dep.Shared, dep.SharedName)
+			env.Dependencies = append(env.Dependencies, envDependencyInfo{
+				Type:  dep.Type,
+				Name:  name,
+				Ready: ready[item.Metadata.Namespace+"/"+name],
+				URL:   item.Status.DependencyURLs[dep.Type],
+				UIURL: item.Status.DependencyURLs[dep.Type+"-ui"],
+			})
+		}
+		envs = append(envs, env)
+	}
+
+	sort.Slice(envs, func(i, j int) bool {
+		if envs[i].Namespace != envs[j].Namespace {
+			return envs[i].Namespace < envs[j].Namespace
+		}
+		return envs[i].Name < envs[j].Name
+	})
+	return envs, nil
+}
+
+// dependencyResourceName mirrors the operator's naming of a dependency's
+// Deployment and Service.
+func dependencyResourceName(envName, depType string, shared bool, sharedName string) string {
+	if !shared {
+		return strings.ReplaceAll(envName, ".", "-") + "-" + depType
+	}
+	if sharedName != "" {
+		return sharedName
+	}
+	return "shared-" + depType
+}
+
+// printEnvStatuses renders environments with one line per dependency.
+func printEnvStatuses(envs []envStatus) {
+	for _, env := range envs {
+		name := env.Name
+		if env.Namespace != "" && env.Namespace != "default" {
+			name = env.Namespace + "/" + env.Name
+		}
+		state := fmt.Sprintf("%s✓ ready%s", colorGreen, colorReset)
+		if !env.Ready {
+			state = fmt.Sprintf("%s⏳ %s%s", colorYellow, env.Reason, colorReset)
+		}
+		url := env.URL
+		if url == "" {
+			url = colorDim + "no ingress" + colorReset
+		}
+		fmt.Printf("    📦 %-28s %d/%d  %s  %s\n", name, env.AvailableReplicas, env.Replicas, state, url)
+
+		for _, dep := range env.Dependencies {
+			icon := fmt.Sprintf("%s✓%s", colorGreen, colorReset)
+			if !dep.Ready {
+				icon = fmt.Sprintf("%s⏳%s", colorYellow, colorReset)
+			}
+			line := fmt.Sprintf("       %s %-14s %s%s%s", icon, dep.Type, colorDim, dep.URL, colorReset)
+			if dep.UIURL != "" {
+				line += fmt.Sprintf("  UI: %s%s%s", colorCyan, dep.UIURL, colorReset)
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+func printEnvStatusesJSON(envs []envStatus) error {
+	data, err := json.MarshalIndent(envs, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// watchEnvironments redraws the environment summary every statusInterval
+// until interrupted. With -o json, each refresh prints a fresh document.
+func watchEnvironments() error {
+	if statusInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+
+	for {
+		envs, err := fetchEnvStatuses()
+		if statusOutput == "json" {
+			if err != nil {
+				return err
+			}
+			if err := printEnvStatusesJSON(envs); err != nil {
+				return err
+			}
+		} else {
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("%sDev Staging Environments%s  %s(every %s — Ctrl-C to stop, %s)%s\n\n",
+				colorBold, colorReset, colorDim, statusInterval, time.Now().Format("15:04:05"), colorReset)
+			switch {
+			case err != nil:
+				fmt.Printf("    %s%v%s\n", colorRed, err, colorReset)
+			case len(envs) == 0:
+				fmt.Printf("    %sNone — run:%s kindling deploy -f <file.yaml>\n", colorDim, colorReset)
+			default:
+				printEnvStatuses(envs)
+			}
+		}
+
+		select {
+		case <-sigCh:
+			fmt.Println()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
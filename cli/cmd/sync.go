@@ -1,14 +1,23 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -52,6 +61,7 @@ type runtimeProfile struct {
 	Mode          restartMode   // How to restart
 	Signal        string        // Signal name for modeSignal (e.g. "HUP")
 	BuildCmd      string        // In-container build command for modeRebuild
+	BuildOutput   string        // Path BuildCmd writes the binary to, if fixed
 	LocalBuildFmt string        // Local cross-compile command (fmt template: %s=GOOS, %s=GOARCH, %s=output path)
 	WaitAfter     time.Duration // Grace period after restart
 	Interpreted   bool          // True if source-file sync alone is useful
@@ -237,7 +247,8 @@ var runtimeTable = map[string]runtimeProfile{
 	// ── Compiled languages ──────────────────────────────────
 	"go": {
 		Name: "Go", Mode: modeRebuild, Interpreted: false,
-		BuildCmd:      "go build -o /tmp/_kindling_bin . && cp /tmp/_kindling_bin /app/main",
+		BuildCmd:      "go build -o /tmp/_kindling_bin .",
+		BuildOutput:   "/tmp/_kindling_bin",
 		LocalBuildFmt: "CGO_ENABLED=0 GOOS=%s GOARCH=%s go build -o %s .",
 		WaitAfter:     3 * time.Second,
 	},
@@ -274,6 +285,22 @@ var runtimeTable = map[string]runtimeProfile{
 		BuildCmd:  "zig build",
 		WaitAfter: 2 * time.Second,
 	},
+	"swift": {
+		Name: "Swift", Mode: modeRebuild, Interpreted: false,
+		WaitAfter: 3 * time.Second,
+	},
+	"dart": {
+		Name: "Dart", Mode: modeRebuild, Interpreted: false,
+		WaitAfter: 2 * time.Second,
+	},
+	"scala": {
+		Name: "Scala", Mode: modeRebuild, Interpreted: false,
+		WaitAfter: 5 * time.Second,
+	},
+	"sbt": {
+		Name: "Scala (sbt)", Mode: modeRebuild, Interpreted: false,
+		WaitAfter: 5 * time.Second,
+	},
 }
 
 // ════════════════════════════════════════════════════════════════════
@@ -306,7 +333,7 @@ runtime:
     script in package.json.  Runs the build locally, then syncs the
     built assets (dist/) into the container — no restart needed.
 
-  COMPILED (Go, Rust, Java, C#, C/C++, Zig):
+  COMPILED (Go, Rust, Java, Scala, C#, C/C++, Zig, Swift, Dart):
     Auto-detected: builds locally with cross-compilation, syncs the
     binary into the container, and restarts the process.
     Use --build-cmd / --build-output to override the build step.
@@ -327,6 +354,9 @@ Examples:
   # Go service — auto-detected local cross-compile
   kindling sync -d gateway --restart --language go
 
+  # Rust service, no local toolchain — build inside the pod instead
+  kindling sync -d search --restart --container-build
+
   # Custom build command for compiled languages
   kindling sync -d gateway --restart \
     --build-cmd 'CGO_ENABLED=0 GOOS=linux GOARCH=arm64 go build -o ./bin/gateway .' \
@@ -338,30 +368,52 @@ Examples:
   # Sync into a custom container path and restart
   kindling sync -d orders --dest /opt/app/src --restart
 
+  # Find the deployment's namespace automatically
+  kindling sync -d orders --namespace-auto
+
   # Target a specific container in a multi-container pod
   kindling sync -d orders --container app --restart
 
+  # Sync, restart, then scale out to 3 replicas
+  kindling sync -d orders --restart --replicas 3
+
+  # Sync every target listed in ./kindling.sync.yaml
+  kindling sync --restart
+
+  # Sync one target from a manifest elsewhere
+  kindling sync --manifest dev/kindling.sync.yaml --target orders
+
   # Multi-service debugging: run sync in parallel terminals
   # Terminal 1 (primary service):
   kindling sync -d orders --restart --src ./services/orders
   # Terminal 2 (debug a dependency):
   kindling sync -d inventory --restart --src ./services/inventory`,
-	RunE: runSync,
+	PreRunE: validateSyncTargetFlags,
+	RunE:    runSync,
 }
 
 var (
-	syncDeployment  string
-	syncContainer   string
-	syncSrc         string
-	syncDest        string
-	syncNamespace   string
-	syncRestart     bool
-	syncOnce        bool
-	syncExclude     []string
-	syncDebounce    time.Duration
-	syncLanguage    string
-	syncBuildCmd    string
-	syncBuildOutput string
+	syncDeployment     string
+	syncContainer      string
+	syncSrc            string
+	syncDest           string
+	syncNamespace      string
+	syncRestart        bool
+	syncOnce           bool
+	syncBuildOnly      bool
+	syncDiff           bool
+	syncContainerBuild bool
+	syncExclude        []string
+	syncDebounce       time.Duration
+	syncLanguage       string
+	syncBuildCmd       string
+	syncBuildOutput    string
+	syncNSAuto         bool
+	syncReplicas       int
+	syncPreserveMode   bool
+	syncBuildTimeout   time.Duration
+	syncManifest       string
+	syncTargetName     string
 )
 
 // Default patterns to exclude from sync — starts from the shared skipDirNames
@@ -378,7 +430,7 @@ var defaultExcludes = append(append([]string{}, skipDirNames...),
 
 func init() {
 	syncCmd.Flags().StringVarP(&syncDeployment, "deployment", "d", "",
-		"Target deployment name (required)")
+		"Target deployment name (omit to use the targets in "+defaultSyncManifest+")")
 	syncCmd.Flags().StringVar(&syncContainer, "container", "",
 		"Container name (for multi-container pods)")
 	syncCmd.Flags().StringVar(&syncSrc, "src", ".",
@@ -387,10 +439,18 @@ func init() {
 		"Destination path inside the container")
 	syncCmd.Flags().StringVarP(&syncNamespace, "namespace", "n", "default",
 		"Kubernetes namespace")
+	syncCmd.Flags().BoolVar(&syncNSAuto, "namespace-auto", false,
+		"Discover the deployment's namespace if it isn't found in --namespace")
 	syncCmd.Flags().BoolVar(&syncRestart, "restart", false,
 		"Restart the app process after each sync batch (strategy auto-detected)")
 	syncCmd.Flags().BoolVar(&syncOnce, "once", false,
 		"Sync once and exit (no file watching)")
+	syncCmd.Flags().BoolVar(&syncBuildOnly, "build-only", false,
+		"Compiled languages: build locally, sync the binary, restart, print the pod name and exit")
+	syncCmd.Flags().BoolVar(&syncContainerBuild, "container-build", false,
+		"Compiled languages: build inside the container instead of locally (falls back if it has no compiler)")
+	syncCmd.Flags().BoolVar(&syncDiff, "diff", false,
+		"List files that differ from the container's copy and exit without syncing")
 	syncCmd.Flags().StringArrayVar(&syncExclude, "exclude", nil,
 		"Additional patterns to exclude (repeatable)")
 	syncCmd.Flags().DurationVar(&syncDebounce, "debounce", 500*time.Millisecond,
@@ -400,8 +460,17 @@ func init() {
 	syncCmd.Flags().StringVar(&syncBuildCmd, "build-cmd", "",
 		"Local build command for compiled languages (e.g. 'go build -o ./bin/app .')")
 	syncCmd.Flags().StringVar(&syncBuildOutput, "build-output", "",
-		"Path to built artifact to sync (e.g. './bin/app')")
-	_ = syncCmd.MarkFlagRequired("deployment")
+		"Path to built artifact to sync (e.g. './bin/app'; relative to --dest with --container-build)")
+	syncCmd.Flags().IntVar(&syncReplicas, "replicas", 0,
+		"Scale the deployment to N replicas after the initial sync (N > 1 restarts by rollout)")
+	syncCmd.Flags().BoolVar(&syncPreserveMode, "preserve-mode", false,
+		"Reapply local file permissions in the container after each sync (extra kubectl exec per batch)")
+	syncCmd.Flags().DurationVar(&syncBuildTimeout, "build-timeout", 10*time.Minute,
+		"Kill a local build that runs longer than this (0 disables the limit)")
+	syncCmd.Flags().StringVar(&syncManifest, "manifest", "",
+		"Read sync targets from this file (default: ./"+defaultSyncManifest+" when -d is omitted)")
+	syncCmd.Flags().StringVar(&syncTargetName, "target", "",
+		"Sync only the named target from the manifest")
 	rootCmd.AddCommand(syncCmd)
 }
 
@@ -430,11 +499,12 @@ func detectRuntime(pod, namespace, container string) (runtimeProfile, string) {
 		cName = ""
 	}
 	if cName != "" {
-		cID, _ := runCapture("docker", "exec", clusterName+"-control-plane",
+		node := kindNodeForPod(pod, namespace)
+		cID, _ := runCapture("docker", "exec", node,
 			"crictl", "ps", "--name", cName, "-q")
 		cID = strings.TrimSpace(cID)
 		if cID != "" {
-			inspectOut, _ := runCapture("docker", "exec", clusterName+"-control-plane",
+			inspectOut, _ := runCapture("docker", "exec", node,
 				"crictl", "inspect", "--output", "json", cID)
 			if inspectOut != "" {
 				var inspectData struct {
@@ -635,6 +705,9 @@ func detectLanguageFromSource(srcDir string) string {
 		{"Gemfile", "ruby"},
 		{"mix.exs", "elixir"},
 		{"composer.json", "php"},
+		{"Package.swift", "swift"},
+		{"pubspec.yaml", "dart"},
+		{"build.sbt", "scala"},
 	}
 	for _, m := range markers {
 		if _, err := os.Stat(filepath.Join(srcDir, m.file)); err == nil {
@@ -730,6 +803,71 @@ func detectNginxHtmlRoot(pod, namespace, container string) string {
 	return "/usr/share/nginx/html"
 }
 
+// runLocalBuild runs a shell build command in dir, streaming its combined
+// output to stderr as it arrives so long Java/Rust/.NET builds don't look
+// frozen. The command runs in its own process group; the whole group is
+// killed when timeout (if non-zero) expires or on Ctrl-C, so compiler
+// daemons and child tools don't outlive it. The command inherits the
+// environment — build commands set GOOS/GOARCH themselves.
+func runLocalBuild(dir, command string, timeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	buildExec := exec.CommandContext(ctx, "sh", "-c", command)
+	buildExec.Dir = dir
+	buildExec.Env = os.Environ()
+	buildExec.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	buildExec.Cancel = func() error {
+		return syscall.Kill(-buildExec.Process.Pid, syscall.SIGKILL)
+	}
+	// A grandchild that escaped the group can hold the output pipe open;
+	// don't wait on it forever once the build itself has exited.
+	buildExec.WaitDelay = 2 * time.Second
+	out := &indentWriter{w: os.Stderr, prefix: "     " + colorDim + "│" + colorReset + " "}
+	buildExec.Stdout = out
+	buildExec.Stderr = out
+
+	err := buildExec.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s and was killed — raise --build-timeout (0 disables it)", timeout)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted")
+	}
+	return err
+}
+
+// indentWriter prefixes every line written through it, so streamed build
+// output sits visibly under the step that started it.
+type indentWriter struct {
+	w       io.Writer
+	prefix  string
+	midLine bool
+}
+
+func (iw *indentWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if !iw.midLine {
+			if _, err := io.WriteString(iw.w, iw.prefix); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := iw.w.Write(line); err != nil {
+			return 0, err
+		}
+		iw.midLine = line[len(line)-1] != '\n'
+	}
+	return len(p), nil
+}
+
 // restartViaFrontendBuild builds a frontend project locally and syncs the
 // built assets into the container's static file directory.
 // No process restart is needed — static file servers serve new content immediately.
@@ -744,10 +882,7 @@ func restartViaFrontendBuild(pod, namespace, container, srcDir string, profile r
 	if _, err := os.Stat(nmPath); err != nil {
 		step("📦", "Installing dependencies...")
 		installCmd := pkgMgr + " install"
-		installExec := exec.Command("sh", "-c", installCmd)
-		installExec.Dir = srcDir
-		if out, err := installExec.CombinedOutput(); err != nil {
-			warn(fmt.Sprintf("Dependency install failed:\n%s", strings.TrimSpace(string(out))))
+		if err := runLocalBuild(srcDir, installCmd, syncBuildTimeout); err != nil {
 			return pod, fmt.Errorf("dependency install failed: %w", err)
 		}
 		success("Dependencies installed")
@@ -756,11 +891,7 @@ func restartViaFrontendBuild(pod, namespace, container, srcDir string, profile r
 	// Build
 	buildCmd := pkgMgr + " run build"
 	step("🔨", fmt.Sprintf("Building: %s", buildCmd))
-	buildExec := exec.Command("sh", "-c", buildCmd)
-	buildExec.Dir = srcDir
-	out, err := buildExec.CombinedOutput()
-	if err != nil {
-		warn(fmt.Sprintf("Build failed:\n%s", strings.TrimSpace(string(out))))
+	if err := runLocalBuild(srcDir, buildCmd, syncBuildTimeout); err != nil {
 		return pod, fmt.Errorf("frontend build failed: %w", err)
 	}
 	success("Build complete")
@@ -888,6 +1019,60 @@ func findPodForDeployment(deployment, namespace string) (string, error) {
 	return "", fmt.Errorf("no running pod found for deployment %q in namespace %q", deployment, namespace)
 }
 
+// deploymentExists reports whether deployment/<name> exists in the namespace.
+func deploymentExists(deployment, namespace string) bool {
+	_, err := runCapture("kubectl", "get", fmt.Sprintf("deployment/%s", deployment),
+		"-n", namespace, "--context", kindContext(), "-o", "name")
+	return err == nil
+}
+
+// discoverDeploymentNamespace returns the namespace to use for a deployment.
+// If the deployment exists in the given namespace, that namespace is returned
+// unchanged. Otherwise all namespaces are searched: exactly one match is
+// used, multiple matches produce an error listing the candidates.
+func discoverDeploymentNamespace(deployment, namespace string) (string, error) {
+	if deploymentExists(deployment, namespace) {
+		return namespace, nil
+	}
+	out, err := runCapture("kubectl", "get", "deployments", "-A",
+		"--field-selector", "metadata.name="+deployment,
+		"--context", kindContext(),
+		"-o", `jsonpath={range .items[*]}{.metadata.namespace}{"\n"}{end}`)
+	if err != nil {
+		return "", fmt.Errorf("cannot search namespaces for deployment %q: %s", deployment, out)
+	}
+	return pickDeploymentNamespace(deployment, namespace, parseNamespaceList(out))
+}
+
+// parseNamespaceList splits newline-separated kubectl output into a
+// de-duplicated list of namespace names.
+func parseNamespaceList(out string) []string {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, line := range strings.Split(out, "\n") {
+		ns := strings.TrimSpace(line)
+		if ns == "" || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces
+}
+
+// pickDeploymentNamespace chooses a namespace from the discovered candidates.
+func pickDeploymentNamespace(deployment, namespace string, candidates []string) (string, error) {
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("deployment %q not found in namespace %q or any other namespace", deployment, namespace)
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("deployment %q exists in multiple namespaces (%s) — pass -n to choose one",
+			deployment, strings.Join(candidates, ", "))
+	}
+}
+
 // getDeploymentRevision returns the current revision annotation for a deployment.
 // Used to snapshot the revision before sync so we can rollback on stop.
 func getDeploymentRevision(deployment, namespace string) string {
@@ -953,25 +1138,305 @@ func syncDir(pod, namespace, localDir, containerDest, container string) error {
 	return err
 }
 
+// containerPath maps a file under srcDir to its path under dest in the
+// container. The path relative to srcDir is returned too, for display and
+// exclude matching.
+func containerPath(srcDir, localPath, dest string) (relPath, destPath string) {
+	relPath, _ = filepath.Rel(srcDir, localPath)
+	destPath = strings.ReplaceAll(filepath.Join(dest, relPath), "\\", "/")
+	return relPath, destPath
+}
+
+// ════════════════════════════════════════════════════════════════════
+// Diff (--diff)
+// ════════════════════════════════════════════════════════════════════
+
+// syncDiffBatch caps how many paths go into a single sha256sum exec so the
+// command line stays well under ARG_MAX.
+const syncDiffBatch = 200
+
+// localChecksums hashes every non-excluded file under srcDir, keyed by the
+// path it syncs to in the container.
+func localChecksums(srcDir, dest string, excludes []string) (map[string]string, error) {
+	sums := make(map[string]string)
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == srcDir {
+			return nil
+		}
+		relPath, destPath := containerPath(srcDir, path, dest)
+		if shouldExclude(relPath, excludes) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		sums[destPath] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	return sums, err
+}
+
+// localFileModes returns the permission bits of every non-excluded file
+// under srcDir, keyed by the path it syncs to in the container.
+func localFileModes(srcDir, dest string, excludes []string) (map[string]os.FileMode, error) {
+	modes := make(map[string]os.FileMode)
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == srcDir {
+			return nil
+		}
+		relPath, destPath := containerPath(srcDir, path, dest)
+		if shouldExclude(relPath, excludes) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			modes[destPath] = info.Mode().Perm()
+		}
+		return nil
+	})
+	return modes, err
+}
+
+// chmodCommands groups container paths by mode into chmod argument lists of
+// at most syncDiffBatch paths each, so a typical tree (644 and 755) costs
+// one or two execs. Commands are ordered by mode and then path.
+func chmodCommands(modes map[string]os.FileMode) [][]string {
+	byMode := make(map[os.FileMode][]string)
+	for path, mode := range modes {
+		byMode[mode] = append(byMode[mode], path)
+	}
+	order := make([]os.FileMode, 0, len(byMode))
+	for mode := range byMode {
+		order = append(order, mode)
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+
+	var cmds [][]string
+	for _, mode := range order {
+		paths := byMode[mode]
+		sort.Strings(paths)
+		for start := 0; start < len(paths); start += syncDiffBatch {
+			end := min(start+syncDiffBatch, len(paths))
+			cmds = append(cmds, append([]string{"chmod", fmt.Sprintf("%o", mode)}, paths[start:end]...))
+		}
+	}
+	return cmds
+}
+
+// applyFileModes reapplies local permission bits to synced files. kubectl cp
+// extracts with the container's umask and user, which can strip the
+// executable bit from scripts.
+func applyFileModes(pod, namespace, container string, modes map[string]os.FileMode) error {
+	for _, chmod := range chmodCommands(modes) {
+		args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+		if container != "" {
+			args = append(args, "-c", container)
+		}
+		args = append(append(args, "--"), chmod...)
+		if out, err := runCapture("kubectl", args...); err != nil {
+			return fmt.Errorf("chmod failed in the container: %s", strings.TrimSpace(out))
+		}
+	}
+	return nil
+}
+
+// remoteOwner returns the uid:gid owning path in the container, or "" when
+// it doesn't exist or stat is unavailable.
+func remoteOwner(pod, namespace, container, path string) string {
+	args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+	if container != "" {
+		args = append(args, "-c", container)
+	}
+	args = append(args, "--", "stat", "-c", "%u:%g", path)
+	out, err := runCapture("kubectl", args...)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// remoteFiles lists the non-excluded files under dest in the container.
+func remoteFiles(pod, namespace, container, dest string, excludes []string) ([]string, error) {
+	args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+	if container != "" {
+		args = append(args, "-c", container)
+	}
+	args = append(args, "--", "find", dest, "-type", "f")
+	out, err := runCapture("kubectl", args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list %s in the container (needs find): %s", dest, out)
+	}
+
+	prefix := strings.TrimRight(dest, "/") + "/"
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		if rel := strings.TrimPrefix(line, prefix); rel != line && !shouldExclude(rel, excludes) {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// remoteChecksums runs sha256sum on paths in the container, in batches.
+func remoteChecksums(pod, namespace, container string, paths []string) (map[string]string, error) {
+	sums := make(map[string]string, len(paths))
+	for start := 0; start < len(paths); start += syncDiffBatch {
+		end := min(start+syncDiffBatch, len(paths))
+		args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+		if container != "" {
+			args = append(args, "-c", container)
+		}
+		args = append(append(args, "--", "sha256sum"), paths[start:end]...)
+		out, err := runCapture("kubectl", args...)
+		// sha256sum exits non-zero if any one file vanished; keep the rest.
+		parsed := parseSha256sum(out)
+		if err != nil && len(parsed) == 0 {
+			return nil, fmt.Errorf("sha256sum failed in the container: %s", out)
+		}
+		for p, sum := range parsed {
+			sums[p] = sum
+		}
+	}
+	return sums, nil
+}
+
+// parseSha256sum parses sha256sum output ("<hex>  <path>", or "<hex> *<path>"
+// in binary mode) into a path → checksum map. Other lines are ignored.
+func parseSha256sum(out string) map[string]string {
+	sums := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		sum, path, ok := strings.Cut(line, " ")
+		if !ok || len(sum) != sha256.Size*2 || len(path) < 2 {
+			continue
+		}
+		sums[path[1:]] = sum
+	}
+	return sums
+}
+
+// diffChecksums compares local and container checksums (both keyed by
+// container path) and returns the sorted paths that only exist locally,
+// differ, and only exist in the container.
+func diffChecksums(local, remote map[string]string) (added, changed, deleted []string) {
+	for p, sum := range local {
+		remoteSum, ok := remote[p]
+		switch {
+		case !ok:
+			added = append(added, p)
+		case remoteSum != sum:
+			changed = append(changed, p)
+		}
+	}
+	for p := range remote {
+		if _, ok := local[p]; !ok {
+			deleted = append(deleted, p)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(changed)
+	sort.Strings(deleted)
+	return added, changed, deleted
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Restart strategies
 // ════════════════════════════════════════════════════════════════════
 
-// restartViaSignal sends a signal to PID 1 for graceful reload.
+// signalReloadTimeout bounds how long restartViaSignal waits for PID 1's
+// workers to be replaced before deciding the signal was ignored.
+const signalReloadTimeout = 10 * time.Second
+
+// restartViaSignal sends a signal to PID 1 for graceful reload, then checks
+// that the reload actually happened.  It returns an error — so the caller
+// falls back to the wrapper — when PID 1 is a shell (the signal would never
+// reach the server) or when PID 1's workers are not replaced in time.
 // Used by: uvicorn, gunicorn, Puma, Nginx, Apache, Caddy.
 func restartViaSignal(pod, namespace, container, sig string) error {
+	podExec := func(cmd ...string) (string, error) {
+		args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+		if container != "" {
+			args = append(args, "-c", container)
+		}
+		return runCapture("kubectl", append(append(args, "--"), cmd...)...)
+	}
+
+	raw, _ := podExec("cat", "/proc/1/cmdline")
+	if cmdline := strings.TrimSpace(strings.ReplaceAll(raw, "\x00", " ")); isShellProcess(cmdline) {
+		return fmt.Errorf("PID 1 is a shell (%s), not the reloadable server", cmdline)
+	}
+
+	// Workers are PID 1's children; HUP/USR1/USR2 reloads replace them.
+	// The children file needs CONFIG_PROC_CHILDREN — when it's unreadable,
+	// or PID 1 has no workers (Caddy), the signal is trusted as before.
+	before, childErr := podExec("cat", "/proc/1/task/1/children")
+
 	step("📡", fmt.Sprintf("Sending SIG%s to PID 1 for graceful reload", sig))
-	args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
-	if container != "" {
-		args = append(args, "-c", container)
+	if _, err := podExec("kill", fmt.Sprintf("-%s", sig), "1"); err != nil {
+		return err
 	}
-	args = append(args, "--", "kill", fmt.Sprintf("-%s", sig), "1")
-	_, err := runSilent("kubectl", args...)
-	return err
+
+	if childErr != nil || len(strings.Fields(before)) == 0 {
+		return nil
+	}
+	for deadline := time.Now().Add(signalReloadTimeout); time.Now().Before(deadline); {
+		time.Sleep(500 * time.Millisecond)
+		after, err := podExec("cat", "/proc/1/task/1/children")
+		if err != nil || workersReplaced(before, after) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no worker was replaced within %s — SIG%s appears to have been ignored", signalReloadTimeout, sig)
+}
+
+// isShellProcess reports whether a /proc/<pid>/cmdline belongs to a shell
+// (including kindling's own restart wrapper), which won't forward signals.
+func isShellProcess(cmdline string) bool {
+	fields := strings.Fields(cmdline)
+	if len(fields) == 0 {
+		return false
+	}
+	switch filepath.Base(fields[0]) {
+	case "sh", "bash", "ash", "dash", "zsh", "busybox":
+		return true
+	}
+	return strings.Contains(cmdline, ".kindling-sync-wrapper")
+}
+
+// workersReplaced reports whether any of the PIDs listed in before (a
+// /proc/<pid>/task/<pid>/children snapshot) is gone from after.
+func workersReplaced(before, after string) bool {
+	current := make(map[string]bool)
+	for _, pid := range strings.Fields(after) {
+		current[pid] = true
+	}
+	for _, pid := range strings.Fields(before) {
+		if !current[pid] {
+			return true
+		}
+	}
+	return false
 }
 
 // patchDeploymentWrapper patches the deployment command to use a shell
-// restart-loop wrapper.  Returns the new pod name after rollout.
+// restart-loop wrapper.  Returns the new pod name after rollout.  If the
+// patched pod never rolls out (e.g. the wrapper mangled the command), the
+// original command is restored and an error is returned.
 func patchDeploymentWrapper(deployment, pod, namespace, container string) (string, error) {
 	step("🔧", "Patching deployment with restart wrapper")
 
@@ -985,9 +1450,21 @@ func patchDeploymentWrapper(deployment, pod, namespace, container string) (strin
 		`touch /tmp/.kindling-sync-wrapper && echo 1 > /tmp/.kindling-sync-wrapper && while true; do %s & PID=$!; echo $PID > /tmp/.kindling-app-pid; wait $PID; echo "Process exited, restarting..."; sleep 1; done`,
 		origCmd)
 
+	// The wrapper keeps its marker and PID files in /tmp, which a
+	// readOnlyRootFilesystem container can't write to.
+	tmpEmptyDir := isReadOnlyRootfs(pod, namespace, container, "/tmp")
+	if tmpEmptyDir {
+		step("💾", "Read-only root filesystem — mounting an emptyDir at /tmp for the wrapper")
+	}
+
 	cName := containerNameForDeployment(deployment, namespace, container)
-	patch := fmt.Sprintf(`{"spec":{"template":{"spec":{"containers":[{"name":"%s","command":["sh","-c","%s"]}]}}}}`,
-		cName, strings.ReplaceAll(wrapperScript, `"`, `\"`))
+	patch := wrapperPatch(cName, wrapperScript, tmpEmptyDir)
+
+	// Snapshot the spec's command (not origCmd, which may come from the
+	// image) so a failed rollout can put back exactly what was there.
+	specCmd, _ := runCapture("kubectl", "get", fmt.Sprintf("deployment/%s", deployment),
+		"-n", namespace, "--context", kindContext(),
+		"-o", fmt.Sprintf(`jsonpath={.spec.template.spec.containers[?(@.name=="%s")].command}`, cName))
 
 	if err := run("kubectl", "patch", fmt.Sprintf("deployment/%s", deployment),
 		"-n", namespace, "--context", kindContext(),
@@ -996,8 +1473,19 @@ func patchDeploymentWrapper(deployment, pod, namespace, container string) (strin
 	}
 
 	step("⏳", "Waiting for patched pod to roll out...")
-	_ = run("kubectl", "rollout", "status", fmt.Sprintf("deployment/%s", deployment),
-		"-n", namespace, "--context", kindContext(), "--timeout=90s")
+	if err := run("kubectl", "rollout", "status", fmt.Sprintf("deployment/%s", deployment),
+		"-n", namespace, "--context", kindContext(), "--timeout=90s"); err != nil {
+		warn("Patched pod did not become ready — restoring the original command")
+		if revertErr := run("kubectl", "patch", fmt.Sprintf("deployment/%s", deployment),
+			"-n", namespace, "--context", kindContext(),
+			"--type=strategic", "-p", wrapperRevertPatch(cName, specCmd, tmpEmptyDir)); revertErr != nil {
+			return pod, fmt.Errorf("wrapper rollout failed (%v) and restoring deployment/%s also failed: %w", err, deployment, revertErr)
+		}
+		_ = run("kubectl", "rollout", "status", fmt.Sprintf("deployment/%s", deployment),
+			"-n", namespace, "--context", kindContext(), "--timeout=90s")
+		return pod, fmt.Errorf("the restart wrapper broke deployment/%s, so it was reverted — the command %q may not survive `sh -c` "+
+			"(check its quoting, or run `kubectl logs deployment/%s --previous`): %w", deployment, origCmd, deployment, err)
+	}
 
 	// Brief wait for old pod termination to avoid stale pod lookup
 	time.Sleep(2 * time.Second)
@@ -1010,6 +1498,38 @@ func patchDeploymentWrapper(deployment, pod, namespace, container string) (strin
 	return newPod, nil
 }
 
+// wrapperPatch returns the strategic merge patch that runs container under
+// the restart-loop script.  With tmpEmptyDir set it also mounts an emptyDir
+// at /tmp so the wrapper can write its marker and PID files.
+func wrapperPatch(container, script string, tmpEmptyDir bool) string {
+	escaped := strings.ReplaceAll(script, `"`, `\"`)
+	if !tmpEmptyDir {
+		return fmt.Sprintf(`{"spec":{"template":{"spec":{"containers":[{"name":"%s","command":["sh","-c","%s"]}]}}}}`,
+			container, escaped)
+	}
+	return fmt.Sprintf(`{"spec":{"template":{"spec":{"containers":[{"name":"%s","command":["sh","-c","%s"],`+
+		`"volumeMounts":[{"name":"kindling-tmp","mountPath":"/tmp"}]}],"volumes":[{"name":"kindling-tmp","emptyDir":{}}]}}}}`,
+		container, escaped)
+}
+
+// wrapperRevertPatch returns the strategic merge patch that restores
+// container's command to specCmd, the JSON array read from the spec before
+// patching.  An empty snapshot means the image's ENTRYPOINT was in use, so
+// the override is removed.  tmpEmptyDir must match the wrapperPatch being
+// undone so its /tmp mount and volume are deleted too.
+func wrapperRevertPatch(container, specCmd string, tmpEmptyDir bool) string {
+	command := strings.TrimSpace(specCmd)
+	if command == "" || command == "[]" {
+		command = "null"
+	}
+	if !tmpEmptyDir {
+		return fmt.Sprintf(`{"spec":{"template":{"spec":{"containers":[{"name":"%s","command":%s}]}}}}`, container, command)
+	}
+	return fmt.Sprintf(`{"spec":{"template":{"spec":{"containers":[{"name":"%s","command":%s,`+
+		`"volumeMounts":[{"mountPath":"/tmp","$patch":"delete"}]}],"volumes":[{"name":"kindling-tmp","$patch":"delete"}]}}}}`,
+		container, command)
+}
+
 // killAppChild kills the app child process (not PID 1 sh) so the wrapper
 // loop respawns it with the updated files.
 func killAppChild(pod, namespace, container string) {
@@ -1047,6 +1567,9 @@ func restartViaWrapper(pod, namespace, container, srcDir, dest string) (string,
 
 	// Sync files
 	if srcDir != "" {
+		if isReadOnlyRootfs(pod, namespace, container, dest) {
+			return pod, readOnlyRootfsError(dest)
+		}
 		step("📦", "Syncing files into container")
 		if err := syncDir(pod, namespace, srcDir, dest, container); err != nil {
 			return pod, fmt.Errorf("sync failed: %w", err)
@@ -1068,6 +1591,14 @@ func restartViaRebuild(pod, namespace, container, srcDir, dest string, profile r
 		return pod, err
 	}
 
+	if syncContainerBuild {
+		reason := containerBuildUnavailable(pod, namespace, container, profile)
+		if reason == "" {
+			return restartViaContainerBuild(deployment, pod, namespace, container, srcDir, dest, profile)
+		}
+		warn(fmt.Sprintf("%s — falling back to a local build", reason))
+	}
+
 	// ── Determine build command and output path ────────────────
 	buildCmd := syncBuildCmd
 	buildOutput := syncBuildOutput
@@ -1103,12 +1634,7 @@ func restartViaRebuild(pod, namespace, container, srcDir, dest string, profile r
 
 	// ── Build locally ──────────────────────────────────────────
 	step("🔨", fmt.Sprintf("Building locally: %s", buildCmd))
-	buildExec := exec.Command("sh", "-c", buildCmd)
-	buildExec.Dir = srcDir
-	buildExec.Env = os.Environ() // inherit env; command itself sets GOOS/GOARCH
-	out, err := buildExec.CombinedOutput()
-	if err != nil {
-		warn(fmt.Sprintf("Local build failed:\n%s", strings.TrimSpace(string(out))))
+	if err := runLocalBuild(srcDir, buildCmd, syncBuildTimeout); err != nil {
 		return pod, fmt.Errorf("local build failed: %w", err)
 	}
 	success("Build complete")
@@ -1148,31 +1674,18 @@ func restartViaRebuild(pod, namespace, container, srcDir, dest string, profile r
 	}
 
 	// ── Detect binary destination inside the container ─────────
-	binDest := dest
-	// Try to find the actual binary path.
-	// If the wrapper is applied, extract the inner command name and resolve it.
-	origCmd := readContainerCommand(deployment, pod, namespace, container)
-	if origCmd != "" {
-		innerBin := extractInnerBinaryFromWrapper(origCmd)
-		if innerBin != "" {
-			if strings.HasPrefix(innerBin, "/") {
-				// Already an absolute path
-				binDest = innerBin
-			} else {
-				// Resolve via `command -v` inside the container (more portable than `which`)
-				resolveArgs := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
-				if container != "" {
-					resolveArgs = append(resolveArgs, "-c", container)
-				}
-				resolveArgs = append(resolveArgs, "--", "sh", "-c", fmt.Sprintf("command -v %s", innerBin))
-				if resolved, err := runCapture("kubectl", resolveArgs...); err == nil && strings.TrimSpace(resolved) != "" {
-					binDest = strings.TrimSpace(resolved)
-				} else {
-					// Last resort: assume binary is under dest dir
-					binDest = filepath.Join(dest, innerBin)
-				}
-			}
-		}
+	binDest := resolveBinDest(deployment, pod, namespace, container, dest)
+
+	if isReadOnlyRootfs(pod, namespace, container, filepath.Dir(binDest)) {
+		return pod, readOnlyRootfsError(filepath.Dir(binDest))
+	}
+
+	// kubectl cp as root keeps the uid from the local archive, which a
+	// non-root app user may not be able to replace next time; remember
+	// the current owner so it can be restored.
+	var owner string
+	if syncPreserveMode {
+		owner = remoteOwner(pod, namespace, container, binDest)
 	}
 
 	// ── Copy the binary into the container ─────────────────────
@@ -1195,6 +1708,24 @@ func restartViaRebuild(pod, namespace, container, srcDir, dest string, profile r
 	chmodArgs = append(chmodArgs, "--", "chmod", "+x", binDest)
 	_, _ = runCapture("kubectl", chmodArgs...)
 
+	if syncPreserveMode {
+		if info, err := os.Stat(absOutput); err == nil {
+			if err := applyFileModes(pod, namespace, container, map[string]os.FileMode{binDest: info.Mode().Perm() | 0o111}); err != nil {
+				warn(err.Error())
+			}
+		}
+		if owner != "" && remoteOwner(pod, namespace, container, binDest) != owner {
+			chownArgs := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+			if container != "" {
+				chownArgs = append(chownArgs, "-c", container)
+			}
+			chownArgs = append(chownArgs, "--", "chown", owner, binDest)
+			if out, err := runCapture("kubectl", chownArgs...); err != nil {
+				warn(fmt.Sprintf("Could not restore owner %s of %s: %s", owner, binDest, strings.TrimSpace(out)))
+			}
+		}
+	}
+
 	// ── Restart via wrapper ────────────────────────────────────
 	step("🔄", "Restarting with new binary")
 	killAppChild(pod, namespace, container)
@@ -1204,6 +1735,149 @@ func restartViaRebuild(pod, namespace, container, srcDir, dest string, profile r
 	return pod, nil
 }
 
+// resolveBinDest finds the app's binary inside the container from the
+// deployment's (possibly wrapped) command.
+func resolveBinDest(deployment, pod, namespace, container, dest string) string {
+	origCmd := readContainerCommand(deployment, pod, namespace, container)
+	return containerBinDest(origCmd, dest, func(name string) string {
+		// Resolve via `command -v` inside the container (more portable than `which`)
+		args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+		if container != "" {
+			args = append(args, "-c", container)
+		}
+		args = append(args, "--", "sh", "-c", fmt.Sprintf("command -v %s", name))
+		out, err := runCapture("kubectl", args...)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(out)
+	})
+}
+
+// containerBinDest returns the binary path for origCmd: its first token if
+// absolute, else whatever resolve finds on the container's PATH, else
+// <dest>/<name>. An unreadable command yields dest.
+func containerBinDest(origCmd, dest string, resolve func(name string) string) string {
+	innerBin := extractInnerBinaryFromWrapper(origCmd)
+	switch {
+	case innerBin == "":
+		return dest
+	case strings.HasPrefix(innerBin, "/"):
+		return innerBin
+	}
+	if resolved := resolve(innerBin); resolved != "" {
+		return resolved
+	}
+	// Last resort: assume binary is under dest dir
+	return filepath.Join(dest, innerBin)
+}
+
+// installBuildOutputScript returns the shell command that puts an
+// in-container build output in place of the app binary. It copies next to
+// binDest and renames over it, since writing into a running executable
+// fails with "text file busy".
+func installBuildOutputScript(output, binDest string) string {
+	tmp := binDest + ".kindling-new"
+	return fmt.Sprintf("cp %q %q && chmod +x %q && mv -f %q %q", output, tmp, tmp, tmp, binDest)
+}
+
+// buildCmdTool returns the program a runtime's in-container build command
+// starts with (e.g. "go" for "go build ..."), skipping leading VAR=value
+// assignments.
+func buildCmdTool(buildCmd string) string {
+	for _, field := range strings.Fields(buildCmd) {
+		if !strings.Contains(field, "=") {
+			return field
+		}
+	}
+	return ""
+}
+
+// containerBuildUnavailable explains why profile can't be built inside the
+// container, or returns "" when it can. Runtime images usually ship without
+// a compiler, so the tool is looked up before anything is patched.
+func containerBuildUnavailable(pod, namespace, container string, profile runtimeProfile) string {
+	tool := buildCmdTool(profile.BuildCmd)
+	if tool == "" {
+		return fmt.Sprintf("%s has no in-container build command", profile.Name)
+	}
+	args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+	if container != "" {
+		args = append(args, "-c", container)
+	}
+	args = append(args, "--", "sh", "-c", "command -v "+tool)
+	if out, err := runCapture("kubectl", args...); err != nil || strings.TrimSpace(out) == "" {
+		return fmt.Sprintf("%s is not installed in the container", tool)
+	}
+	return ""
+}
+
+// restartViaContainerBuild syncs the source into the container, runs the
+// profile's BuildCmd there, installs the output over the app's binary, and
+// restarts via the wrapper loop. Used by
+// --container-build when the developer's machine lacks the toolchain.
+func restartViaContainerBuild(deployment, pod, namespace, container, srcDir, dest string, profile runtimeProfile) (string, error) {
+	// Patch first: the rollout replaces the pod and anything synced into it.
+	if !isAlreadyPatched(pod, namespace) {
+		newPod, err := patchDeploymentWrapper(deployment, pod, namespace, container)
+		if err != nil {
+			return pod, err
+		}
+		pod = newPod
+	}
+
+	if srcDir != "" {
+		step("📦", "Syncing source files into container")
+		if err := syncDir(pod, namespace, srcDir, dest, container); err != nil {
+			return pod, fmt.Errorf("sync failed: %w", err)
+		}
+	}
+
+	step("🔨", fmt.Sprintf("Building in container: %s", profile.BuildCmd))
+	args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+	if container != "" {
+		args = append(args, "-c", container)
+	}
+	args = append(args, "--", "sh", "-c", fmt.Sprintf("cd %s && %s", dest, profile.BuildCmd))
+	if out, err := runCapture("kubectl", args...); err != nil {
+		warn(fmt.Sprintf("In-container build failed:\n%s", strings.TrimSpace(out)))
+		return pod, fmt.Errorf("in-container build failed: %w", err)
+	}
+	success("Build complete")
+
+	output := profile.BuildOutput
+	if syncBuildOutput != "" {
+		output = syncBuildOutput
+	}
+	if output == "" {
+		warn(fmt.Sprintf("%s has no fixed build output — pass --build-output to install it over the running binary", profile.Name))
+	} else {
+		if !path.IsAbs(output) {
+			output = path.Join(dest, output)
+		}
+		binDest := resolveBinDest(deployment, pod, namespace, container, dest)
+		if isReadOnlyRootfs(pod, namespace, container, path.Dir(binDest)) {
+			return pod, readOnlyRootfsError(path.Dir(binDest))
+		}
+		step("📦", fmt.Sprintf("Installing %s → %s", output, binDest))
+		installArgs := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+		if container != "" {
+			installArgs = append(installArgs, "-c", container)
+		}
+		installArgs = append(installArgs, "--", "sh", "-c", installBuildOutputScript(output, binDest))
+		if out, err := runCapture("kubectl", installArgs...); err != nil {
+			return pod, fmt.Errorf("failed to install build output: %s", strings.TrimSpace(out))
+		}
+	}
+
+	step("🔄", "Restarting with new binary")
+	killAppChild(pod, namespace, container)
+
+	time.Sleep(profile.WaitAfter)
+	success(fmt.Sprintf("Rebuilt in container + restarted (%s)", profile.Name))
+	return pod, nil
+}
+
 // isDistroless returns true if the container appears to be a distroless or
 // scratch image (no shell available).
 func isDistroless(pod, namespace, container string) bool {
@@ -1219,6 +1893,35 @@ func isDistroless(pod, namespace, container string) bool {
 	return false
 }
 
+// isReadOnlyRootfs reports whether dir inside the container is on a
+// read-only filesystem, typically because the container sets
+// securityContext.readOnlyRootFilesystem.  It probes by touching a file, so
+// a container without a shell (or a dir that doesn't exist) reads as
+// writable and is left to fail on its own.
+func isReadOnlyRootfs(pod, namespace, container, dir string) bool {
+	args := []string{"exec", pod, "-n", namespace, "--context", kindContext()}
+	if container != "" {
+		args = append(args, "-c", container)
+	}
+	probe := path.Join(dir, ".kindling-rw-probe")
+	args = append(args, "--", "sh", "-c", fmt.Sprintf("touch %q && rm -f %q", probe, probe))
+	out, err := runCapture("kubectl", args...)
+	return err != nil && isReadOnlyFSOutput(out)
+}
+
+// isReadOnlyFSOutput reports whether out carries the EROFS message that
+// touch, cp, and tar print for a read-only mount.
+func isReadOnlyFSOutput(out string) bool {
+	return strings.Contains(strings.ToLower(out), "read-only file system")
+}
+
+// readOnlyRootfsError explains that sync-restart can't replace files under
+// dir and points at kindling push instead.
+func readOnlyRootfsError(dir string) error {
+	return fmt.Errorf("%s is on a read-only filesystem (readOnlyRootFilesystem: true?) — sync-restart needs a writable path "+
+		"to replace the app's files; use `kindling push` to rebuild and redeploy the image instead", dir)
+}
+
 // patchDistrolessWithWrapper injects busybox debug tools AND the restart
 // wrapper into a distroless deployment in a single patch (single rollout).
 // The wrapper uses /debug-tools/sh (absolute path) since distroless images
@@ -1281,12 +1984,32 @@ func patchDistrolessWithWrapper(deployment, namespace, container, origCmd string
 
 // ── Local build helpers ────────────────────────────────────────────
 
-// detectNodeArch returns (GOOS, GOARCH) of the Kind cluster's node.
+var (
+	nodeArchOnce         sync.Once
+	nodeGOOS, nodeGOARCH string
+)
+
+// detectNodeArch returns (GOOS, GOARCH) of the Kind cluster's node.  The
+// node can't change arch mid-session, so the lookup runs once per process
+// rather than on every rebuild.  In a multi-node cluster (kindling init
+// --nodes) every node runs the same image on the same Docker host, so the
+// first node's arch holds for all of them.
 func detectNodeArch() (string, string) {
-	goos, _ := runCapture("kubectl", "get", "nodes", "--context", kindContext(),
-		"-o", "jsonpath={.items[0].status.nodeInfo.operatingSystem}")
-	goarch, _ := runCapture("kubectl", "get", "nodes", "--context", kindContext(),
-		"-o", "jsonpath={.items[0].status.nodeInfo.architecture}")
+	nodeArchOnce.Do(func() {
+		out, err := runCapture("kubectl", "get", "nodes", "--context", kindContext(),
+			"-o", "jsonpath={.items[0].status.nodeInfo.operatingSystem}/{.items[0].status.nodeInfo.architecture}")
+		if err != nil {
+			out = "" // the output is kubectl's error message
+		}
+		nodeGOOS, nodeGOARCH = parseNodeArch(out)
+	})
+	return nodeGOOS, nodeGOARCH
+}
+
+// parseNodeArch splits the "os/arch" jsonpath output of detectNodeArch,
+// falling back to linux and the host's arch for missing fields.
+func parseNodeArch(out string) (string, string) {
+	goos, goarch, _ := strings.Cut(strings.TrimSpace(out), "/")
 	goos = strings.TrimSpace(goos)
 	goarch = strings.TrimSpace(goarch)
 	if goos == "" {
@@ -1300,12 +2023,18 @@ func detectNodeArch() (string, string) {
 
 // autoLocalBuild returns a (buildCmd, outputPath) pair for known compiled
 // languages.  Returns ("", "") if the language isn't auto-detectable.
+// Outputs that would otherwise land in a temp dir go to the project's build
+// cache (see buildCacheDir) so the next sync builds incrementally.
 func autoLocalBuild(profile runtimeProfile, srcDir string) (string, string) {
 	goos, goarch := detectNodeArch()
+	cacheDir, err := buildCacheDir(srcDir)
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
 
 	switch profile.Name {
 	case "Go":
-		outPath := filepath.Join(os.TempDir(), "_kindling_go_bin")
+		outPath := filepath.Join(cacheDir, "_kindling_go_bin")
 		cmd := fmt.Sprintf("CGO_ENABLED=0 GOOS=%s GOARCH=%s go build -o %s .", goos, goarch, outPath)
 		// Check if go.mod exists to validate it's a Go project
 		if _, err := os.Stat(filepath.Join(srcDir, "go.mod")); err == nil {
@@ -1329,9 +2058,9 @@ func autoLocalBuild(profile runtimeProfile, srcDir string) (string, string) {
 
 	case ".NET":
 		rid := fmt.Sprintf("%s-%s", goos, goarchToDotnet(goarch))
-		outDir := filepath.Join(os.TempDir(), "_kindling_dotnet_out")
+		outDir := filepath.Join(cacheDir, "_kindling_dotnet_out")
 		cmd := fmt.Sprintf("dotnet publish -r %s -c Release -o %s --self-contained", rid, outDir)
-		if _, err := os.Stat(filepath.Join(srcDir, "*.csproj")); err == nil {
+		if matches, _ := filepath.Glob(filepath.Join(srcDir, "*.csproj")); len(matches) > 0 {
 			return cmd, outDir
 		}
 		return "", ""
@@ -1377,6 +2106,34 @@ func autoLocalBuild(profile runtimeProfile, srcDir string) (string, string) {
 			return "zig build", outPath
 		}
 		return "", ""
+
+	case "Swift":
+		// The static Linux SDK makes the binary runnable in any container
+		sdk := fmt.Sprintf("%s-swift-linux-musl", goarchToRust(goarch))
+		outPath := filepath.Join(srcDir, ".build", sdk, "release")
+		if _, err := os.Stat(filepath.Join(srcDir, "Package.swift")); err == nil {
+			return fmt.Sprintf("swift build -c release --swift-sdk %s", sdk), outPath
+		}
+		return "", ""
+
+	case "Dart":
+		if _, err := os.Stat(filepath.Join(srcDir, "pubspec.yaml")); err != nil {
+			return "", ""
+		}
+		entry := dartEntrypoint(srcDir)
+		if entry == "" {
+			return "", ""
+		}
+		outPath := filepath.Join(cacheDir, "_kindling_dart_bin")
+		return fmt.Sprintf("dart compile exe %s -o %s --target-os %s --target-arch %s", entry, outPath, goos, goarchToDart(goarch)), outPath
+
+	case "Scala", "Scala (sbt)":
+		// sbt-native-packager's stage task writes a runnable layout
+		outDir := filepath.Join(srcDir, "target", "universal", "stage")
+		if _, err := os.Stat(filepath.Join(srcDir, "build.sbt")); err == nil {
+			return "sbt stage", outDir
+		}
+		return "", ""
 	}
 
 	return "", ""
@@ -1394,6 +2151,30 @@ func goarchToRust(goarch string) string {
 	}
 }
 
+// goarchToDart maps Go arch names to dart compile --target-arch values.
+func goarchToDart(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x64"
+	default:
+		return goarch
+	}
+}
+
+// dartEntrypoint returns the bin/ script `dart compile exe` should build:
+// bin/server.dart (the dart create -t server-shelf default) when present,
+// otherwise the only .dart file in bin/.  Returns "" when ambiguous.
+func dartEntrypoint(srcDir string) string {
+	if _, err := os.Stat(filepath.Join(srcDir, "bin", "server.dart")); err == nil {
+		return filepath.Join("bin", "server.dart")
+	}
+	matches, _ := filepath.Glob(filepath.Join(srcDir, "bin", "*.dart"))
+	if len(matches) == 1 {
+		return filepath.Join("bin", filepath.Base(matches[0]))
+	}
+	return ""
+}
+
 // goarchToDotnet maps Go arch names to .NET RID arch names.
 func goarchToDotnet(goarch string) string {
 	switch goarch {
@@ -1520,6 +2301,19 @@ func restartContainer(pod, namespace, container string) error {
 // Command helpers
 // ════════════════════════════════════════════════════════════════════
 
+// kindNodeForPod returns the Kind node container running pod, which is
+// where crictl can see its containers. Kind names node containers after
+// their Kubernetes nodes; it falls back to the control plane, the only
+// node in a default cluster.
+func kindNodeForPod(pod, namespace string) string {
+	out, err := runCapture("kubectl", "get", "pod", pod, "-n", namespace,
+		"--context", kindContext(), "-o", "jsonpath={.spec.nodeName}")
+	if node := strings.TrimSpace(out); err == nil && node != "" {
+		return node
+	}
+	return clusterName + "-control-plane"
+}
+
 // readContainerCommand returns the original entrypoint/cmd for the deployment.
 func readContainerCommand(deployment, pod, namespace, container string) string {
 	currentCmd, _ := runCapture("kubectl", "get", fmt.Sprintf("deployment/%s", deployment),
@@ -1543,11 +2337,12 @@ func readContainerCommand(deployment, pod, namespace, container string) string {
 	if cName == "" {
 		cName = containerNameForDeployment(deployment, namespace, "")
 	}
-	cID, _ := runCapture("docker", "exec", clusterName+"-control-plane",
+	node := kindNodeForPod(pod, namespace)
+	cID, _ := runCapture("docker", "exec", node,
 		"crictl", "ps", "--name", cName, "-q")
 	cID = strings.TrimSpace(cID)
 	if cID != "" {
-		inspectOut, _ := runCapture("docker", "exec", clusterName+"-control-plane",
+		inspectOut, _ := runCapture("docker", "exec", node,
 			"crictl", "inspect", "--output", "json", cID)
 		if inspectOut != "" {
 			// Parse runtimeSpec.process.args from the JSON
@@ -1638,11 +2433,150 @@ func parseJSONStringArray(s string) string {
 // Main command entry point
 // ════════════════════════════════════════════════════════════════════
 
+// runSyncBuildOnly does a single local build + binary sync + restart and
+// exits without setting up a watcher, so a script can hand off to the app's
+// own reloader afterwards. The final pod name is the only thing written to
+// stdout so callers can chain commands on it.
+func runSyncBuildOnly(deployment, pod, srcDir string, profile runtimeProfile) error {
+	if profile.Mode != modeRebuild {
+		return fmt.Errorf("--build-only needs a compiled runtime, but deployment/%s runs %s — use --once instead", deployment, profile.Name)
+	}
+	if syncBuildCmd == "" && !(syncContainerBuild && profile.BuildCmd != "") {
+		if buildCmd, _ := autoLocalBuild(profile, srcDir); buildCmd == "" {
+			return fmt.Errorf("no local build detected for %s in %s — pass --build-cmd and --build-output", profile.Name, srcDir)
+		}
+	}
+
+	if _, err := restartViaRebuild(pod, syncNamespace, syncContainer, srcDir, syncDest, profile); err != nil {
+		return fmt.Errorf("build+restart failed: %w", err)
+	}
+
+	// Re-discover in case the restart rolled the deployment
+	pod, err := findPodForDeployment(deployment, syncNamespace)
+	if err != nil {
+		return err
+	}
+	success(fmt.Sprintf("Build complete — running in pod %s", pod))
+	fmt.Println(pod)
+	return nil
+}
+
+// runSyncDiff prints which files a sync would add or change in the
+// container, and which container files have no local counterpart, without
+// copying anything. Paths are shown relative to --dest.
+func runSyncDiff(pod, srcDir string, excludes []string) error {
+	step("🔎", fmt.Sprintf("Comparing %s with %s:%s", srcDir, pod, syncDest))
+
+	local, err := localChecksums(srcDir, syncDest, excludes)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", srcDir, err)
+	}
+	files, err := remoteFiles(pod, syncNamespace, syncContainer, syncDest, excludes)
+	if err != nil {
+		return err
+	}
+
+	// Only files present on both sides need hashing in the container.
+	remote := make(map[string]string, len(files))
+	var common []string
+	for _, f := range files {
+		if _, ok := local[f]; ok {
+			common = append(common, f)
+		} else {
+			remote[f] = ""
+		}
+	}
+	sums, err := remoteChecksums(pod, syncNamespace, syncContainer, common)
+	if err != nil {
+		return err
+	}
+	for p, sum := range sums {
+		remote[p] = sum
+	}
+
+	added, changed, deleted := diffChecksums(local, remote)
+	if len(added)+len(changed)+len(deleted) == 0 {
+		success("No differences — the container is up to date")
+		return nil
+	}
+
+	prefix := strings.TrimRight(syncDest, "/") + "/"
+	fmt.Println()
+	for _, group := range []struct {
+		mark, color string
+		paths       []string
+	}{
+		{"+", colorGreen, added},
+		{"~", colorYellow, changed},
+		{"-", colorRed, deleted},
+	} {
+		for _, p := range group.paths {
+			fmt.Printf("  %s%s %s%s\n", group.color, group.mark, strings.TrimPrefix(p, prefix), colorReset)
+		}
+	}
+	fmt.Println()
+	fmt.Printf("  %d added, %d changed, %d only in the container\n", len(added), len(changed), len(deleted))
+	return nil
+}
+
+// validateSyncReplicas rejects --replicas values and combinations sync
+// can't honor.
+func validateSyncReplicas(replicas int, diff, buildOnly bool) error {
+	switch {
+	case replicas < 0:
+		return fmt.Errorf("--replicas must be at least 1")
+	case replicas > 0 && (diff || buildOnly):
+		return fmt.Errorf("--replicas can't be combined with --diff or --build-only")
+	}
+	return nil
+}
+
+// syncRollsOnChange reports whether changes restart the app by rolling
+// the deployment. kubectl cp copies into a single pod, so with more than
+// one replica the wrapper restart path would leave the others stale.
+func syncRollsOnChange(replicas int) bool {
+	return replicas > 1
+}
+
+// scaleDeployment sets the deployment's replica count and waits for the
+// rollout to settle.
+func scaleDeployment(deployment, namespace string, replicas int) error {
+	step("📈", fmt.Sprintf("Scaling deployment/%s to %d replica(s)", deployment, replicas))
+	if err := run("kubectl", "scale", fmt.Sprintf("deployment/%s", deployment),
+		fmt.Sprintf("--replicas=%d", replicas), "-n", namespace, "--context", kindContext()); err != nil {
+		return fmt.Errorf("scale failed: %w", err)
+	}
+	if err := run("kubectl", "rollout", "status", fmt.Sprintf("deployment/%s", deployment),
+		"-n", namespace, "--context", kindContext(), "--timeout=120s"); err != nil {
+		return fmt.Errorf("deployment/%s did not scale within 120s: %w", deployment, err)
+	}
+	success(fmt.Sprintf("deployment/%s running %d replica(s)", deployment, replicas))
+	return nil
+}
+
+// rolloutRestartDeployment replaces every pod of the deployment and waits
+// for the new ones.
+func rolloutRestartDeployment(deployment, namespace string) error {
+	step("♻️", fmt.Sprintf("kubectl rollout restart deployment/%s", deployment))
+	if err := run("kubectl", "rollout", "restart", fmt.Sprintf("deployment/%s", deployment),
+		"-n", namespace, "--context", kindContext()); err != nil {
+		return err
+	}
+	return run("kubectl", "rollout", "status", fmt.Sprintf("deployment/%s", deployment),
+		"-n", namespace, "--context", kindContext(), "--timeout=120s")
+}
+
 func runSync(cmd *cobra.Command, args []string) error {
 	// ── Validate ────────────────────────────────────────────────
 	deployment := strings.TrimSpace(syncDeployment)
 	if deployment == "" {
-		return fmt.Errorf("--deployment is required")
+		return runSyncManifest(cmd, args)
+	}
+	if syncDiff && (syncRestart || syncBuildOnly || syncContainerBuild) {
+		return fmt.Errorf("--diff is read-only and can't be combined with --restart, --build-only, or --container-build")
+	}
+	if err := validateSyncReplicas(syncReplicas, syncDiff, syncBuildOnly); err != nil {
+		return err
 	}
 
 	srcDir, err := filepath.Abs(syncSrc)
@@ -1653,7 +2587,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("source directory does not exist: %s", srcDir)
 	}
 
-	if !clusterExists(clusterName) {
+	if usingKindCluster() && !clusterExists(clusterName) {
 		return fmt.Errorf("Kind cluster %q not found — run: kindling init", clusterName)
 	}
 
@@ -1663,6 +2597,19 @@ func runSync(cmd *cobra.Command, args []string) error {
 
 	// ── Find target pod ─────────────────────────────────────────
 	header("Sync")
+
+	if syncNSAuto {
+		ns, err := discoverDeploymentNamespace(deployment, syncNamespace)
+		if err != nil {
+			return err
+		}
+		if ns != syncNamespace {
+			step("🧭", fmt.Sprintf("deployment/%s not in %q — using namespace %s%s%s",
+				deployment, syncNamespace, colorCyan, ns, colorReset))
+			syncNamespace = ns
+		}
+	}
+
 	step("🔍", fmt.Sprintf("Finding pod for deployment/%s", deployment))
 
 	pod, err := findPodForDeployment(deployment, syncNamespace)
@@ -1675,6 +2622,16 @@ func runSync(cmd *cobra.Command, args []string) error {
 	profile, _ := detectRuntime(pod, syncNamespace, syncContainer)
 	frontendMode := profile.Mode == modeSignal && !profile.Interpreted && isFrontendProject(srcDir)
 
+	// ── Diff mode ───────────────────────────────────────────────
+	if syncDiff {
+		return runSyncDiff(pod, srcDir, excludes)
+	}
+
+	// ── Build-only mode ─────────────────────────────────────────
+	if syncBuildOnly {
+		return runSyncBuildOnly(deployment, pod, srcDir, profile)
+	}
+
 	// ── Initial sync ────────────────────────────────────────────
 	if syncRestart {
 		newPod, syncErr := syncAndRestart(pod, syncNamespace, syncContainer, srcDir, syncDest, excludes)
@@ -1695,6 +2652,28 @@ func runSync(cmd *cobra.Command, args []string) error {
 		success("Initial sync complete")
 		printSyncOnlyTips(profile)
 	}
+	if syncPreserveMode {
+		modes, err := localFileModes(srcDir, syncDest, excludes)
+		if err == nil {
+			err = applyFileModes(pod, syncNamespace, syncContainer, modes)
+		}
+		if err != nil {
+			warn(fmt.Sprintf("File modes not preserved: %v", err))
+		}
+	}
+
+	// ── Scale ───────────────────────────────────────────────────
+	if syncReplicas > 0 {
+		if err := scaleDeployment(deployment, syncNamespace, syncReplicas); err != nil {
+			return err
+		}
+		if pod, err = findPodForDeployment(deployment, syncNamespace); err != nil {
+			return err
+		}
+		if syncRollsOnChange(syncReplicas) {
+			warn(fmt.Sprintf("Only %s has the synced files — the other replicas run the deployment's image", pod))
+		}
+	}
 
 	// ── One-shot mode ───────────────────────────────────────────
 	if syncOnce {
@@ -1730,6 +2709,9 @@ func runSync(cmd *cobra.Command, args []string) error {
 				modeDesc = "local build + binary sync"
 			}
 		}
+		if syncRollsOnChange(syncReplicas) {
+			modeDesc = fmt.Sprintf("rollout (%d replicas, no file sync)", syncReplicas)
+		}
 		fmt.Printf("  🔄  Restart: %s%s%s\n", colorGreen, modeDesc, colorReset)
 	}
 	fmt.Printf("\n  %sPress Ctrl+C to stop%s\n\n", colorDim, colorReset)
@@ -1775,6 +2757,24 @@ func runSync(cmd *cobra.Command, args []string) error {
 		count := len(fileList)
 		ts := time.Now().Format("15:04:05")
 
+		// kubectl cp reaches one pod, so with several replicas there is
+		// nothing to sync into: roll every pod instead, or skip.
+		if syncRollsOnChange(syncReplicas) {
+			fmt.Printf("  %s[%s]%s  %d file(s) changed\n", colorDim, ts, colorReset, count)
+			if !syncRestart {
+				warn(fmt.Sprintf("Not synced — %d replicas; use --replicas 1 to sync, or --restart to roll the deployment", syncReplicas))
+				return
+			}
+			if err := rolloutRestartDeployment(deployment, syncNamespace); err != nil {
+				warn(fmt.Sprintf("Rollout failed: %v", err))
+				return
+			}
+			if newPod, err := findPodForDeployment(deployment, syncNamespace); err == nil {
+				pod = newPod
+			}
+			return
+		}
+
 		if count <= 3 {
 			for _, f := range fileList {
 				rel, _ := filepath.Rel(srcDir, f)
@@ -1795,16 +2795,24 @@ func runSync(cmd *cobra.Command, args []string) error {
 			}
 		} else {
 			var syncErrors int
+			modes := make(map[string]os.FileMode)
 			for _, localPath := range fileList {
-				relPath, _ := filepath.Rel(srcDir, localPath)
-				destPath := filepath.Join(syncDest, relPath)
-				destPath = strings.ReplaceAll(destPath, "\\", "/")
+				relPath, destPath := containerPath(srcDir, localPath, syncDest)
 
 				if err := syncFile(pod, syncNamespace, localPath, destPath, syncContainer); err != nil {
 					syncErrors++
 					if syncErrors <= 3 {
 						warn(fmt.Sprintf("  %s: %v", relPath, err))
 					}
+				} else if syncPreserveMode {
+					if info, err := os.Stat(localPath); err == nil {
+						modes[destPath] = info.Mode().Perm()
+					}
+				}
+			}
+			if syncPreserveMode {
+				if err := applyFileModes(pod, syncNamespace, syncContainer, modes); err != nil {
+					warn(fmt.Sprintf("File modes not preserved: %v", err))
 				}
 			}
 
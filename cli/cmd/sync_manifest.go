@@ -0,0 +1,466 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// defaultSyncManifest is read by `kindling sync` when no -d is given.
+const defaultSyncManifest = "kindling.sync.yaml"
+
+// syncTarget is one entry of a sync manifest. Each field maps to the sync
+// flag of the same name.
+type syncTarget struct {
+	Name        string
+	Deployment  string
+	Namespace   string
+	Container   string
+	Src         string
+	Dest        string
+	Restart     bool
+	Language    string
+	BuildCmd    string
+	BuildOutput string
+	Exclude     []string
+}
+
+// syncTargetFlags maps manifest keys to sync flag names. exclude, restart,
+// and name are handled separately.
+var syncTargetFlags = map[string]string{
+	"deployment":  "deployment",
+	"namespace":   "namespace",
+	"container":   "container",
+	"src":         "src",
+	"dest":        "dest",
+	"language":    "language",
+	"buildCmd":    "build-cmd",
+	"buildOutput": "build-output",
+}
+
+// parseSyncManifest parses a kindling.sync.yaml:
+//
+//	targets:
+//	  - name: orders            # defaults to the deployment
+//	    deployment: orders
+//	    src: ./services/orders
+//	    restart: true
+//	    exclude: ["*.log", tmp]
+//
+// Only this shape is accepted, so typos fail loudly instead of being
+// ignored.
+func parseSyncManifest(data []byte) ([]syncTarget, error) {
+	lines := strings.Split(string(data), "\n")
+	var targets []syncTarget
+	var cur *syncTarget
+	inTargets, inExclude := false, false
+	itemIndent, keyIndent := -1, -1
+
+	for n, line := range lines {
+		lineNo := n + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		ind := indentOf(line)
+
+		if ind == 0 {
+			if trimmed != "targets:" {
+				return nil, fmt.Errorf("line %d: unknown top-level key %q (want targets:)", lineNo, trimmed)
+			}
+			inTargets = true
+			continue
+		}
+		if !inTargets {
+			return nil, fmt.Errorf("line %d: expected targets: first", lineNo)
+		}
+
+		// A block exclude list ends at the first line that isn't nested
+		// under it, which may be the next target's "- ".
+		if inExclude && ind <= keyIndent {
+			inExclude = false
+		}
+
+		if strings.HasPrefix(trimmed, "- ") && (itemIndent < 0 || ind == itemIndent) && !inExclude {
+			itemIndent = ind
+			keyIndent = ind + 2
+			targets = append(targets, syncTarget{})
+			cur = &targets[len(targets)-1]
+			trimmed = strings.TrimSpace(trimmed[2:])
+			ind = keyIndent
+		} else if inExclude && ind > keyIndent && strings.HasPrefix(trimmed, "- ") {
+			cur.Exclude = append(cur.Exclude, unquoteYAML(strings.TrimSpace(trimmed[2:])))
+			continue
+		}
+		if cur == nil {
+			return nil, fmt.Errorf("line %d: expected a list item (- deployment: ...) under targets", lineNo)
+		}
+		if ind != keyIndent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", lineNo)
+		}
+		inExclude = false
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value, got %q", lineNo, trimmed)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "name":
+			cur.Name = unquoteYAML(value)
+		case "restart":
+			b, err := strconv.ParseBool(unquoteYAML(value))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: restart must be true or false, got %q", lineNo, value)
+			}
+			cur.Restart = b
+		case "exclude":
+			if value == "" {
+				inExclude = true
+				continue
+			}
+			list, err := parseFlowList(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cur.Exclude = append(cur.Exclude, list...)
+		default:
+			if _, known := syncTargetFlags[key]; !known {
+				return nil, fmt.Errorf("line %d: unknown key %q (want one of: %s)", lineNo, key, strings.Join(syncTargetKeys(), ", "))
+			}
+			cur.set(key, unquoteYAML(value))
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets defined")
+	}
+	seen := map[string]bool{}
+	for i := range targets {
+		t := &targets[i]
+		if t.Deployment == "" {
+			return nil, fmt.Errorf("target %d: deployment is required", i+1)
+		}
+		if t.Name == "" {
+			t.Name = t.Deployment
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("duplicate target name %q — set name: on one of them", t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return targets, nil
+}
+
+// parseFlowList parses a one-line YAML flow sequence such as ["a", b].
+func parseFlowList(v string) ([]string, error) {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "[") || !strings.HasSuffix(v, "]") {
+		return nil, fmt.Errorf("expected a list, got %q", v)
+	}
+	var out []string
+	for _, item := range strings.Split(v[1:len(v)-1], ",") {
+		if item = unquoteYAML(strings.TrimSpace(item)); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// syncTargetKeys lists every key a manifest target accepts.
+func syncTargetKeys() []string {
+	keys := []string{"name", "restart", "exclude"}
+	for k := range syncTargetFlags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (t *syncTarget) set(key, value string) {
+	switch key {
+	case "deployment":
+		t.Deployment = value
+	case "namespace":
+		t.Namespace = value
+	case "container":
+		t.Container = value
+	case "src":
+		t.Src = value
+	case "dest":
+		t.Dest = value
+	case "language":
+		t.Language = value
+	case "buildCmd":
+		t.BuildCmd = value
+	case "buildOutput":
+		t.BuildOutput = value
+	}
+}
+
+// flagValues returns the sync flags the target sets, keyed by flag name.
+func (t syncTarget) flagValues() map[string]string {
+	values := map[string]string{}
+	for key, flag := range syncTargetFlags {
+		var v string
+		switch key {
+		case "deployment":
+			v = t.Deployment
+		case "namespace":
+			v = t.Namespace
+		case "container":
+			v = t.Container
+		case "src":
+			v = t.Src
+		case "dest":
+			v = t.Dest
+		case "language":
+			v = t.Language
+		case "buildCmd":
+			v = t.BuildCmd
+		case "buildOutput":
+			v = t.BuildOutput
+		}
+		if v != "" {
+			values[flag] = v
+		}
+	}
+	if t.Restart {
+		values["restart"] = "true"
+	}
+	return values
+}
+
+// loadSyncManifest reads and validates the manifest at path. A relative src
+// is resolved against the manifest's directory (buildOutput stays relative
+// to src, as with the flag).
+func loadSyncManifest(path string) ([]syncTarget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := parseSyncManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for i := range targets {
+		t := &targets[i]
+		if t.Src == "" {
+			t.Src = "."
+		}
+		if !filepath.IsAbs(t.Src) {
+			t.Src = filepath.Join(dir, t.Src)
+		}
+	}
+	return targets, nil
+}
+
+// selectSyncTargets returns the target named name, or every target when
+// name is empty.
+func selectSyncTargets(targets []syncTarget, name string) ([]syncTarget, error) {
+	if name == "" {
+		return targets, nil
+	}
+	var names []string
+	for _, t := range targets {
+		if t.Name == name {
+			return []syncTarget{t}, nil
+		}
+		names = append(names, t.Name)
+	}
+	return nil, fmt.Errorf("no sync target named %q (have: %s)", name, strings.Join(names, ", "))
+}
+
+// validateSyncTargetFlags rejects mixing -d with the manifest flags, before
+// a single manifest target fills -d in itself.
+func validateSyncTargetFlags(cmd *cobra.Command, _ []string) error {
+	if cmd.Flags().Changed("deployment") && (cmd.Flags().Changed("manifest") || cmd.Flags().Changed("target")) {
+		return fmt.Errorf("--manifest and --target pick targets from a manifest — drop -d/--deployment to use them")
+	}
+	return nil
+}
+
+// runSyncManifest runs `kindling sync` for the manifest's targets. Flags
+// given on the command line win over the manifest's values.
+func runSyncManifest(cmd *cobra.Command, args []string) error {
+	path := syncManifest
+	if path == "" {
+		path = defaultSyncManifest
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("--deployment is required (or describe your targets in %s)", defaultSyncManifest)
+		}
+	}
+	targets, err := loadSyncManifest(path)
+	if err != nil {
+		return err
+	}
+	targets, err = selectSyncTargets(targets, syncTargetName)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 1 {
+		t := targets[0]
+		step("📄", fmt.Sprintf("Sync target %s%s%s from %s", colorCyan, t.Name, colorReset, path))
+		if err := applySyncTarget(cmd, t); err != nil {
+			return err
+		}
+		return runSync(cmd, args)
+	}
+
+	header(fmt.Sprintf("Syncing %d targets from %s", len(targets), path))
+	return runSyncTargets(cmd, targets)
+}
+
+// applySyncTarget sets the sync flags from t, leaving any flag the user
+// passed explicitly alone. Exclude patterns are added to the user's.
+func applySyncTarget(cmd *cobra.Command, t syncTarget) error {
+	flags := cmd.Flags()
+	for name, value := range t.flagValues() {
+		if flags.Changed(name) {
+			continue
+		}
+		if err := flags.Set(name, value); err != nil {
+			return fmt.Errorf("target %s: %s: %w", t.Name, name, err)
+		}
+	}
+	for _, pattern := range t.Exclude {
+		if err := flags.Set("exclude", pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncTargetArgs builds the `kindling sync` arguments for one target of a
+// multi-target run: the target's flags, then every flag the user passed on
+// the command line (inherited ones such as --cluster included).
+func syncTargetArgs(cmd *cobra.Command, t syncTarget) []string {
+	args := []string{"sync"}
+	values := t.flagValues()
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !cmd.Flags().Changed(name) {
+			args = append(args, "--"+name+"="+values[name])
+		}
+	}
+	for _, pattern := range t.Exclude {
+		args = append(args, "--exclude="+pattern)
+	}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "manifest" || f.Name == "target" {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				args = append(args, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+	return args
+}
+
+// runSyncTargets runs one `kindling sync` child per target in parallel,
+// prefixing each line of output with the target name. Ctrl-C is passed on
+// to every child so each one can roll back its pod before exiting.
+func runSyncTargets(cmd *cobra.Command, targets []syncTarget) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate kindling binary: %w", err)
+	}
+
+	width := 0
+	for _, t := range targets {
+		width = max(width, len(t.Name))
+	}
+
+	var mu sync.Mutex
+	out := &lockedWriter{w: os.Stdout, mu: &mu}
+	children := make([]*exec.Cmd, len(targets))
+	for i, t := range targets {
+		prefix := fmt.Sprintf("  %s%-*s%s │ ", colorCyan, width, t.Name, colorReset)
+		c := exec.Command(self, syncTargetArgs(cmd, t)...)
+		c.Stdout = &indentWriter{w: out, prefix: prefix}
+		c.Stderr = &indentWriter{w: out, prefix: prefix}
+		// Own process group: signals reach children only via the parent,
+		// exactly once.
+		c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		if err := c.Start(); err != nil {
+			stopSyncChildren(children[:i], os.Interrupt)
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		children[i] = c
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		for sig := range sigCh {
+			stopSyncChildren(children, sig)
+		}
+	}()
+
+	errs := make([]error, len(children))
+	var wg sync.WaitGroup
+	for i, c := range children {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Wait(); err != nil {
+				errs[i] = fmt.Errorf("target %s: %w", targets[i].Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if syncOnce || syncBuildOnly || syncDiff {
+		success(fmt.Sprintf("All %d targets done", len(targets)))
+	}
+	return nil
+}
+
+// stopSyncChildren forwards sig to every started child.
+func stopSyncChildren(children []*exec.Cmd, sig os.Signal) {
+	for _, c := range children {
+		if c != nil && c.Process != nil {
+			_ = c.Process.Signal(sig)
+		}
+	}
+}
+
+// lockedWriter serialises writes from several children so prefixed lines
+// don't interleave mid-line.
+type lockedWriter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -493,6 +495,9 @@ func TestDetectLanguageFromSource(t *testing.T) {
 		{"ruby", "Gemfile", "ruby"},
 		{"elixir", "mix.exs", "elixir"},
 		{"php", "composer.json", "php"},
+		{"swift", "Package.swift", "swift"},
+		{"dart", "pubspec.yaml", "dart"},
+		{"scala", "build.sbt", "scala"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -797,6 +802,71 @@ func TestParseJSONStringArray(t *testing.T) {
 	}
 }
 
+// ════════════════════════════════════════════════════════════════════
+// Namespace auto-discovery
+// ════════════════════════════════════════════════════════════════════
+
+func TestParseNamespaceList(t *testing.T) {
+	got := parseNamespaceList("team-a\n\n  team-b \nteam-a\n")
+	want := []string{"team-a", "team-b"}
+	if len(got) != len(want) {
+		t.Fatalf("parseNamespaceList() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseNamespaceList()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if ns := parseNamespaceList(""); len(ns) != 0 {
+		t.Errorf("parseNamespaceList(\"\") = %v, want empty", ns)
+	}
+}
+
+func TestPickDeploymentNamespace(t *testing.T) {
+	ns, err := pickDeploymentNamespace("orders", "default", []string{"jeff-dev"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ns != "jeff-dev" {
+		t.Errorf("namespace = %q, want jeff-dev", ns)
+	}
+
+	if _, err := pickDeploymentNamespace("orders", "default", nil); err == nil {
+		t.Error("expected error when no namespace contains the deployment")
+	}
+
+	_, err = pickDeploymentNamespace("orders", "default", []string{"team-a", "team-b"})
+	if err == nil {
+		t.Fatal("expected error for ambiguous namespaces")
+	}
+	for _, want := range []string{"team-a", "team-b"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should list namespace %q", err, want)
+		}
+	}
+}
+
+// ════════════════════════════════════════════════════════════════════
+// detectNodeArch / parseNodeArch
+// ════════════════════════════════════════════════════════════════════
+
+func TestParseNodeArch(t *testing.T) {
+	tests := []struct {
+		out, goos, goarch string
+	}{
+		{"linux/arm64", "linux", "arm64"},
+		{"linux/amd64\n", "linux", "amd64"},
+		{"/", "linux", runtime.GOARCH},
+		{"", "linux", runtime.GOARCH},
+	}
+	for _, tt := range tests {
+		goos, goarch := parseNodeArch(tt.out)
+		if goos != tt.goos || goarch != tt.goarch {
+			t.Errorf("parseNodeArch(%q) = %s/%s, want %s/%s", tt.out, goos, goarch, tt.goos, tt.goarch)
+		}
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // goarchToRust
 // ════════════════════════════════════════════════════════════════════
@@ -824,6 +894,100 @@ func TestGoarchToRust(t *testing.T) {
 // goarchToDotnet
 // ════════════════════════════════════════════════════════════════════
 
+func TestAutoLocalBuild_SwiftDartScala(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   string
+		files     []string
+		wantCmd   string
+		wantInOut string
+	}{
+		{"swift", "Swift", []string{"Package.swift"}, "swift build -c release --swift-sdk ", ".build"},
+		{"dart server", "Dart", []string{"pubspec.yaml", "bin/server.dart", "bin/tool.dart"}, "dart compile exe bin/server.dart -o ", "_kindling_dart_bin"},
+		{"dart single bin", "Dart", []string{"pubspec.yaml", "bin/api.dart"}, "dart compile exe bin/api.dart -o ", "_kindling_dart_bin"},
+		{"dart ambiguous", "Dart", []string{"pubspec.yaml", "bin/a.dart", "bin/b.dart"}, "", ""},
+		{"scala", "Scala (sbt)", []string{"build.sbt"}, "sbt stage", filepath.Join("target", "universal", "stage")},
+		{"swift without manifest", "Swift", nil, "", ""},
+		{"dotnet", ".NET", []string{"Api.csproj"}, "dotnet publish -r ", "_kindling_dotnet_out"},
+		{"dotnet without project", ".NET", nil, "", ""},
+	}
+	t.Setenv("HOME", t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755)
+				os.WriteFile(filepath.Join(dir, f), []byte(""), 0644)
+			}
+			cmd, out := autoLocalBuild(runtimeProfile{Name: tt.profile}, dir)
+			if !strings.HasPrefix(cmd, tt.wantCmd) || (tt.wantCmd == "") != (cmd == "") {
+				t.Errorf("cmd = %q, want prefix %q", cmd, tt.wantCmd)
+			}
+			if !strings.Contains(out, tt.wantInOut) || (tt.wantInOut == "") != (out == "") {
+				t.Errorf("output = %q, want it to contain %q", out, tt.wantInOut)
+			}
+		})
+	}
+}
+
+func TestAutoLocalBuild_UsesBuildCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0644)
+
+	cacheDir, err := buildCacheDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, out := autoLocalBuild(runtimeProfile{Name: "Go"}, dir)
+	if filepath.Dir(out) != cacheDir {
+		t.Errorf("Go output = %q, want it in the project's build cache %q", out, cacheDir)
+	}
+}
+
+func TestBuildCacheDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	a, err := buildCacheDir("/src/orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := buildCacheDir("/src/orders")
+	b, _ := buildCacheDir("/src/gateway")
+
+	if a != again {
+		t.Errorf("cache dir not stable: %q vs %q", a, again)
+	}
+	if a == b {
+		t.Errorf("different projects share cache dir %q", a)
+	}
+	if want := filepath.Join(home, ".kindling", "build-cache"); filepath.Dir(a) != want {
+		t.Errorf("cache dir %q not under %q", a, want)
+	}
+	if info, err := os.Stat(a); err != nil || !info.IsDir() {
+		t.Errorf("cache dir %q was not created", a)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KB"},
+		{5 << 20, "5.0 MB"},
+		{3 << 30, "3.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.in); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
 func TestGoarchToDotnet(t *testing.T) {
 	tests := []struct {
 		in, want string
@@ -867,6 +1031,7 @@ func TestRuntimeTable_KeyEntries(t *testing.T) {
 		"nginx", "caddy",
 		// Compiled
 		"go", "java", "kotlin", "dotnet", "cargo", "rustc", "gcc", "zig",
+		"swift", "dart", "scala", "sbt",
 	}
 	for _, key := range expectedKeys {
 		if _, ok := runtimeTable[key]; !ok {
@@ -889,7 +1054,7 @@ func TestRuntimeTable_Modes(t *testing.T) {
 	}
 
 	// Verify compiled runtimes
-	compiledRuntimes := []string{"go", "java", "kotlin", "dotnet", "cargo", "rustc", "gcc", "zig"}
+	compiledRuntimes := []string{"go", "java", "kotlin", "dotnet", "cargo", "rustc", "gcc", "zig", "swift", "dart", "scala", "sbt"}
 	for _, key := range compiledRuntimes {
 		p := runtimeTable[key]
 		if p.Mode != modeRebuild {
@@ -939,6 +1104,77 @@ func TestRuntimeTable_GoProfile(t *testing.T) {
 	}
 }
 
+func TestBuildCmdTool(t *testing.T) {
+	cases := map[string]string{
+		runtimeTable["go"].BuildCmd:     "go",
+		runtimeTable["cargo"].BuildCmd:  "cargo",
+		runtimeTable["dotnet"].BuildCmd: "dotnet",
+		"CGO_ENABLED=0 go build .":      "go",
+		"":                              "",
+	}
+	for cmd, want := range cases {
+		if got := buildCmdTool(cmd); got != want {
+			t.Errorf("buildCmdTool(%q) = %q, want %q", cmd, got, want)
+		}
+	}
+}
+
+func TestContainerBuildInstallsToResolvedBinary(t *testing.T) {
+	wrapped := `sh -c touch /tmp/.kindling-sync-wrapper && while true; do server --port 8080 & PID=$!; wait $PID; done`
+	var looked string
+	binDest := containerBinDest(wrapped, "/app", func(name string) string {
+		looked = name
+		return "/usr/local/bin/server"
+	})
+	if looked != "server" || binDest != "/usr/local/bin/server" {
+		t.Fatalf("containerBinDest() = %q after resolving %q, want /usr/local/bin/server", binDest, looked)
+	}
+
+	script := installBuildOutputScript(runtimeTable["go"].BuildOutput, binDest)
+	want := `cp "/tmp/_kindling_bin" "/usr/local/bin/server.kindling-new" && ` +
+		`chmod +x "/usr/local/bin/server.kindling-new" && ` +
+		`mv -f "/usr/local/bin/server.kindling-new" "/usr/local/bin/server"`
+	if script != want {
+		t.Errorf("installBuildOutputScript() =\n  %s\nwant\n  %s", script, want)
+	}
+}
+
+func TestContainerBinDest_Fallbacks(t *testing.T) {
+	none := func(string) string { return "" }
+	cases := []struct{ cmd, want string }{
+		{"", "/app"},
+		{"/srv/api --debug", "/srv/api"},
+		{"api --debug", "/app/api"},
+	}
+	for _, tc := range cases {
+		if got := containerBinDest(tc.cmd, "/app", none); got != tc.want {
+			t.Errorf("containerBinDest(%q) = %q, want %q", tc.cmd, got, tc.want)
+		}
+	}
+}
+
+func TestValidateSyncReplicas(t *testing.T) {
+	if err := validateSyncReplicas(0, true, true); err != nil {
+		t.Errorf("unset --replicas should combine with anything: %v", err)
+	}
+	if err := validateSyncReplicas(3, false, false); err != nil {
+		t.Errorf("--replicas 3 rejected: %v", err)
+	}
+	if err := validateSyncReplicas(-1, false, false); err == nil {
+		t.Error("negative --replicas accepted")
+	}
+	if err := validateSyncReplicas(2, true, false); err == nil {
+		t.Error("--replicas with --diff accepted")
+	}
+	if err := validateSyncReplicas(2, false, true); err == nil {
+		t.Error("--replicas with --build-only accepted")
+	}
+
+	if syncRollsOnChange(1) || !syncRollsOnChange(2) {
+		t.Error("only more than one replica should switch restarts to a rollout")
+	}
+}
+
 func TestRuntimeTable_AllHaveNames(t *testing.T) {
 	for key, p := range runtimeTable {
 		if p.Name == "" {
@@ -1140,6 +1376,270 @@ func TestMatchRuntimeEndToEnd(t *testing.T) {
 // LoadImageTag (from core/load.go)
 // ════════════════════════════════════════════════════════════════════
 
+func TestIsShellProcess(t *testing.T) {
+	tests := []struct {
+		cmdline string
+		want    bool
+	}{
+		{"/bin/sh -c nginx -g daemon off;", true},
+		{"bash /entrypoint.sh", true},
+		{"sh -c touch /tmp/.kindling-sync-wrapper && echo 1 > /tmp/.kindling-sync-wrapper && while true; do puma & PID=$!; done", true},
+		{"/usr/local/bin/tini -- sh -c .kindling-sync-wrapper", true},
+		{"nginx: master process nginx -g daemon off;", false},
+		{"/usr/local/bin/python /usr/local/bin/gunicorn app:app", false},
+		{"puma 6.4.0 (tcp://0.0.0.0:3000) [app]", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isShellProcess(tt.cmdline); got != tt.want {
+			t.Errorf("isShellProcess(%q) = %v, want %v", tt.cmdline, got, tt.want)
+		}
+	}
+}
+
+func TestWorkersReplaced(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          bool
+	}{
+		{"unchanged", "12 13", "12 13", false},
+		{"all replaced", "12 13", "40 41", true},
+		{"one replaced", "12 13", "12 41", true},
+		{"worker added only", "12", "12 41", false},
+		{"all exited", "12 13\n", "", true},
+	}
+	for _, tt := range tests {
+		if got := workersReplaced(tt.before, tt.after); got != tt.want {
+			t.Errorf("%s: workersReplaced(%q, %q) = %v, want %v", tt.name, tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestWrapperRevertPatch(t *testing.T) {
+	tests := []struct {
+		specCmd string
+		want    string
+	}{
+		{`["node","server.js"]`, `{"spec":{"template":{"spec":{"containers":[{"name":"api","command":["node","server.js"]}]}}}}`},
+		{"", `{"spec":{"template":{"spec":{"containers":[{"name":"api","command":null}]}}}}`},
+		{"[]\n", `{"spec":{"template":{"spec":{"containers":[{"name":"api","command":null}]}}}}`},
+	}
+	for _, tt := range tests {
+		if got := wrapperRevertPatch("api", tt.specCmd, false); got != tt.want {
+			t.Errorf("wrapperRevertPatch(%q) = %s, want %s", tt.specCmd, got, tt.want)
+		}
+	}
+}
+
+func TestWrapperRevertPatch_RemovesTmpEmptyDir(t *testing.T) {
+	var patch struct {
+		Spec struct {
+			Template struct {
+				Spec struct {
+					Containers []struct {
+						Name         string              `json:"name"`
+						Command      []string            `json:"command"`
+						VolumeMounts []map[string]string `json:"volumeMounts"`
+					} `json:"containers"`
+					Volumes []map[string]string `json:"volumes"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	raw := wrapperRevertPatch("api", `["node","server.js"]`, true)
+	if err := json.Unmarshal([]byte(raw), &patch); err != nil {
+		t.Fatalf("wrapperRevertPatch(tmp) is not valid JSON: %v\n%s", err, raw)
+	}
+	pod := patch.Spec.Template.Spec
+	if len(pod.Containers) != 1 || strings.Join(pod.Containers[0].Command, " ") != "node server.js" {
+		t.Errorf("containers = %+v, want command restored", pod.Containers)
+	}
+	want := map[string]string{"mountPath": "/tmp", "$patch": "delete"}
+	if mounts := pod.Containers[0].VolumeMounts; len(mounts) != 1 || !reflect.DeepEqual(mounts[0], want) {
+		t.Errorf("volumeMounts = %v, want the /tmp mount deleted", mounts)
+	}
+	want = map[string]string{"name": "kindling-tmp", "$patch": "delete"}
+	if len(pod.Volumes) != 1 || !reflect.DeepEqual(pod.Volumes[0], want) {
+		t.Errorf("volumes = %v, want kindling-tmp deleted", pod.Volumes)
+	}
+}
+
+func TestWrapperPatch(t *testing.T) {
+	for _, tmp := range []bool{false, true} {
+		var patch struct {
+			Spec struct {
+				Template struct {
+					Spec struct {
+						Containers []struct {
+							Name         string   `json:"name"`
+							Command      []string `json:"command"`
+							VolumeMounts []struct {
+								Name      string `json:"name"`
+								MountPath string `json:"mountPath"`
+							} `json:"volumeMounts"`
+						} `json:"containers"`
+						Volumes []struct {
+							Name string `json:"name"`
+						} `json:"volumes"`
+					} `json:"spec"`
+				} `json:"template"`
+			} `json:"spec"`
+		}
+		raw := wrapperPatch("api", `echo "hi" && node server.js`, tmp)
+		if err := json.Unmarshal([]byte(raw), &patch); err != nil {
+			t.Fatalf("wrapperPatch(tmp=%v) is not valid JSON: %v\n%s", tmp, err, raw)
+		}
+		pod := patch.Spec.Template.Spec
+		if len(pod.Containers) != 1 || pod.Containers[0].Command[2] != `echo "hi" && node server.js` {
+			t.Errorf("wrapperPatch(tmp=%v) containers = %+v", tmp, pod.Containers)
+		}
+		mounted := len(pod.Containers[0].VolumeMounts) == 1 && pod.Containers[0].VolumeMounts[0].MountPath == "/tmp" &&
+			len(pod.Volumes) == 1 && pod.Volumes[0].Name == pod.Containers[0].VolumeMounts[0].Name
+		if mounted != tmp {
+			t.Errorf("wrapperPatch(tmp=%v) mounts /tmp = %v:\n%s", tmp, mounted, raw)
+		}
+	}
+}
+
+func TestIsReadOnlyFSOutput(t *testing.T) {
+	tests := map[string]bool{
+		"touch: /tmp/.kindling-rw-probe: Read-only file system\ncommand terminated with exit code 1": true,
+		"touch: cannot touch '/app/.kindling-rw-probe': Read-only file system":                       true,
+		"touch: /app/.kindling-rw-probe: Permission denied":                                          false,
+		`exec: "sh": executable file not found in $PATH`:                                             false,
+	}
+	for out, want := range tests {
+		if got := isReadOnlyFSOutput(out); got != want {
+			t.Errorf("isReadOnlyFSOutput(%q) = %v, want %v", out, got, want)
+		}
+	}
+}
+
+// ════════════════════════════════════════════════════════════════════
+// --diff
+// ════════════════════════════════════════════════════════════════════
+
+func TestLocalChecksums(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	os.MkdirAll(filepath.Join(dir, "node_modules", "x"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "app.js"), []byte("hello\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "cache.pyc"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, "node_modules", "x", "index.js"), []byte("x"), 0644)
+
+	sums, err := localChecksums(dir, "/app", defaultExcludes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"/app/src/app.js": "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+	}
+	if !reflect.DeepEqual(sums, want) {
+		t.Errorf("localChecksums = %v, want %v", sums, want)
+	}
+}
+
+func TestParseSha256sum(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	out := sum + "  /app/a b.txt\n" + sum + " */app/bin\nsha256sum: /app/gone: No such file or directory"
+	want := map[string]string{"/app/a b.txt": sum, "/app/bin": sum}
+	if got := parseSha256sum(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSha256sum = %v, want %v", got, want)
+	}
+}
+
+func TestDiffChecksums(t *testing.T) {
+	local := map[string]string{"/app/new.js": "1", "/app/same.js": "2", "/app/edit.js": "3"}
+	remote := map[string]string{"/app/same.js": "2", "/app/edit.js": "4", "/app/old.js": "5"}
+	added, changed, deleted := diffChecksums(local, remote)
+	if !reflect.DeepEqual(added, []string{"/app/new.js"}) ||
+		!reflect.DeepEqual(changed, []string{"/app/edit.js"}) ||
+		!reflect.DeepEqual(deleted, []string{"/app/old.js"}) {
+		t.Errorf("diffChecksums = %v, %v, %v", added, changed, deleted)
+	}
+}
+
+// ════════════════════════════════════════════════════════════════════
+// --preserve-mode
+// ════════════════════════════════════════════════════════════════════
+
+func TestLocalFileModes(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	os.WriteFile(filepath.Join(dir, "scripts", "start.sh"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(dir, "app.py"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, "cache.pyc"), []byte("x"), 0644)
+	os.Chmod(filepath.Join(dir, "scripts", "start.sh"), 0755) // not subject to umask
+
+	modes, err := localFileModes(dir, "/app", defaultExcludes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modes["/app/scripts/start.sh"] != 0755 || len(modes) != 2 {
+		t.Errorf("localFileModes = %v", modes)
+	}
+}
+
+func TestChmodCommands(t *testing.T) {
+	modes := map[string]os.FileMode{
+		"/app/b.sh":  0755,
+		"/app/a.sh":  0755,
+		"/app/x.txt": 0644,
+	}
+	want := [][]string{
+		{"chmod", "644", "/app/x.txt"},
+		{"chmod", "755", "/app/a.sh", "/app/b.sh"},
+	}
+	if got := chmodCommands(modes); !reflect.DeepEqual(got, want) {
+		t.Errorf("chmodCommands = %v, want %v", got, want)
+	}
+
+	many := make(map[string]os.FileMode)
+	for i := range syncDiffBatch + 1 {
+		many[fmt.Sprintf("/app/%03d", i)] = 0644
+	}
+	if got := chmodCommands(many); len(got) != 2 || len(got[1]) != 3 {
+		t.Errorf("chmodCommands should split at %d paths, got %d commands", syncDiffBatch, len(got))
+	}
+}
+
+// ════════════════════════════════════════════════════════════════════
+// Local builds
+// ════════════════════════════════════════════════════════════════════
+
+func TestIndentWriter(t *testing.T) {
+	var buf strings.Builder
+	w := &indentWriter{w: &buf, prefix: "> "}
+	fmt.Fprint(w, "one\ntw")
+	fmt.Fprint(w, "o\nthree\n")
+	if got, want := buf.String(), "> one\n> two\n> three\n"; got != want {
+		t.Errorf("indentWriter wrote %q, want %q", got, want)
+	}
+}
+
+func TestRunLocalBuild(t *testing.T) {
+	dir := t.TempDir()
+	if err := runLocalBuild(dir, "echo built > out.txt", time.Minute); err != nil {
+		t.Fatalf("runLocalBuild: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out.txt")); err != nil {
+		t.Errorf("build should run in dir: %v", err)
+	}
+	if err := runLocalBuild(dir, "exit 3", 0); err == nil {
+		t.Error("a failing build should return an error")
+	}
+
+	start := time.Now()
+	err := runLocalBuild(dir, "sleep 30 & sleep 30", 200*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "--build-timeout") {
+		t.Errorf("err = %v, want a --build-timeout error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("timed-out build took %s to stop — process group not killed", elapsed)
+	}
+}
+
 func TestLoadImageTag(t *testing.T) {
 	tag := core.LoadImageTag("orders")
 
@@ -1186,3 +1686,110 @@ func TestLoadImageTag_UniqueTimestamps(t *testing.T) {
 		t.Errorf("tags for different services should differ: %q vs %q", tag1, tag2)
 	}
 }
+
+// ════════════════════════════════════════════════════════════════════
+// sync manifest
+// ════════════════════════════════════════════════════════════════════
+
+func TestParseSyncManifest(t *testing.T) {
+	data := []byte(`# dev sync targets
+targets:
+  - deployment: orders
+    src: ./services/orders
+    restart: true
+    exclude: ["*.log", tmp]
+  - name: gw
+    deployment: gateway
+    namespace: dev
+    buildCmd: "go build -o ./bin/gw ."
+    buildOutput: ./bin/gw
+    exclude:
+      - vendor
+      - '*.tmp'
+  - deployment: web
+    exclude:
+      - node_modules
+    restart: true
+`)
+	got, err := parseSyncManifest(data)
+	if err != nil {
+		t.Fatalf("parseSyncManifest: %v", err)
+	}
+	want := []syncTarget{
+		{Name: "orders", Deployment: "orders", Src: "./services/orders", Restart: true, Exclude: []string{"*.log", "tmp"}},
+		{Name: "gw", Deployment: "gateway", Namespace: "dev", BuildCmd: "go build -o ./bin/gw .", BuildOutput: "./bin/gw", Exclude: []string{"vendor", "*.tmp"}},
+		{Name: "web", Deployment: "web", Restart: true, Exclude: []string{"node_modules"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSyncManifest =\n  %+v\nwant\n  %+v", got, want)
+	}
+}
+
+func TestParseSyncManifest_Invalid(t *testing.T) {
+	tests := []struct {
+		name, data, wantErr string
+	}{
+		{"empty", "targets:\n", "no targets"},
+		{"top-level key", "deployments:\n  - deployment: a\n", "unknown top-level key"},
+		{"unknown key", "targets:\n  - deployment: a\n    restrat: true\n", `line 3: unknown key "restrat"`},
+		{"missing deployment", "targets:\n  - src: ./a\n", "target 1: deployment is required"},
+		{"duplicate name", "targets:\n  - deployment: a\n  - deployment: a\n", `duplicate target name "a"`},
+		{"bad restart", "targets:\n  - deployment: a\n    restart: maybe\n", "restart must be true or false"},
+		{"bad exclude", "targets:\n  - deployment: a\n    exclude: tmp\n", "expected a list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSyncManifest([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadSyncManifest_ResolvesSrc(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, defaultSyncManifest)
+	data := "targets:\n  - deployment: orders\n    src: services/orders\n  - deployment: web\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	targets, err := loadSyncManifest(path)
+	if err != nil {
+		t.Fatalf("loadSyncManifest: %v", err)
+	}
+	if got, want := targets[0].Src, filepath.Join(dir, "services/orders"); got != want {
+		t.Errorf("src = %q, want %q", got, want)
+	}
+	if got := targets[1].Src; got != dir {
+		t.Errorf("default src = %q, want manifest dir %q", got, dir)
+	}
+}
+
+func TestSelectSyncTargets(t *testing.T) {
+	targets := []syncTarget{{Name: "orders"}, {Name: "gw"}}
+	if got, _ := selectSyncTargets(targets, ""); len(got) != 2 {
+		t.Errorf("no --target should select all, got %d", len(got))
+	}
+	got, err := selectSyncTargets(targets, "gw")
+	if err != nil || len(got) != 1 || got[0].Name != "gw" {
+		t.Errorf("selectSyncTargets(gw) = %v, %v", got, err)
+	}
+	if _, err := selectSyncTargets(targets, "nope"); err == nil || !strings.Contains(err.Error(), "orders, gw") {
+		t.Errorf("unknown target err = %v, want the known names listed", err)
+	}
+}
+
+func TestSyncTargetFlagValues(t *testing.T) {
+	tgt := syncTarget{Name: "gw", Deployment: "gateway", Src: "/src/gw", Restart: true, BuildCmd: "make"}
+	want := map[string]string{"deployment": "gateway", "src": "/src/gw", "restart": "true", "build-cmd": "make"}
+	if got := tgt.flagValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("flagValues = %v, want %v", got, want)
+	}
+	// Every flag a target can set must exist on the sync command.
+	for _, name := range syncTargetFlags {
+		if syncCmd.Flags().Lookup(name) == nil {
+			t.Errorf("manifest maps to unknown flag --%s", name)
+		}
+	}
+}
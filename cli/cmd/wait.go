@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait <name> [name...]",
+	Short: "Block until DevStagingEnvironments report Ready",
+	Long: `Polls each DevStagingEnvironment's status until its Ready condition is
+True, printing DeploymentReady, ServiceReady, and DependenciesReady as
+the operator reports them. Exits non-zero on timeout — use it as a CI
+gate after the environment is applied.
+
+Examples:
+  kindling wait jeff-orders
+  kindling wait jeff-orders jeff-gateway --timeout 10m
+  kindling wait jeff-orders -n staging`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runWait,
+}
+
+var (
+	waitNamespace string
+	waitTimeout   time.Duration
+)
+
+// waitPollInterval is how often the DSE status is re-read while waiting.
+const waitPollInterval = 2 * time.Second
+
+func init() {
+	waitCmd.Flags().StringVarP(&waitNamespace, "namespace", "n", "default", "Namespace of the environments")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 5*time.Minute, "Give up after this long")
+	rootCmd.AddCommand(waitCmd)
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	header("Waiting for DevStagingEnvironments")
+	getArgs := append([]string{"get", "devstagingenvironments"}, args...)
+	getArgs = append(getArgs, "-n", waitNamespace, "--context", kindContext(), "-o", "json")
+	return waitForEnvironments(getArgs, len(args), waitTimeout)
+}
+
+// dseProgress is the readiness the operator has reported for one
+// DevStagingEnvironment.
+type dseProgress struct {
+	Name              string
+	Namespace         string
+	DeploymentReady   bool
+	ServiceReady      bool
+	DependenciesReady bool
+	Ready             bool
+	Message           string // Ready condition message while not ready
+}
+
+// key identifies the environment across polls.
+func (p dseProgress) key() string { return p.Namespace + "/" + p.Name }
+
+// steps returns the per-step readiness in the order the operator works
+// through them.
+func (p dseProgress) steps() []struct {
+	name  string
+	ready bool
+} {
+	return []struct {
+		name  string
+		ready bool
+	}{
+		{"DeploymentReady", p.DeploymentReady},
+		{"ServiceReady", p.ServiceReady},
+		{"DependenciesReady", p.DependenciesReady},
+		{"Ready", p.Ready},
+	}
+}
+
+// pendingSteps lists the steps that are not ready yet.
+func (p dseProgress) pendingSteps() []string {
+	var pending []string
+	for _, s := range p.steps() {
+		if !s.ready {
+			pending = append(pending, s.name)
+		}
+	}
+	return pending
+}
+
+// parseDSEProgress reads kubectl get -o json output — a single object or
+// a List, possibly mixed with other kinds when read with -f — and returns
+// the DevStagingEnvironments in it.
+func parseDSEProgress(data []byte) ([]dseProgress, error) {
+	type dseObject struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Status struct {
+			DeploymentReady   bool `json:"deploymentReady"`
+			ServiceReady      bool `json:"serviceReady"`
+			DependenciesReady bool `json:"dependenciesReady"`
+			Conditions        []struct {
+				Type    string `json:"type"`
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"conditions"`
+		} `json:"status"`
+	}
+	var obj struct {
+		dseObject
+		Items []dseObject `json:"items"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("parse DevStagingEnvironments: %w", err)
+	}
+	items := obj.Items
+	if !strings.HasSuffix(obj.Kind, "List") {
+		items = []dseObject{obj.dseObject}
+	}
+
+	var out []dseProgress
+	for _, item := range items {
+		if item.Kind != "" && item.Kind != "DevStagingEnvironment" {
+			continue
+		}
+		p := dseProgress{
+			Name:              item.Metadata.Name,
+			Namespace:         item.Metadata.Namespace,
+			DeploymentReady:   item.Status.DeploymentReady,
+			ServiceReady:      item.Status.ServiceReady,
+			DependenciesReady: item.Status.DependenciesReady,
+		}
+		for _, c := range item.Status.Conditions {
+			if c.Type == "Ready" {
+				p.Ready = c.Status == "True"
+				if !p.Ready {
+					p.Message = c.Message
+				}
+			}
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// waitForEnvironments polls `kubectl <getArgs>` until want environments
+// are all Ready, printing each step as it turns ready. want is the number
+// of environments expected (0 = whatever the query returns).
+func waitForEnvironments(getArgs []string, want int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	seen := map[string]bool{} // "<ns>/<name>/<step>" already reported
+	var last []dseProgress
+	var lastErr error
+
+	for {
+		out, err := runCapture("kubectl", getArgs...)
+		if err == nil {
+			last, err = parseDSEProgress([]byte(out))
+		}
+		lastErr = err
+
+		if err == nil {
+			allReady := len(last) > 0 && (want == 0 || len(last) >= want)
+			for _, p := range last {
+				for _, s := range p.steps() {
+					id := p.key() + "/" + s.name
+					if s.ready && !seen[id] {
+						seen[id] = true
+						step("✅", fmt.Sprintf("%s: %s", p.Name, s.name))
+					}
+				}
+				if !p.Ready {
+					allReady = false
+				}
+			}
+			if allReady {
+				success(fmt.Sprintf("%d environment(s) ready", len(last)))
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(waitPollInterval)
+	}
+
+	if len(last) == 0 {
+		if lastErr != nil {
+			return fmt.Errorf("timed out after %s: %v", timeout, lastErr)
+		}
+		return fmt.Errorf("timed out after %s: no DevStagingEnvironments found", timeout)
+	}
+	var notReady []string
+	for _, p := range last {
+		if p.Ready {
+			continue
+		}
+		line := fmt.Sprintf("%s (waiting on %s)", p.Name, strings.Join(p.pendingSteps(), ", "))
+		if p.Message != "" {
+			line += ": " + p.Message
+		}
+		notReady = append(notReady, line)
+	}
+	if len(notReady) == 0 {
+		notReady = append(notReady, fmt.Sprintf("found %d of %d environments", len(last), want))
+	}
+	return fmt.Errorf("timed out after %s — %s", timeout, strings.Join(notReady, "; "))
+}
@@ -11,22 +11,36 @@ import (
 	"strings"
 )
 
-// ClusterContext returns the kubectl --context value for a Kind cluster.
+// KubeContext, when set, is the kubectl context every helper in this
+// package uses instead of the Kind cluster's. The CLI sets it from
+// --kube-context before any command runs.
+var KubeContext string
+
+// ClusterContext returns the kubectl --context value for a Kind cluster,
+// or KubeContext when it is set.
 func ClusterContext(clusterName string) string {
+	if KubeContext != "" {
+		return KubeContext
+	}
 	return "kind-" + clusterName
 }
 
+// KubectlArgs returns the kubectl arguments that run args against the
+// named cluster, with --context injected.
+func KubectlArgs(clusterName string, args ...string) []string {
+	return append([]string{"--context", ClusterContext(clusterName)}, args...)
+}
+
 // Kubectl runs a kubectl command against the named Kind cluster and returns
 // combined stdout. The --context flag is injected automatically.
 func Kubectl(clusterName string, args ...string) (string, error) {
-	full := append([]string{"--context", ClusterContext(clusterName)}, args...)
-	return RunCapture("kubectl", full...)
+	return RunCapture("kubectl", KubectlArgs(clusterName, args...)...)
 }
 
 // KubectlApplyStdin pipes the given YAML to `kubectl apply -f -` with the
 // correct cluster context.
 func KubectlApplyStdin(clusterName, yaml string) (string, error) {
-	cmd := exec.Command("kubectl", "--context", ClusterContext(clusterName), "apply", "-f", "-")
+	cmd := exec.Command("kubectl", KubectlArgs(clusterName, "apply", "-f", "-")...)
 	cmd.Stdin = strings.NewReader(yaml)
 	out, err := cmd.CombinedOutput()
 	return strings.TrimSpace(string(out)), err
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -26,3 +27,18 @@ func TestClusterContext(t *testing.T) {
 		})
 	}
 }
+
+func TestKubectlArgs_KubeContextOverride(t *testing.T) {
+	defer func() { KubeContext = "" }()
+
+	got := KubectlArgs("dev", "get", "pods")
+	if want := []string{"--context", "kind-dev", "get", "pods"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KubectlArgs = %v, want %v", got, want)
+	}
+
+	KubeContext = "staging"
+	got = KubectlArgs("dev", "get", "pods")
+	if want := []string{"--context", "staging", "get", "pods"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KubectlArgs with KubeContext = %v, want %v", got, want)
+	}
+}
@@ -1,7 +1,10 @@
 package core
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -123,3 +126,128 @@ func ParseSecretKeys(jsonData string) []string {
 	}
 	return keys
 }
+
+// SecretInfo describes a kindling-managed secret without exposing its value.
+type SecretInfo struct {
+	Name        string   // K8s Secret name (kindling-secret-<name>)
+	Keys        []string // data keys, sorted
+	ValueLength int      // length of the stored value, or -1 if unknown
+}
+
+// ListSecretInfo returns every kindling-managed secret in the namespace,
+// sorted by name.
+func ListSecretInfo(clusterName, namespace string) ([]SecretInfo, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	out, err := Kubectl(clusterName, "get", "secrets",
+		"-n", namespace,
+		"-l", SecretsLabelKey+"="+SecretsLabelValue,
+		"-o", "json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSecretInfo(out)
+}
+
+// ParseSecretInfo parses `kubectl get secrets -o json` output. Secrets made
+// by CreateSecret hold the value under both the logical name and "value";
+// the length is read from "value", or from the only key if there is one.
+func ParseSecretInfo(jsonData string) ([]SecretInfo, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Data map[string]string `json:"data"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(jsonData), &list); err != nil {
+		return nil, fmt.Errorf("parse secret list: %w", err)
+	}
+
+	infos := make([]SecretInfo, 0, len(list.Items))
+	for _, item := range list.Items {
+		info := SecretInfo{Name: item.Metadata.Name, ValueLength: -1}
+		for k := range item.Data {
+			info.Keys = append(info.Keys, k)
+		}
+		sort.Strings(info.Keys)
+
+		encoded, ok := item.Data["value"]
+		if !ok && len(item.Data) == 1 {
+			encoded, ok = item.Data[info.Keys[0]]
+		}
+		if ok {
+			if decoded, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+				info.ValueLength = len(decoded)
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// SecretReferences maps each Secret name referenced by a secretKeyRef in a
+// DevStagingEnvironment in the namespace to the environments that use it.
+func SecretReferences(clusterName, namespace string) (map[string][]string, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	out, err := Kubectl(clusterName, "get", "devstagingenvironments",
+		"-n", namespace, "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSecretReferences(out)
+}
+
+// ParseSecretReferences parses `kubectl get devstagingenvironments -o json`
+// output. Every secretKeyRef in a spec counts, whether on the app, a
+// sidecar, or a dependency. Environment names are sorted.
+func ParseSecretReferences(jsonData string) (map[string][]string, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec any `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(jsonData), &list); err != nil {
+		return nil, fmt.Errorf("parse DevStagingEnvironment list: %w", err)
+	}
+
+	refs := make(map[string][]string)
+	for _, item := range list.Items {
+		seen := make(map[string]bool)
+		collectSecretKeyRefs(item.Spec, seen)
+		for name := range seen {
+			refs[name] = append(refs[name], item.Metadata.Name)
+		}
+	}
+	for name := range refs {
+		sort.Strings(refs[name])
+	}
+	return refs, nil
+}
+
+// collectSecretKeyRefs adds the name of every secretKeyRef under v to seen.
+func collectSecretKeyRefs(v any, seen map[string]bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		if ref, ok := v["secretKeyRef"].(map[string]any); ok {
+			if name, ok := ref["name"].(string); ok && name != "" {
+				seen[name] = true
+			}
+		}
+		for _, child := range v {
+			collectSecretKeyRefs(child, seen)
+		}
+	case []any:
+		for _, child := range v {
+			collectSecretKeyRefs(child, seen)
+		}
+	}
+}
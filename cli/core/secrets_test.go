@@ -1,6 +1,7 @@
 package core
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -117,3 +118,74 @@ func TestSecretsConstants(t *testing.T) {
 		t.Errorf("SecretsLabelValue = %q", SecretsLabelValue)
 	}
 }
+
+// ────────────────────────────────────────────────────────────────────────────
+// ParseSecretInfo
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestParseSecretInfo(t *testing.T) {
+	// "c2tfdGVzdF8xMjM=" is "sk_test_123" (11 bytes); "YWJj" is "abc".
+	data := `{"items":[
+		{"metadata":{"name":"kindling-secret-stripe-key"},"data":{"STRIPE_KEY":"c2tfdGVzdF8xMjM=","value":"c2tfdGVzdF8xMjM="}},
+		{"metadata":{"name":"kindling-secret-api-token"},"data":{"API_TOKEN":"YWJj"}},
+		{"metadata":{"name":"kindling-secret-odd"},"data":{"a":"YWJj","b":"YWJj"}}
+	]}`
+	got, err := ParseSecretInfo(data)
+	if err != nil {
+		t.Fatalf("ParseSecretInfo error: %v", err)
+	}
+	want := []SecretInfo{
+		{Name: "kindling-secret-api-token", Keys: []string{"API_TOKEN"}, ValueLength: 3},
+		{Name: "kindling-secret-odd", Keys: []string{"a", "b"}, ValueLength: -1},
+		{Name: "kindling-secret-stripe-key", Keys: []string{"STRIPE_KEY", "value"}, ValueLength: 11},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d secrets, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].ValueLength != want[i].ValueLength ||
+			strings.Join(got[i].Keys, ",") != strings.Join(want[i].Keys, ",") {
+			t.Errorf("secret %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseSecretInfo_InvalidJSON(t *testing.T) {
+	if _, err := ParseSecretInfo("not json"); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// ParseSecretReferences
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestParseSecretReferences(t *testing.T) {
+	data := `{"items":[
+		{"metadata":{"name":"orders"},"spec":{"deployment":{
+			"env":[
+				{"name":"STRIPE_KEY","valueFrom":{"secretKeyRef":{"name":"kindling-secret-stripe-key","key":"value"}}},
+				{"name":"PLAIN","value":"x"}
+			],
+			"sidecars":[{"name":"proxy","env":[{"name":"TOKEN","valueFrom":{"secretKeyRef":{"name":"kindling-secret-api-token","key":"value"}}}]}]
+		}}},
+		{"metadata":{"name":"billing"},"spec":{"deployment":{"env":[
+			{"name":"STRIPE_KEY","valueFrom":{"secretKeyRef":{"name":"kindling-secret-stripe-key","key":"value"}}},
+			{"name":"AGAIN","valueFrom":{"secretKeyRef":{"name":"kindling-secret-stripe-key","key":"STRIPE_KEY"}}}
+		]}}},
+		{"metadata":{"name":"idle"},"spec":{"deployment":{"image":"idle:dev"}}}
+	]}`
+	got, err := ParseSecretReferences(data)
+	if err != nil {
+		t.Fatalf("ParseSecretReferences error: %v", err)
+	}
+	if users := strings.Join(got["kindling-secret-stripe-key"], ","); users != "billing,orders" {
+		t.Errorf("stripe-key used by %q, want billing,orders (sorted, deduplicated)", users)
+	}
+	if users := strings.Join(got["kindling-secret-api-token"], ","); users != "orders" {
+		t.Errorf("sidecar refs should count, got %q", users)
+	}
+	if len(got) != 2 {
+		t.Errorf("expected 2 referenced secrets, got %v", got)
+	}
+}
@@ -118,7 +118,17 @@ func StartCloudflaredTunnel(port int, maxWaitURL int, waitDNS bool) (*TunnelResu
 
 // StartNgrokTunnel starts an ngrok tunnel on the given port.
 func StartNgrokTunnel(port int) (*TunnelResult, error) {
-	tunnelCmd := exec.Command("ngrok", "http",
+	return startNgrok("http", port)
+}
+
+// StartNgrokTCPTunnel starts a raw TCP ngrok tunnel on the given port. The
+// public URL has the form tcp://<host>:<port>.
+func StartNgrokTCPTunnel(port int) (*TunnelResult, error) {
+	return startNgrok("tcp", port)
+}
+
+func startNgrok(proto string, port int) (*TunnelResult, error) {
+	tunnelCmd := exec.Command("ngrok", proto,
 		fmt.Sprintf("%d", port),
 		"--log", "stdout",
 		"--log-format", "json",
@@ -196,9 +206,27 @@ func DetectTunnelProvider() string {
 // SaveTunnelInfo persists the tunnel URL and PID to .kindling/tunnel.yaml
 // and creates a ConfigMap in the cluster so the deploy action can discover it.
 func SaveTunnelInfo(clusterName, publicURL, provider string, pid int) {
+	if cwd := writeTunnelFile(publicURL, provider, pid); cwd != "" {
+		ensureTunnelGitignored(cwd)
+		saveTunnelConfigMap(clusterName, publicURL)
+	}
+}
+
+// SaveTCPTunnelInfo persists a raw TCP tunnel to .kindling/tunnel.yaml.
+// Unlike SaveTunnelInfo it publishes no ConfigMap: a TCP endpoint is not
+// an OAuth callback host.
+func SaveTCPTunnelInfo(publicURL, provider string, pid int) {
+	if cwd := writeTunnelFile(publicURL, provider, pid); cwd != "" {
+		ensureTunnelGitignored(cwd)
+	}
+}
+
+// writeTunnelFile writes .kindling/tunnel.yaml and returns the directory
+// it was written under, or "" if the working directory is unknown.
+func writeTunnelFile(publicURL, provider string, pid int) string {
 	cwd, err := os.Getwd()
 	if err != nil {
-		return
+		return ""
 	}
 	kindlingDir := filepath.Join(cwd, ".kindling")
 	_ = os.MkdirAll(kindlingDir, 0755)
@@ -208,9 +236,7 @@ func SaveTunnelInfo(clusterName, publicURL, provider string, pid int) {
 		provider, publicURL, pid, time.Now().Format(time.RFC3339))
 
 	_ = os.WriteFile(tunnelFile, []byte(content), 0644)
-
-	ensureTunnelGitignored(cwd)
-	saveTunnelConfigMap(clusterName, publicURL)
+	return cwd
 }
 
 func saveTunnelConfigMap(clusterName, publicURL string) {
@@ -17,8 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -28,12 +30,14 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	appsv1alpha1 "github.com/jeffvincent/kindling/api/v1alpha1"
 	"github.com/jeffvincent/kindling/internal/controller"
+	webhookv1alpha1 "github.com/jeffvincent/kindling/internal/webhook/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -53,11 +57,23 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var dependencyImageMirror string
+	var imagePullSecrets string
+	var insecureSharedCreds bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&dependencyImageMirror, "dependency-image-mirror", os.Getenv("KINDLING_DEPENDENCY_IMAGE_MIRROR"),
+		"Registry prefix (e.g. mirror.internal:5000) prepended to dependency images that don't "+
+			"already name a registry host. Defaults to $KINDLING_DEPENDENCY_IMAGE_MIRROR.")
+	flag.StringVar(&imagePullSecrets, "image-pull-secrets", os.Getenv("KINDLING_IMAGE_PULL_SECRETS"),
+		"Comma-separated Secret names attached to every app and dependency pod. "+
+			"Defaults to $KINDLING_IMAGE_PULL_SECRETS.")
+	flag.BoolVar(&insecureSharedCreds, "insecure-shared-creds", os.Getenv("KINDLING_INSECURE_SHARED_CREDS") == "true",
+		"Give every dependency the same static dev password (\"devpass\") instead of a per-environment one. "+
+			"Defaults to $KINDLING_INSECURE_SHARED_CREDS.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -89,9 +105,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Dependency passwords are keyed with a per-cluster secret. The
+	// manager's cache isn't running yet, so read it with a direct client.
+	var credentialKey []byte
+	if !insecureSharedCreds {
+		c, err := client.New(mgr.GetConfig(), client.Options{Scheme: scheme})
+		if err == nil {
+			credentialKey, err = controller.LoadCredentialKey(context.Background(), c, operatorNamespace())
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to load dependency credential key")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controller.DevStagingEnvironmentReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		DependencyImageMirror: dependencyImageMirror,
+		ImagePullSecrets:      splitList(imagePullSecrets),
+		InsecureSharedCreds:   insecureSharedCreds,
+		CredentialKey:         credentialKey,
+		ValidateSpec:          webhookv1alpha1.ValidateDevStagingEnvironment,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DevStagingEnvironment")
 		os.Exit(1)
@@ -103,6 +138,17 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "CIRunnerPool")
 		os.Exit(1)
 	}
+	// The webhook needs a serving certificate, which the default install
+	// doesn't provision. Uncommenting the [WEBHOOK] and [CERTMANAGER]
+	// sections of config/default (with cert-manager installed) sets
+	// ENABLE_WEBHOOKS; without it the reconciler applies the same rules
+	// via ValidateSpec.
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = webhookv1alpha1.SetupDevStagingEnvironmentWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DevStagingEnvironment")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -120,3 +166,23 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// operatorNamespace returns the namespace the manager runs in, from the
+// downward-API POD_NAMESPACE, defaulting to the install's kindling-system.
+func operatorNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	return "kindling-system"
+}
+
+// splitList splits a comma-separated flag value, dropping blank entries.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
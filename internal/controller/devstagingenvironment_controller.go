@@ -18,29 +18,43 @@ package controller
 
 import (
 	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net/url"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	appsv1alpha1 "github.com/jeffvincent/kindling/api/v1alpha1"
+	"github.com/jeffvincent/kindling/pkg/ci"
 )
 
 // safeName converts a CR name (DNS-1123 subdomain, which allows dots) into a
@@ -55,10 +69,40 @@ type DevStagingEnvironmentReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+
+	// DependencyImageMirror is an optional registry prefix (e.g.
+	// "mirror.internal:5000") prepended to dependency images that don't
+	// already name a registry host, for air-gapped clusters.
+	DependencyImageMirror string
+
+	// ImagePullSecrets are attached to every app and dependency pod.
+	ImagePullSecrets []string
+
+	// InsecureSharedCreds gives every dependency the static registry
+	// passwords ("devpass") instead of per-environment ones.
+	InsecureSharedCreds bool
+
+	// CredentialKey is the per-cluster secret dependency passwords are
+	// derived with (see LoadCredentialKey).
+	CredentialKey []byte
+
+	// ValidateSpec, when set, runs the validating webhook's rules at the
+	// start of every reconcile. The webhook is only served when
+	// cert-manager is installed, so this is what rejects bad specs in the
+	// default install: they get Ready=False (InvalidSpec) and nothing is
+	// created.
+	ValidateSpec func(*appsv1alpha1.DevStagingEnvironment) error
 }
 
 const specHashAnnotation = "apps.example.com/spec-hash"
 
+// Annotations that tell the ingress controller a backend speaks gRPC or
+// HTTP/2: ingress-nginx reads the Ingress, Traefik reads the Service.
+const (
+	nginxBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+	traefikServersSchemeAnnotation = "traefik.ingress.kubernetes.io/service.serversscheme"
+)
+
 //+kubebuilder:rbac:groups=apps.example.com,resources=devstagingenvironments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps.example.com,resources=devstagingenvironments/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=apps.example.com,resources=devstagingenvironments/finalizers,verbs=update
@@ -66,8 +110,14 @@ const specHashAnnotation = "apps.example.com/spec-hash"
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reads the state of the cluster for a DevStagingEnvironment object and makes changes
 // to bring the cluster state closer to the desired state defined in the CR spec.
@@ -85,6 +135,53 @@ func (r *DevStagingEnvironmentReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, err
 	}
 
+	// ── Expire the CR once its TTL has elapsed ────────────────────────
+	// Deleting the CR lets owner-reference GC remove every child
+	// Deployment, Service, Secret, and PVC.
+	expiresAt, expires := expiryTime(cr)
+	if expires && cr.DeletionTimestamp.IsZero() && !time.Now().Before(expiresAt) {
+		logger.Info("DevStagingEnvironment TTL elapsed, deleting", "ttlSeconds", *cr.Spec.TTLSecondsAfterCreation)
+		r.recordEvent(cr, "Normal", "Expired", "TTL of %ds elapsed, deleting environment", *cr.Spec.TTLSecondsAfterCreation)
+		if err := r.Delete(ctx, cr, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// ── Reject specs the validating webhook would have ────────────────
+	// Nothing is created until the spec is fixed; the edit triggers the
+	// next reconcile, so there's no requeue.
+	if r.ValidateSpec != nil {
+		if err := r.ValidateSpec(cr); err != nil {
+			logger.Info("DevStagingEnvironment spec is invalid", "error", err.Error())
+			r.recordEvent(cr, "Warning", "InvalidSpec", "%v", err)
+			meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "InvalidSpec",
+				Message: err.Error(),
+			})
+			if err := r.Status().Update(ctx, cr); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// ── Reconcile the app's ServiceAccount and Role (if configured) ───
+	// Before the Deployment, so new pods don't wait on a missing account.
+	if err := r.reconcileServiceAccount(ctx, cr); err != nil {
+		r.recordEvent(cr, "Warning", "ReconcileFailed", "ServiceAccount reconciliation failed: %v", err)
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    "ServiceAccountReady",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ReconcileFailed",
+			Message: err.Error(),
+		})
+		_ = r.Status().Update(ctx, cr)
+		return ctrl.Result{}, err
+	}
+
 	// ── Step 2: Reconcile the Deployment ───────────────────────────────
 	if err := r.reconcileDeployment(ctx, cr); err != nil {
 		r.recordEvent(cr, "Warning", "ReconcileFailed", "Deployment reconciliation failed: %v", err)
@@ -124,7 +221,20 @@ func (r *DevStagingEnvironmentReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, err
 	}
 
-	// ── Step 5: Reconcile Dependencies (databases, caches, etc.) ──────
+	// ── Step 5: Reconcile the HorizontalPodAutoscaler (if configured) ─
+	if err := r.reconcileHPA(ctx, cr); err != nil {
+		r.recordEvent(cr, "Warning", "ReconcileFailed", "HorizontalPodAutoscaler reconciliation failed: %v", err)
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    "AutoscalingReady",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ReconcileFailed",
+			Message: err.Error(),
+		})
+		_ = r.Status().Update(ctx, cr)
+		return ctrl.Result{}, err
+	}
+
+	// ── Step 6: Reconcile Dependencies (databases, caches, etc.) ──────
 	if err := r.reconcileDependencies(ctx, cr); err != nil {
 		r.recordEvent(cr, "Warning", "ReconcileFailed", "Dependencies reconciliation failed: %v", err)
 		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
@@ -137,7 +247,7 @@ func (r *DevStagingEnvironmentReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, err
 	}
 
-	// ── Step 6: Update status ──────────────────────────────────────────
+	// ── Step 7: Update status ──────────────────────────────────────────
 	if err := r.updateStatus(ctx, cr); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -146,12 +256,41 @@ func (r *DevStagingEnvironmentReconciler) Reconcile(ctx context.Context, req ctr
 	// status changes (e.g. Deployment replicas becoming available).
 	if !cr.Status.DeploymentReady || !cr.Status.ServiceReady || !cr.Status.DependenciesReady {
 		logger.Info("Not all child resources are ready yet, requeueing")
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: ttlRequeue(cr, 5*time.Second)}, nil
 	}
 
 	logger.Info("Reconciliation complete")
 	r.recordEvent(cr, "Normal", "ReconcileComplete", "All resources reconciled successfully")
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: ttlRequeue(cr, 0)}, nil
+}
+
+// expiryTime returns when cr's TTL elapses. The bool is false when
+// spec.ttlSecondsAfterCreation is unset or zero.
+func expiryTime(cr *appsv1alpha1.DevStagingEnvironment) (time.Time, bool) {
+	ttl := cr.Spec.TTLSecondsAfterCreation
+	if ttl == nil || *ttl <= 0 {
+		return time.Time{}, false
+	}
+	return cr.CreationTimestamp.Add(time.Duration(*ttl) * time.Second), true
+}
+
+// ttlRequeue returns the sooner of after and the time left until cr
+// expires, so an expiring CR is reconciled (and deleted) on time. A zero
+// after means no other requeue is wanted.
+func ttlRequeue(cr *appsv1alpha1.DevStagingEnvironment, after time.Duration) time.Duration {
+	expiresAt, ok := expiryTime(cr)
+	if !ok {
+		return after
+	}
+	// Never return zero here: a zero RequeueAfter means "don't requeue".
+	remaining := time.Until(expiresAt)
+	if remaining < time.Second {
+		remaining = time.Second
+	}
+	if after == 0 || remaining < after {
+		return remaining
+	}
+	return after
 }
 
 // ────────────────────────────────────────────────────────────────────────────
@@ -162,13 +301,27 @@ func (r *DevStagingEnvironmentReconciler) reconcileDeployment(ctx context.Contex
 	logger := log.FromContext(ctx)
 	desired := r.buildDeployment(cr)
 
+	// Roll the pods when a ConfigMap or Secret they load env from changes;
+	// the kubelet only reads envFrom at container start.
+	envFromHash, err := r.envFromSourcesHash(ctx, cr)
+	if err != nil {
+		return err
+	}
+	if envFromHash != "" {
+		if desired.Spec.Template.Annotations == nil {
+			desired.Spec.Template.Annotations = map[string]string{}
+		}
+		desired.Spec.Template.Annotations[envFromHashAnnotation] = envFromHash
+		desired.Annotations[specHashAnnotation] = computeSpecHash(desired.Spec)
+	}
+
 	// Set the CR as the owner so garbage collection cleans up if the CR is deleted
 	if err := controllerutil.SetControllerReference(cr, desired, r.Scheme); err != nil {
 		return err
 	}
 
 	existing := &appsv1.Deployment{}
-	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+	err = r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			logger.Info("Creating Deployment", "name", desired.Name)
@@ -185,6 +338,10 @@ func (r *DevStagingEnvironmentReconciler) reconcileDeployment(ctx context.Contex
 		return nil
 	}
 
+	// Leave the replica count to the HPA when autoscaling is enabled
+	if cr.Spec.Deployment.Autoscaling != nil {
+		desired.Spec.Replicas = existing.Spec.Replicas
+	}
 	existing.Spec = desired.Spec
 	if existing.Annotations == nil {
 		existing.Annotations = make(map[string]string)
@@ -203,11 +360,22 @@ func (r *DevStagingEnvironmentReconciler) buildDeployment(cr *appsv1alpha1.DevSt
 	// user env vars can reference them via Kubernetes $(VAR) expansion —
 	// e.g. PG_DSN: "$(DATABASE_URL)" only resolves if DATABASE_URL is
 	// defined earlier in the env list.
-	var allEnv []corev1.EnvVar
+	// With a collector, apps export OTLP to it rather than straight to
+	// Jaeger; the collector forwards traces on.
+	hasCollector := slices.ContainsFunc(cr.Spec.Dependencies, func(d appsv1alpha1.DependencySpec) bool {
+		return d.Type == appsv1alpha1.DependencyOtelCollector
+	})
+	var depEnv []corev1.EnvVar
 	for _, dep := range cr.Spec.Dependencies {
-		allEnv = append(allEnv, buildDependencyConnectionEnvVars(cr.Name, dep)...)
+		if defaults, ok := dependencyDefaultsFor(cr, dep, r.creds()); ok {
+			envs := buildDependencyConnectionEnvVars(cr.Name, dep, defaults)
+			if hasCollector && dep.Type == appsv1alpha1.DependencyJaeger {
+				envs = slices.DeleteFunc(envs, func(e corev1.EnvVar) bool { return e.Name == "OTEL_EXPORTER_OTLP_ENDPOINT" })
+			}
+			depEnv = append(depEnv, envs...)
+		}
 	}
-	allEnv = append(allEnv, spec.Env...)
+	allEnv := append(slices.Clone(depEnv), spec.Env...)
 
 	container := corev1.Container{
 		Name:    safeName(cr.Name),
@@ -215,8 +383,11 @@ func (r *DevStagingEnvironmentReconciler) buildDeployment(cr *appsv1alpha1.DevSt
 		Command: spec.Command,
 		Args:    spec.Args,
 		Env:     allEnv,
+		// The kubelet applies Env over EnvFrom, so injected dependency
+		// vars and user Env win over keys of the same name.
+		EnvFrom: spec.EnvFrom,
 		Ports: []corev1.ContainerPort{{
-			Name:          "http",
+			Name:          appPortName(cr),
 			ContainerPort: spec.Port,
 			Protocol:      corev1.ProtocolTCP,
 		}},
@@ -227,50 +398,253 @@ func (r *DevStagingEnvironmentReconciler) buildDeployment(cr *appsv1alpha1.DevSt
 		container.Resources = buildResourceRequirements(spec.Resources)
 	}
 
+	// Mount ConfigMaps first, then the pass-through volumes
+	volumes, mounts := buildConfigMountVolumes(spec.ConfigMounts)
+	volumes = append(volumes, spec.Volumes...)
+	container.VolumeMounts = append(mounts, spec.VolumeMounts...)
+
 	// Wire up health checks if specified
-	if spec.HealthCheck != nil {
-		switch spec.HealthCheck.Type {
-		case "grpc":
-			probe := buildGRPCProbe(spec.HealthCheck, spec.Port)
-			container.LivenessProbe = probe.DeepCopy()
-			container.ReadinessProbe = probe.DeepCopy()
-		case "none":
-			// No probes — intentionally left empty
-		default: // "http" or empty
-			probe := buildHTTPProbe(spec.HealthCheck, spec.Port)
+	if hc := appHealthCheck(cr); hc != nil {
+		if probe := buildProbe(hc, spec.Port); probe != nil {
 			container.LivenessProbe = probe.DeepCopy()
-			container.ReadinessProbe = probe.DeepCopy()
+			container.ReadinessProbe = buildReadinessProbe(hc, probe)
+			container.StartupProbe = buildStartupProbe(hc, probe)
 		}
 	}
 
-	// Build init containers that wait for each dependency to accept TCP connections
-	initContainers := buildDependencyWaitInitContainers(cr)
+	container.Lifecycle = buildPreStopLifecycle(&spec)
+
+	// The HPA owns the replica count when autoscaling is enabled
+	replicas := spec.Replicas
+	if spec.Autoscaling != nil {
+		replicas = nil
+	}
+
+	// Build init containers that wait for each dependency to become ready.
+	// They run dependency images, so they follow the dependency mirror.
+	initContainers := buildDependencyWaitInitContainers(cr, r.creds())
+	for i := range initContainers {
+		initContainers[i].Image = mirrorImage(r.DependencyImageMirror, initContainers[i].Image)
+	}
 
-	return &appsv1.Deployment{
+	deploy := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      safeName(cr.Name),
 			Namespace: cr.Namespace,
 			Labels:    labels,
-			Annotations: map[string]string{
-				specHashAnnotation: computeSpecHash(cr.Spec),
-			},
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: spec.Replicas,
+			Replicas: replicas,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
+			RevisionHistoryLimit: appRevisionHistoryLimit(spec),
+			Strategy:             appDeploymentStrategy(spec),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
-					InitContainers: initContainers,
-					Containers:     []corev1.Container{container},
+					InitContainers:   initContainers,
+					Containers:       append([]corev1.Container{container}, buildSidecarContainers(spec.Sidecars)...),
+					Volumes:          volumes,
+					ImagePullSecrets: imagePullSecretRefs(r.ImagePullSecrets, spec.ImagePullSecrets),
+					// nil keeps the Kubernetes default of 30s
+					TerminationGracePeriodSeconds: spec.TerminationGracePeriodSeconds,
+					NodeSelector:                  spec.NodeSelector,
+					Tolerations:                   spec.Tolerations,
+					Affinity:                      spec.Affinity,
+					ServiceAccountName:            appServiceAccountName(cr),
 				},
 			},
 		},
 	}
+
+	if len(depEnv) > 0 {
+		deploy.Spec.Template.Annotations = map[string]string{
+			dependencyEnvHashAnnotation: computeSpecHash(depEnv),
+		}
+	}
+
+	// Hash the built spec rather than cr.Spec so anything that changes the
+	// final pod template (injected dependency URLs, registry defaults,
+	// operator flags) rolls the Deployment, and nothing else does.
+	deploy.Annotations = map[string]string{
+		specHashAnnotation: computeSpecHash(deploy.Spec),
+	}
+	return deploy
+}
+
+// envFromHashAnnotation is set on the app's pod template to a hash of the
+// resource versions of the ConfigMaps and Secrets named in
+// spec.deployment.envFrom, so editing one rolls the app.
+const envFromHashAnnotation = "apps.example.com/env-from-hash"
+
+// envFromSourcesHash hashes the resource versions of the objects cr's
+// envFrom references, or returns "" when it has none. A missing object
+// hashes as such, so creating it later rolls the pods too.
+func (r *DevStagingEnvironmentReconciler) envFromSourcesHash(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) (string, error) {
+	sources := cr.Spec.Deployment.EnvFrom
+	if len(sources) == 0 {
+		return "", nil
+	}
+	versions := make([]string, 0, len(sources))
+	for _, src := range sources {
+		var obj client.Object
+		var kind, name string
+		switch {
+		case src.ConfigMapRef != nil:
+			obj, kind, name = &corev1.ConfigMap{}, "configmap", src.ConfigMapRef.Name
+		case src.SecretRef != nil:
+			obj, kind, name = &corev1.Secret{}, "secret", src.SecretRef.Name
+		default:
+			continue
+		}
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, obj); err != nil && !errors.IsNotFound(err) {
+			return "", err
+		}
+		versions = append(versions, kind+"/"+name+"@"+obj.GetResourceVersion())
+	}
+	return computeSpecHash(versions), nil
+}
+
+// envFromConsumers maps a ConfigMap or Secret to the CRs in its namespace
+// that load env from it.
+func (r *DevStagingEnvironmentReconciler) envFromConsumers(ctx context.Context, obj client.Object) []reconcile.Request {
+	_, isSecret := obj.(*corev1.Secret)
+	list := &appsv1alpha1.DevStagingEnvironmentList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cr := range list.Items {
+		if slices.ContainsFunc(cr.Spec.Deployment.EnvFrom, func(src corev1.EnvFromSource) bool {
+			if isSecret {
+				return src.SecretRef != nil && src.SecretRef.Name == obj.GetName()
+			}
+			return src.ConfigMapRef != nil && src.ConfigMapRef.Name == obj.GetName()
+		}) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}})
+		}
+	}
+	return requests
+}
+
+// defaultRevisionHistoryLimit keeps a couple of ReplicaSets for rollback
+// instead of the Kubernetes default of 10, which push and sync cycles
+// quickly fill.
+const defaultRevisionHistoryLimit int32 = 2
+
+// appRevisionHistoryLimit returns the app Deployment's revision history
+// limit.
+func appRevisionHistoryLimit(spec appsv1alpha1.DeploymentSpec) *int32 {
+	limit := defaultRevisionHistoryLimit
+	if spec.RevisionHistoryLimit != nil {
+		limit = *spec.RevisionHistoryLimit
+	}
+	return &limit
+}
+
+// appDeploymentStrategy converts spec.deployment.strategy. Unset fields are
+// left empty so the API server fills in its defaults.
+func appDeploymentStrategy(spec appsv1alpha1.DeploymentSpec) appsv1.DeploymentStrategy {
+	s := spec.Strategy
+	if s == nil {
+		return appsv1.DeploymentStrategy{}
+	}
+	if s.Type == appsv1alpha1.DeploymentStrategyRecreate {
+		return appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	}
+	strategy := appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType}
+	if s.MaxSurge != nil || s.MaxUnavailable != nil {
+		strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{
+			MaxSurge:       s.MaxSurge,
+			MaxUnavailable: s.MaxUnavailable,
+		}
+	}
+	return strategy
+}
+
+// isGRPCApp reports whether the app serves gRPC on its port.
+func isGRPCApp(cr *appsv1alpha1.DevStagingEnvironment) bool {
+	return cr.Spec.Service.AppProtocol == "grpc"
+}
+
+// appPortName names the app's container and Service port.
+func appPortName(cr *appsv1alpha1.DevStagingEnvironment) string {
+	if isGRPCApp(cr) {
+		return "grpc"
+	}
+	return "http"
+}
+
+// appHealthCheck returns the health check to probe the app with. gRPC apps
+// get a gRPC probe when healthCheck is unset or leaves its type empty.
+func appHealthCheck(cr *appsv1alpha1.DevStagingEnvironment) *appsv1alpha1.HealthCheckSpec {
+	hc := cr.Spec.Deployment.HealthCheck
+	if !isGRPCApp(cr) {
+		return hc
+	}
+	if hc == nil {
+		return &appsv1alpha1.HealthCheckSpec{Type: "grpc"}
+	}
+	if hc.Type == "" {
+		hc = hc.DeepCopy()
+		hc.Type = "grpc"
+	}
+	return hc
+}
+
+// buildPreStopLifecycle returns the main container's preStop hook, or nil
+// when none is configured. PreStopExec wins if both are set; the webhook
+// rejects that combination.
+func buildPreStopLifecycle(spec *appsv1alpha1.DeploymentSpec) *corev1.Lifecycle {
+	var handler *corev1.LifecycleHandler
+	switch {
+	case len(spec.PreStopExec) > 0:
+		handler = &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: spec.PreStopExec},
+		}
+	case spec.PreStopHTTPGet != nil:
+		port := spec.Port
+		if spec.PreStopHTTPGet.Port != nil {
+			port = *spec.PreStopHTTPGet.Port
+		}
+		handler = &corev1.LifecycleHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: spec.PreStopHTTPGet.Path,
+				Port: intstr.FromInt(int(port)),
+			},
+		}
+	default:
+		return nil
+	}
+	return &corev1.Lifecycle{PreStop: handler}
+}
+
+// buildConfigMountVolumes turns ConfigMounts into a ConfigMap volume and a
+// read-only mount for each, named config-mount-<index>.
+func buildConfigMountVolumes(configMounts []appsv1alpha1.ConfigMount) ([]corev1.Volume, []corev1.VolumeMount) {
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for i, cm := range configMounts {
+		name := fmt.Sprintf("config-mount-%d", i)
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: cm.ConfigMap},
+					Items:                cm.Items,
+				},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: cm.MountPath,
+			ReadOnly:  true,
+		})
+	}
+	return volumes, mounts
 }
 
 // ────────────────────────────────────────────────────────────────────────────
@@ -311,6 +685,11 @@ func (r *DevStagingEnvironmentReconciler) reconcileService(ctx context.Context,
 		existing.Annotations = make(map[string]string)
 	}
 	existing.Annotations[specHashAnnotation] = desiredHash
+	if scheme, ok := desired.Annotations[traefikServersSchemeAnnotation]; ok {
+		existing.Annotations[traefikServersSchemeAnnotation] = scheme
+	} else {
+		delete(existing.Annotations, traefikServersSchemeAnnotation)
+	}
 	logger.Info("Updating Service", "name", desired.Name)
 	return r.Update(ctx, existing)
 }
@@ -333,24 +712,38 @@ func (r *DevStagingEnvironmentReconciler) buildService(cr *appsv1alpha1.DevStagi
 		svcType = corev1.ServiceTypeLoadBalancer
 	}
 
+	port := corev1.ServicePort{
+		Name:       appPortName(cr),
+		Port:       spec.Port,
+		TargetPort: intstr.FromInt(int(targetPort)),
+		Protocol:   corev1.ProtocolTCP,
+	}
+	annotations := map[string]string{
+		specHashAnnotation: computeSpecHash(cr.Spec.Service),
+	}
+	if spec.AppProtocol != "" {
+		appProtocol := spec.AppProtocol
+		if appProtocol == "h2c" {
+			// The standard name from KEP-3726.
+			appProtocol = "kubernetes.io/h2c"
+		}
+		port.AppProtocol = &appProtocol
+	}
+	if isGRPCApp(cr) || spec.AppProtocol == "h2c" {
+		annotations[traefikServersSchemeAnnotation] = "h2c"
+	}
+
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      safeName(cr.Name),
-			Namespace: cr.Namespace,
-			Labels:    labels,
-			Annotations: map[string]string{
-				specHashAnnotation: computeSpecHash(cr.Spec.Service),
-			},
+			Name:        safeName(cr.Name),
+			Namespace:   cr.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Type:     svcType,
 			Selector: labels,
-			Ports: []corev1.ServicePort{{
-				Name:       "http",
-				Port:       spec.Port,
-				TargetPort: intstr.FromInt(int(targetPort)),
-				Protocol:   corev1.ProtocolTCP,
-			}},
+			Ports:    []corev1.ServicePort{port},
 		},
 	}
 }
@@ -403,10 +796,64 @@ func (r *DevStagingEnvironmentReconciler) reconcileIngress(ctx context.Context,
 	for k, v := range desired.Annotations {
 		existing.Annotations[k] = v
 	}
+	for _, a := range backendProtocolAnnotators {
+		for _, key := range a.Keys {
+			if _, ok := desired.Annotations[key]; !ok {
+				delete(existing.Annotations, key)
+			}
+		}
+	}
 	logger.Info("Updating Ingress", "name", desired.Name)
 	return r.Update(ctx, existing)
 }
 
+// backendProtocolAnnotator sets the Ingress annotations one ingress
+// controller reads to pick the protocol it speaks to a backend.
+type backendProtocolAnnotator struct {
+	// Keys lists every annotation Annotate may set, so they can be removed
+	// when the app goes back to plain HTTP.
+	Keys []string
+	// Annotate returns the annotations for a service.appProtocol value, or
+	// nil when the controller's default (HTTP/1.1) is right.
+	Annotate func(appProtocol string) map[string]string
+}
+
+// backendProtocolAnnotators maps an ingress class name to its annotator.
+// Classes without an entry, and Ingresses without a class, use nginx's:
+// backend-protocol is the annotation gRPC users expect to see. Traefik,
+// which setup-ingress.sh installs, reads the protocol from the Service
+// (traefikServersSchemeAnnotation) instead, so its entry adds nothing to
+// the Ingress.
+var backendProtocolAnnotators = map[string]backendProtocolAnnotator{
+	"traefik": {
+		Annotate: func(string) map[string]string { return nil },
+	},
+	"nginx": {
+		Keys: []string{nginxBackendProtocolAnnotation},
+		Annotate: func(appProtocol string) map[string]string {
+			switch appProtocol {
+			case "grpc":
+				return map[string]string{nginxBackendProtocolAnnotation: "GRPC"}
+			case "h2c":
+				return map[string]string{nginxBackendProtocolAnnotation: "HTTP2"}
+			}
+			return nil
+		},
+	},
+}
+
+// backendProtocolAnnotations returns the Ingress annotations for an app
+// speaking appProtocol behind the given ingress class.
+func backendProtocolAnnotations(className *string, appProtocol string) map[string]string {
+	a := backendProtocolAnnotators["nginx"]
+	if className != nil {
+		if classAnnotator, ok := backendProtocolAnnotators[*className]; ok {
+			a = classAnnotator
+		}
+	}
+	return a.Annotate(appProtocol)
+}
+
 func (r *DevStagingEnvironmentReconciler) buildIngress(cr *appsv1alpha1.DevStagingEnvironment) *networkingv1.Ingress {
 	labels := labelsForCR(cr)
 	spec := cr.Spec.Ingress
@@ -429,7 +876,18 @@ func (r *DevStagingEnvironmentReconciler) buildIngress(cr *appsv1alpha1.DevStagi
 	for k, v := range spec.Annotations {
 		annotations[k] = v
 	}
-	annotations[specHashAnnotation] = computeSpecHash(cr.Spec.Ingress)
+	// gRPC and h2c backends need the controller to speak HTTP/2 to the
+	// pod. A user-set annotation wins.
+	hashed := any(cr.Spec.Ingress)
+	if proto := backendProtocolAnnotations(spec.IngressClassName, cr.Spec.Service.AppProtocol); len(proto) > 0 {
+		for k, v := range proto {
+			if _, ok := annotations[k]; !ok {
+				annotations[k] = v
+			}
+		}
+		hashed = []any{cr.Spec.Ingress, cr.Spec.Service.AppProtocol}
+	}
+	annotations[specHashAnnotation] = computeSpecHash(hashed)
 
 	ingress := &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
@@ -478,104 +936,399 @@ func (r *DevStagingEnvironmentReconciler) buildIngress(cr *appsv1alpha1.DevStagi
 }
 
 // ────────────────────────────────────────────────────────────────────────────
-// Status
+// HorizontalPodAutoscaler
 // ────────────────────────────────────────────────────────────────────────────
 
-func (r *DevStagingEnvironmentReconciler) updateStatus(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) error {
-	// Fetch current Deployment state
-	deploy := &appsv1.Deployment{}
-	if err := r.Get(ctx, types.NamespacedName{Name: safeName(cr.Name), Namespace: cr.Namespace}, deploy); err == nil {
-		cr.Status.AvailableReplicas = deploy.Status.AvailableReplicas
-		cr.Status.DeploymentReady = deploy.Status.AvailableReplicas == deploy.Status.Replicas &&
-			deploy.Status.Replicas > 0
+// defaultHPATargetCPUUtilization is used when Autoscaling.TargetCPUUtilization is unset.
+const defaultHPATargetCPUUtilization = 80
+
+func (r *DevStagingEnvironmentReconciler) reconcileHPA(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) error {
+	logger := log.FromContext(ctx)
+	hpaName := types.NamespacedName{Name: safeName(cr.Name), Namespace: cr.Namespace}
+
+	// If autoscaling is not configured, clean up any existing HPA
+	if cr.Spec.Deployment.Autoscaling == nil {
+		existing := &autoscalingv2.HorizontalPodAutoscaler{}
+		if err := r.Get(ctx, hpaName, existing); err == nil {
+			logger.Info("Deleting HorizontalPodAutoscaler (autoscaling removed)", "name", cr.Name)
+			return r.Delete(ctx, existing)
+		}
+		return nil
 	}
 
-	// Fetch current Service state
-	svc := &corev1.Service{}
-	if err := r.Get(ctx, types.NamespacedName{Name: safeName(cr.Name), Namespace: cr.Namespace}, svc); err == nil {
-		cr.Status.ServiceReady = true
-	} else {
-		cr.Status.ServiceReady = false
+	if res := cr.Spec.Deployment.Resources; res == nil || res.CPURequest == nil {
+		r.recordEvent(cr, "Warning", "AutoscalingNoCPURequest", "Autoscaling targets CPU utilization but deployment.resources.cpuRequest is not set")
 	}
 
-	// Fetch current Ingress state
-	if cr.Spec.Ingress != nil && cr.Spec.Ingress.Enabled {
-		ing := &networkingv1.Ingress{}
-		if err := r.Get(ctx, types.NamespacedName{Name: safeName(cr.Name), Namespace: cr.Namespace}, ing); err == nil {
-			cr.Status.IngressReady = true
-			if cr.Spec.Ingress.Host != "" {
-				scheme := "http"
-				if cr.Spec.Ingress.TLS != nil {
-					scheme = "https"
-				}
-				cr.Status.URL = fmt.Sprintf("%s://%s%s", scheme, cr.Spec.Ingress.Host, cr.Spec.Ingress.Path)
-			}
-		} else {
-			cr.Status.IngressReady = false
-		}
-	} else {
-		cr.Status.IngressReady = false
-		cr.Status.URL = ""
+	desired := r.buildHPA(cr)
+	if err := controllerutil.SetControllerReference(cr, desired, r.Scheme); err != nil {
+		return err
 	}
 
-	// Check dependency readiness
-	depsReady := true
-	for _, dep := range cr.Spec.Dependencies {
-		depDeploy := &appsv1.Deployment{}
-		depName := dependencyName(cr.Name, dep.Type)
-		if err := r.Get(ctx, types.NamespacedName{Name: depName, Namespace: cr.Namespace}, depDeploy); err != nil {
-			depsReady = false
-			break
-		}
-		if depDeploy.Status.AvailableReplicas < 1 {
-			depsReady = false
-			break
+	existing := &autoscalingv2.HorizontalPodAutoscaler{}
+	err := r.Get(ctx, hpaName, existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("Creating HorizontalPodAutoscaler", "name", desired.Name)
+			return r.Create(ctx, desired)
 		}
+		return err
 	}
-	if len(cr.Spec.Dependencies) == 0 {
-		depsReady = true
-	}
-	cr.Status.DependenciesReady = depsReady
 
-	// Set an overall "Ready" condition
-	allReady := cr.Status.DeploymentReady && cr.Status.ServiceReady && depsReady
-	if allReady {
-		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
-			Type:    "Ready",
-			Status:  metav1.ConditionTrue,
-			Reason:  "AllResourcesReady",
-			Message: "Deployment, Service, Ingress (if enabled), and Dependencies are ready",
-		})
-	} else {
-		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
-			Type:    "Ready",
-			Status:  metav1.ConditionFalse,
-			Reason:  "ResourcesNotReady",
-			Message: "One or more child resources are not yet ready",
-		})
+	desiredHash := desired.Annotations[specHashAnnotation]
+	existingHash := existing.Annotations[specHashAnnotation]
+	if desiredHash == existingHash {
+		logger.V(1).Info("HorizontalPodAutoscaler already up to date, skipping", "name", desired.Name)
+		return nil
 	}
 
-	return r.Status().Update(ctx, cr)
+	existing.Spec = desired.Spec
+	if existing.Annotations == nil {
+		existing.Annotations = make(map[string]string)
+	}
+	existing.Annotations[specHashAnnotation] = desiredHash
+	logger.Info("Updating HorizontalPodAutoscaler", "name", desired.Name)
+	return r.Update(ctx, existing)
 }
 
-// ────────────────────────────────────────────────────────────────────────────
-// Helpers
-// ────────────────────────────────────────────────────────────────────────────
+// buildHPA builds an autoscaling/v2 HPA that scales the app Deployment on
+// average CPU utilization.
+func (r *DevStagingEnvironmentReconciler) buildHPA(cr *appsv1alpha1.DevStagingEnvironment) *autoscalingv2.HorizontalPodAutoscaler {
+	spec := cr.Spec.Deployment.Autoscaling
 
-// labelsForCR returns the standard set of labels applied to all child resources.
-// This is the glue that connects Deployments → Pods → Services.
-func labelsForCR(cr *appsv1alpha1.DevStagingEnvironment) map[string]string {
-	return map[string]string{
-		"app.kubernetes.io/name":       cr.Name,
-		"app.kubernetes.io/managed-by": "devstagingenvironment-operator",
-		"app.kubernetes.io/instance":   cr.Name,
+	target := int32(defaultHPATargetCPUUtilization)
+	if spec.TargetCPUUtilization != nil {
+		target = *spec.TargetCPUUtilization
 	}
-}
 
-// buildResourceRequirements converts our simplified resource spec into the full K8s type.
-func buildResourceRequirements(res *appsv1alpha1.ResourceRequirements) corev1.ResourceRequirements {
-	reqs := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{},
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      safeName(cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    labelsForCR(cr),
+			Annotations: map[string]string{
+				specHashAnnotation: computeSpecHash(spec),
+			},
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       safeName(cr.Name),
+			},
+			MinReplicas: spec.MinReplicas,
+			MaxReplicas: spec.MaxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{
+						Type:               autoscalingv2.UtilizationMetricType,
+						AverageUtilization: &target,
+					},
+				},
+			}},
+		},
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// ServiceAccount and RBAC
+// ────────────────────────────────────────────────────────────────────────────
+
+// appServiceAccountName returns the ServiceAccount app pods run as, or ""
+// for the namespace's default account.
+func appServiceAccountName(cr *appsv1alpha1.DevStagingEnvironment) string {
+	if name := cr.Spec.Deployment.ServiceAccountName; name != "" {
+		return name
+	}
+	if cr.Spec.CreateServiceAccount {
+		return safeName(cr.Name)
+	}
+	return ""
+}
+
+// reconcileServiceAccount creates the app's ServiceAccount when
+// spec.createServiceAccount is set, deletes ones this CR created that are
+// no longer wanted, and keeps the optional Role and RoleBinding in step.
+func (r *DevStagingEnvironmentReconciler) reconcileServiceAccount(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) error {
+	logger := log.FromContext(ctx)
+	saName := appServiceAccountName(cr)
+
+	if cr.Spec.CreateServiceAccount {
+		sa := &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      saName,
+				Namespace: cr.Namespace,
+				Labels:    labelsForCR(cr),
+			},
+		}
+		if err := controllerutil.SetControllerReference(cr, sa, r.Scheme); err != nil {
+			return err
+		}
+		existing := &corev1.ServiceAccount{}
+		if err := r.Get(ctx, types.NamespacedName{Name: saName, Namespace: cr.Namespace}, existing); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			logger.Info("Creating ServiceAccount", "name", saName)
+			if err := r.Create(ctx, sa); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Drop accounts left behind by an earlier createServiceAccount or a
+	// renamed deployment.serviceAccountName.
+	owned := &corev1.ServiceAccountList{}
+	if err := r.List(ctx, owned, client.InNamespace(cr.Namespace), client.MatchingLabels(labelsForCR(cr))); err != nil {
+		return err
+	}
+	for i := range owned.Items {
+		sa := &owned.Items[i]
+		if !metav1.IsControlledBy(sa, cr) || (cr.Spec.CreateServiceAccount && sa.Name == saName) {
+			continue
+		}
+		logger.Info("Deleting ServiceAccount (no longer wanted)", "name", sa.Name)
+		if err := r.Delete(ctx, sa); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return r.reconcileRBAC(ctx, cr, saName)
+}
+
+// reconcileRBAC applies the Role and RoleBinding built from spec.rbac, or
+// deletes them once spec.rbac is removed.
+func (r *DevStagingEnvironmentReconciler) reconcileRBAC(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, saName string) error {
+	logger := log.FromContext(ctx)
+	name := types.NamespacedName{Name: safeName(cr.Name), Namespace: cr.Namespace}
+
+	if cr.Spec.RBAC == nil || len(cr.Spec.RBAC.Rules) == 0 {
+		binding := &rbacv1.RoleBinding{}
+		if err := r.Get(ctx, name, binding); err == nil && metav1.IsControlledBy(binding, cr) {
+			logger.Info("Deleting RoleBinding (rbac removed)", "name", name.Name)
+			if err := r.Delete(ctx, binding); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		role := &rbacv1.Role{}
+		if err := r.Get(ctx, name, role); err == nil && metav1.IsControlledBy(role, cr) {
+			logger.Info("Deleting Role (rbac removed)", "name", name.Name)
+			if err := r.Delete(ctx, role); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	role, binding := buildAppRBAC(cr, saName)
+	if err := controllerutil.SetControllerReference(cr, role, r.Scheme); err != nil {
+		return err
+	}
+	if err := controllerutil.SetControllerReference(cr, binding, r.Scheme); err != nil {
+		return err
+	}
+
+	existingRole := &rbacv1.Role{}
+	if err := r.Get(ctx, name, existingRole); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Creating Role", "name", role.Name)
+		if err := r.Create(ctx, role); err != nil {
+			return err
+		}
+	} else if existingRole.Annotations[specHashAnnotation] != role.Annotations[specHashAnnotation] {
+		existingRole.Rules = role.Rules
+		if existingRole.Annotations == nil {
+			existingRole.Annotations = make(map[string]string)
+		}
+		existingRole.Annotations[specHashAnnotation] = role.Annotations[specHashAnnotation]
+		logger.Info("Updating Role", "name", role.Name)
+		if err := r.Update(ctx, existingRole); err != nil {
+			return err
+		}
+	}
+
+	existingBinding := &rbacv1.RoleBinding{}
+	if err := r.Get(ctx, name, existingBinding); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Creating RoleBinding", "name", binding.Name)
+		return r.Create(ctx, binding)
+	}
+	if existingBinding.Annotations[specHashAnnotation] != binding.Annotations[specHashAnnotation] {
+		existingBinding.Subjects = binding.Subjects
+		if existingBinding.Annotations == nil {
+			existingBinding.Annotations = make(map[string]string)
+		}
+		existingBinding.Annotations[specHashAnnotation] = binding.Annotations[specHashAnnotation]
+		logger.Info("Updating RoleBinding", "name", binding.Name)
+		return r.Update(ctx, existingBinding)
+	}
+	return nil
+}
+
+// buildAppRBAC builds the Role holding spec.rbac.rules and the RoleBinding
+// granting it to saName ("default" when the app has no account of its own).
+func buildAppRBAC(cr *appsv1alpha1.DevStagingEnvironment, saName string) (*rbacv1.Role, *rbacv1.RoleBinding) {
+	if saName == "" {
+		saName = "default"
+	}
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      saName,
+		Namespace: cr.Namespace,
+	}}
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      safeName(cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    labelsForCR(cr),
+			Annotations: map[string]string{
+				specHashAnnotation: computeSpecHash(cr.Spec.RBAC.Rules),
+			},
+		},
+		Rules: cr.Spec.RBAC.Rules,
+	}
+	// The RoleRef is immutable, and always names the Role above, so only
+	// the subjects are hashed.
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      safeName(cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    labelsForCR(cr),
+			Annotations: map[string]string{
+				specHashAnnotation: computeSpecHash(subjects),
+			},
+		},
+		Subjects: subjects,
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     safeName(cr.Name),
+		},
+	}
+	return role, binding
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Status
+// ────────────────────────────────────────────────────────────────────────────
+
+func (r *DevStagingEnvironmentReconciler) updateStatus(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) error {
+	// Fetch current Deployment state
+	deploy := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: safeName(cr.Name), Namespace: cr.Namespace}, deploy); err == nil {
+		cr.Status.AvailableReplicas = deploy.Status.AvailableReplicas
+		cr.Status.DeploymentReady = deploy.Status.AvailableReplicas == deploy.Status.Replicas &&
+			deploy.Status.Replicas > 0
+	}
+
+	// Fetch current Service state
+	svc := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Name: safeName(cr.Name), Namespace: cr.Namespace}, svc); err == nil {
+		cr.Status.ServiceReady = true
+	} else {
+		cr.Status.ServiceReady = false
+	}
+
+	// Fetch current Ingress state
+	if cr.Spec.Ingress != nil && cr.Spec.Ingress.Enabled {
+		ing := &networkingv1.Ingress{}
+		if err := r.Get(ctx, types.NamespacedName{Name: safeName(cr.Name), Namespace: cr.Namespace}, ing); err == nil {
+			cr.Status.IngressReady = true
+			if cr.Spec.Ingress.Host != "" {
+				scheme := "http"
+				if cr.Spec.Ingress.TLS != nil {
+					scheme = "https"
+				}
+				cr.Status.URL = fmt.Sprintf("%s://%s%s", scheme, cr.Spec.Ingress.Host, cr.Spec.Ingress.Path)
+			}
+		} else {
+			cr.Status.IngressReady = false
+		}
+	} else {
+		cr.Status.IngressReady = false
+		cr.Status.URL = ""
+	}
+
+	// Check dependency readiness
+	depsReady := true
+	for _, dep := range cr.Spec.Dependencies {
+		depDeploy := &appsv1.Deployment{}
+		depName := dependencyResourceName(cr.Name, dep)
+		if err := r.Get(ctx, types.NamespacedName{Name: depName, Namespace: cr.Namespace}, depDeploy); err != nil {
+			depsReady = false
+			break
+		}
+		if depDeploy.Status.AvailableReplicas < 1 {
+			depsReady = false
+			break
+		}
+		// A dependency with bootstrap entries is only ready once they exist.
+		if len(dependencyBootstrapNames(dep)) > 0 {
+			job := &batchv1.Job{}
+			if err := r.Get(ctx, types.NamespacedName{Name: dependencyBootstrapJobName(depName), Namespace: cr.Namespace}, job); err != nil || job.Status.Succeeded < 1 {
+				depsReady = false
+				break
+			}
+		}
+	}
+	if len(cr.Spec.Dependencies) == 0 {
+		depsReady = true
+	}
+	cr.Status.DependenciesReady = depsReady
+	cr.Status.DependencyURLs = dependencyURLs(cr, r.creds())
+
+	// Record creation and expiry times
+	cr.Status.CreatedAt = cr.CreationTimestamp.DeepCopy()
+	cr.Status.ExpiresAt = nil
+	if expiresAt, ok := expiryTime(cr); ok {
+		cr.Status.ExpiresAt = &metav1.Time{Time: expiresAt}
+	}
+
+	// Set an overall "Ready" condition
+	allReady := cr.Status.DeploymentReady && cr.Status.ServiceReady && depsReady
+	if allReady {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionTrue,
+			Reason:  "AllResourcesReady",
+			Message: "Deployment, Service, Ingress (if enabled), and Dependencies are ready",
+		})
+	} else {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "ResourcesNotReady",
+			Message: "One or more child resources are not yet ready",
+		})
+	}
+
+	return r.Status().Update(ctx, cr)
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Helpers
+// ────────────────────────────────────────────────────────────────────────────
+
+// labelsForCR returns the standard set of labels applied to all child resources.
+// This is the glue that connects Deployments → Pods → Services.
+func labelsForCR(cr *appsv1alpha1.DevStagingEnvironment) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       cr.Name,
+		"app.kubernetes.io/managed-by": "devstagingenvironment-operator",
+		"app.kubernetes.io/instance":   cr.Name,
+	}
+}
+
+// buildResourceRequirements converts our simplified resource spec into the full K8s type.
+func buildResourceRequirements(res *appsv1alpha1.ResourceRequirements) corev1.ResourceRequirements {
+	reqs := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{},
 		Limits:   corev1.ResourceList{},
 	}
 	if res.CPURequest != nil {
@@ -593,6 +1346,117 @@ func buildResourceRequirements(res *appsv1alpha1.ResourceRequirements) corev1.Re
 	return reqs
 }
 
+// buildSidecarContainers converts the sidecar specs into containers. Unlike
+// the main container, sidecars only get the env vars they declare.
+func buildSidecarContainers(sidecars []appsv1alpha1.ContainerSpec) []corev1.Container {
+	var containers []corev1.Container
+	for _, sc := range sidecars {
+		c := corev1.Container{
+			Name:    sc.Name,
+			Image:   sc.Image,
+			Command: sc.Command,
+			Args:    sc.Args,
+			Env:     sc.Env,
+		}
+		if sc.Port != nil {
+			c.Ports = []corev1.ContainerPort{{
+				ContainerPort: *sc.Port,
+				Protocol:      corev1.ProtocolTCP,
+			}}
+		}
+		if sc.Resources != nil {
+			c.Resources = buildResourceRequirements(sc.Resources)
+		}
+		containers = append(containers, c)
+	}
+	return containers
+}
+
+// defaultStartupFailureThreshold is how many startup probe failures are
+// tolerated before the container is restarted. At the default 10s period
+// that gives an app five minutes to boot.
+const defaultStartupFailureThreshold int32 = 30
+
+// buildProbe constructs the liveness/readiness probe for the health check
+// type. It returns nil when no probe should be attached ("none", or "exec"
+// without a command).
+func buildProbe(hc *appsv1alpha1.HealthCheckSpec, defaultPort int32) *corev1.Probe {
+	switch hc.Type {
+	case "grpc":
+		return buildGRPCProbe(hc, defaultPort)
+	case "tcp":
+		return buildTCPProbe(hc, defaultPort)
+	case "exec":
+		if len(hc.Command) == 0 {
+			return nil
+		}
+		return buildExecProbe(hc)
+	case "none":
+		return nil
+	default: // "http" or empty
+		return buildHTTPProbe(hc, defaultPort)
+	}
+}
+
+// buildReadinessProbe returns the readiness probe: probe with ReadinessPath
+// swapped in for HTTP checks and SuccessThreshold applied.
+func buildReadinessProbe(hc *appsv1alpha1.HealthCheckSpec, probe *corev1.Probe) *corev1.Probe {
+	readiness := probe.DeepCopy()
+	if readiness.HTTPGet != nil && hc.ReadinessPath != "" {
+		readiness.HTTPGet.Path = hc.ReadinessPath
+	}
+	if hc.SuccessThreshold != nil {
+		readiness.SuccessThreshold = *hc.SuccessThreshold
+	}
+	return readiness
+}
+
+// buildStartupProbe returns a startup probe sharing the handler of probe,
+// or nil when none of StartupPeriodSeconds, StartupPath, or
+// StartupFailureThreshold is set. It has no initial delay and a generous
+// failure budget so liveness never fires during a slow boot.
+func buildStartupProbe(hc *appsv1alpha1.HealthCheckSpec, probe *corev1.Probe) *corev1.Probe {
+	if probe == nil || (hc.StartupPeriodSeconds == nil && hc.StartupPath == "" && hc.StartupFailureThreshold == nil) {
+		return nil
+	}
+	startup := &corev1.Probe{
+		ProbeHandler:     *probe.ProbeHandler.DeepCopy(),
+		PeriodSeconds:    probe.PeriodSeconds,
+		TimeoutSeconds:   probe.TimeoutSeconds,
+		FailureThreshold: defaultStartupFailureThreshold,
+	}
+	if hc.StartupPeriodSeconds != nil {
+		startup.PeriodSeconds = *hc.StartupPeriodSeconds
+	}
+	if startup.HTTPGet != nil && hc.StartupPath != "" {
+		startup.HTTPGet.Path = hc.StartupPath
+	}
+	switch {
+	case hc.StartupFailureThreshold != nil:
+		startup.FailureThreshold = *hc.StartupFailureThreshold
+	case hc.FailureThreshold != nil && *hc.FailureThreshold > startup.FailureThreshold:
+		startup.FailureThreshold = *hc.FailureThreshold
+	}
+	return startup
+}
+
+// applyProbeTiming copies the optional timing fields of the health check
+// spec onto probe.
+func applyProbeTiming(probe *corev1.Probe, hc *appsv1alpha1.HealthCheckSpec) {
+	if hc.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *hc.InitialDelaySeconds
+	}
+	if hc.PeriodSeconds != nil {
+		probe.PeriodSeconds = *hc.PeriodSeconds
+	}
+	if hc.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *hc.TimeoutSeconds
+	}
+	if hc.FailureThreshold != nil {
+		probe.FailureThreshold = *hc.FailureThreshold
+	}
+}
+
 // buildHTTPProbe constructs a liveness/readiness probe from the health check spec.
 func buildHTTPProbe(hc *appsv1alpha1.HealthCheckSpec, defaultPort int32) *corev1.Probe {
 	port := defaultPort
@@ -609,13 +1473,7 @@ func buildHTTPProbe(hc *appsv1alpha1.HealthCheckSpec, defaultPort int32) *corev1
 		},
 	}
 
-	if hc.InitialDelaySeconds != nil {
-		probe.InitialDelaySeconds = *hc.InitialDelaySeconds
-	}
-	if hc.PeriodSeconds != nil {
-		probe.PeriodSeconds = *hc.PeriodSeconds
-	}
-
+	applyProbeTiming(probe, hc)
 	return probe
 }
 
@@ -634,20 +1492,49 @@ func buildGRPCProbe(hc *appsv1alpha1.HealthCheckSpec, defaultPort int32) *corev1
 		},
 	}
 
-	if hc.InitialDelaySeconds != nil {
-		probe.InitialDelaySeconds = *hc.InitialDelaySeconds
+	applyProbeTiming(probe, hc)
+	return probe
+}
+
+// buildTCPProbe constructs a liveness/readiness probe that checks the port
+// accepts TCP connections.
+func buildTCPProbe(hc *appsv1alpha1.HealthCheckSpec, defaultPort int32) *corev1.Probe {
+	port := defaultPort
+	if hc.Port != nil {
+		port = *hc.Port
 	}
-	if hc.PeriodSeconds != nil {
-		probe.PeriodSeconds = *hc.PeriodSeconds
+
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(int(port)),
+			},
+		},
 	}
 
+	applyProbeTiming(probe, hc)
 	return probe
 }
 
-// computeSpecHash returns a short SHA-256 hash of the JSON-serialized input.
-// Used as an annotation to detect when the desired spec has actually changed,
-// avoiding unnecessary updates that trigger reconcile loops.
-func computeSpecHash(obj interface{}) string {
+// buildExecProbe constructs a liveness/readiness probe that runs the
+// health check command inside the container.
+func buildExecProbe(hc *appsv1alpha1.HealthCheckSpec) *corev1.Probe {
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: append([]string(nil), hc.Command...),
+			},
+		},
+	}
+
+	applyProbeTiming(probe, hc)
+	return probe
+}
+
+// computeSpecHash returns a short SHA-256 hash of the JSON-serialized input.
+// Used as an annotation to detect when the desired spec has actually changed,
+// avoiding unnecessary updates that trigger reconcile loops.
+func computeSpecHash(obj interface{}) string {
 	data, _ := json.Marshal(obj)
 	sum := sha256.Sum256(data)
 	return fmt.Sprintf("%x", sum[:8])
@@ -655,8 +1542,9 @@ func computeSpecHash(obj interface{}) string {
 
 // SetupWithManager sets up the controller with the Manager.
 // It watches DevStagingEnvironment (primary) and also watches Deployments, Services,
-// and Ingresses that the operator owns, so changes to child resources
-// trigger a reconciliation of the parent CR.
+// Ingresses, HPAs, NetworkPolicies, and dependency bootstrap Jobs that the operator owns, so changes to child resources
+// trigger a reconciliation of the parent CR. ConfigMaps and Secrets are also
+// watched for CRs that load env from them.
 func (r *DevStagingEnvironmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Recorder = mgr.GetEventRecorderFor("devstagingenvironment-controller")
 	return ctrl.NewControllerManagedBy(mgr).
@@ -665,6 +1553,22 @@ func (r *DevStagingEnvironmentReconciler) SetupWithManager(mgr ctrl.Manager) err
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
 		Owns(&networkingv1.Ingress{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		// Shared dependencies have no controller owner, so Owns() misses
+		// them; wake every consumer instead.
+		Watches(&appsv1.Deployment{},
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &appsv1alpha1.DevStagingEnvironment{}),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetLabels()[sharedDependencyLabel] != ""
+			}))).
+		// ConfigMaps and Secrets named in envFrom aren't owned by the CR.
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.envFromConsumers)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.envFromConsumers)).
 		Complete(r)
 }
 
@@ -683,60 +1587,94 @@ func (r *DevStagingEnvironmentReconciler) recordEvent(cr *appsv1alpha1.DevStagin
 
 // dependencyDefaults holds the convention-over-configuration defaults for a
 // well-known dependency type.
+//
+// Image, Port, EnvVarName, and DefaultVersion come from ci.Dependencies.
 type dependencyDefaults struct {
-	Image      string          // e.g. "postgres:16"
+	Image      string          // e.g. "postgres"
 	Port       int32           // e.g. 5432
 	EnvVarName string          // injected into the app container
 	Env        []corev1.EnvVar // container env vars to configure the dep itself
 	Stateful   bool            // true = needs a PVC
+	DataPath   string          // where the PVC is mounted (stateful deps only)
+
+	// DefaultVersion is the image tag used when neither Version nor Image
+	// is set, so dependencies don't float on ":latest". Empty pulls the
+	// registry's latest tag.
+	DefaultVersion string
+
+	// InitScriptExt is the file extension InitScripts are mounted with in
+	// /docker-entrypoint-initdb.d. Empty means init scripts are unsupported.
+	InitScriptExt string
+
+	// Resources are applied when DependencySpec.Resources is unset, so
+	// memory-hungry deps can't take down a small Kind node.
+	Resources corev1.ResourceRequirements
 }
 
+// memoryResources returns requirements with only a memory request and limit.
+func memoryResources(request, limit string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(request)},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(limit)},
+	}
+}
+
+// initScriptsHashAnnotation is set on dependency pod templates so edits to
+// InitScripts roll the Deployment.
+const initScriptsHashAnnotation = "apps.example.com/init-scripts-hash"
+
+// dependencyEnvHashAnnotation is set on the app's pod template to a hash of
+// the env injected from its dependencies, so a dependency edit that changes
+// a connection URL or credential always rolls the app, and the rollout can
+// be traced back to it.
+const dependencyEnvHashAnnotation = "apps.example.com/dependency-env-hash"
+
+// defaultDependencyStorageSize is the PVC size used when DependencySpec.StorageSize is unset.
+const defaultDependencyStorageSize = "1Gi"
+
 // dependencyRegistry maps each supported DependencyType to its defaults.
+// Image, DefaultVersion, Port, and EnvVarName are filled in from
+// ci.Dependencies, the catalog the CLI and generate prompt share, so
+// only operator-side settings live here.
 var dependencyRegistry = map[appsv1alpha1.DependencyType]dependencyDefaults{
 	appsv1alpha1.DependencyPostgres: {
-		Image:      "postgres",
-		Port:       5432,
-		EnvVarName: "DATABASE_URL",
 		Env: []corev1.EnvVar{
 			{Name: "POSTGRES_USER", Value: "devuser"},
 			{Name: "POSTGRES_PASSWORD", Value: "devpass"},
 			{Name: "POSTGRES_DB", Value: "devdb"},
+			// Keep the cluster in a subdirectory so a lost+found at the
+			// volume root doesn't make initdb refuse to run.
+			{Name: "PGDATA", Value: "/var/lib/postgresql/data/pgdata"},
 		},
-		Stateful: true,
+		Stateful:      true,
+		DataPath:      "/var/lib/postgresql/data",
+		InitScriptExt: ".sql",
 	},
 	appsv1alpha1.DependencyRedis: {
-		Image:      "redis",
-		Port:       6379,
-		EnvVarName: "REDIS_URL",
-		Env:        nil,
-		Stateful:   false,
+		Env:      nil,
+		Stateful: false,
 	},
 	appsv1alpha1.DependencyMySQL: {
-		Image:      "mysql",
-		Port:       3306,
-		EnvVarName: "DATABASE_URL",
 		Env: []corev1.EnvVar{
 			{Name: "MYSQL_ROOT_PASSWORD", Value: "devpass"},
 			{Name: "MYSQL_DATABASE", Value: "devdb"},
 			{Name: "MYSQL_USER", Value: "devuser"},
 			{Name: "MYSQL_PASSWORD", Value: "devpass"},
 		},
-		Stateful: true,
+		Stateful:      true,
+		DataPath:      "/var/lib/mysql",
+		InitScriptExt: ".sql",
 	},
 	appsv1alpha1.DependencyMongoDB: {
-		Image:      "mongo",
-		Port:       27017,
-		EnvVarName: "MONGO_URL",
 		Env: []corev1.EnvVar{
 			{Name: "MONGO_INITDB_ROOT_USERNAME", Value: "devuser"},
 			{Name: "MONGO_INITDB_ROOT_PASSWORD", Value: "devpass"},
 		},
-		Stateful: true,
+		Stateful:      true,
+		DataPath:      "/data/db",
+		InitScriptExt: ".js",
 	},
 	appsv1alpha1.DependencyRabbitMQ: {
-		Image:      "rabbitmq",
-		Port:       5672,
-		EnvVarName: "AMQP_URL",
 		Env: []corev1.EnvVar{
 			{Name: "RABBITMQ_DEFAULT_USER", Value: "devuser"},
 			{Name: "RABBITMQ_DEFAULT_PASS", Value: "devpass"},
@@ -744,30 +1682,24 @@ var dependencyRegistry = map[appsv1alpha1.DependencyType]dependencyDefaults{
 		Stateful: false,
 	},
 	appsv1alpha1.DependencyMinIO: {
-		Image:      "minio/minio",
-		Port:       9000,
-		EnvVarName: "S3_ENDPOINT",
 		Env: []corev1.EnvVar{
 			{Name: "MINIO_ROOT_USER", Value: "minioadmin"},
 			{Name: "MINIO_ROOT_PASSWORD", Value: "minioadmin"},
 		},
 		Stateful: true,
+		DataPath: "/data",
 	},
 	appsv1alpha1.DependencyElasticsearch: {
-		Image:      "docker.elastic.co/elasticsearch/elasticsearch",
-		Port:       9200,
-		EnvVarName: "ELASTICSEARCH_URL",
 		Env: []corev1.EnvVar{
 			{Name: "discovery.type", Value: "single-node"},
 			{Name: "xpack.security.enabled", Value: "false"},
 			{Name: "ES_JAVA_OPTS", Value: "-Xms256m -Xmx256m"},
 		},
-		Stateful: true,
+		Stateful:  true,
+		DataPath:  "/usr/share/elasticsearch/data",
+		Resources: memoryResources("512Mi", "1Gi"),
 	},
 	appsv1alpha1.DependencyKafka: {
-		Image:      "apache/kafka",
-		Port:       9092,
-		EnvVarName: "KAFKA_BROKER_URL",
 		Env: []corev1.EnvVar{
 			{Name: "KAFKA_NODE_ID", Value: "1"},
 			{Name: "KAFKA_PROCESS_ROLES", Value: "broker,controller"},
@@ -776,27 +1708,23 @@ var dependencyRegistry = map[appsv1alpha1.DependencyType]dependencyDefaults{
 			{Name: "KAFKA_LISTENER_SECURITY_PROTOCOL_MAP", Value: "PLAINTEXT:PLAINTEXT,CONTROLLER:PLAINTEXT"},
 			{Name: "KAFKA_CONTROLLER_LISTENER_NAMES", Value: "CONTROLLER"},
 			{Name: "CLUSTER_ID", Value: "kindling-dev-kafka-cluster"},
+			{Name: "KAFKA_LOG_DIRS", Value: "/var/lib/kafka/data"},
+			// The image defaults to a 1G heap, which alone fills the limit.
+			{Name: "KAFKA_HEAP_OPTS", Value: "-Xms256m -Xmx256m"},
 		},
-		Stateful: true,
+		Stateful:  true,
+		DataPath:  "/var/lib/kafka/data",
+		Resources: memoryResources("512Mi", "1Gi"),
 	},
 	appsv1alpha1.DependencyNATS: {
-		Image:      "nats",
-		Port:       4222,
-		EnvVarName: "NATS_URL",
-		Env:        nil,
-		Stateful:   false,
+		Env:      nil,
+		Stateful: false,
 	},
 	appsv1alpha1.DependencyMemcached: {
-		Image:      "memcached",
-		Port:       11211,
-		EnvVarName: "MEMCACHED_URL",
-		Env:        nil,
-		Stateful:   false,
+		Env:      nil,
+		Stateful: false,
 	},
 	appsv1alpha1.DependencyCassandra: {
-		Image:      "cassandra",
-		Port:       9042,
-		EnvVarName: "CASSANDRA_URL",
 		Env: []corev1.EnvVar{
 			{Name: "CASSANDRA_CLUSTER_NAME", Value: "DevCluster"},
 			{Name: "CASSANDRA_DC", Value: "dc1"},
@@ -804,18 +1732,15 @@ var dependencyRegistry = map[appsv1alpha1.DependencyType]dependencyDefaults{
 			{Name: "HEAP_NEWSIZE", Value: "64M"},
 		},
 		Stateful: true,
+		DataPath: "/var/lib/cassandra",
+		// Off-heap memtables and caches roughly double the 256M heap.
+		Resources: memoryResources("768Mi", "1536Mi"),
 	},
 	appsv1alpha1.DependencyConsul: {
-		Image:      "hashicorp/consul",
-		Port:       8500,
-		EnvVarName: "CONSUL_HTTP_ADDR",
-		Env:        nil,
-		Stateful:   false,
+		Env:      nil,
+		Stateful: false,
 	},
 	appsv1alpha1.DependencyVault: {
-		Image:      "hashicorp/vault",
-		Port:       8200,
-		EnvVarName: "VAULT_ADDR",
 		Env: []corev1.EnvVar{
 			{Name: "VAULT_DEV_ROOT_TOKEN_ID", Value: "dev-root-token"},
 			{Name: "VAULT_DEV_LISTEN_ADDRESS", Value: "0.0.0.0:8200"},
@@ -823,27 +1748,366 @@ var dependencyRegistry = map[appsv1alpha1.DependencyType]dependencyDefaults{
 		Stateful: false,
 	},
 	appsv1alpha1.DependencyInfluxDB: {
-		Image:      "influxdb",
-		Port:       8086,
-		EnvVarName: "INFLUXDB_URL",
 		Env: []corev1.EnvVar{
 			{Name: "DOCKER_INFLUXDB_INIT_MODE", Value: "setup"},
 			{Name: "DOCKER_INFLUXDB_INIT_USERNAME", Value: "devuser"},
 			{Name: "DOCKER_INFLUXDB_INIT_PASSWORD", Value: "devpass123"},
 			{Name: "DOCKER_INFLUXDB_INIT_ORG", Value: "devorg"},
 			{Name: "DOCKER_INFLUXDB_INIT_BUCKET", Value: "devbucket"},
+			{Name: "DOCKER_INFLUXDB_INIT_ADMIN_TOKEN", Value: "dev-influxdb-token"},
 		},
 		Stateful: true,
+		DataPath: "/var/lib/influxdb2",
 	},
 	appsv1alpha1.DependencyJaeger: {
-		Image:      "jaegertracing/all-in-one",
-		Port:       16686,
-		EnvVarName: "JAEGER_ENDPOINT",
 		Env: []corev1.EnvVar{
 			{Name: "COLLECTOR_OTLP_ENABLED", Value: "true"},
 		},
 		Stateful: false,
 	},
+	appsv1alpha1.DependencyClickHouse: {
+		Env: []corev1.EnvVar{
+			{Name: "CLICKHOUSE_USER", Value: "devuser"},
+			{Name: "CLICKHOUSE_PASSWORD", Value: "devpass"},
+			{Name: "CLICKHOUSE_DB", Value: "devdb"},
+			{Name: "CLICKHOUSE_DEFAULT_ACCESS_MANAGEMENT", Value: "1"},
+		},
+		Stateful: true,
+		DataPath: "/var/lib/clickhouse",
+	},
+	appsv1alpha1.DependencyCockroach: {
+		Env: []corev1.EnvVar{
+			{Name: "COCKROACH_DATABASE", Value: "devdb"},
+		},
+		Stateful: true,
+		DataPath: "/cockroach/cockroach-data",
+	},
+	appsv1alpha1.DependencyTimescaleDB: {
+		Env: []corev1.EnvVar{
+			{Name: "POSTGRES_USER", Value: "devuser"},
+			{Name: "POSTGRES_PASSWORD", Value: "devpass"},
+			{Name: "POSTGRES_DB", Value: "devdb"},
+			{Name: "PGDATA", Value: "/var/lib/postgresql/data/pgdata"},
+		},
+		Stateful:      true,
+		DataPath:      "/var/lib/postgresql/data",
+		InitScriptExt: ".sql",
+	},
+	appsv1alpha1.DependencyMailpit: {
+		Env: []corev1.EnvVar{
+			// Accept whatever SMTP credentials the app is configured with.
+			{Name: "MP_SMTP_AUTH_ACCEPT_ANY", Value: "1"},
+			{Name: "MP_SMTP_AUTH_ALLOW_INSECURE", Value: "1"},
+		},
+		Stateful: false,
+	},
+	appsv1alpha1.DependencyQdrant: {
+		Env:      nil,
+		Stateful: true,
+		DataPath: "/qdrant/storage",
+	},
+	appsv1alpha1.DependencyWeaviate: {
+		Env: []corev1.EnvVar{
+			{Name: "AUTHENTICATION_ANONYMOUS_ACCESS_ENABLED", Value: "true"},
+			{Name: "PERSISTENCE_DATA_PATH", Value: "/var/lib/weaviate"},
+			{Name: "DEFAULT_VECTORIZER_MODULE", Value: "none"},
+			{Name: "CLUSTER_HOSTNAME", Value: "node1"},
+		},
+		Stateful: true,
+		DataPath: "/var/lib/weaviate",
+	},
+	appsv1alpha1.DependencyChroma: {
+		Env:      nil,
+		Stateful: false,
+	},
+	appsv1alpha1.DependencyLocalStack: {
+		Env:      nil,
+		Stateful: false,
+	},
+	appsv1alpha1.DependencyNeo4j: {
+		Env: []corev1.EnvVar{
+			{Name: "NEO4J_AUTH", Value: "neo4j/devpass123"},
+		},
+		Stateful: true,
+		DataPath: "/data",
+	},
+	appsv1alpha1.DependencyMariaDB: {
+		Env: []corev1.EnvVar{
+			{Name: "MARIADB_ROOT_PASSWORD", Value: "devpass"},
+			{Name: "MARIADB_DATABASE", Value: "devdb"},
+			{Name: "MARIADB_USER", Value: "devuser"},
+			{Name: "MARIADB_PASSWORD", Value: "devpass"},
+		},
+		Stateful:      true,
+		DataPath:      "/var/lib/mysql",
+		InitScriptExt: ".sql",
+	},
+	appsv1alpha1.DependencySQLServer: {
+		Env: []corev1.EnvVar{
+			{Name: "ACCEPT_EULA", Value: "Y"},
+			// SQL Server rejects passwords that don't mix character classes.
+			{Name: "MSSQL_SA_PASSWORD", Value: "DevPass123!"},
+			{Name: "MSSQL_PID", Value: "Developer"},
+		},
+		Stateful: true,
+		DataPath: "/var/opt/mssql",
+	},
+	appsv1alpha1.DependencyEtcd: {
+		Stateful: false,
+	},
+	appsv1alpha1.DependencyPrometheus: {
+		Stateful: false,
+	},
+	appsv1alpha1.DependencyGrafana: {
+		Env: []corev1.EnvVar{
+			{Name: "GF_SECURITY_ADMIN_USER", Value: "admin"},
+			{Name: "GF_SECURITY_ADMIN_PASSWORD", Value: "devpass"},
+			// Skip the login page for the dev UI.
+			{Name: "GF_AUTH_ANONYMOUS_ENABLED", Value: "true"},
+			{Name: "GF_AUTH_ANONYMOUS_ORG_ROLE", Value: "Admin"},
+		},
+		Stateful: false,
+	},
+	appsv1alpha1.DependencyArangoDB: {
+		Env: []corev1.EnvVar{
+			{Name: "ARANGO_ROOT_PASSWORD", Value: "devpass"},
+		},
+		Stateful: true,
+		DataPath: "/var/lib/arangodb3",
+	},
+	appsv1alpha1.DependencyCouchDB: {
+		Env: []corev1.EnvVar{
+			{Name: "COUCHDB_USER", Value: "devuser"},
+			{Name: "COUCHDB_PASSWORD", Value: "devpass"},
+		},
+		Stateful: true,
+		DataPath: "/opt/couchdb/data",
+	},
+	appsv1alpha1.DependencyPulsar: {
+		Env: []corev1.EnvVar{
+			// Standalone defaults to a 2G heap plus as much direct memory.
+			{Name: "PULSAR_MEM", Value: "-Xms256m -Xmx256m -XX:MaxDirectMemorySize=256m"},
+		},
+		Stateful:  true,
+		DataPath:  "/pulsar/data",
+		Resources: memoryResources("512Mi", "1Gi"),
+	},
+	appsv1alpha1.DependencyMeilisearch: {
+		Env: []corev1.EnvVar{
+			{Name: "MEILI_MASTER_KEY", Value: "dev-meilisearch-key"},
+			{Name: "MEILI_ENV", Value: "development"},
+			{Name: "MEILI_NO_ANALYTICS", Value: "true"},
+		},
+		Stateful: true,
+		DataPath: "/meili_data",
+	},
+	appsv1alpha1.DependencyTypesense: {
+		Env: []corev1.EnvVar{
+			{Name: "TYPESENSE_API_KEY", Value: "dev-typesense-key"},
+		},
+		Stateful: true,
+		DataPath: "/data",
+	},
+	appsv1alpha1.DependencyOtelCollector: {
+		Stateful: false,
+	},
+	appsv1alpha1.DependencyZookeeper: {
+		Env: []corev1.EnvVar{
+			// The readiness check uses the ruok four-letter word.
+			{Name: "ZOO_4LW_COMMANDS_WHITELIST", Value: "ruok,srvr"},
+			{Name: "JVMFLAGS", Value: "-Xms128m -Xmx256m"},
+		},
+		Stateful:  true,
+		DataPath:  "/data",
+		Resources: memoryResources("256Mi", "512Mi"),
+	},
+	appsv1alpha1.DependencySurrealDB: {
+		Env: []corev1.EnvVar{
+			{Name: "SURREAL_USER", Value: "root"},
+			{Name: "SURREAL_PASS", Value: "devpass"},
+		},
+		// In-memory storage: the image runs as a non-root user with no
+		// writable data directory.
+		Stateful: false,
+	},
+	appsv1alpha1.DependencyRethinkDB: {
+		Stateful: true,
+		DataPath: "/data",
+	},
+	appsv1alpha1.DependencyOpenSearch: {
+		Env: []corev1.EnvVar{
+			{Name: "discovery.type", Value: "single-node"},
+			// Plain HTTP with no admin password, like elasticsearch's
+			// xpack.security.enabled=false.
+			{Name: "DISABLE_SECURITY_PLUGIN", Value: "true"},
+			{Name: "DISABLE_INSTALL_DEMO_CONFIG", Value: "true"},
+			{Name: "OPENSEARCH_JAVA_OPTS", Value: "-Xms256m -Xmx256m"},
+		},
+		Stateful:  true,
+		DataPath:  "/usr/share/opensearch/data",
+		Resources: memoryResources("512Mi", "1Gi"),
+	},
+}
+
+func init() {
+	for _, d := range ci.Dependencies {
+		depType := appsv1alpha1.DependencyType(d.Type)
+		defaults := dependencyRegistry[depType]
+		defaults.Image = d.Image
+		defaults.DefaultVersion = d.DefaultVersion
+		defaults.Port = d.Port
+		defaults.EnvVarName = d.EnvVarName()
+		dependencyRegistry[depType] = defaults
+	}
+}
+
+// dependencyVariantImages maps each dependency type's supported variants to
+// the image that replaces defaults.Image. Everything else about the
+// dependency (port, env var, URL, readiness check) stays the same.
+var dependencyVariantImages = map[appsv1alpha1.DependencyType]map[appsv1alpha1.DependencyVariant]string{
+	appsv1alpha1.DependencyRedis: {
+		appsv1alpha1.DependencyVariantValkey:    "valkey/valkey",
+		appsv1alpha1.DependencyVariantDragonfly: "docker.dragonflydb.io/dragonflydb/dragonfly",
+	},
+}
+
+// dependencyVariantImage returns the image for dep's variant, if it has a
+// supported one.
+func dependencyVariantImage(dep appsv1alpha1.DependencySpec) (string, bool) {
+	if dep.Variant == "" {
+		return "", false
+	}
+	image, ok := dependencyVariantImages[dep.Type][dep.Variant]
+	return image, ok
+}
+
+// defaultCacheMaxMemory caps cache dependencies that don't set MaxMemory,
+// so a dev cache fills up and evicts rather than eating the node.
+var defaultCacheMaxMemory = resource.MustParse("256Mi")
+
+// defaultEvictionPolicy is used when a capped cache sets no EvictionPolicy.
+const defaultEvictionPolicy = "allkeys-lru"
+
+// dragonflyBytesPerThread is the memory Dragonfly insists on per proactor
+// thread; it refuses to start if maxmemory is below threads × this.
+const dragonflyBytesPerThread = 256 << 20
+
+// supportsMemoryCap reports whether t honors MaxMemory and EvictionPolicy.
+func supportsMemoryCap(t appsv1alpha1.DependencyType) bool {
+	return t == appsv1alpha1.DependencyRedis || t == appsv1alpha1.DependencyMemcached
+}
+
+// cacheMemoryArgs returns the server flags that apply dep's memory cap and
+// eviction policy. The redis, valkey, dragonfly and memcached images all
+// run their server when the first arg is a flag, so no command is needed.
+// A MaxMemory of zero leaves the server uncapped.
+func cacheMemoryArgs(dep appsv1alpha1.DependencySpec) []string {
+	maxMemory := defaultCacheMaxMemory
+	if dep.MaxMemory != nil {
+		maxMemory = *dep.MaxMemory
+	}
+	bytes := maxMemory.Value()
+	if bytes <= 0 {
+		return nil
+	}
+	policy := dep.EvictionPolicy
+	if policy == "" {
+		policy = defaultEvictionPolicy
+	}
+
+	switch {
+	case dep.Type == appsv1alpha1.DependencyMemcached:
+		// memcached sizes in MiB and only knows LRU; -M returns errors
+		// instead of evicting.
+		args := []string{"-m", fmt.Sprint(max(bytes>>20, 1))}
+		if policy == "noeviction" {
+			args = append(args, "-M")
+		}
+		return args
+	case dep.Variant == appsv1alpha1.DependencyVariantDragonfly:
+		// Dragonfly has no per-policy choice: cache mode evicts, otherwise
+		// writes fail at the cap. Scale threads down so small caps start.
+		args := []string{
+			fmt.Sprintf("--maxmemory=%d", bytes),
+			fmt.Sprintf("--proactor_threads=%d", max(bytes/dragonflyBytesPerThread, 1)),
+		}
+		if policy != "noeviction" {
+			args = append(args, "--cache_mode=true")
+		}
+		return args
+	default:
+		return []string{"--maxmemory", fmt.Sprint(bytes), "--maxmemory-policy", policy}
+	}
+}
+
+// localStackRegion is the AWS region injected alongside LocalStack.
+const localStackRegion = "us-east-1"
+
+// mailpitUIPort is the port of Mailpit's web UI and API.
+const mailpitUIPort int32 = 8025
+
+// neo4jHTTPPort serves the Neo4j Browser and HTTP API; apps connect over
+// Bolt on the main port.
+const neo4jHTTPPort int32 = 7474
+
+// pulsarAdminPort serves Pulsar's admin REST API; clients use the binary
+// protocol on the main port.
+const pulsarAdminPort int32 = 8080
+
+// rethinkDBAdminPort serves RethinkDB's web admin UI; drivers use the main
+// port.
+const rethinkDBAdminPort int32 = 8080
+
+// otelCollectorHealthPort serves the collector's health_check extension.
+const otelCollectorHealthPort int32 = 13133
+
+// rabbitMQManagementPort serves the RabbitMQ management UI and HTTP API.
+const rabbitMQManagementPort int32 = 15672
+
+// minioConsolePort pins the MinIO console, which otherwise listens on a
+// random port.
+const minioConsolePort int32 = 9001
+
+// dependencyExtraPorts returns the ports a dependency listens on besides its
+// main port (management UIs, gRPC APIs, ...). They are exposed on both the
+// container and the Service.
+func dependencyExtraPorts(depType appsv1alpha1.DependencyType) []corev1.ContainerPort {
+	tcp := func(name string, port int32) corev1.ContainerPort {
+		return corev1.ContainerPort{Name: name, ContainerPort: port, Protocol: corev1.ProtocolTCP}
+	}
+	switch depType {
+	case appsv1alpha1.DependencyJaeger:
+		return []corev1.ContainerPort{tcp("otlp-grpc", 4317), tcp("otlp-http", 4318)}
+	case appsv1alpha1.DependencyKafka:
+		return []corev1.ContainerPort{tcp("controller", 9093)}
+	case appsv1alpha1.DependencyPulsar:
+		return []corev1.ContainerPort{tcp("admin", pulsarAdminPort)}
+	case appsv1alpha1.DependencyOtelCollector:
+		return []corev1.ContainerPort{tcp("otlp-grpc", 4317), tcp("health", otelCollectorHealthPort)}
+	case appsv1alpha1.DependencyRethinkDB:
+		return []corev1.ContainerPort{tcp("admin", rethinkDBAdminPort)}
+	case appsv1alpha1.DependencyRabbitMQ:
+		return []corev1.ContainerPort{tcp("management", rabbitMQManagementPort)}
+	case appsv1alpha1.DependencyMinIO:
+		return []corev1.ContainerPort{tcp("console", minioConsolePort)}
+	case appsv1alpha1.DependencyElasticsearch, appsv1alpha1.DependencyOpenSearch:
+		return []corev1.ContainerPort{tcp("transport", 9300)}
+	case appsv1alpha1.DependencyClickHouse:
+		return []corev1.ContainerPort{tcp("native", 9000)}
+	case appsv1alpha1.DependencyCockroach:
+		return []corev1.ContainerPort{tcp("http", 8080)}
+	case appsv1alpha1.DependencyMailpit:
+		return []corev1.ContainerPort{tcp("http", mailpitUIPort)}
+	case appsv1alpha1.DependencyQdrant:
+		return []corev1.ContainerPort{tcp("grpc", 6334)}
+	case appsv1alpha1.DependencyWeaviate:
+		return []corev1.ContainerPort{tcp("grpc", 50051)}
+	case appsv1alpha1.DependencyNeo4j:
+		return []corev1.ContainerPort{tcp("http", neo4jHTTPPort)}
+	case appsv1alpha1.DependencyEtcd:
+		return []corev1.ContainerPort{tcp("peer", 2380)}
+	}
+	return nil
 }
 
 // dependencyName returns the child resource name for a given dependency.
@@ -851,339 +2115,1447 @@ func dependencyName(crName string, depType appsv1alpha1.DependencyType) string {
 	return fmt.Sprintf("%s-%s", safeName(crName), string(depType))
 }
 
+// dependencyWaitImage runs the TCP and HTTP readiness checks.
+const dependencyWaitImage = "busybox:1.36"
+
+// dependencyReadinessCheck returns a shell command that succeeds once the
+// dependency can actually serve requests, not just accept connections, and
+// the image to run it in. Client tools come from the dependency's own image;
+// HTTP checks use busybox wget. Types without a known check fall back to a
+// TCP probe.
+func dependencyReadinessCheck(dep appsv1alpha1.DependencySpec, defaults dependencyDefaults, svcName string, port int32) (image, check string) {
+	httpCheck := func(p int32, path string) string {
+		return fmt.Sprintf("wget -q -O /dev/null 'http://%s:%d%s'", svcName, p, path)
+	}
+	switch dep.Type {
+	case appsv1alpha1.DependencyPostgres, appsv1alpha1.DependencyTimescaleDB:
+		return dependencyImage(dep, defaults), fmt.Sprintf("pg_isready -q -h %s -p %d", svcName, port)
+	case appsv1alpha1.DependencyRedis:
+		// Variants don't all ship redis-cli, so they are checked from the
+		// stock image; the protocol is the same.
+		image := dependencyImage(dep, defaults)
+		if _, ok := dependencyVariantImage(dep); ok {
+			image = dependencyImage(appsv1alpha1.DependencySpec{Type: dep.Type}, defaults)
+		}
+		return image, fmt.Sprintf("redis-cli -h %s -p %d ping | grep -q PONG", svcName, port)
+	case appsv1alpha1.DependencyMySQL:
+		return dependencyImage(dep, defaults), fmt.Sprintf("mysqladmin ping -h %s -P %d --silent", svcName, port)
+	case appsv1alpha1.DependencyMariaDB:
+		// MariaDB 11 dropped the mysql* client aliases.
+		return dependencyImage(dep, defaults), fmt.Sprintf("$(command -v mariadb-admin || command -v mysqladmin) ping -h %s -P %d --silent", svcName, port)
+	case appsv1alpha1.DependencySQLServer:
+		// The 2022 image ships sqlcmd under mssql-tools18, which needs -C
+		// to trust the server's self-signed certificate.
+		pass := dependencyEnvMap(dep, defaults)["MSSQL_SA_PASSWORD"]
+		return dependencyImage(dep, defaults), fmt.Sprintf(
+			`if [ -x /opt/mssql-tools18/bin/sqlcmd ]; then c="/opt/mssql-tools18/bin/sqlcmd -C"; else c=/opt/mssql-tools/bin/sqlcmd; fi; $c -S %s,%d -U sa -P '%s' -Q 'SELECT 1' >/dev/null`,
+			svcName, port, pass)
+	case appsv1alpha1.DependencyMongoDB:
+		// mongosh replaced the legacy mongo shell in 6.0.
+		return dependencyImage(dep, defaults), fmt.Sprintf(`$(command -v mongosh || command -v mongo) --quiet --host %s --port %d --eval 'db.adminCommand("ping")' >/dev/null`, svcName, port)
+	case appsv1alpha1.DependencyElasticsearch, appsv1alpha1.DependencyOpenSearch:
+		return dependencyWaitImage, httpCheck(port, "/_cluster/health?wait_for_status=yellow&timeout=5s")
+	case appsv1alpha1.DependencyClickHouse:
+		return dependencyWaitImage, httpCheck(port, "/ping")
+	case appsv1alpha1.DependencyCockroach:
+		return dependencyWaitImage, httpCheck(8080, "/health?ready=1")
+	case appsv1alpha1.DependencyConsul:
+		return dependencyWaitImage, httpCheck(port, "/v1/status/leader")
+	case appsv1alpha1.DependencyVault:
+		return dependencyWaitImage, httpCheck(port, "/v1/sys/health")
+	case appsv1alpha1.DependencyInfluxDB, appsv1alpha1.DependencyEtcd:
+		return dependencyWaitImage, httpCheck(port, "/health")
+	case appsv1alpha1.DependencyPrometheus:
+		return dependencyWaitImage, httpCheck(port, "/-/ready")
+	case appsv1alpha1.DependencyGrafana:
+		return dependencyWaitImage, httpCheck(port, "/api/health")
+	case appsv1alpha1.DependencyQdrant:
+		return dependencyWaitImage, httpCheck(port, "/readyz")
+	case appsv1alpha1.DependencyArangoDB:
+		// Unlike /_api/version, this endpoint doesn't need credentials.
+		return dependencyWaitImage, httpCheck(port, "/_admin/server/availability")
+	case appsv1alpha1.DependencyCouchDB:
+		return dependencyWaitImage, httpCheck(port, "/_up")
+	case appsv1alpha1.DependencyMeilisearch, appsv1alpha1.DependencyTypesense:
+		return dependencyWaitImage, httpCheck(port, "/health")
+	case appsv1alpha1.DependencyOtelCollector:
+		return dependencyWaitImage, httpCheck(otelCollectorHealthPort, "/")
+	case appsv1alpha1.DependencyZookeeper:
+		return dependencyWaitImage, fmt.Sprintf("echo ruok | nc -w2 %s %d | grep -q imok", svcName, port)
+	case appsv1alpha1.DependencySurrealDB:
+		return dependencyWaitImage, httpCheck(port, "/health")
+	case appsv1alpha1.DependencyRethinkDB:
+		// The admin UI only answers once the server has joined its cluster.
+		return dependencyWaitImage, httpCheck(rethinkDBAdminPort, "/")
+	case appsv1alpha1.DependencyPulsar:
+		// The admin API comes up before the broker can serve topics.
+		return dependencyWaitImage, httpCheck(pulsarAdminPort, "/admin/v2/brokers/health")
+	case appsv1alpha1.DependencyLocalStack:
+		return dependencyWaitImage, httpCheck(port, "/_localstack/health")
+	case appsv1alpha1.DependencyNeo4j:
+		// The HTTP endpoint only answers once the database has started.
+		return dependencyWaitImage, httpCheck(neo4jHTTPPort, "/")
+	}
+	return dependencyWaitImage, fmt.Sprintf("nc -z -w2 %s %d", svcName, port)
+}
+
 // buildDependencyWaitInitContainers creates one init container per dependency
-// that blocks until the dependency service is accepting TCP connections. This
-// prevents the app container from crashing on startup because a database or
-// queue isn't ready yet.
-func buildDependencyWaitInitContainers(cr *appsv1alpha1.DevStagingEnvironment) []corev1.Container {
+// that blocks until the dependency is ready to serve (see
+// dependencyReadinessCheck). This prevents the app container from crashing
+// on startup because a database or queue isn't ready yet.
+func buildDependencyWaitInitContainers(cr *appsv1alpha1.DevStagingEnvironment, creds dependencyCreds) []corev1.Container {
 	if len(cr.Spec.Dependencies) == 0 {
 		return nil
 	}
 
 	var initContainers []corev1.Container
 	for _, dep := range cr.Spec.Dependencies {
-		defaults, ok := dependencyRegistry[dep.Type]
+		defaults, ok := dependencyDefaultsFor(cr, dep, creds)
 		if !ok {
 			continue
 		}
 
-		svcName := dependencyName(cr.Name, dep.Type)
+		svcName := dependencyResourceName(cr.Name, dep)
 		port := defaults.Port
 		if dep.Port != nil {
 			port = *dep.Port
 		}
 
-		// Use busybox to do a TCP probe in a loop until the service is reachable
+		// Poll the readiness check in a loop until the dependency is ready
+		image, check := dependencyReadinessCheck(dep, defaults, svcName, port)
 		script := fmt.Sprintf(
 			`echo "Waiting for %s at %s:%d..."
-until nc -z -w2 %s %d; do
+until %s; do
   echo "  %s not ready, retrying in 2s..."
   sleep 2
 done
 echo "%s is ready!"`,
 			dep.Type, svcName, port,
-			svcName, port,
+			check,
 			dep.Type,
 			dep.Type,
 		)
 
-		initContainers = append(initContainers, corev1.Container{
-			Name:    fmt.Sprintf("wait-for-%s", dep.Type),
-			Image:   "busybox:1.36",
-			Command: []string{"/bin/sh", "-c", script},
-		})
+		initContainers = append(initContainers, corev1.Container{
+			Name:    fmt.Sprintf("wait-for-%s", dep.Type),
+			Image:   image,
+			Command: []string{"/bin/sh", "-c", script},
+		})
+	}
+
+	return initContainers
+}
+
+// reconcileDependencies processes each declared dependency: creates a Secret
+// (with credentials), a Deployment, and a Service.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencies(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) error {
+	logger := log.FromContext(ctx)
+
+	for _, dep := range cr.Spec.Dependencies {
+		defaults, ok := dependencyDefaultsFor(cr, dep, r.creds())
+		if !ok {
+			return fmt.Errorf("unsupported dependency type: %s", dep.Type)
+		}
+
+		// A shared dependency is only changed while every consumer agrees
+		// on its settings; otherwise each would keep rewriting it.
+		var consumers []appsv1alpha1.DevStagingEnvironment
+		if dep.Shared {
+			var conflict bool
+			var err error
+			consumers, conflict, err = r.sharedDependencyConsumers(ctx, cr.Namespace, sharedDependencyName(dep))
+			if err != nil {
+				return fmt.Errorf("dependency %s consumers: %w", dep.Type, err)
+			}
+			if conflict {
+				r.recordEvent(cr, "Warning", "SharedDependencyConflict",
+					"Shared dependency %s is declared with different settings by its consumers; leaving it unchanged", sharedDependencyName(dep))
+				continue
+			}
+			// The cache may not have caught up with a CR created moments
+			// ago; never sync the owners without it.
+			if !slices.ContainsFunc(consumers, func(c appsv1alpha1.DevStagingEnvironment) bool { return c.UID == cr.UID }) {
+				consumers = append(consumers, *cr)
+			}
+		}
+
+		// 1. Reconcile the credentials Secret
+		if err := r.reconcileDependencySecret(ctx, cr, dep, defaults); err != nil {
+			return fmt.Errorf("dependency %s secret: %w", dep.Type, err)
+		}
+
+		if dep.Replicas != nil && *dep.Replicas > 1 && defaults.Stateful {
+			r.recordEvent(cr, "Warning", "ReplicasIgnored", "Dependency %s is stateful and always runs 1 replica", dep.Type)
+		}
+		if _, ok := dependencyVariantImage(dep); dep.Variant != "" && !ok {
+			r.recordEvent(cr, "Warning", "VariantIgnored", "Dependency %s does not support variant %q", dep.Type, dep.Variant)
+		}
+		if (dep.MaxMemory != nil || dep.EvictionPolicy != "") && !supportsMemoryCap(dep.Type) {
+			r.recordEvent(cr, "Warning", "MemoryCapIgnored", "Dependency %s does not support maxMemory or evictionPolicy", dep.Type)
+		}
+		if len(dep.InitScripts) > 0 && defaults.InitScriptExt == "" {
+			r.recordEvent(cr, "Warning", "InitScriptsIgnored", "Dependency %s does not support initScripts", dep.Type)
+		}
+		if err := r.reconcileDependencyInitScripts(ctx, cr, dep, defaults); err != nil {
+			return fmt.Errorf("dependency %s init scripts: %w", dep.Type, err)
+		}
+		if err := r.reconcileDependencyConfig(ctx, cr, dep, consumers); err != nil {
+			return fmt.Errorf("dependency %s config: %w", dep.Type, err)
+		}
+
+		// 2. Reconcile the data PVC (stateful dependencies only)
+		if err := r.reconcileDependencyPVC(ctx, cr, dep, defaults); err != nil {
+			return fmt.Errorf("dependency %s pvc: %w", dep.Type, err)
+		}
+
+		// 3. Reconcile the Deployment for this dependency
+		if err := r.reconcileDependencyDeployment(ctx, cr, dep, defaults); err != nil {
+			return fmt.Errorf("dependency %s deployment: %w", dep.Type, err)
+		}
+
+		// 4. Reconcile the Service for this dependency
+		if err := r.reconcileDependencyService(ctx, cr, dep, defaults); err != nil {
+			return fmt.Errorf("dependency %s service: %w", dep.Type, err)
+		}
+
+		// 5. Reconcile the bootstrap Job (buckets, topics, queues)
+		if dependencyBootstrapIgnored(dep) {
+			r.recordEvent(cr, "Warning", "BootstrapIgnored", "Dependency %s ignores unsupported bootstrap entries", dep.Type)
+		}
+		if err := r.reconcileDependencyBootstrap(ctx, cr, dep, defaults); err != nil {
+			return fmt.Errorf("dependency %s bootstrap: %w", dep.Type, err)
+		}
+
+		// 6. Reconcile the NetworkPolicy (if isolation is enabled)
+		if err := r.reconcileDependencyNetworkPolicy(ctx, cr, dep); err != nil {
+			return fmt.Errorf("dependency %s network policy: %w", dep.Type, err)
+		}
+
+		// 7. Reconcile the web UI Ingress (if exposeUI is set)
+		if _, ok := dependencyUIPort(dep, defaults); dep.ExposeUI && !ok {
+			r.recordEvent(cr, "Warning", "ExposeUIIgnored", "Dependency %s has no web UI to expose", dep.Type)
+		}
+		if err := r.reconcileDependencyUIIngress(ctx, cr, dep, defaults); err != nil {
+			return fmt.Errorf("dependency %s ui ingress: %w", dep.Type, err)
+		}
+
+		// 8. Point a shared dependency's owner references at every consumer
+		if dep.Shared {
+			if err := r.syncSharedDependencyOwners(ctx, cr.Namespace, sharedDependencyName(dep), consumers); err != nil {
+				return fmt.Errorf("dependency %s owners: %w", dep.Type, err)
+			}
+		}
+
+		logger.Info("Dependency reconciled", "type", dep.Type, "name", dependencyResourceName(cr.Name, dep))
+	}
+
+	// 9. Prune stale dependencies — if a dep was removed from the spec,
+	//    delete its Deployment, Service, Secret, bootstrap Job,
+	//    NetworkPolicy, UI Ingress, and PVC. Shared dependencies are only deleted
+	//    once their last consumer drops them.
+	if err := r.pruneOrphanedDependencies(ctx, cr); err != nil {
+		return fmt.Errorf("prune orphaned dependencies: %w", err)
+	}
+	if err := r.pruneSharedDependencies(ctx, cr); err != nil {
+		return fmt.Errorf("prune shared dependencies: %w", err)
+	}
+
+	return nil
+}
+
+// pruneOrphanedDependencies deletes Deployments, Services, Secrets, init-script
+// and generated-config ConfigMaps, bootstrap Jobs, NetworkPolicies, UI Ingresses, and PVCs for
+// dependencies that were removed from the CR spec. It finds all child
+// Deployments labelled as managed by this CR and deletes any whose dependency
+// type is no longer in cr.Spec.Dependencies.
+func (r *DevStagingEnvironmentReconciler) pruneOrphanedDependencies(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) error {
+	logger := log.FromContext(ctx)
+
+	// Build a set of the per-environment dependency types currently
+	// declared in the spec. Shared ones live under their shared name, so a
+	// dependency switched to shared leaves its old resources to be pruned.
+	wantedTypes := make(map[string]bool, len(cr.Spec.Dependencies))
+	for _, dep := range cr.Spec.Dependencies {
+		if !dep.Shared {
+			wantedTypes[string(dep.Type)] = true
+		}
+	}
+
+	// List all Deployments that belong to this CR's dependencies
+	depDeployments := &appsv1.DeploymentList{}
+	if err := r.List(ctx, depDeployments,
+		client.InNamespace(cr.Namespace),
+		client.MatchingLabels{
+			"app.kubernetes.io/part-of":    cr.Name,
+			"app.kubernetes.io/managed-by": "devstagingenvironment-operator",
+		},
+	); err != nil {
+		return err
+	}
+
+	for i := range depDeployments.Items {
+		dep := &depDeployments.Items[i]
+		component := dep.Labels["app.kubernetes.io/component"]
+		if component == "" {
+			continue // not a dependency resource
+		}
+		if wantedTypes[component] {
+			continue // still declared in the spec
+		}
+
+		logger.Info("Pruning orphaned dependency Deployment", "name", dep.Name, "type", component)
+		if err := r.Delete(ctx, dep); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		// Also delete the corresponding Service
+		svc := &corev1.Service{}
+		svcKey := types.NamespacedName{Name: dep.Name, Namespace: cr.Namespace}
+		if err := r.Get(ctx, svcKey, svc); err == nil {
+			logger.Info("Pruning orphaned dependency Service", "name", svc.Name)
+			if err := r.Delete(ctx, svc); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		// Also delete the corresponding credentials Secret
+		secret := &corev1.Secret{}
+		secretKey := types.NamespacedName{Name: dep.Name + "-credentials", Namespace: cr.Namespace}
+		if err := r.Get(ctx, secretKey, secret); err == nil {
+			logger.Info("Pruning orphaned dependency Secret", "name", secret.Name)
+			if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		// Also delete the corresponding init-scripts and generated-config ConfigMaps
+		for _, cmName := range []string{dependencyInitConfigMapName(dep.Name), dependencyConfigMapName(dep.Name)} {
+			cm := &corev1.ConfigMap{}
+			cmKey := types.NamespacedName{Name: cmName, Namespace: cr.Namespace}
+			if err := r.Get(ctx, cmKey, cm); err == nil {
+				logger.Info("Pruning orphaned dependency ConfigMap", "name", cm.Name)
+				if err := r.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+					return err
+				}
+			}
+		}
+
+		// Also delete the corresponding bootstrap Job
+		job := &batchv1.Job{}
+		jobKey := types.NamespacedName{Name: dependencyBootstrapJobName(dep.Name), Namespace: cr.Namespace}
+		if err := r.Get(ctx, jobKey, job); err == nil {
+			logger.Info("Pruning orphaned dependency bootstrap Job", "name", job.Name)
+			if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		// Also delete the corresponding NetworkPolicy
+		np := &networkingv1.NetworkPolicy{}
+		npKey := types.NamespacedName{Name: dep.Name, Namespace: cr.Namespace}
+		if err := r.Get(ctx, npKey, np); err == nil {
+			logger.Info("Pruning orphaned dependency NetworkPolicy", "name", np.Name)
+			if err := r.Delete(ctx, np); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		// Also delete the corresponding UI Ingress
+		ing := &networkingv1.Ingress{}
+		ingKey := types.NamespacedName{Name: dependencyUIIngressName(dep.Name), Namespace: cr.Namespace}
+		if err := r.Get(ctx, ingKey, ing); err == nil {
+			logger.Info("Pruning orphaned dependency UI Ingress", "name", ing.Name)
+			if err := r.Delete(ctx, ing); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		// Also delete the corresponding data PVC (stateful deps only)
+		pvc := &corev1.PersistentVolumeClaim{}
+		pvcKey := types.NamespacedName{Name: dependencyPVCName(dep.Name), Namespace: cr.Namespace}
+		if err := r.Get(ctx, pvcKey, pvc); err == nil {
+			logger.Info("Pruning orphaned dependency PVC", "name", pvc.Name)
+			if err := r.Delete(ctx, pvc); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Shared dependencies
+//
+// A dependency with Shared set is created once per namespace under its
+// shared name and reused by every DevStagingEnvironment that declares it.
+// Its resources carry a non-controller owner reference to each consumer, so
+// garbage collection only removes them once every consumer is deleted, and
+// sharedDependencyLabel instead of a part-of label, so no single consumer's
+// pruning claims them.
+// ────────────────────────────────────────────────────────────────────────────
+
+// sharedDependencyLabel is set to the shared name on every resource of a
+// shared dependency.
+const sharedDependencyLabel = "apps.example.com/shared-dependency"
+
+// sharedDependencyName returns the name a shared dependency's resources are
+// created under.
+func sharedDependencyName(dep appsv1alpha1.DependencySpec) string {
+	if dep.SharedName != "" {
+		return dep.SharedName
+	}
+	return "shared-" + string(dep.Type)
+}
+
+// sharedDependencySettings strips the fields of dep that only affect its
+// consumer, so two consumers declaring the same server compare equal.
+func sharedDependencySettings(dep appsv1alpha1.DependencySpec) appsv1alpha1.DependencySpec {
+	dep.EnvVarName = ""
+	return dep
+}
+
+// setDependencyOwner makes cr the controller of a per-environment
+// dependency resource, or one of the owners of a shared one.
+func (r *DevStagingEnvironmentReconciler) setDependencyOwner(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, obj client.Object) error {
+	if dep.Shared {
+		return controllerutil.SetOwnerReference(cr, obj, r.Scheme)
+	}
+	return controllerutil.SetControllerReference(cr, obj, r.Scheme)
+}
+
+// sharedDependencyConsumers lists the DevStagingEnvironments in namespace
+// that declare the shared dependency name, sorted by name. conflict reports
+// whether they disagree on its settings.
+func (r *DevStagingEnvironmentReconciler) sharedDependencyConsumers(ctx context.Context, namespace, name string) (consumers []appsv1alpha1.DevStagingEnvironment, conflict bool, err error) {
+	list := &appsv1alpha1.DevStagingEnvironmentList{}
+	if err := r.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, false, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+
+	settings := ""
+	for _, cr := range list.Items {
+		if !cr.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, dep := range cr.Spec.Dependencies {
+			if !dep.Shared || sharedDependencyName(dep) != name {
+				continue
+			}
+			hash := computeSpecHash(sharedDependencySettings(dep))
+			if settings != "" && hash != settings {
+				conflict = true
+			}
+			settings = hash
+			consumers = append(consumers, cr)
+			break
+		}
+	}
+	return consumers, conflict, nil
+}
+
+// syncSharedDependencyOwners sets the owner references of every resource of
+// the shared dependency name to consumers, or deletes the resources when
+// there are no consumers left.
+func (r *DevStagingEnvironmentReconciler) syncSharedDependencyOwners(ctx context.Context, namespace, name string, consumers []appsv1alpha1.DevStagingEnvironment) error {
+	logger := log.FromContext(ctx)
+
+	lists := []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&corev1.ServiceList{},
+		&corev1.SecretList{},
+		&corev1.ConfigMapList{},
+		&corev1.PersistentVolumeClaimList{},
+		&batchv1.JobList{},
+		&networkingv1.IngressList{},
+	}
+	for _, list := range lists {
+		if err := r.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels{sharedDependencyLabel: name}); err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+
+			if len(consumers) == 0 {
+				logger.Info("Pruning shared dependency resource (no consumers left)", "name", obj.GetName(), "kind", fmt.Sprintf("%T", obj))
+				if err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+					return err
+				}
+				continue
+			}
+
+			before := computeSpecHash(obj.GetOwnerReferences())
+			obj.SetOwnerReferences(nil)
+			for i := range consumers {
+				if err := controllerutil.SetOwnerReference(&consumers[i], obj, r.Scheme); err != nil {
+					return err
+				}
+			}
+			if computeSpecHash(obj.GetOwnerReferences()) == before {
+				continue
+			}
+			if err := r.Update(ctx, obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneSharedDependencies releases the shared dependencies cr owns but no
+// longer declares, deleting each one its last consumer has dropped.
+func (r *DevStagingEnvironmentReconciler) pruneSharedDependencies(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) error {
+	declared := make(map[string]bool)
+	for _, dep := range cr.Spec.Dependencies {
+		if dep.Shared {
+			declared[sharedDependencyName(dep)] = true
+		}
+	}
+
+	shared := &appsv1.DeploymentList{}
+	if err := r.List(ctx, shared, client.InNamespace(cr.Namespace), client.HasLabels{sharedDependencyLabel}); err != nil {
+		return err
+	}
+	for _, deploy := range shared.Items {
+		name := deploy.Labels[sharedDependencyLabel]
+		if declared[name] || !ownedBy(&deploy, cr) {
+			continue
+		}
+		consumers, _, err := r.sharedDependencyConsumers(ctx, cr.Namespace, name)
+		if err != nil {
+			return err
+		}
+		if err := r.syncSharedDependencyOwners(ctx, cr.Namespace, name, consumers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ownedBy reports whether obj has an owner reference to cr.
+func ownedBy(obj client.Object, cr *appsv1alpha1.DevStagingEnvironment) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == cr.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileDependencySecret creates a Secret containing the dependency credentials.
+// These are used both by the dependency container and by the app via env var injection.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencySecret(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
+	name := dependencyResourceName(cr.Name, dep) + "-credentials"
+	labels := dependencyLabels(cr, dep)
+
+	// Build the data map from defaults, allowing user overrides via dep.Env
+	data := make(map[string][]byte)
+	envMap := envVarsToMap(defaults.Env)
+	for _, e := range dep.Env {
+		envMap[e.Name] = e.Value
+	}
+	for k, v := range envMap {
+		data[k] = []byte(v)
+	}
+
+	// Also store the connection URL for convenience
+	connURL := buildConnectionURL(cr.Name, dep, defaults)
+	data["CONNECTION_URL"] = []byte(connURL)
+
+	desired := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Data: data,
+	}
+
+	if err := r.setDependencyOwner(cr, dep, desired); err != nil {
+		return err
+	}
+
+	existing := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, existing); err != nil {
+		if errors.IsNotFound(err) {
+			return r.Create(ctx, desired)
+		}
+		return err
+	}
+
+	// Update if data changed
+	existingHash := existing.Annotations[specHashAnnotation]
+	desiredHash := computeSpecHash(desired.Data)
+	if existingHash == desiredHash {
+		return nil
+	}
+	existing.Data = desired.Data
+	if existing.Annotations == nil {
+		existing.Annotations = make(map[string]string)
+	}
+	existing.Annotations[specHashAnnotation] = desiredHash
+	return r.Update(ctx, existing)
+}
+
+// reconcileDependencyDeployment creates a Deployment for the dependency service.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencyDeployment(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
+	name := dependencyResourceName(cr.Name, dep)
+	desired := buildDependencyDeployment(cr, dep, defaults)
+	r.applyDependencyImagePolicy(&desired.Spec.Template.Spec)
+	desired.Annotations[specHashAnnotation] = computeSpecHash(desired.Spec)
+
+	if err := r.setDependencyOwner(cr, dep, desired); err != nil {
+		return err
+	}
+
+	existing := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, existing); err != nil {
+		if errors.IsNotFound(err) {
+			return r.Create(ctx, desired)
+		}
+		return err
+	}
+
+	desiredHash := desired.Annotations[specHashAnnotation]
+	existingHash := existing.Annotations[specHashAnnotation]
+	if desiredHash == existingHash {
+		return nil
+	}
+
+	existing.Spec = desired.Spec
+	if existing.Annotations == nil {
+		existing.Annotations = make(map[string]string)
+	}
+	existing.Annotations[specHashAnnotation] = desiredHash
+	return r.Update(ctx, existing)
+}
+
+// mirrorImage prepends mirror to image unless mirror is empty or image
+// already names a registry host (e.g. "docker.elastic.co/..." or
+// "localhost:5000/...").
+func mirrorImage(mirror, image string) string {
+	mirror = strings.TrimSuffix(mirror, "/")
+	if mirror == "" || imageHasRegistry(image) {
+		return image
+	}
+	return mirror + "/" + image
+}
+
+// imageHasRegistry reports whether image's first path component is a
+// registry host, using the same rule as the Docker CLI.
+func imageHasRegistry(image string) bool {
+	host, _, ok := strings.Cut(image, "/")
+	return ok && (strings.ContainsAny(host, ".:") || host == "localhost")
+}
+
+// imagePullSecretRefs merges lists of pull Secret names, dropping blanks
+// and duplicates.
+func imagePullSecretRefs(lists ...[]string) []corev1.LocalObjectReference {
+	var refs []corev1.LocalObjectReference
+	seen := make(map[string]bool)
+	for _, names := range lists {
+		for _, n := range names {
+			if n == "" || seen[n] {
+				continue
+			}
+			seen[n] = true
+			refs = append(refs, corev1.LocalObjectReference{Name: n})
+		}
+	}
+	return refs
+}
+
+// applyDependencyImagePolicy rewrites a dependency pod's images through
+// the registry mirror and attaches the default pull secrets.
+func (r *DevStagingEnvironmentReconciler) applyDependencyImagePolicy(spec *corev1.PodSpec) {
+	for i := range spec.InitContainers {
+		spec.InitContainers[i].Image = mirrorImage(r.DependencyImageMirror, spec.InitContainers[i].Image)
+	}
+	for i := range spec.Containers {
+		spec.Containers[i].Image = mirrorImage(r.DependencyImageMirror, spec.Containers[i].Image)
+	}
+	spec.ImagePullSecrets = imagePullSecretRefs(r.ImagePullSecrets)
+}
+
+// dependencyImage resolves the container image for a dependency: an explicit
+// Image wins, then Version as the tag, then the per-type default tag.
+func dependencyImage(dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) string {
+	if dep.Image != "" {
+		return dep.Image
+	}
+	if image, ok := dependencyVariantImage(dep); ok {
+		if dep.Version != "" {
+			return fmt.Sprintf("%s:%s", image, dep.Version)
+		}
+		return image
+	}
+	version := dep.Version
+	if version == "" {
+		version = defaults.DefaultVersion
+	}
+	if version != "" {
+		return fmt.Sprintf("%s:%s", defaults.Image, version)
+	}
+	return defaults.Image
+}
+
+// buildDependencyDeployment builds the Deployment for a dependency service.
+// Stateful dependencies mount their data PVC at defaults.DataPath and use the
+// Recreate strategy so two pods never share the same data directory.
+func buildDependencyDeployment(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) *appsv1.Deployment {
+	name := dependencyResourceName(cr.Name, dep)
+	labels := dependencyLabels(cr, dep)
+
+	// Resolve image
+	image := dependencyImage(dep, defaults)
+
+	// Resolve port
+	port := defaults.Port
+	if dep.Port != nil {
+		port = *dep.Port
+	}
+
+	// Build env: merge defaults + values derived from the Service name,
+	// then user overrides
+	env := mergeEnvVars(append(append([]corev1.EnvVar{}, defaults.Env...), dependencyDerivedEnv(name, dep.Type, port)...), dep.Env)
+
+	// Handle special container args (e.g. MinIO needs "server /data")
+	var args []string
+	if dep.Type == appsv1alpha1.DependencyMinIO {
+		args = []string{"server", "/data", "--console-address", fmt.Sprintf(":%d", minioConsolePort)}
+	}
+	if dep.Type == appsv1alpha1.DependencyConsul {
+		args = []string{"agent", "-dev", "-client=0.0.0.0"}
+	}
+	if dep.Type == appsv1alpha1.DependencyVault {
+		args = []string{"server", "-dev"}
+	}
+	if dep.Type == appsv1alpha1.DependencyCockroach {
+		args = []string{"start-single-node", "--insecure"}
+	}
+	if dep.Type == appsv1alpha1.DependencyEtcd {
+		// The image has no entrypoint, and etcd listens on localhost only
+		// unless told otherwise. Clients follow the advertised URL, so it
+		// must be the Service name.
+		args = []string{
+			"/usr/local/bin/etcd",
+			fmt.Sprintf("--listen-client-urls=http://0.0.0.0:%d", port),
+			fmt.Sprintf("--advertise-client-urls=http://%s:%d", name, port),
+			"--listen-peer-urls=http://0.0.0.0:2380",
+		}
+	}
+	if dep.Type == appsv1alpha1.DependencyPulsar {
+		// The image has no entrypoint. Lookups hand clients the advertised
+		// address, so it must be the Service name rather than the pod's.
+		args = []string{
+			"bin/pulsar", "standalone",
+			"--no-functions-worker", "--no-stream-storage",
+			"--advertised-address", name,
+		}
+	}
+	if dep.Type == appsv1alpha1.DependencyTypesense {
+		// Typesense refuses to start without an API key and data dir; the
+		// key is expanded from the container env so overrides apply.
+		args = []string{
+			"--api-key=$(TYPESENSE_API_KEY)",
+			"--data-dir=" + defaults.DataPath,
+			fmt.Sprintf("--api-port=%d", port),
+			"--enable-cors",
+		}
+	}
+	if dep.Type == appsv1alpha1.DependencyOtelCollector {
+		args = []string{"--config=" + otelCollectorConfigDir + "/config.yaml"}
+	}
+	if dep.Type == appsv1alpha1.DependencySurrealDB {
+		// Credentials are expanded from the container env so overrides
+		// and per-environment passwords apply.
+		args = []string{
+			"start",
+			"--user", "$(SURREAL_USER)", "--pass", "$(SURREAL_PASS)",
+			"--bind", fmt.Sprintf("0.0.0.0:%d", port),
+			"memory",
+		}
+	}
+	if dep.Type == appsv1alpha1.DependencyRethinkDB {
+		args = []string{"rethinkdb", "--bind", "all", "--directory", defaults.DataPath}
+	}
+	if supportsMemoryCap(dep.Type) {
+		args = cacheMemoryArgs(dep)
+	}
+	if dep.Type == appsv1alpha1.DependencyPrometheus {
+		// The generated config's targets change as a shared Prometheus
+		// gains consumers, so have Prometheus pick up edits itself.
+		args = []string{
+			"--config.file=" + prometheusConfigDir + "/prometheus.yml",
+			"--storage.tsdb.path=/prometheus",
+			fmt.Sprintf("--web.listen-address=:%d", port),
+			"--enable-feature=auto-reload-config",
+			"--config.auto-reload-interval=30s",
+		}
+	}
+
+	container := corev1.Container{
+		Name:  string(dep.Type),
+		Image: image,
+		Env:   env,
+		Args:  args,
+		Ports: []corev1.ContainerPort{{
+			Name:          string(dep.Type),
+			ContainerPort: port,
+			Protocol:      corev1.ProtocolTCP,
+		}},
+	}
+
+	// Some services expose multiple ports that the app needs to reach.
+	container.Ports = append(container.Ports, dependencyExtraPorts(dep.Type)...)
+
+	// Apply resource requirements: the spec's replace the per-type defaults
+	container.Resources = *defaults.Resources.DeepCopy()
+	if dep.Resources != nil {
+		container.Resources = buildResourceRequirements(dep.Resources)
+	}
+
+	var volumes []corev1.Volume
+	strategy := appsv1.DeploymentStrategy{}
+	if defaults.Stateful && defaults.DataPath != "" {
+		container.VolumeMounts = []corev1.VolumeMount{{
+			Name:      "data",
+			MountPath: defaults.DataPath,
+		}}
+		volumes = []corev1.Volume{{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: dependencyPVCName(name),
+				},
+			},
+		}}
+		strategy.Type = appsv1.RecreateDeploymentStrategyType
+	}
+
+	var podAnnotations map[string]string
+	if cm := buildDependencyInitScripts(cr, dep, defaults); cm != nil {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "init-scripts",
+			MountPath: "/docker-entrypoint-initdb.d",
+			ReadOnly:  true,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: "init-scripts",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+				},
+			},
+		})
+		podAnnotations = map[string]string{initScriptsHashAnnotation: computeSpecHash(cm.Data)}
+	}
+	if cm := buildDependencyConfig(cr, dep, nil); cm != nil {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "config",
+			MountPath: dependencyConfigDir(dep.Type),
+			ReadOnly:  true,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+				},
+			},
+		})
+		// Grafana and the collector only read their config at startup.
+		if dep.Type == appsv1alpha1.DependencyGrafana || dep.Type == appsv1alpha1.DependencyOtelCollector {
+			if podAnnotations == nil {
+				podAnnotations = make(map[string]string)
+			}
+			podAnnotations[dependencyConfigHashAnnotation] = computeSpecHash(cm.Data)
+		}
+	}
+
+	replicas := dependencyReplicas(dep, defaults)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Strategy: strategy,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: podAnnotations},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{container},
+					Volumes:    volumes,
+				},
+			},
+		},
+	}
+	// The neo4j image turns every NEO4J_* env var into a config setting, so
+	// a service-link var such as NEO4J_PORT (from any Service named "neo4j"
+	// in the namespace) would stop it from starting.
+	if dep.Type == appsv1alpha1.DependencyNeo4j {
+		enableServiceLinks := false
+		deploy.Spec.Template.Spec.EnableServiceLinks = &enableServiceLinks
+	}
+	// Hash the built spec rather than the DependencySpec so operator-side
+	// changes (new volumes, env defaults) also roll out to existing deps.
+	deploy.Annotations = map[string]string{
+		specHashAnnotation: computeSpecHash(deploy.Spec),
+	}
+	return deploy
+}
+
+// dependencyReplicas returns the replica count for a dependency Deployment.
+// Stateful dependencies always get one replica: their single ReadWriteOnce
+// PVC can't be shared between pods.
+func dependencyReplicas(dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) int32 {
+	if dep.Replicas == nil || *dep.Replicas < 1 || defaults.Stateful {
+		return 1
+	}
+	return *dep.Replicas
+}
+
+// dependencyDerivedEnv returns dependency container env vars that depend on
+// the dependency's own Service name and can't live in dependencyRegistry.
+func dependencyDerivedEnv(svcName string, depType appsv1alpha1.DependencyType, port int32) []corev1.EnvVar {
+	switch depType {
+	case appsv1alpha1.DependencyKafka:
+		// Clients bootstrap via the Service and then connect to whatever
+		// address the broker advertises; without this it advertises the
+		// pod hostname and every request after metadata fails.
+		return []corev1.EnvVar{
+			{Name: "KAFKA_ADVERTISED_LISTENERS", Value: fmt.Sprintf("PLAINTEXT://%s:%d", svcName, port)},
+		}
+	case appsv1alpha1.DependencyGrafana:
+		return []corev1.EnvVar{{Name: "GF_SERVER_HTTP_PORT", Value: fmt.Sprint(port)}}
+	case appsv1alpha1.DependencyMeilisearch:
+		return []corev1.EnvVar{{Name: "MEILI_HTTP_ADDR", Value: fmt.Sprintf("0.0.0.0:%d", port)}}
+	}
+	return nil
+}
+
+// dependencyInitConfigMapName returns the init-scripts ConfigMap name for a
+// dependency Deployment.
+func dependencyInitConfigMapName(depName string) string {
+	return depName + "-init"
+}
+
+// buildDependencyInitScripts builds the ConfigMap holding dep.InitScripts.
+// Keys are zero-padded ("001-init.sql", "002-init.sql", ...) because the
+// image entrypoints run /docker-entrypoint-initdb.d in lexical order.
+// Returns nil when there are no scripts or the type doesn't support them.
+func buildDependencyInitScripts(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) *corev1.ConfigMap {
+	if len(dep.InitScripts) == 0 || defaults.InitScriptExt == "" {
+		return nil
+	}
+
+	data := make(map[string]string, len(dep.InitScripts))
+	for i, script := range dep.InitScripts {
+		data[fmt.Sprintf("%03d-init%s", i+1, defaults.InitScriptExt)] = script
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dependencyInitConfigMapName(dependencyResourceName(cr.Name, dep)),
+			Namespace: cr.Namespace,
+			Labels:    dependencyLabels(cr, dep),
+		},
+		Data: data,
 	}
+}
 
-	return initContainers
+// reconcileDependencyInitScripts creates or updates the init-scripts
+// ConfigMap, and deletes it once InitScripts is cleared.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencyInitScripts(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
+	name := dependencyInitConfigMapName(dependencyResourceName(cr.Name, dep))
+	return r.reconcileDependencyConfigMap(ctx, cr, dep, name, buildDependencyInitScripts(cr, dep, defaults))
 }
 
-// reconcileDependencies processes each declared dependency: creates a Secret
-// (with credentials), a Deployment, and a Service.
-func (r *DevStagingEnvironmentReconciler) reconcileDependencies(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) error {
-	logger := log.FromContext(ctx)
+// Generated config files are mounted at these directories; see
+// buildDependencyConfig.
+const (
+	prometheusConfigDir    = "/etc/prometheus/kindling"
+	grafanaDatasourceDir   = "/etc/grafana/provisioning/datasources"
+	otelCollectorConfigDir = "/etc/otelcol/kindling"
+)
 
-	for _, dep := range cr.Spec.Dependencies {
-		defaults, ok := dependencyRegistry[dep.Type]
-		if !ok {
-			return fmt.Errorf("unsupported dependency type: %s", dep.Type)
-		}
+// dependencyConfigHashAnnotation is set on pod templates of dependencies
+// that only read their generated config at startup, so edits roll them.
+const dependencyConfigHashAnnotation = "apps.example.com/config-hash"
 
-		// 1. Reconcile the credentials Secret
-		if err := r.reconcileDependencySecret(ctx, cr, dep, defaults); err != nil {
-			return fmt.Errorf("dependency %s secret: %w", dep.Type, err)
+// dependencyConfigMapName returns the generated-config ConfigMap name for a
+// dependency Deployment.
+func dependencyConfigMapName(depName string) string {
+	return depName + "-config"
+}
+
+// dependencyConfigDir returns where depType's generated config is mounted.
+func dependencyConfigDir(depType appsv1alpha1.DependencyType) string {
+	switch depType {
+	case appsv1alpha1.DependencyPrometheus:
+		return prometheusConfigDir
+	case appsv1alpha1.DependencyOtelCollector:
+		return otelCollectorConfigDir
+	}
+	return grafanaDatasourceDir
+}
+
+// buildDependencyConfig builds the ConfigMap of config files the operator
+// generates for a dependency: for prometheus, a scrape config targeting
+// /metrics on the app Service of every consumer (just cr when consumers is
+// empty); for grafana, a default datasource pointing at cr's prometheus;
+// for otel-collector, OTLP receivers that export traces to cr's jaeger, or
+// log everything when there is none. Returns nil for other types, and for
+// grafana without a prometheus.
+func buildDependencyConfig(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, consumers []appsv1alpha1.DevStagingEnvironment) *corev1.ConfigMap {
+	var data map[string]string
+	switch dep.Type {
+	case appsv1alpha1.DependencyPrometheus:
+		if len(consumers) == 0 {
+			consumers = []appsv1alpha1.DevStagingEnvironment{*cr}
+		}
+		targets := make([]string, 0, len(consumers))
+		for _, c := range consumers {
+			targets = append(targets, fmt.Sprintf("%s:%d", safeName(c.Name), c.Spec.Service.Port))
+		}
+		sort.Strings(targets)
+		port := dependencyRegistry[dep.Type].Port
+		if dep.Port != nil {
+			port = *dep.Port
 		}
+		var b strings.Builder
+		b.WriteString("global:\n  scrape_interval: 15s\nscrape_configs:\n")
+		fmt.Fprintf(&b, "  - job_name: prometheus\n    static_configs:\n      - targets: [\"localhost:%d\"]\n", port)
+		b.WriteString("  - job_name: app\n    metrics_path: /metrics\n    static_configs:\n      - targets:\n")
+		for _, t := range targets {
+			fmt.Fprintf(&b, "          - %q\n", t)
+		}
+		data = map[string]string{"prometheus.yml": b.String()}
 
-		// 2. Reconcile the Deployment for this dependency
-		if err := r.reconcileDependencyDeployment(ctx, cr, dep, defaults); err != nil {
-			return fmt.Errorf("dependency %s deployment: %w", dep.Type, err)
+	case appsv1alpha1.DependencyGrafana:
+		i := slices.IndexFunc(cr.Spec.Dependencies, func(d appsv1alpha1.DependencySpec) bool {
+			return d.Type == appsv1alpha1.DependencyPrometheus
+		})
+		if i < 0 {
+			return nil
 		}
+		prom := cr.Spec.Dependencies[i]
+		url := buildConnectionURL(cr.Name, prom, dependencyRegistry[prom.Type])
+		data = map[string]string{"kindling.yaml": fmt.Sprintf(
+			"apiVersion: 1\ndatasources:\n  - name: Prometheus\n    type: prometheus\n    access: proxy\n    url: %s\n    isDefault: true\n", url)}
 
-		// 3. Reconcile the Service for this dependency
-		if err := r.reconcileDependencyService(ctx, cr, dep, defaults); err != nil {
-			return fmt.Errorf("dependency %s service: %w", dep.Type, err)
+	case appsv1alpha1.DependencyOtelCollector:
+		port := dependencyRegistry[dep.Type].Port
+		if dep.Port != nil {
+			port = *dep.Port
 		}
+		traceExporter := "debug"
+		var b strings.Builder
+		fmt.Fprintf(&b, "receivers:\n  otlp:\n    protocols:\n      grpc:\n        endpoint: 0.0.0.0:4317\n      http:\n        endpoint: 0.0.0.0:%d\n", port)
+		b.WriteString("processors:\n  batch: {}\nexporters:\n  debug: {}\n")
+		if i := slices.IndexFunc(cr.Spec.Dependencies, func(d appsv1alpha1.DependencySpec) bool {
+			return d.Type == appsv1alpha1.DependencyJaeger
+		}); i >= 0 {
+			traceExporter = "otlp/jaeger"
+			fmt.Fprintf(&b, "  otlp/jaeger:\n    endpoint: %s:4317\n    tls:\n      insecure: true\n", dependencyResourceName(cr.Name, cr.Spec.Dependencies[i]))
+		}
+		fmt.Fprintf(&b, "extensions:\n  health_check:\n    endpoint: 0.0.0.0:%d\n", otelCollectorHealthPort)
+		b.WriteString("service:\n  extensions: [health_check]\n  pipelines:\n")
+		fmt.Fprintf(&b, "    traces:\n      receivers: [otlp]\n      processors: [batch]\n      exporters: [%s]\n", traceExporter)
+		for _, signal := range []string{"metrics", "logs"} {
+			fmt.Fprintf(&b, "    %s:\n      receivers: [otlp]\n      processors: [batch]\n      exporters: [debug]\n", signal)
+		}
+		data = map[string]string{"config.yaml": b.String()}
 
-		logger.Info("Dependency reconciled", "type", dep.Type, "name", dependencyName(cr.Name, dep.Type))
+	default:
+		return nil
 	}
 
-	// 4. Prune stale dependencies — if a dep was removed from the spec,
-	//    delete its Deployment, Service, and Secret.
-	if err := r.pruneOrphanedDependencies(ctx, cr); err != nil {
-		return fmt.Errorf("prune orphaned dependencies: %w", err)
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dependencyConfigMapName(dependencyResourceName(cr.Name, dep)),
+			Namespace: cr.Namespace,
+			Labels:    dependencyLabels(cr, dep),
+		},
+		Data: data,
 	}
+}
 
-	return nil
+// reconcileDependencyConfig creates or updates the generated-config
+// ConfigMap, and deletes it once the dependency no longer needs one.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencyConfig(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, consumers []appsv1alpha1.DevStagingEnvironment) error {
+	name := dependencyConfigMapName(dependencyResourceName(cr.Name, dep))
+	return r.reconcileDependencyConfigMap(ctx, cr, dep, name, buildDependencyConfig(cr, dep, consumers))
 }
 
-// pruneOrphanedDependencies deletes Deployments, Services, and Secrets for
-// dependencies that were removed from the CR spec. It finds all child
-// Deployments labelled as managed by this CR and deletes any whose dependency
-// type is no longer in cr.Spec.Dependencies.
-func (r *DevStagingEnvironmentReconciler) pruneOrphanedDependencies(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment) error {
-	logger := log.FromContext(ctx)
+// reconcileDependencyConfigMap creates or updates the dependency ConfigMap
+// called name to match desired, or deletes it when desired is nil.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencyConfigMap(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, name string, desired *corev1.ConfigMap) error {
+	existing := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
 
-	// Build a set of dependency types currently declared in the spec
-	wantedTypes := make(map[string]bool, len(cr.Spec.Dependencies))
-	for _, dep := range cr.Spec.Dependencies {
-		wantedTypes[string(dep.Type)] = true
+	if desired == nil {
+		if found {
+			return r.Delete(ctx, existing)
+		}
+		return nil
 	}
 
-	// List all Deployments that belong to this CR's dependencies
-	depDeployments := &appsv1.DeploymentList{}
-	if err := r.List(ctx, depDeployments,
-		client.InNamespace(cr.Namespace),
-		client.MatchingLabels{
-			"app.kubernetes.io/part-of":    cr.Name,
-			"app.kubernetes.io/managed-by": "devstagingenvironment-operator",
-		},
-	); err != nil {
+	if err := r.setDependencyOwner(cr, dep, desired); err != nil {
 		return err
 	}
+	desiredHash := computeSpecHash(desired.Data)
 
-	for i := range depDeployments.Items {
-		dep := &depDeployments.Items[i]
-		component := dep.Labels["app.kubernetes.io/component"]
-		if component == "" {
-			continue // not a dependency resource
-		}
-		if wantedTypes[component] {
-			continue // still declared in the spec
-		}
+	if !found {
+		desired.Annotations = map[string]string{specHashAnnotation: desiredHash}
+		return r.Create(ctx, desired)
+	}
 
-		logger.Info("Pruning orphaned dependency Deployment", "name", dep.Name, "type", component)
-		if err := r.Delete(ctx, dep); err != nil && !errors.IsNotFound(err) {
-			return err
-		}
+	if existing.Annotations[specHashAnnotation] == desiredHash {
+		return nil
+	}
+	existing.Data = desired.Data
+	if existing.Annotations == nil {
+		existing.Annotations = make(map[string]string)
+	}
+	existing.Annotations[specHashAnnotation] = desiredHash
+	return r.Update(ctx, existing)
+}
 
-		// Also delete the corresponding Service
-		svc := &corev1.Service{}
-		svcKey := types.NamespacedName{Name: dep.Name, Namespace: cr.Namespace}
-		if err := r.Get(ctx, svcKey, svc); err == nil {
-			logger.Info("Pruning orphaned dependency Service", "name", svc.Name)
-			if err := r.Delete(ctx, svc); err != nil && !errors.IsNotFound(err) {
-				return err
-			}
-		}
+// Images for the bootstrap Job when it can't reuse the dependency image:
+// minio/minio ships without the mc client, and only the management variant
+// of rabbitmq includes rabbitmqadmin.
+const (
+	minioClientImage       = "minio/mc"
+	rabbitmqBootstrapImage = "rabbitmq:3-management"
+)
 
-		// Also delete the corresponding credentials Secret
-		secret := &corev1.Secret{}
-		secretKey := types.NamespacedName{Name: dep.Name + "-credentials", Namespace: cr.Namespace}
-		if err := r.Get(ctx, secretKey, secret); err == nil {
-			logger.Info("Pruning orphaned dependency Secret", "name", secret.Name)
-			if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
-				return err
-			}
-		}
-	}
+// defaultBootstrapBackoffLimit bounds bootstrap Job retries. The scripts wait
+// for the dependency themselves, so failures are real errors.
+const defaultBootstrapBackoffLimit = 4
+
+// dependencyBootstrapJobName returns the bootstrap Job name for a dependency
+// Deployment.
+func dependencyBootstrapJobName(depName string) string {
+	return depName + "-bootstrap"
+}
 
+// dependencyBootstrapNames returns the entries of dep.Bootstrap that dep.Type
+// can create: buckets for minio, topics for kafka, queues for rabbitmq.
+// Everything else is ignored.
+func dependencyBootstrapNames(dep appsv1alpha1.DependencySpec) []string {
+	if dep.Bootstrap == nil {
+		return nil
+	}
+	switch dep.Type {
+	case appsv1alpha1.DependencyMinIO:
+		return dep.Bootstrap.Buckets
+	case appsv1alpha1.DependencyKafka:
+		return dep.Bootstrap.Topics
+	case appsv1alpha1.DependencyRabbitMQ:
+		return dep.Bootstrap.Queues
+	}
 	return nil
 }
 
-// reconcileDependencySecret creates a Secret containing the dependency credentials.
-// These are used both by the dependency container and by the app via env var injection.
-func (r *DevStagingEnvironmentReconciler) reconcileDependencySecret(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
-	name := dependencyName(cr.Name, dep.Type) + "-credentials"
-	labels := labelsForDependency(cr, dep.Type)
+// dependencyBootstrapIgnored reports whether dep.Bootstrap lists entries
+// that dep.Type does not support.
+func dependencyBootstrapIgnored(dep appsv1alpha1.DependencySpec) bool {
+	if dep.Bootstrap == nil {
+		return false
+	}
+	total := len(dep.Bootstrap.Buckets) + len(dep.Bootstrap.Topics) + len(dep.Bootstrap.Queues)
+	return total > len(dependencyBootstrapNames(dep))
+}
 
-	// Build the data map from defaults, allowing user overrides via dep.Env
-	data := make(map[string][]byte)
-	envMap := envVarsToMap(defaults.Env)
-	for _, e := range dep.Env {
-		envMap[e.Name] = e.Value
+// buildDependencyBootstrapJob builds the Job that creates dep.Bootstrap
+// entries once the dependency accepts connections. Each script polls until
+// the dependency is reachable and then creates every name passed as a
+// positional argument, skipping ones that already exist. Returns nil when
+// there is nothing to create.
+func buildDependencyBootstrapJob(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) *batchv1.Job {
+	names := dependencyBootstrapNames(dep)
+	if len(names) == 0 {
+		return nil
 	}
-	for k, v := range envMap {
-		data[k] = []byte(v)
+
+	svcName := dependencyResourceName(cr.Name, dep)
+	port := defaults.Port
+	if dep.Port != nil {
+		port = *dep.Port
 	}
 
-	// Also store the connection URL for convenience
-	connURL := buildConnectionURL(cr.Name, dep, defaults)
-	data["CONNECTION_URL"] = []byte(connURL)
+	var image, script string
+	var envFrom []corev1.EnvFromSource
+	credentials := []corev1.EnvFromSource{{
+		SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: svcName + "-credentials"},
+		},
+	}}
+	switch dep.Type {
+	case appsv1alpha1.DependencyMinIO:
+		image = minioClientImage
+		envFrom = credentials
+		script = fmt.Sprintf(`until mc alias set dep http://%s:%d "$MINIO_ROOT_USER" "$MINIO_ROOT_PASSWORD" >/dev/null 2>&1; do sleep 2; done
+for b in "$@"; do mc mb --ignore-existing "dep/$b" || exit 1; done`, svcName, port)
+	case appsv1alpha1.DependencyKafka:
+		image = dependencyImage(dep, defaults)
+		script = fmt.Sprintf(`KT=$(command -v kafka-topics.sh || echo /opt/kafka/bin/kafka-topics.sh)
+until "$KT" --bootstrap-server %[1]s:%[2]d --list >/dev/null 2>&1; do sleep 2; done
+for t in "$@"; do "$KT" --bootstrap-server %[1]s:%[2]d --create --if-not-exists --topic "$t" || exit 1; done`, svcName, port)
+	case appsv1alpha1.DependencyRabbitMQ:
+		image = rabbitmqBootstrapImage
+		envFrom = credentials
+		script = fmt.Sprintf(`ADMIN="rabbitmqadmin -H %s -P 15672 -u $RABBITMQ_DEFAULT_USER -p $RABBITMQ_DEFAULT_PASS"
+until $ADMIN list queues >/dev/null 2>&1; do sleep 2; done
+for q in "$@"; do $ADMIN declare queue name="$q" durable=true || exit 1; done`, svcName)
+	}
 
-	desired := &corev1.Secret{
+	name := dependencyBootstrapJobName(svcName)
+	labels := dependencyLabels(cr, dep)
+	// Keep the Job's pods out of the dependency Service's selector.
+	labels["app.kubernetes.io/name"] = name
+
+	backoffLimit := int32(defaultBootstrapBackoffLimit)
+	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: cr.Namespace,
 			Labels:    labels,
 		},
-		Data: data,
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyOnFailure,
+					Containers: []corev1.Container{{
+						Name:    "bootstrap",
+						Image:   image,
+						Command: append([]string{"/bin/sh", "-c", script, "bootstrap"}, names...),
+						EnvFrom: envFrom,
+					}},
+				},
+			},
+		},
 	}
+}
 
-	if err := controllerutil.SetControllerReference(cr, desired, r.Scheme); err != nil {
+// reconcileDependencyBootstrap creates the bootstrap Job, and deletes it once
+// Bootstrap is cleared. Job pod templates are immutable, so a changed Job is
+// deleted here and recreated on the next reconcile.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencyBootstrap(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
+	name := dependencyBootstrapJobName(dependencyResourceName(cr.Name, dep))
+	desired := buildDependencyBootstrapJob(cr, dep, defaults)
+
+	existing := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, existing)
+	if err != nil && !errors.IsNotFound(err) {
 		return err
 	}
+	found := err == nil
 
-	existing := &corev1.Secret{}
-	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, existing); err != nil {
-		if errors.IsNotFound(err) {
-			return r.Create(ctx, desired)
+	background := client.PropagationPolicy(metav1.DeletePropagationBackground)
+	if desired == nil {
+		if found {
+			return client.IgnoreNotFound(r.Delete(ctx, existing, background))
 		}
+		return nil
+	}
+
+	r.applyDependencyImagePolicy(&desired.Spec.Template.Spec)
+	if err := r.setDependencyOwner(cr, dep, desired); err != nil {
 		return err
 	}
+	desiredHash := computeSpecHash(desired.Spec)
 
-	// Update if data changed
-	existingHash := existing.Annotations[specHashAnnotation]
-	desiredHash := computeSpecHash(desired.Data)
-	if existingHash == desiredHash {
-		return nil
+	if !found {
+		desired.Annotations = map[string]string{specHashAnnotation: desiredHash}
+		return r.Create(ctx, desired)
 	}
-	existing.Data = desired.Data
-	if existing.Annotations == nil {
-		existing.Annotations = make(map[string]string)
+
+	if existing.Annotations[specHashAnnotation] == desiredHash {
+		return nil
 	}
-	existing.Annotations[specHashAnnotation] = desiredHash
-	return r.Update(ctx, existing)
+	return client.IgnoreNotFound(r.Delete(ctx, existing, background))
 }
 
-// reconcileDependencyDeployment creates a Deployment for the dependency service.
-func (r *DevStagingEnvironmentReconciler) reconcileDependencyDeployment(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
-	name := dependencyName(cr.Name, dep.Type)
-	labels := labelsForDependency(cr, dep.Type)
+// dependencyPVCName returns the data PVC name for a dependency Deployment.
+func dependencyPVCName(depName string) string {
+	return depName + "-data"
+}
 
-	// Resolve image
-	image := defaults.Image
-	if dep.Image != "" {
-		image = dep.Image
-	} else if dep.Version != "" {
-		image = fmt.Sprintf("%s:%s", defaults.Image, dep.Version)
+// buildDependencyPVC builds the data PVC for a stateful dependency, sized
+// from dep.StorageSize (default 1Gi). Returns nil for stateless dependencies.
+func buildDependencyPVC(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) *corev1.PersistentVolumeClaim {
+	if !defaults.Stateful || defaults.DataPath == "" {
+		return nil
 	}
 
-	// Resolve port
-	port := defaults.Port
-	if dep.Port != nil {
-		port = *dep.Port
+	size := resource.MustParse(defaultDependencyStorageSize)
+	if dep.StorageSize != nil {
+		size = *dep.StorageSize
+	}
+
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dependencyPVCName(dependencyResourceName(cr.Name, dep)),
+			Namespace: cr.Namespace,
+			Labels:    dependencyLabels(cr, dep),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
 	}
+}
 
-	// Build env: merge defaults + user overrides
-	env := mergeEnvVars(defaults.Env, dep.Env)
+// reconcileDependencyPVC creates the data PVC for a stateful dependency.
+// An existing PVC is left alone: most of its spec is immutable, and
+// resizing depends on the storage class allowing expansion.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencyPVC(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
+	desired := buildDependencyPVC(cr, dep, defaults)
+	if desired == nil {
+		return nil
+	}
 
-	// Handle special container args (e.g. MinIO needs "server /data")
-	var args []string
-	if dep.Type == appsv1alpha1.DependencyMinIO {
-		args = []string{"server", "/data"}
+	if err := r.setDependencyOwner(cr, dep, desired); err != nil {
+		return err
 	}
-	if dep.Type == appsv1alpha1.DependencyRabbitMQ {
-		// Use the management tag by default for the UI
-		if dep.Image == "" && dep.Version == "" {
-			image = defaults.Image + ":3-management"
+
+	existing := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: cr.Namespace}, existing); err != nil {
+		if errors.IsNotFound(err) {
+			log.FromContext(ctx).Info("Creating dependency PVC", "name", desired.Name)
+			return r.Create(ctx, desired)
 		}
+		return err
 	}
-	if dep.Type == appsv1alpha1.DependencyConsul {
-		args = []string{"agent", "-dev", "-client=0.0.0.0"}
+	return nil
+}
+
+// reconcileDependencyService creates a ClusterIP Service for the dependency.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencyService(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
+	name := dependencyResourceName(cr.Name, dep)
+	labels := dependencyLabels(cr, dep)
+
+	port := defaults.Port
+	if dep.Port != nil {
+		port = *dep.Port
 	}
-	if dep.Type == appsv1alpha1.DependencyVault {
-		args = []string{"server", "-dev"}
+
+	desired := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: labels,
+			Ports: []corev1.ServicePort{{
+				Name:       string(dep.Type),
+				Port:       port,
+				TargetPort: intstr.FromInt(int(port)),
+				Protocol:   corev1.ProtocolTCP,
+			}},
+		},
 	}
-	if dep.Type == appsv1alpha1.DependencyElasticsearch {
-		if dep.Image == "" && dep.Version == "" {
-			image = defaults.Image + ":8.12.0"
-		}
+
+	for _, p := range dependencyExtraPorts(dep.Type) {
+		desired.Spec.Ports = append(desired.Spec.Ports, corev1.ServicePort{
+			Name:       p.Name,
+			Port:       p.ContainerPort,
+			TargetPort: intstr.FromInt(int(p.ContainerPort)),
+			Protocol:   p.Protocol,
+		})
 	}
-	if dep.Type == appsv1alpha1.DependencyKafka {
-		if dep.Image == "" && dep.Version == "" {
-			image = defaults.Image + ":latest"
-		}
+	desired.Annotations = map[string]string{
+		specHashAnnotation: computeSpecHash(desired.Spec),
 	}
-	if dep.Type == appsv1alpha1.DependencyJaeger {
-		if dep.Image == "" && dep.Version == "" {
-			image = defaults.Image + ":latest"
+
+	if err := r.setDependencyOwner(cr, dep, desired); err != nil {
+		return err
+	}
+
+	existing := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, existing); err != nil {
+		if errors.IsNotFound(err) {
+			return r.Create(ctx, desired)
 		}
+		return err
 	}
 
-	container := corev1.Container{
-		Name:  string(dep.Type),
-		Image: image,
-		Env:   env,
-		Args:  args,
-		Ports: []corev1.ContainerPort{{
-			Name:          string(dep.Type),
-			ContainerPort: port,
-			Protocol:      corev1.ProtocolTCP,
-		}},
+	desiredHash := desired.Annotations[specHashAnnotation]
+	existingHash := existing.Annotations[specHashAnnotation]
+	if desiredHash == existingHash {
+		return nil
 	}
 
-	// Some services expose multiple ports that the app needs to reach.
-	switch dep.Type {
-	case appsv1alpha1.DependencyJaeger:
-		container.Ports = append(container.Ports,
-			corev1.ContainerPort{Name: "otlp-grpc", ContainerPort: 4317, Protocol: corev1.ProtocolTCP},
-			corev1.ContainerPort{Name: "otlp-http", ContainerPort: 4318, Protocol: corev1.ProtocolTCP},
-		)
-	case appsv1alpha1.DependencyKafka:
-		container.Ports = append(container.Ports,
-			corev1.ContainerPort{Name: "controller", ContainerPort: 9093, Protocol: corev1.ProtocolTCP},
-		)
-	case appsv1alpha1.DependencyRabbitMQ:
-		container.Ports = append(container.Ports,
-			corev1.ContainerPort{Name: "management", ContainerPort: 15672, Protocol: corev1.ProtocolTCP},
-		)
-	case appsv1alpha1.DependencyElasticsearch:
-		container.Ports = append(container.Ports,
-			corev1.ContainerPort{Name: "transport", ContainerPort: 9300, Protocol: corev1.ProtocolTCP},
-		)
+	desired.Spec.ClusterIP = existing.Spec.ClusterIP
+	existing.Spec = desired.Spec
+	if existing.Annotations == nil {
+		existing.Annotations = make(map[string]string)
 	}
+	existing.Annotations[specHashAnnotation] = desiredHash
+	return r.Update(ctx, existing)
+}
 
-	// Apply resource requirements if provided
-	if dep.Resources != nil {
-		container.Resources = buildResourceRequirements(dep.Resources)
+// reconcileDependencyNetworkPolicy restricts ingress to the dependency's pods
+// when spec.networkIsolation is set, and removes the policy when it isn't.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencyNetworkPolicy(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec) error {
+	logger := log.FromContext(ctx)
+	key := types.NamespacedName{Name: dependencyResourceName(cr.Name, dep), Namespace: cr.Namespace}
+
+	// A shared dependency serves several environments, so no one
+	// consumer's policy can describe who may reach it.
+	if dep.Shared {
+		return nil
 	}
 
-	replicas := int32(1)
-	desired := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: cr.Namespace,
-			Labels:    labels,
-			Annotations: map[string]string{
-				specHashAnnotation: computeSpecHash(dep),
-			},
-		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: &replicas,
-			Selector: &metav1.LabelSelector{MatchLabels: labels},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: labels},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{container},
-				},
-			},
-		},
+	if !cr.Spec.NetworkIsolation {
+		existing := &networkingv1.NetworkPolicy{}
+		if err := r.Get(ctx, key, existing); err == nil {
+			logger.Info("Deleting dependency NetworkPolicy (isolation disabled)", "name", existing.Name)
+			if err := r.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
 	}
 
+	desired := buildDependencyNetworkPolicy(cr, dep)
 	if err := controllerutil.SetControllerReference(cr, desired, r.Scheme); err != nil {
 		return err
 	}
 
-	existing := &appsv1.Deployment{}
-	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, existing); err != nil {
+	existing := &networkingv1.NetworkPolicy{}
+	if err := r.Get(ctx, key, existing); err != nil {
 		if errors.IsNotFound(err) {
+			logger.Info("Creating dependency NetworkPolicy", "name", desired.Name)
 			return r.Create(ctx, desired)
 		}
 		return err
 	}
 
 	desiredHash := desired.Annotations[specHashAnnotation]
-	existingHash := existing.Annotations[specHashAnnotation]
-	if desiredHash == existingHash {
+	if existing.Annotations[specHashAnnotation] == desiredHash {
 		return nil
 	}
 
@@ -1192,71 +3564,224 @@ func (r *DevStagingEnvironmentReconciler) reconcileDependencyDeployment(ctx cont
 		existing.Annotations = make(map[string]string)
 	}
 	existing.Annotations[specHashAnnotation] = desiredHash
+	logger.Info("Updating dependency NetworkPolicy", "name", desired.Name)
 	return r.Update(ctx, existing)
 }
 
-// reconcileDependencyService creates a ClusterIP Service for the dependency.
-func (r *DevStagingEnvironmentReconciler) reconcileDependencyService(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
-	name := dependencyName(cr.Name, dep.Type)
-	labels := labelsForDependency(cr, dep.Type)
+// buildDependencyNetworkPolicy allows traffic to a dependency's pods only
+// from the app's pods and from the dependency's own pods (replica peers and
+// its bootstrap Job), which share its part-of and component labels, plus
+// the exposed UI port from any namespace.
+func buildDependencyNetworkPolicy(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec) *networkingv1.NetworkPolicy {
+	labels := dependencyLabels(cr, dep)
+	peers := map[string]string{
+		"app.kubernetes.io/component":  labels["app.kubernetes.io/component"],
+		"app.kubernetes.io/part-of":    labels["app.kubernetes.io/part-of"],
+		"app.kubernetes.io/managed-by": labels["app.kubernetes.io/managed-by"],
+	}
 
-	port := defaults.Port
-	if dep.Port != nil {
-		port = *dep.Port
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{MatchLabels: labels},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{
+				{PodSelector: &metav1.LabelSelector{MatchLabels: labelsForCR(cr)}},
+				{PodSelector: &metav1.LabelSelector{MatchLabels: peers}},
+			},
+		}},
 	}
 
-	desired := &corev1.Service{
+	// Grafana queries Prometheus as its datasource, and the collector
+	// forwards traces to Jaeger.
+	if client, ok := map[appsv1alpha1.DependencyType]appsv1alpha1.DependencyType{
+		appsv1alpha1.DependencyPrometheus: appsv1alpha1.DependencyGrafana,
+		appsv1alpha1.DependencyJaeger:     appsv1alpha1.DependencyOtelCollector,
+	}[dep.Type]; ok {
+		spec.Ingress[0].From = append(spec.Ingress[0].From, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+				"app.kubernetes.io/component":  string(client),
+				"app.kubernetes.io/managed-by": labels["app.kubernetes.io/managed-by"],
+			}},
+		})
+	}
+
+	// An exposed UI is reached through the ingress controller, which runs
+	// in another namespace; open only the UI port to it.
+	if port, ok := dependencyUIPort(dep, dependencyRegistry[dep.Type]); ok && dep.ExposeUI {
+		uiPort := intstr.FromInt(int(port))
+		spec.Ingress = append(spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{}}},
+			Ports: []networkingv1.NetworkPolicyPort{{Port: &uiPort}},
+		})
+	}
+
+	return &networkingv1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
+			Name:      dependencyResourceName(cr.Name, dep),
 			Namespace: cr.Namespace,
 			Labels:    labels,
 			Annotations: map[string]string{
-				specHashAnnotation: computeSpecHash(dep),
+				specHashAnnotation: computeSpecHash(spec),
 			},
 		},
-		Spec: corev1.ServiceSpec{
-			Type:     corev1.ServiceTypeClusterIP,
-			Selector: labels,
-			Ports: []corev1.ServicePort{{
-				Name:       string(dep.Type),
-				Port:       port,
-				TargetPort: intstr.FromInt(int(port)),
-				Protocol:   corev1.ProtocolTCP,
-			}},
-		},
+		Spec: spec,
 	}
+}
 
-	if err := controllerutil.SetControllerReference(cr, desired, r.Scheme); err != nil {
+// dependencyUIIngressName returns the name of a dependency's UI Ingress.
+func dependencyUIIngressName(depName string) string {
+	return depName + "-ui"
+}
+
+// dependencyUIHost returns the host a dependency's UI is exposed at, e.g.
+// myapp-rabbitmq-ui.localhost.
+func dependencyUIHost(crName string, dep appsv1alpha1.DependencySpec) string {
+	return dependencyUIIngressName(dependencyResourceName(crName, dep)) + ".localhost"
+}
+
+// dependencyUIPort returns the Service port of dep's web UI, or false for
+// types without one.
+func dependencyUIPort(dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) (int32, bool) {
+	switch dep.Type {
+	case appsv1alpha1.DependencyRabbitMQ:
+		return rabbitMQManagementPort, true
+	case appsv1alpha1.DependencyMinIO:
+		return minioConsolePort, true
+	case appsv1alpha1.DependencyRethinkDB:
+		return rethinkDBAdminPort, true
+	case appsv1alpha1.DependencyJaeger, appsv1alpha1.DependencyInfluxDB, appsv1alpha1.DependencyElasticsearch,
+		appsv1alpha1.DependencyOpenSearch, appsv1alpha1.DependencyPrometheus, appsv1alpha1.DependencyGrafana,
+		appsv1alpha1.DependencyArangoDB, appsv1alpha1.DependencyCouchDB, appsv1alpha1.DependencyMeilisearch:
+		// The UI is served on the main port.
+		if dep.Port != nil {
+			return *dep.Port, true
+		}
+		return defaults.Port, true
+	}
+	return 0, false
+}
+
+// reconcileDependencyUIIngress exposes the dependency's web UI when exposeUI
+// is set, and removes the Ingress when it isn't.
+func (r *DevStagingEnvironmentReconciler) reconcileDependencyUIIngress(ctx context.Context, cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) error {
+	logger := log.FromContext(ctx)
+	key := types.NamespacedName{Name: dependencyUIIngressName(dependencyResourceName(cr.Name, dep)), Namespace: cr.Namespace}
+
+	desired := buildDependencyUIIngress(cr, dep, defaults)
+	if desired == nil {
+		existing := &networkingv1.Ingress{}
+		if err := r.Get(ctx, key, existing); err == nil {
+			logger.Info("Deleting dependency UI Ingress (exposeUI disabled)", "name", existing.Name)
+			if err := r.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := r.setDependencyOwner(cr, dep, desired); err != nil {
 		return err
 	}
 
-	existing := &corev1.Service{}
-	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cr.Namespace}, existing); err != nil {
+	existing := &networkingv1.Ingress{}
+	if err := r.Get(ctx, key, existing); err != nil {
 		if errors.IsNotFound(err) {
+			logger.Info("Creating dependency UI Ingress", "name", desired.Name, "host", desired.Spec.Rules[0].Host)
 			return r.Create(ctx, desired)
 		}
 		return err
 	}
 
 	desiredHash := desired.Annotations[specHashAnnotation]
-	existingHash := existing.Annotations[specHashAnnotation]
-	if desiredHash == existingHash {
+	if existing.Annotations[specHashAnnotation] == desiredHash {
 		return nil
 	}
 
-	desired.Spec.ClusterIP = existing.Spec.ClusterIP
 	existing.Spec = desired.Spec
 	if existing.Annotations == nil {
 		existing.Annotations = make(map[string]string)
 	}
 	existing.Annotations[specHashAnnotation] = desiredHash
+	logger.Info("Updating dependency UI Ingress", "name", desired.Name)
 	return r.Update(ctx, existing)
 }
 
+// buildDependencyUIIngress routes dependencyUIHost to the dependency
+// Service's UI port, using the app Ingress's class when one is set. It
+// returns nil when exposeUI is off or the type has no UI.
+func buildDependencyUIIngress(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) *networkingv1.Ingress {
+	port, ok := dependencyUIPort(dep, defaults)
+	if !dep.ExposeUI || !ok {
+		return nil
+	}
+
+	var className *string
+	if cr.Spec.Ingress != nil {
+		className = cr.Spec.Ingress.IngressClassName
+	}
+	pathType := networkingv1.PathTypePrefix
+	spec := networkingv1.IngressSpec{
+		IngressClassName: className,
+		Rules: []networkingv1.IngressRule{{
+			Host: dependencyUIHost(cr.Name, dep),
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: dependencyResourceName(cr.Name, dep),
+								Port: networkingv1.ServiceBackendPort{Number: port},
+							},
+						},
+					}},
+				},
+			},
+		}},
+	}
+
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dependencyUIIngressName(dependencyResourceName(cr.Name, dep)),
+			Namespace: cr.Namespace,
+			Labels:    dependencyLabels(cr, dep),
+			Annotations: map[string]string{
+				specHashAnnotation: computeSpecHash(spec),
+			},
+		},
+		Spec: spec,
+	}
+}
+
 // ────────────────────────────────────────────────────────────────────────────
 // Dependency Helpers
 // ────────────────────────────────────────────────────────────────────────────
 
+// dependencyResourceName returns the name of dep's child resources (and its
+// Service's DNS name): the shared name for shared dependencies, otherwise
+// dependencyName.
+func dependencyResourceName(crName string, dep appsv1alpha1.DependencySpec) string {
+	if dep.Shared {
+		return sharedDependencyName(dep)
+	}
+	return dependencyName(crName, dep.Type)
+}
+
+// dependencyLabels returns the labels for dep's child resources. Shared
+// dependencies carry sharedDependencyLabel in place of a part-of label.
+func dependencyLabels(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec) map[string]string {
+	if !dep.Shared {
+		return labelsForDependency(cr, dep.Type)
+	}
+	name := sharedDependencyName(dep)
+	return map[string]string{
+		"app.kubernetes.io/name":       name,
+		"app.kubernetes.io/component":  string(dep.Type),
+		"app.kubernetes.io/managed-by": "devstagingenvironment-operator",
+		sharedDependencyLabel:          name,
+	}
+}
+
 // labelsForDependency returns labels for a dependency's child resources.
 func labelsForDependency(cr *appsv1alpha1.DevStagingEnvironment, depType appsv1alpha1.DependencyType) map[string]string {
 	return map[string]string{
@@ -1270,24 +3795,25 @@ func labelsForDependency(cr *appsv1alpha1.DevStagingEnvironment, depType appsv1a
 // buildConnectionURL constructs the connection string for a dependency using
 // the in-cluster DNS name of the dependency Service.
 func buildConnectionURL(crName string, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) string {
-	svcName := dependencyName(crName, dep.Type)
+	svcName := dependencyResourceName(crName, dep)
 
 	port := defaults.Port
 	if dep.Port != nil {
 		port = *dep.Port
 	}
 
-	envMap := envVarsToMap(defaults.Env)
-	for _, e := range dep.Env {
-		envMap[e.Name] = e.Value
-	}
+	envMap := dependencyEnvMap(dep, defaults)
 
 	switch dep.Type {
-	case appsv1alpha1.DependencyPostgres:
+	case appsv1alpha1.DependencyPostgres, appsv1alpha1.DependencyTimescaleDB:
 		user := envMap["POSTGRES_USER"]
 		pass := envMap["POSTGRES_PASSWORD"]
 		db := envMap["POSTGRES_DB"]
 		return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable", user, pass, svcName, port, db)
+	case appsv1alpha1.DependencyCockroach:
+		// Insecure single-node mode only has the passwordless root user.
+		db := envMap["COCKROACH_DATABASE"]
+		return fmt.Sprintf("postgres://root@%s:%d/%s?sslmode=disable", svcName, port, db)
 	case appsv1alpha1.DependencyRedis:
 		return fmt.Sprintf("redis://%s:%d/0", svcName, port)
 	case appsv1alpha1.DependencyMySQL:
@@ -1295,6 +3821,15 @@ func buildConnectionURL(crName string, dep appsv1alpha1.DependencySpec, defaults
 		pass := envMap["MYSQL_PASSWORD"]
 		db := envMap["MYSQL_DATABASE"]
 		return fmt.Sprintf("mysql://%s:%s@%s:%d/%s", user, pass, svcName, port, db)
+	case appsv1alpha1.DependencyMariaDB:
+		user := envMap["MARIADB_USER"]
+		pass := envMap["MARIADB_PASSWORD"]
+		db := envMap["MARIADB_DATABASE"]
+		return fmt.Sprintf("mariadb://%s:%s@%s:%d/%s", user, pass, svcName, port, db)
+	case appsv1alpha1.DependencySQLServer:
+		// SQL Server has no init-time database; apps start in master.
+		pass := envMap["MSSQL_SA_PASSWORD"]
+		return fmt.Sprintf("sqlserver://sa:%s@%s:%d?database=master", pass, svcName, port)
 	case appsv1alpha1.DependencyMongoDB:
 		user := envMap["MONGO_INITDB_ROOT_USERNAME"]
 		pass := envMap["MONGO_INITDB_ROOT_PASSWORD"]
@@ -1305,14 +3840,34 @@ func buildConnectionURL(crName string, dep appsv1alpha1.DependencySpec, defaults
 		return fmt.Sprintf("amqp://%s:%s@%s:%d/", user, pass, svcName, port)
 	case appsv1alpha1.DependencyMinIO:
 		return fmt.Sprintf("http://%s:%d", svcName, port)
-	case appsv1alpha1.DependencyElasticsearch:
+	case appsv1alpha1.DependencyElasticsearch, appsv1alpha1.DependencyOpenSearch:
 		return fmt.Sprintf("http://%s:%d", svcName, port)
 	case appsv1alpha1.DependencyKafka:
 		return fmt.Sprintf("%s:%d", svcName, port)
 	case appsv1alpha1.DependencyNATS:
 		return fmt.Sprintf("nats://%s:%d", svcName, port)
+	case appsv1alpha1.DependencyPulsar:
+		return fmt.Sprintf("pulsar://%s:%d", svcName, port)
 	case appsv1alpha1.DependencyMemcached:
 		return fmt.Sprintf("%s:%d", svcName, port)
+	case appsv1alpha1.DependencyEtcd:
+		// etcd clients take a bare host:port endpoint list.
+		return fmt.Sprintf("%s:%d", svcName, port)
+	case appsv1alpha1.DependencyZookeeper:
+		// ZooKeeper clients take a bare host:port connect string.
+		return fmt.Sprintf("%s:%d", svcName, port)
+	case appsv1alpha1.DependencySurrealDB:
+		return fmt.Sprintf("http://%s:%d", svcName, port)
+	case appsv1alpha1.DependencyRethinkDB:
+		return fmt.Sprintf("rethinkdb://%s:%d", svcName, port)
+	case appsv1alpha1.DependencyPrometheus, appsv1alpha1.DependencyGrafana:
+		return fmt.Sprintf("http://%s:%d", svcName, port)
+	case appsv1alpha1.DependencyArangoDB:
+		return fmt.Sprintf("http://root:%s@%s:%d", envMap["ARANGO_ROOT_PASSWORD"], svcName, port)
+	case appsv1alpha1.DependencyCouchDB:
+		return fmt.Sprintf("http://%s:%s@%s:%d", envMap["COUCHDB_USER"], envMap["COUCHDB_PASSWORD"], svcName, port)
+	case appsv1alpha1.DependencyMeilisearch, appsv1alpha1.DependencyTypesense, appsv1alpha1.DependencyOtelCollector:
+		return fmt.Sprintf("http://%s:%d", svcName, port)
 	case appsv1alpha1.DependencyCassandra:
 		return fmt.Sprintf("%s:%d", svcName, port)
 	case appsv1alpha1.DependencyConsul:
@@ -1325,19 +3880,103 @@ func buildConnectionURL(crName string, dep appsv1alpha1.DependencySpec, defaults
 		return fmt.Sprintf("http://%s:%s@%s:%d", user, pass, svcName, port)
 	case appsv1alpha1.DependencyJaeger:
 		return fmt.Sprintf("http://%s:%d", svcName, port)
+	case appsv1alpha1.DependencyMailpit:
+		return fmt.Sprintf("smtp://%s:%d", svcName, port)
+	case appsv1alpha1.DependencyNeo4j:
+		// NEO4J_AUTH is "<user>/<password>".
+		user, pass, _ := strings.Cut(envMap["NEO4J_AUTH"], "/")
+		return fmt.Sprintf("bolt://%s:%s@%s:%d", user, pass, svcName, port)
+	case appsv1alpha1.DependencyQdrant, appsv1alpha1.DependencyWeaviate, appsv1alpha1.DependencyChroma,
+		appsv1alpha1.DependencyLocalStack:
+		return fmt.Sprintf("http://%s:%d", svcName, port)
+	case appsv1alpha1.DependencyClickHouse:
+		user := envMap["CLICKHOUSE_USER"]
+		pass := envMap["CLICKHOUSE_PASSWORD"]
+		db := envMap["CLICKHOUSE_DB"]
+		return fmt.Sprintf("http://%s:%s@%s:%d/%s", user, pass, svcName, port, db)
 	default:
 		return fmt.Sprintf("%s:%d", svcName, port)
 	}
 }
 
-// buildDependencyConnectionEnvVars returns the env vars that should be injected
-// into the app container for a given dependency (e.g. DATABASE_URL, REDIS_URL).
-func buildDependencyConnectionEnvVars(crName string, dep appsv1alpha1.DependencySpec) []corev1.EnvVar {
+// dependencyURLs returns the connection URL injected for each declared
+// dependency, keyed by dependency type, with any password redacted since
+// status is readable by anyone who can get the CR. It returns nil when
+// there are none.
+func dependencyURLs(cr *appsv1alpha1.DevStagingEnvironment, creds dependencyCreds) map[string]string {
+	var urls map[string]string
+	for _, dep := range cr.Spec.Dependencies {
+		defaults, ok := dependencyDefaultsFor(cr, dep, creds)
+		if !ok {
+			continue
+		}
+		if urls == nil {
+			urls = make(map[string]string)
+		}
+		urls[string(dep.Type)] = redactURLPassword(buildConnectionURL(cr.Name, dep, defaults))
+		switch dep.Type {
+		case appsv1alpha1.DependencyMailpit:
+			urls[string(dep.Type)+"-ui"] = fmt.Sprintf("http://%s:%d", dependencyResourceName(cr.Name, dep), mailpitUIPort)
+		case appsv1alpha1.DependencyNeo4j:
+			urls[string(dep.Type)+"-ui"] = fmt.Sprintf("http://%s:%d", dependencyResourceName(cr.Name, dep), neo4jHTTPPort)
+		}
+		if _, ok := dependencyUIPort(dep, defaults); ok && dep.ExposeUI {
+			urls[string(dep.Type)+"-ui"] = "http://" + dependencyUIHost(cr.Name, dep)
+		}
+	}
+	return urls
+}
+
+// redactURLPassword replaces the password in a connection URL's userinfo
+// with "xxxxx", as url.URL.Redacted does. Strings that don't parse as URLs
+// get the same treatment by hand.
+func redactURLPassword(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		if _, ok := u.User.Password(); ok {
+			return u.Redacted()
+		}
+		return raw
+	}
+	scheme, rest, ok := strings.Cut(raw, "://")
+	at := strings.LastIndex(rest, "@")
+	if !ok || at < 0 {
+		return raw
+	}
+	user, _, hasPass := strings.Cut(rest[:at], ":")
+	if !hasPass {
+		return raw
+	}
+	return scheme + "://" + user + ":xxxxx@" + rest[at+1:]
+}
+
+// DependencyTypes returns every dependency type the operator can provision,
+// sorted by name.
+func DependencyTypes() []appsv1alpha1.DependencyType {
+	types := make([]appsv1alpha1.DependencyType, 0, len(dependencyRegistry))
+	for t := range dependencyRegistry {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// DependencyEnvVarNames returns the names of the env vars dep injects into
+// the app container, in the order they are injected.
+func DependencyEnvVarNames(dep appsv1alpha1.DependencySpec) []string {
 	defaults, ok := dependencyRegistry[dep.Type]
 	if !ok {
 		return nil
 	}
+	var names []string
+	for _, e := range buildDependencyConnectionEnvVars("", dep, defaults) {
+		names = append(names, e.Name)
+	}
+	return names
+}
 
+// buildDependencyConnectionEnvVars returns the env vars that should be injected
+// into the app container for a given dependency (e.g. DATABASE_URL, REDIS_URL).
+func buildDependencyConnectionEnvVars(crName string, dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) []corev1.EnvVar {
 	envVarName := defaults.EnvVarName
 	if dep.EnvVarName != "" {
 		envVarName = dep.EnvVarName
@@ -1372,21 +4011,109 @@ func buildDependencyConnectionEnvVars(crName string, dep appsv1alpha1.Dependency
 		)
 	}
 
-	// For InfluxDB, inject org and bucket so the app can write metrics.
+	// For InfluxDB, inject the admin token, org and bucket so the app can
+	// authenticate and write metrics.
 	if dep.Type == appsv1alpha1.DependencyInfluxDB {
 		envMap := envVarsToMap(defaults.Env)
 		for _, e := range dep.Env {
 			envMap[e.Name] = e.Value
 		}
 		envVars = append(envVars,
+			corev1.EnvVar{Name: "INFLUXDB_TOKEN", Value: envMap["DOCKER_INFLUXDB_INIT_ADMIN_TOKEN"]},
 			corev1.EnvVar{Name: "INFLUXDB_ORG", Value: envMap["DOCKER_INFLUXDB_INIT_ORG"]},
 			corev1.EnvVar{Name: "INFLUXDB_BUCKET", Value: envMap["DOCKER_INFLUXDB_INIT_BUCKET"]},
 		)
 	}
 
+	// Search engines authenticate with an API key rather than URL userinfo.
+	if dep.Type == appsv1alpha1.DependencyMeilisearch {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "MEILISEARCH_API_KEY", Value: dependencyEnvMap(dep, defaults)["MEILI_MASTER_KEY"]},
+		)
+	}
+	if dep.Type == appsv1alpha1.DependencyTypesense {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "TYPESENSE_API_KEY", Value: dependencyEnvMap(dep, defaults)["TYPESENSE_API_KEY"]},
+		)
+	}
+
+	// For Mailpit, also inject host and port for mailers configured piecewise.
+	if dep.Type == appsv1alpha1.DependencyMailpit {
+		port := defaults.Port
+		if dep.Port != nil {
+			port = *dep.Port
+		}
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "SMTP_HOST", Value: dependencyResourceName(crName, dep)},
+			corev1.EnvVar{Name: "SMTP_PORT", Value: fmt.Sprintf("%d", port)},
+		)
+	}
+
+	// Weaviate's v4 clients also need the gRPC port.
+	if dep.Type == appsv1alpha1.DependencyWeaviate {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "WEAVIATE_GRPC_URL", Value: fmt.Sprintf("%s:50051", dependencyResourceName(crName, dep))},
+		)
+	}
+
+	// Chroma's HttpClient takes host and port separately.
+	if dep.Type == appsv1alpha1.DependencyChroma {
+		port := defaults.Port
+		if dep.Port != nil {
+			port = *dep.Port
+		}
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "CHROMA_HOST", Value: dependencyResourceName(crName, dep)},
+			corev1.EnvVar{Name: "CHROMA_PORT", Value: fmt.Sprintf("%d", port)},
+		)
+	}
+
+	// For LocalStack, inject the dummy credentials and region the AWS SDKs
+	// require before they will send a request to AWS_ENDPOINT_URL.
+	if dep.Type == appsv1alpha1.DependencyLocalStack {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "AWS_ACCESS_KEY_ID", Value: "test"},
+			corev1.EnvVar{Name: "AWS_SECRET_ACCESS_KEY", Value: "test"},
+			corev1.EnvVar{Name: "AWS_REGION", Value: localStackRegion},
+			corev1.EnvVar{Name: "AWS_DEFAULT_REGION", Value: localStackRegion},
+		)
+	}
+
+	// SurrealDB signs in with a user and password rather than URL userinfo.
+	if dep.Type == appsv1alpha1.DependencySurrealDB {
+		envMap := dependencyEnvMap(dep, defaults)
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "SURREAL_USER", Value: envMap["SURREAL_USER"]},
+			corev1.EnvVar{Name: "SURREAL_PASS", Value: envMap["SURREAL_PASS"]},
+		)
+	}
+
+	// OpenSearch speaks the Elasticsearch REST API, so apps written against
+	// an Elasticsearch client find it under the usual name too.
+	if dep.Type == appsv1alpha1.DependencyOpenSearch && envVarName != "ELASTICSEARCH_URL" {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "ELASTICSEARCH_URL", Value: connURL},
+		)
+	}
+
+	// For Pulsar, also inject the admin REST API for topic and tenant setup.
+	if dep.Type == appsv1alpha1.DependencyPulsar {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "PULSAR_ADMIN_URL", Value: fmt.Sprintf("http://%s:%d", dependencyResourceName(crName, dep), pulsarAdminPort)},
+		)
+	}
+
+	// The collector's main URL is the OTLP/HTTP endpoint, so SDKs that
+	// default to gRPC must be told to use HTTP.
+	if dep.Type == appsv1alpha1.DependencyOtelCollector {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_PROTOCOL", Value: "http/protobuf"},
+		)
+	}
+
 	// For Jaeger, inject the OTLP collector endpoint (gRPC port 4317).
 	if dep.Type == appsv1alpha1.DependencyJaeger {
-		svcName := dependencyName(crName, dep.Type)
+		svcName := dependencyResourceName(crName, dep)
 		envVars = append(envVars,
 			corev1.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: fmt.Sprintf("http://%s:4317", svcName)},
 		)
@@ -1404,6 +4131,16 @@ func envVarsToMap(envs []corev1.EnvVar) map[string]string {
 	return m
 }
 
+// dependencyEnvMap returns dep's container env: the registry defaults with
+// dep.Env applied on top.
+func dependencyEnvMap(dep appsv1alpha1.DependencySpec, defaults dependencyDefaults) map[string]string {
+	envMap := envVarsToMap(defaults.Env)
+	for _, e := range dep.Env {
+		envMap[e.Name] = e.Value
+	}
+	return envMap
+}
+
 // mergeEnvVars merges two slices of EnvVar, with overrides taking precedence.
 func mergeEnvVars(base, overrides []corev1.EnvVar) []corev1.EnvVar {
 	m := make(map[string]corev1.EnvVar, len(base)+len(overrides))
@@ -1425,6 +4162,125 @@ func mergeEnvVars(base, overrides []corev1.EnvVar) []corev1.EnvVar {
 	return result
 }
 
+// dependencyPasswordEnv are the registry env vars that hold a password or
+// token. dependencyDefaultsFor replaces their static values per environment.
+var dependencyPasswordEnv = map[string]bool{
+	"POSTGRES_PASSWORD":                true,
+	"MYSQL_ROOT_PASSWORD":              true,
+	"MYSQL_PASSWORD":                   true,
+	"MONGO_INITDB_ROOT_PASSWORD":       true,
+	"RABBITMQ_DEFAULT_PASS":            true,
+	"MINIO_ROOT_PASSWORD":              true,
+	"VAULT_DEV_ROOT_TOKEN_ID":          true,
+	"DOCKER_INFLUXDB_INIT_PASSWORD":    true,
+	"DOCKER_INFLUXDB_INIT_ADMIN_TOKEN": true,
+	"CLICKHOUSE_PASSWORD":              true,
+	"MARIADB_ROOT_PASSWORD":            true,
+	"MARIADB_PASSWORD":                 true,
+	"GF_SECURITY_ADMIN_PASSWORD":       true,
+	"ARANGO_ROOT_PASSWORD":             true,
+	"COUCHDB_PASSWORD":                 true,
+	"MEILI_MASTER_KEY":                 true,
+	"TYPESENSE_API_KEY":                true,
+	"SURREAL_PASS":                     true,
+}
+
+// credentialKeySecretName is the Secret in the operator's namespace that
+// holds the key dependency passwords are derived with.
+const credentialKeySecretName = "kindling-credential-key"
+
+// credentialKeyField is the credential key Secret's data key.
+const credentialKeyField = "key"
+
+// LoadCredentialKey returns the cluster's credential key from the
+// kindling-credential-key Secret in namespace, creating it with a random
+// key on first start. Deleting the Secret rotates every derived password.
+func LoadCredentialKey(ctx context.Context, c client.Client, namespace string) ([]byte, error) {
+	name := types.NamespacedName{Name: credentialKeySecretName, Namespace: namespace}
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, name, secret)
+	if errors.IsNotFound(err) {
+		key := make([]byte, 32)
+		if _, err := crand.Read(key); err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      credentialKeySecretName,
+				Namespace: namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "kindling"},
+			},
+			Data: map[string][]byte{credentialKeyField: key},
+		}
+		err = c.Create(ctx, secret)
+		if errors.IsAlreadyExists(err) {
+			// Another replica created it first; use theirs.
+			err = c.Get(ctx, name, secret)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("credential key secret %s: %w", name, err)
+	}
+	key := secret.Data[credentialKeyField]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("credential key secret %s has no %q", name, credentialKeyField)
+	}
+	return key, nil
+}
+
+// dependencyCreds selects how dependency passwords are chosen.
+type dependencyCreds struct {
+	shared bool   // keep the static registry passwords
+	key    []byte // per-cluster key passwords are derived with
+}
+
+// creds returns the reconciler's dependency credential settings.
+func (r *DevStagingEnvironmentReconciler) creds() dependencyCreds {
+	return dependencyCreds{shared: r.InsecureSharedCreds, key: r.CredentialKey}
+}
+
+// dependencyPassword derives the password for the dependency Deployment
+// depName in namespace. It is stable across reconciles and operator
+// restarts, so the URLs injected into apps never have to be looked up, but
+// differs between environments. Keying it with the cluster's secret key
+// stops anyone who can see a namespace and CR name from computing it.
+func dependencyPassword(key []byte, namespace, depName string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("kindling/" + namespace + "/" + depName))
+	return fmt.Sprintf("%x", mac.Sum(nil)[:12])
+}
+
+// dependencyDefaultsFor returns dep's registry defaults with its passwords
+// derived from cr's namespace and the dependency's name (see
+// dependencyPassword). A shared dependency is named by its sharedName, so
+// every consumer derives the same password. With creds.shared the static
+// registry passwords are kept. User-set dep.Env still wins either way.
+func dependencyDefaultsFor(cr *appsv1alpha1.DevStagingEnvironment, dep appsv1alpha1.DependencySpec, creds dependencyCreds) (dependencyDefaults, bool) {
+	defaults, ok := dependencyRegistry[dep.Type]
+	if !ok || creds.shared {
+		return defaults, ok
+	}
+
+	pass := dependencyPassword(creds.key, cr.Namespace, dependencyResourceName(cr.Name, dep))
+	env := make([]corev1.EnvVar, len(defaults.Env))
+	for i, e := range defaults.Env {
+		switch {
+		case e.Name == "NEO4J_AUTH":
+			// NEO4J_AUTH is "<user>/<password>".
+			user, _, _ := strings.Cut(e.Value, "/")
+			e.Value = user + "/" + pass
+		case e.Name == "MSSQL_SA_PASSWORD":
+			// SQL Server wants three of: upper, lower, digit, symbol.
+			e.Value = "Dev" + pass + "!"
+		case dependencyPasswordEnv[e.Name]:
+			e.Value = pass
+		}
+		env[i] = e
+	}
+	defaults.Env = env
+	return defaults, true
+}
+
 // generatePassword creates a random alphanumeric password.
 // Used for auto-generating dependency credentials.
 func generatePassword(length int) string {
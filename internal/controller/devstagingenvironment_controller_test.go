@@ -26,6 +26,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -88,7 +89,7 @@ var _ = Describe("buildConnectionURL", func() {
 var _ = Describe("buildDependencyConnectionEnvVars", func() {
 	It("injects DATABASE_URL for postgres", func() {
 		dep := appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyPostgres}
-		envVars := buildDependencyConnectionEnvVars("myapp", dep)
+		envVars := buildDependencyConnectionEnvVars("myapp", dep, dependencyRegistry[dep.Type])
 		Expect(envVars).To(HaveLen(1))
 		Expect(envVars[0].Name).To(Equal("DATABASE_URL"))
 		Expect(envVars[0].Value).To(ContainSubstring("postgres://"))
@@ -96,7 +97,7 @@ var _ = Describe("buildDependencyConnectionEnvVars", func() {
 
 	It("injects REDIS_URL for redis", func() {
 		dep := appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyRedis}
-		envVars := buildDependencyConnectionEnvVars("myapp", dep)
+		envVars := buildDependencyConnectionEnvVars("myapp", dep, dependencyRegistry[dep.Type])
 		Expect(envVars).To(HaveLen(1))
 		Expect(envVars[0].Name).To(Equal("REDIS_URL"))
 	})
@@ -106,41 +107,41 @@ var _ = Describe("buildDependencyConnectionEnvVars", func() {
 			Type:       appsv1alpha1.DependencyPostgres,
 			EnvVarName: "MY_CUSTOM_DB_URL",
 		}
-		envVars := buildDependencyConnectionEnvVars("myapp", dep)
+		envVars := buildDependencyConnectionEnvVars("myapp", dep, dependencyRegistry[dep.Type])
 		Expect(envVars[0].Name).To(Equal("MY_CUSTOM_DB_URL"))
 	})
 
 	It("injects extra env vars for MinIO (S3_ACCESS_KEY, S3_SECRET_KEY)", func() {
 		dep := appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyMinIO}
-		envVars := buildDependencyConnectionEnvVars("myapp", dep)
+		envVars := buildDependencyConnectionEnvVars("myapp", dep, dependencyRegistry[dep.Type])
 		names := envVarNames(envVars)
 		Expect(names).To(ContainElements("S3_ENDPOINT", "S3_ACCESS_KEY", "S3_SECRET_KEY"))
 	})
 
 	It("injects VAULT_TOKEN for vault", func() {
 		dep := appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyVault}
-		envVars := buildDependencyConnectionEnvVars("myapp", dep)
+		envVars := buildDependencyConnectionEnvVars("myapp", dep, dependencyRegistry[dep.Type])
 		names := envVarNames(envVars)
 		Expect(names).To(ContainElements("VAULT_ADDR", "VAULT_TOKEN"))
 	})
 
-	It("injects INFLUXDB_ORG and INFLUXDB_BUCKET for influxdb", func() {
+	It("injects INFLUXDB_TOKEN, INFLUXDB_ORG and INFLUXDB_BUCKET for influxdb", func() {
 		dep := appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyInfluxDB}
-		envVars := buildDependencyConnectionEnvVars("myapp", dep)
+		envVars := buildDependencyConnectionEnvVars("myapp", dep, dependencyRegistry[dep.Type])
 		names := envVarNames(envVars)
-		Expect(names).To(ContainElements("INFLUXDB_URL", "INFLUXDB_ORG", "INFLUXDB_BUCKET"))
+		Expect(names).To(ContainElements("INFLUXDB_URL", "INFLUXDB_TOKEN", "INFLUXDB_ORG", "INFLUXDB_BUCKET"))
 	})
 
 	It("injects OTEL_EXPORTER_OTLP_ENDPOINT for jaeger", func() {
 		dep := appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyJaeger}
-		envVars := buildDependencyConnectionEnvVars("myapp", dep)
+		envVars := buildDependencyConnectionEnvVars("myapp", dep, dependencyRegistry[dep.Type])
 		names := envVarNames(envVars)
 		Expect(names).To(ContainElements("JAEGER_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT"))
 	})
 
 	It("returns nil for an unknown dependency type", func() {
 		dep := appsv1alpha1.DependencySpec{Type: "foobar"}
-		envVars := buildDependencyConnectionEnvVars("myapp", dep)
+		envVars := buildDependencyConnectionEnvVars("myapp", dep, dependencyRegistry[dep.Type])
 		Expect(envVars).To(BeNil())
 	})
 })
@@ -195,6 +196,18 @@ var _ = Describe("dependencyName", func() {
 // Builder tests (no cluster, but need a reconciler for receiver methods)
 // ────────────────────────────────────────────────────────────────────────────
 
+var _ = Describe("buildDependencyDeployment", func() {
+	It("advertises the Kafka listener at the dependency Service DNS name", func() {
+		cr := newTestDSE("platform-api")
+		dep := appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyKafka}
+		deploy := buildDependencyDeployment(cr, dep, dependencyRegistry[dep.Type])
+
+		svcName := dependencyName(cr.Name, dep.Type)
+		env := envVarsToMap(deploy.Spec.Template.Spec.Containers[0].Env)
+		Expect(env).To(HaveKeyWithValue("KAFKA_ADVERTISED_LISTENERS", "PLAINTEXT://"+svcName+":9092"))
+	})
+})
+
 var _ = Describe("buildDeployment", func() {
 	var r *DevStagingEnvironmentReconciler
 
@@ -285,6 +298,54 @@ var _ = Describe("buildDeployment", func() {
 		Expect(container.LivenessProbe).To(BeNil())
 		Expect(container.ReadinessProbe).To(BeNil())
 	})
+
+	It("appends sidecars without dependency env vars", func() {
+		cr := newTestDSE("test-sidecar")
+		cr.Spec.Dependencies = []appsv1alpha1.DependencySpec{{Type: appsv1alpha1.DependencyPostgres}}
+		cr.Spec.Deployment.Sidecars = []appsv1alpha1.ContainerSpec{
+			{Name: "otel", Image: "otel/opentelemetry-collector:latest"},
+		}
+		deploy := r.buildDeployment(cr)
+		containers := deploy.Spec.Template.Spec.Containers
+		Expect(containers).To(HaveLen(2))
+		Expect(containers[1].Name).To(Equal("otel"))
+		Expect(containers[1].Env).To(BeEmpty())
+		Expect(containers[0].Env).NotTo(BeEmpty())
+
+		before := deploy.Annotations[specHashAnnotation]
+		cr.Spec.Deployment.Sidecars[0].Image = "otel/opentelemetry-collector:0.100.0"
+		Expect(r.buildDeployment(cr).Annotations[specHashAnnotation]).NotTo(Equal(before))
+	})
+
+	It("sets TCP probes and a startup probe when requested", func() {
+		cr := newTestDSE("test-tcp")
+		startupPeriod := int32(10)
+		cr.Spec.Deployment.HealthCheck = &appsv1alpha1.HealthCheckSpec{
+			Type:                 "tcp",
+			StartupPeriodSeconds: &startupPeriod,
+		}
+		deploy := r.buildDeployment(cr)
+		container := deploy.Spec.Template.Spec.Containers[0]
+		Expect(container.LivenessProbe.TCPSocket).NotTo(BeNil())
+		Expect(container.ReadinessProbe.TCPSocket).NotTo(BeNil())
+		Expect(container.StartupProbe).NotTo(BeNil())
+		Expect(container.StartupProbe.TCPSocket).NotTo(BeNil())
+		Expect(container.StartupProbe.PeriodSeconds).To(Equal(int32(10)))
+	})
+
+	It("mirrors dependency wait images and merges pull secrets", func() {
+		r := &DevStagingEnvironmentReconciler{
+			DependencyImageMirror: "mirror.internal:5000",
+			ImagePullSecrets:      []string{"mirror-creds"},
+		}
+		cr := newTestDSE("test-airgap")
+		cr.Spec.Dependencies = []appsv1alpha1.DependencySpec{{Type: appsv1alpha1.DependencyNATS}}
+		cr.Spec.Deployment.ImagePullSecrets = []string{"app-creds", "mirror-creds"}
+		pod := r.buildDeployment(cr).Spec.Template.Spec
+		Expect(pod.InitContainers[0].Image).To(Equal("mirror.internal:5000/busybox:1.36"))
+		Expect(pod.Containers[0].Image).To(Equal(cr.Spec.Deployment.Image))
+		Expect(pod.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "mirror-creds"}, {Name: "app-creds"}}))
+	})
 })
 
 var _ = Describe("buildService", func() {
@@ -536,6 +597,54 @@ var _ = Describe("DevStagingEnvironment Reconciler", func() {
 		})
 	})
 
+	Context("when two CRs share a dependency", func() {
+		It("should create it once, owned by both, and prune it after the last consumer drops it", func() {
+			shared := appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyRedis, Shared: true, SharedName: "team-redis"}
+			a := newTestDSE("reconcile-shared-a")
+			a.Spec.Dependencies = []appsv1alpha1.DependencySpec{shared}
+			b := newTestDSE("reconcile-shared-b")
+			b.Spec.Dependencies = []appsv1alpha1.DependencySpec{shared}
+			Expect(k8sClient.Create(ctx, a)).To(Succeed())
+			Expect(k8sClient.Create(ctx, b)).To(Succeed())
+
+			key := types.NamespacedName{Name: "team-redis", Namespace: "default"}
+			Eventually(func(g Gomega) []string {
+				deploy := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, key, deploy)).To(Succeed())
+				var owners []string
+				for _, ref := range deploy.OwnerReferences {
+					owners = append(owners, ref.Name)
+				}
+				return owners
+			}, timeout, interval).Should(ConsistOf("reconcile-shared-a", "reconcile-shared-b"))
+
+			appDeploy := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: a.Name, Namespace: "default"}, appDeploy)).To(Succeed())
+			Expect(findEnvVar(appDeploy.Spec.Template.Spec.Containers[0].Env, "REDIS_URL")).To(Equal("redis://team-redis:6379/0"))
+
+			// Dropping it from one consumer keeps it for the other.
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: a.Name, Namespace: "default"}, a)).To(Succeed())
+			a.Spec.Dependencies = nil
+			Expect(k8sClient.Update(ctx, a)).To(Succeed())
+			Eventually(func(g Gomega) int {
+				deploy := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, key, deploy)).To(Succeed())
+				return len(deploy.OwnerReferences)
+			}, timeout, interval).Should(Equal(1))
+
+			// Dropping it from the last consumer deletes it.
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: b.Name, Namespace: "default"}, b)).To(Succeed())
+			b.Spec.Dependencies = nil
+			Expect(k8sClient.Update(ctx, b)).To(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))
+			}, timeout, interval).Should(BeTrue())
+
+			_ = k8sClient.Delete(ctx, a)
+			_ = k8sClient.Delete(ctx, b)
+		})
+	})
+
 	Context("when the CR spec is updated", func() {
 		It("should update the Deployment image", func() {
 			cr := newTestDSE("reconcile-update")
@@ -562,6 +671,105 @@ var _ = Describe("DevStagingEnvironment Reconciler", func() {
 
 			_ = k8sClient.Delete(ctx, cr)
 		})
+
+		It("should roll the app with the new DATABASE_URL when a dependency is added", func() {
+			cr := newTestDSE("reconcile-add-dep")
+			Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+
+			deploy := &appsv1.Deployment{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: "default"}, deploy)
+			}, timeout, interval).Should(Succeed())
+			Expect(envVarNames(deploy.Spec.Template.Spec.Containers[0].Env)).NotTo(ContainElement("DATABASE_URL"))
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: "default"}, cr)).To(Succeed())
+			cr.Spec.Dependencies = []appsv1alpha1.DependencySpec{{Type: appsv1alpha1.DependencyPostgres}}
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+
+			// The pod template changes, so the Deployment controller replaces
+			// the app's pods with ones that see the new URL.
+			Eventually(func(g Gomega) {
+				d := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: "default"}, d)).To(Succeed())
+				g.Expect(d.Spec.Template.Annotations).To(HaveKey(dependencyEnvHashAnnotation))
+				g.Expect(envVarsToMap(d.Spec.Template.Spec.Containers[0].Env)).To(
+					HaveKeyWithValue("DATABASE_URL", ContainSubstring("@reconcile-add-dep-postgres:5432/")))
+			}, timeout, interval).Should(Succeed())
+
+			_ = k8sClient.Delete(ctx, cr)
+		})
+
+		It("should roll the app when a ConfigMap it loads env from changes", func() {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "reconcile-env-from", Namespace: "default"},
+				Data:       map[string]string{"LOG_LEVEL": "info"},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+			cr := newTestDSE("reconcile-env-from")
+			cr.Spec.Deployment.EnvFrom = []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name}},
+			}}
+			Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+
+			key := types.NamespacedName{Name: cr.Name, Namespace: "default"}
+			var initialHash string
+			Eventually(func(g Gomega) {
+				d := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, key, d)).To(Succeed())
+				g.Expect(d.Spec.Template.Spec.Containers[0].EnvFrom).To(Equal(cr.Spec.Deployment.EnvFrom))
+				initialHash = d.Spec.Template.Annotations[envFromHashAnnotation]
+				g.Expect(initialHash).NotTo(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+
+			// The ConfigMap watch picks up the edit without touching the CR.
+			cm.Data["LOG_LEVEL"] = "debug"
+			Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+			Eventually(func(g Gomega) string {
+				d := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, key, d)).To(Succeed())
+				return d.Spec.Template.Annotations[envFromHashAnnotation]
+			}, timeout, interval).ShouldNot(Equal(initialHash))
+
+			_ = k8sClient.Delete(ctx, cr)
+			_ = k8sClient.Delete(ctx, cm)
+		})
+
+		It("should create the app's ServiceAccount and bind its Role", func() {
+			cr := newTestDSE("reconcile-sa")
+			cr.Spec.CreateServiceAccount = true
+			cr.Spec.RBAC = &appsv1alpha1.RBACSpec{Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+				Verbs:     []string{"get", "list", "watch"},
+			}}}
+			Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+
+			key := types.NamespacedName{Name: cr.Name, Namespace: "default"}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, key, &corev1.ServiceAccount{})
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				binding := &rbacv1.RoleBinding{}
+				g.Expect(k8sClient.Get(ctx, key, binding)).To(Succeed())
+				g.Expect(binding.Subjects).To(ConsistOf(HaveField("Name", cr.Name)))
+				g.Expect(binding.RoleRef.Name).To(Equal(cr.Name))
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				d := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, key, d)).To(Succeed())
+				g.Expect(d.Spec.Template.Spec.ServiceAccountName).To(Equal(cr.Name))
+			}, timeout, interval).Should(Succeed())
+
+			// Dropping rbac removes the Role and RoleBinding.
+			Expect(k8sClient.Get(ctx, key, cr)).To(Succeed())
+			cr.Spec.RBAC = nil
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, key, &rbacv1.Role{}))
+			}, timeout, interval).Should(BeTrue())
+
+			_ = k8sClient.Delete(ctx, cr)
+		})
 	})
 })
 
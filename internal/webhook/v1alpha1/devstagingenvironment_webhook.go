@@ -0,0 +1,198 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/jeffvincent/kindling/api/v1alpha1"
+	"github.com/jeffvincent/kindling/internal/controller"
+)
+
+// SetupDevStagingEnvironmentWebhookWithManager registers the
+// DevStagingEnvironment validating webhook with the manager.
+func SetupDevStagingEnvironmentWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &appsv1alpha1.DevStagingEnvironment{}).
+		WithValidator(&DevStagingEnvironmentCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-apps-example-com-v1alpha1-devstagingenvironment,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.example.com,resources=devstagingenvironments,verbs=create;update,versions=v1alpha1,name=vdevstagingenvironment.kb.io,admissionReviewVersions=v1
+
+// DevStagingEnvironmentCustomValidator rejects DevStagingEnvironments that
+// would otherwise only fail deep inside reconcile. The rules mirror the
+// FINAL VALIDATION checklist `kindling generate` gives the model.
+type DevStagingEnvironmentCustomValidator struct{}
+
+var _ admission.Validator[*appsv1alpha1.DevStagingEnvironment] = &DevStagingEnvironmentCustomValidator{}
+
+// ValidateCreate implements admission.Validator.
+func (v *DevStagingEnvironmentCustomValidator) ValidateCreate(_ context.Context, cr *appsv1alpha1.DevStagingEnvironment) (admission.Warnings, error) {
+	return nil, validateDevStagingEnvironment(cr)
+}
+
+// ValidateUpdate implements admission.Validator.
+func (v *DevStagingEnvironmentCustomValidator) ValidateUpdate(_ context.Context, _, cr *appsv1alpha1.DevStagingEnvironment) (admission.Warnings, error) {
+	return nil, validateDevStagingEnvironment(cr)
+}
+
+// ValidateDelete implements admission.Validator. Deletes are always allowed.
+func (v *DevStagingEnvironmentCustomValidator) ValidateDelete(_ context.Context, _ *appsv1alpha1.DevStagingEnvironment) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDevStagingEnvironment applies the webhook's rules outside
+// admission. The reconciler runs it so invalid specs are reported even
+// when the webhook isn't served.
+func ValidateDevStagingEnvironment(cr *appsv1alpha1.DevStagingEnvironment) error {
+	return validateDevStagingEnvironment(cr)
+}
+
+// validateDevStagingEnvironment returns an Invalid error listing every rule
+// cr breaks, or nil.
+func validateDevStagingEnvironment(cr *appsv1alpha1.DevStagingEnvironment) error {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+
+	if p := cr.Spec.Deployment.Port; p < 1 || p > 65535 {
+		errs = append(errs, field.Invalid(spec.Child("deployment", "port"), p, "must be between 1 and 65535"))
+	}
+
+	if ing := cr.Spec.Ingress; ing != nil && ing.Enabled && strings.TrimSpace(ing.Host) == "" {
+		errs = append(errs, field.Required(spec.Child("ingress", "host"), "is required when ingress is enabled"))
+	}
+
+	if d := cr.Spec.Deployment; len(d.PreStopExec) > 0 && d.PreStopHTTPGet != nil {
+		errs = append(errs, field.Forbidden(spec.Child("deployment", "preStopHTTPGet"), "may not be set together with preStopExec"))
+	}
+
+	if s := cr.Spec.Deployment.Strategy; s != nil && s.Type == appsv1alpha1.DeploymentStrategyRecreate {
+		path := spec.Child("deployment", "strategy")
+		if s.MaxSurge != nil {
+			errs = append(errs, field.Forbidden(path.Child("maxSurge"), "only applies to RollingUpdate"))
+		}
+		if s.MaxUnavailable != nil {
+			errs = append(errs, field.Forbidden(path.Child("maxUnavailable"), "only applies to RollingUpdate"))
+		}
+	}
+
+	// Binding rules to the namespace's default account would hand them to
+	// every pod that doesn't name one.
+	if cr.Spec.RBAC != nil && cr.Spec.Deployment.ServiceAccountName == "" && !cr.Spec.CreateServiceAccount {
+		errs = append(errs, field.Required(spec.Child("deployment", "serviceAccountName"), "is required for rbac unless createServiceAccount is set"))
+	}
+	if cr.Spec.RBAC != nil {
+		errs = append(errs, validateRBACRules(cr.Spec.RBAC.Rules, spec.Child("rbac", "rules"))...)
+	}
+
+	seen := make(map[appsv1alpha1.DependencyType]bool)
+	for i, dep := range cr.Spec.Dependencies {
+		if seen[dep.Type] {
+			errs = append(errs, field.Duplicate(spec.Child("dependencies").Index(i).Child("type"), dep.Type))
+		}
+		seen[dep.Type] = true
+	}
+
+	errs = append(errs, validateEnvReferences(cr, spec.Child("deployment", "env"))...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1alpha1.GroupVersion.WithKind("DevStagingEnvironment").GroupKind(), cr.Name, errs)
+}
+
+// validateRBACRules keeps spec.rbac to plain namespaced grants. Wildcards
+// and rules on RBAC objects themselves would let the app's account mint or
+// bind any role in the namespace.
+func validateRBACRules(rules []rbacv1.PolicyRule, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, rule := range rules {
+		p := path.Index(i)
+		for j, g := range rule.APIGroups {
+			switch g {
+			case rbacv1.APIGroupAll:
+				errs = append(errs, field.Forbidden(p.Child("apiGroups").Index(j), "wildcard API groups are not allowed"))
+			case rbacv1.GroupName:
+				errs = append(errs, field.Forbidden(p.Child("apiGroups").Index(j), "rules on rbac.authorization.k8s.io are not allowed; bind an existing Role yourself"))
+			}
+		}
+		for j, r := range rule.Resources {
+			if strings.Contains(r, rbacv1.ResourceAll) {
+				errs = append(errs, field.Forbidden(p.Child("resources").Index(j), "wildcard resources are not allowed"))
+			}
+		}
+		for j, v := range rule.Verbs {
+			if v == rbacv1.VerbAll {
+				errs = append(errs, field.Forbidden(p.Child("verbs").Index(j), "wildcard verbs are not allowed"))
+			}
+		}
+	}
+	return errs
+}
+
+// envReferencePattern matches Kubernetes $(VAR) references. "$$" is matched
+// as well so escaped references are consumed rather than reported.
+var envReferencePattern = regexp.MustCompile(`\$\$|\$\(([^)]+)\)`)
+
+// validateEnvReferences reports every $(VAR) in the app's env that names a
+// connection var only a dependency can inject (e.g. $(AMQP_URL)) when no
+// declared dependency injects it. References to vars defined earlier in the
+// env list, or to names no dependency provides, are left alone.
+func validateEnvReferences(cr *appsv1alpha1.DevStagingEnvironment, path *field.Path) field.ErrorList {
+	// Every name some dependency type would inject, with the types that do.
+	providers := make(map[string][]string)
+	for _, t := range controller.DependencyTypes() {
+		for _, name := range controller.DependencyEnvVarNames(appsv1alpha1.DependencySpec{Type: t}) {
+			providers[name] = append(providers[name], string(t))
+		}
+	}
+
+	// Dependency vars come first in the container's env, so they are always
+	// in scope; user vars are in scope for entries after them.
+	defined := make(map[string]bool)
+	for _, dep := range cr.Spec.Dependencies {
+		for _, name := range controller.DependencyEnvVarNames(dep) {
+			defined[name] = true
+		}
+	}
+
+	var errs field.ErrorList
+	for i, env := range cr.Spec.Deployment.Env {
+		for _, m := range envReferencePattern.FindAllStringSubmatch(env.Value, -1) {
+			name := m[1]
+			if name == "" || defined[name] {
+				continue
+			}
+			if types, ok := providers[name]; ok {
+				errs = append(errs, field.Invalid(path.Index(i).Child("value"), env.Value,
+					fmt.Sprintf("references $(%s), which is only injected by a %s dependency; declare one in spec.dependencies",
+						name, strings.Join(types, " or "))))
+			}
+		}
+		defined[env.Name] = true
+	}
+	return errs
+}
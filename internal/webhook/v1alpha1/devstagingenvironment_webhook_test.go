@@ -0,0 +1,175 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appsv1alpha1 "github.com/jeffvincent/kindling/api/v1alpha1"
+)
+
+func validCR() *appsv1alpha1.DevStagingEnvironment {
+	return &appsv1alpha1.DevStagingEnvironment{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: appsv1alpha1.DevStagingEnvironmentSpec{
+			Deployment: appsv1alpha1.DeploymentSpec{Image: "myapp:dev", Port: 8080},
+			Service:    appsv1alpha1.ServiceSpec{Port: 8080},
+			Dependencies: []appsv1alpha1.DependencySpec{
+				{Type: appsv1alpha1.DependencyPostgres},
+			},
+		},
+	}
+}
+
+// assertInvalid fails unless err is an Invalid error mentioning every want.
+func assertInvalid(t *testing.T, err error, want ...string) {
+	t.Helper()
+	if !apierrors.IsInvalid(err) {
+		t.Fatalf("expected Invalid error, got %v", err)
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("error %q does not mention %q", err.Error(), w)
+		}
+	}
+}
+
+func TestValidate_AcceptsValidSpec(t *testing.T) {
+	cr := validCR()
+	cr.Spec.Ingress = &appsv1alpha1.IngressSpec{Enabled: true, Host: "myapp.localhost"}
+	cr.Spec.Deployment.Env = []corev1.EnvVar{
+		{Name: "PG_DSN", Value: "$(DATABASE_URL)"},
+		{Name: "BASE", Value: "http://localhost"},
+		{Name: "CALLBACK", Value: "$(BASE)/cb"},
+		{Name: "LITERAL", Value: "$$(AMQP_URL)"},
+		{Name: "UNKNOWN", Value: "$(NOT_A_DEPENDENCY_VAR)"},
+	}
+	if err := validateDevStagingEnvironment(cr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_RejectsDuplicateDependencyType(t *testing.T) {
+	cr := validCR()
+	cr.Spec.Dependencies = append(cr.Spec.Dependencies, appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyPostgres})
+	assertInvalid(t, validateDevStagingEnvironment(cr), "spec.dependencies[1].type", "Duplicate")
+}
+
+func TestValidate_RejectsPortOutOfRange(t *testing.T) {
+	for _, port := range []int32{0, -1, 65536} {
+		cr := validCR()
+		cr.Spec.Deployment.Port = port
+		assertInvalid(t, validateDevStagingEnvironment(cr), "spec.deployment.port")
+	}
+}
+
+func TestValidate_RejectsIngressWithoutHost(t *testing.T) {
+	cr := validCR()
+	cr.Spec.Ingress = &appsv1alpha1.IngressSpec{Enabled: true}
+	assertInvalid(t, validateDevStagingEnvironment(cr), "spec.ingress.host", "Required")
+
+	cr.Spec.Ingress.Enabled = false
+	if err := validateDevStagingEnvironment(cr); err != nil {
+		t.Fatalf("disabled ingress should not need a host: %v", err)
+	}
+}
+
+func TestValidate_RejectsBothPreStopHooks(t *testing.T) {
+	cr := validCR()
+	cr.Spec.Deployment.PreStopExec = []string{"sleep", "5"}
+	if err := validateDevStagingEnvironment(cr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cr.Spec.Deployment.PreStopHTTPGet = &appsv1alpha1.PreStopHTTPGet{Path: "/drain"}
+	assertInvalid(t, validateDevStagingEnvironment(cr), "spec.deployment.preStopHTTPGet", "Forbidden")
+}
+
+func TestValidate_RecreateRejectsRollingUpdateParams(t *testing.T) {
+	cr := validCR()
+	one := intstr.FromInt32(1)
+	cr.Spec.Deployment.Strategy = &appsv1alpha1.DeploymentStrategy{Type: appsv1alpha1.DeploymentStrategyRecreate}
+	if err := validateDevStagingEnvironment(cr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cr.Spec.Deployment.Strategy.MaxSurge = &one
+	assertInvalid(t, validateDevStagingEnvironment(cr), "spec.deployment.strategy.maxSurge", "Forbidden")
+}
+
+func TestValidate_RBACNeedsServiceAccount(t *testing.T) {
+	cr := validCR()
+	cr.Spec.RBAC = &appsv1alpha1.RBACSpec{Rules: []rbacv1.PolicyRule{{
+		APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"},
+	}}}
+	assertInvalid(t, validateDevStagingEnvironment(cr), "spec.deployment.serviceAccountName", "Required")
+
+	cr.Spec.CreateServiceAccount = true
+	if err := validateDevStagingEnvironment(cr); err != nil {
+		t.Fatalf("createServiceAccount should satisfy rbac: %v", err)
+	}
+
+	cr.Spec.CreateServiceAccount = false
+	cr.Spec.Deployment.ServiceAccountName = "operator-under-test"
+	if err := validateDevStagingEnvironment(cr); err != nil {
+		t.Fatalf("serviceAccountName should satisfy rbac: %v", err)
+	}
+}
+
+func TestValidate_RBACRulesRejectEscalation(t *testing.T) {
+	cr := validCR()
+	cr.Spec.CreateServiceAccount = true
+	cr.Spec.RBAC = &appsv1alpha1.RBACSpec{Rules: []rbacv1.PolicyRule{{
+		APIGroups: []string{"", "apps"}, Resources: []string{"configmaps", "deployments/scale"}, Verbs: []string{"get", "update"},
+	}}}
+	if err := validateDevStagingEnvironment(cr); err != nil {
+		t.Fatalf("namespaced rules should be accepted: %v", err)
+	}
+
+	cases := map[string]rbacv1.PolicyRule{
+		"spec.rbac.rules[0].verbs[1]":     {APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "*"}},
+		"spec.rbac.rules[0].resources[0]": {APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"get"}},
+		"spec.rbac.rules[0].apiGroups[0]": {APIGroups: []string{"*"}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+		"spec.rbac.rules[0].apiGroups[1]": {APIGroups: []string{"", "rbac.authorization.k8s.io"}, Resources: []string{"roles"}, Verbs: []string{"create"}},
+	}
+	for path, rule := range cases {
+		cr.Spec.RBAC.Rules = []rbacv1.PolicyRule{rule}
+		assertInvalid(t, validateDevStagingEnvironment(cr), path, "Forbidden")
+	}
+}
+
+func TestValidate_RejectsUndeclaredDependencyReference(t *testing.T) {
+	cr := validCR()
+	cr.Spec.Deployment.Env = []corev1.EnvVar{{Name: "BROKER", Value: "$(AMQP_URL)"}}
+	assertInvalid(t, validateDevStagingEnvironment(cr), "spec.deployment.env[0].value", "$(AMQP_URL)", "rabbitmq")
+
+	cr.Spec.Dependencies = append(cr.Spec.Dependencies, appsv1alpha1.DependencySpec{Type: appsv1alpha1.DependencyRabbitMQ})
+	if err := validateDevStagingEnvironment(cr); err != nil {
+		t.Fatalf("declared rabbitmq should satisfy $(AMQP_URL): %v", err)
+	}
+}
+
+func TestValidate_CustomEnvVarNameSatisfiesReference(t *testing.T) {
+	cr := validCR()
+	cr.Spec.Dependencies[0].EnvVarName = "PRIMARY_DB"
+	cr.Spec.Deployment.Env = []corev1.EnvVar{{Name: "PG_DSN", Value: "$(PRIMARY_DB)"}}
+	if err := validateDevStagingEnvironment(cr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// DATABASE_URL is no longer injected once it's renamed.
+	cr.Spec.Deployment.Env = []corev1.EnvVar{{Name: "PG_DSN", Value: "$(DATABASE_URL)"}}
+	assertInvalid(t, validateDevStagingEnvironment(cr), "$(DATABASE_URL)")
+}
+
+func TestValidate_ReportsEveryViolation(t *testing.T) {
+	cr := validCR()
+	cr.Spec.Deployment.Port = 0
+	cr.Spec.Ingress = &appsv1alpha1.IngressSpec{Enabled: true}
+	assertInvalid(t, validateDevStagingEnvironment(cr), "spec.deployment.port", "spec.ingress.host")
+}
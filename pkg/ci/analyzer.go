@@ -0,0 +1,281 @@
+package ci
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Severity ranks a [Finding]. The values are SARIF result levels, so they
+// pass straight through to code-scanning tools.
+type Severity string
+
+const (
+	// SeverityError blocks a deploy: the build or rollout will fail.
+	SeverityError Severity = "error"
+	// SeverityWarning is likely to cause trouble, or is patched at build time.
+	SeverityWarning Severity = "warning"
+	// SeverityNote is informational.
+	SeverityNote Severity = "note"
+)
+
+// Rule IDs reported by [Analyzer].
+const (
+	RuleMissingDockerfile   = "missing_dockerfile"
+	RuleUnresolvedContext   = "unresolved_build_context"
+	RuleKanikoPlatformArgs  = "kaniko_platform_args"
+	RuleKanikoPoetryNoRoot  = "kaniko_poetry_no_root"
+	RuleKanikoNpmCache      = "kaniko_npm_cache"
+	RuleKanikoGoBuildVCS    = "kaniko_go_buildvcs"
+	RuleMissingHealthCheck  = "missing_health_check"
+	RuleHealthCheckMismatch = "health_check_mismatch"
+)
+
+// Rule describes one check, for tools that list rules alongside results.
+type Rule struct {
+	ID          string
+	Description string
+}
+
+// Rules lists every rule [Analyzer] can report, in a stable order.
+var Rules = []Rule{
+	{RuleMissingDockerfile, "A build step's context has no Dockerfile"},
+	{RuleUnresolvedContext, "A build step's context depends on CI-only values and can't be checked locally"},
+	{RuleKanikoPlatformArgs, "Dockerfile relies on BuildKit platform ARGs, which Kaniko doesn't set"},
+	{RuleKanikoPoetryNoRoot, "Dockerfile runs 'poetry install' without --no-root"},
+	{RuleKanikoNpmCache, "Dockerfile runs npm without redirecting its cache to a writable path"},
+	{RuleKanikoGoBuildVCS, "Dockerfile runs 'go build' without -buildvcs=false"},
+	{RuleMissingHealthCheck, "A deploy step's health check has no route to probe"},
+	{RuleHealthCheckMismatch, "A deploy step probes /healthz but the code serves a different health route"},
+}
+
+// Finding is one problem found by [Analyzer].
+type Finding struct {
+	RuleID   string   `json:"ruleId"`
+	Severity Severity `json:"severity"`
+	// Service is the build or deploy step's name.
+	Service string `json:"service,omitempty"`
+	// File is the path the finding applies to, relative to the repo root:
+	// the Dockerfile for Kaniko findings, otherwise the workflow.
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// BuildStep is a workflow step that builds an image with Kaniko.
+type BuildStep struct {
+	Name       string
+	Context    string
+	Dockerfile string
+	Image      string
+}
+
+// DeployStep is a workflow step that deploys a DevStagingEnvironment.
+type DeployStep struct {
+	Name       string
+	Image      string
+	HealthPath string
+	HealthType string
+	// Inline is set for a DevStagingEnvironment written out in the
+	// workflow, where no /healthz default applies.
+	Inline bool
+}
+
+// Analyzer runs the static build and deploy checks against a repo
+// checkout. It needs no cluster and no network.
+type Analyzer struct {
+	// RepoPath is the repo root that build contexts resolve against.
+	RepoPath string
+
+	// Workflow is the workflow file, relative to RepoPath, reported as
+	// the location of findings about its steps.
+	Workflow string
+
+	// HealthRoutes returns the health routes served by the code in dir,
+	// relative to RepoPath. Nil means none are known.
+	HealthRoutes func(dir string) []string
+
+	// DockerfileFix suggests a Dockerfile for the build context at the
+	// absolute path dir. Nil gives a generic suggestion.
+	DockerfileFix func(dir string) string
+}
+
+// Analyze checks every build and deploy step and returns what it found,
+// builds first.
+func (a *Analyzer) Analyze(builds []BuildStep, deploys []DeployStep) []Finding {
+	var findings []Finding
+	for _, b := range builds {
+		_, f := a.CheckBuild(b)
+		findings = append(findings, f...)
+	}
+	for _, d := range deploys {
+		findings = append(findings, a.CheckDeploy(builds, d)...)
+	}
+	return findings
+}
+
+// CheckBuild verifies a build step has a Dockerfile in its context and
+// runs the Kaniko compatibility checks against it. dockerfile is the
+// Dockerfile it resolved to, relative to RepoPath, or "" when there is
+// none to check.
+func (a *Analyzer) CheckBuild(b BuildStep) (dockerfile string, findings []Finding) {
+	dir := ResolveBuildContext(b.Context)
+	if dir == "" {
+		return "", []Finding{{
+			RuleID:   RuleUnresolvedContext,
+			Severity: SeverityWarning,
+			Service:  b.Name,
+			File:     a.Workflow,
+			Message:  fmt.Sprintf("build %s: context %q can't be resolved locally — Dockerfile not checked", b.Name, b.Context),
+		}}
+	}
+
+	rel, ok := ResolveDockerfile(a.RepoPath, dir, b.Dockerfile)
+	if !ok {
+		want := filepath.Join(dir, "Dockerfile")
+		if b.Dockerfile != "" {
+			want = filepath.Join(dir, b.Dockerfile)
+		}
+		fix := "Create a Dockerfile in " + filepath.Join(a.RepoPath, dir)
+		if a.DockerfileFix != nil {
+			fix = a.DockerfileFix(filepath.Join(a.RepoPath, dir))
+		}
+		return "", []Finding{{
+			RuleID:   RuleMissingDockerfile,
+			Severity: SeverityError,
+			Service:  b.Name,
+			File:     a.Workflow,
+			Message:  fmt.Sprintf("build %s: no Dockerfile at %s", b.Name, want),
+			Fix:      fix,
+		}}
+	}
+
+	data, err := os.ReadFile(filepath.Join(a.RepoPath, rel))
+	if err != nil {
+		return rel, nil
+	}
+	findings = KanikoFindings(rel, string(data))
+	for i := range findings {
+		findings[i].Service = b.Name
+	}
+	return rel, findings
+}
+
+// CheckDeploy verifies a deploy step's health check targets a route the
+// code serves. kindling-deploy probes /healthz unless told otherwise, so
+// a missing health-check-path is only fine when /healthz exists. builds
+// map the deploy's image back to the source directory to search.
+func (a *Analyzer) CheckDeploy(builds []BuildStep, d DeployStep) []Finding {
+	if (d.HealthType != "" && d.HealthType != "http") || d.HealthPath != "" {
+		return nil
+	}
+
+	dir := "."
+	for _, b := range builds {
+		if b.Image != "" && b.Image == d.Image {
+			if c := ResolveBuildContext(b.Context); c != "" {
+				dir = c
+			}
+		}
+	}
+	var routes []string
+	if a.HealthRoutes != nil {
+		routes = a.HealthRoutes(dir)
+	}
+
+	f := Finding{Service: d.Name, File: a.Workflow}
+	switch {
+	case d.Inline && len(routes) > 0:
+		f.RuleID, f.Severity = RuleMissingHealthCheck, SeverityWarning
+		f.Message = fmt.Sprintf("deploy %s: no healthCheck — pods report ready before the app is", d.Name)
+		f.Fix = fmt.Sprintf("Add under spec.deployment:  healthCheck: {type: http, path: %s}", routes[0])
+	case d.Inline:
+		f.RuleID, f.Severity = RuleMissingHealthCheck, SeverityWarning
+		f.Message = fmt.Sprintf("deploy %s: no healthCheck and no health route found in source", d.Name)
+		f.Fix = "Add a /healthz route and, under spec.deployment,  healthCheck: {type: http, path: /healthz}"
+	case slices.Contains(routes, "/healthz"):
+		return nil
+	case len(routes) > 0:
+		f.RuleID, f.Severity = RuleHealthCheckMismatch, SeverityWarning
+		f.Message = fmt.Sprintf("deploy %s: no health-check-path, so /healthz is probed — but the code serves %s", d.Name, strings.Join(routes, ", "))
+		f.Fix = fmt.Sprintf("health-check-path: %q", routes[0])
+	default:
+		f.RuleID, f.Severity = RuleMissingHealthCheck, SeverityError
+		f.Message = fmt.Sprintf("deploy %s: no health-check-path and no health route found — the /healthz probe will fail", d.Name)
+		f.Fix = `Add a /healthz route to the app, set health-check-path to a route it serves, or set health-check-type: "none"`
+	}
+	return []Finding{f}
+}
+
+// KanikoFindings reports Dockerfile patterns that break, or need patching,
+// under Kaniko. path is only used to label the findings.
+func KanikoFindings(path, content string) []Finding {
+	var findings []Finding
+	add := func(rule, message, fix string) {
+		findings = append(findings, Finding{
+			RuleID:   rule,
+			Severity: SeverityWarning,
+			File:     path,
+			Message:  fmt.Sprintf("%s %s", path, message),
+			Fix:      fix,
+		})
+	}
+
+	// BuildKit platform ARGs
+	if strings.Contains(content, "TARGETARCH") || strings.Contains(content, "BUILDPLATFORM") ||
+		strings.Contains(content, "TARGETPLATFORM") || strings.Contains(content, "TARGETOS") {
+		add(RuleKanikoPlatformArgs, "uses BuildKit platform ARGs — kindling will auto-patch for Kaniko",
+			"Give the ARG a default (ARG TARGETARCH=amd64) and drop --platform=$BUILDPLATFORM from FROM lines")
+	}
+
+	// Poetry without --no-root
+	if strings.Contains(content, "poetry install") && !strings.Contains(content, "--no-root") {
+		add(RuleKanikoPoetryNoRoot, "has 'poetry install' without --no-root — kindling will auto-patch",
+			"RUN poetry install --no-root")
+	}
+
+	// npm without cache redirect
+	if (strings.Contains(content, "npm install") || strings.Contains(content, "npm ci") ||
+		strings.Contains(content, "npm run")) && !strings.Contains(content, "npm_config_cache") {
+		add(RuleKanikoNpmCache, "uses npm without cache redirect — kindling will auto-patch for Kaniko",
+			"Add 'ENV npm_config_cache=/tmp/.npm' before the first npm command")
+	}
+
+	// Go build without -buildvcs=false
+	if strings.Contains(content, "go build") && !strings.Contains(content, "-buildvcs=false") {
+		add(RuleKanikoGoBuildVCS, "has 'go build' without -buildvcs=false — kindling will auto-patch for Kaniko",
+			"Add -buildvcs=false to the build: RUN go build -buildvcs=false ...")
+	}
+
+	return findings
+}
+
+// ResolveBuildContext maps a build context input to a path relative to the
+// repo root, or "" when it depends on values only known in CI.
+func ResolveBuildContext(raw string) string {
+	for _, root := range []string{"${{ github.workspace }}", "${{github.workspace}}", "${CI_PROJECT_DIR}", "$CI_PROJECT_DIR"} {
+		raw = strings.ReplaceAll(raw, root, ".")
+	}
+	if strings.Contains(raw, "$") {
+		return ""
+	}
+	return filepath.Clean(raw)
+}
+
+// ResolveDockerfile finds a build's Dockerfile, relative to the repo root.
+// An explicit dockerfile is tried against the context and then the repo
+// root, matching how generated workflows use it.
+func ResolveDockerfile(repoPath, contextDir, dockerfile string) (string, bool) {
+	candidates := []string{filepath.Join(contextDir, "Dockerfile"), filepath.Join(contextDir, "dockerfile")}
+	if dockerfile != "" {
+		candidates = []string{filepath.Join(contextDir, dockerfile), filepath.Clean(dockerfile)}
+	}
+	for _, c := range candidates {
+		if info, err := os.Stat(filepath.Join(repoPath, c)); err == nil && !info.IsDir() {
+			return c, true
+		}
+	}
+	return "", false
+}
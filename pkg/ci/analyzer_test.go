@@ -0,0 +1,159 @@
+package ci
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ────────────────────────────────────────────────────────────────────────────
+// ResolveBuildContext
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestResolveBuildContext(t *testing.T) {
+	cases := map[string]string{
+		"${{ github.workspace }}":              ".",
+		"${{ github.workspace }}/services/api": "services/api",
+		"${CI_PROJECT_DIR}/ui":                 "ui",
+		"./worker":                             "worker",
+		"${{ matrix.service }}":                "",
+	}
+	for in, want := range cases {
+		if got := ResolveBuildContext(in); got != want {
+			t.Errorf("ResolveBuildContext(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// Analyzer
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestAnalyze(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "api"), 0755)
+	os.WriteFile(filepath.Join(dir, "api", "Dockerfile"),
+		[]byte("FROM golang:1.22\nRUN go build -o /server .\n"), 0644)
+
+	a := &Analyzer{
+		RepoPath: dir,
+		Workflow: ".github/workflows/dev-deploy.yml",
+		HealthRoutes: func(dir string) []string {
+			if dir == "api" {
+				return []string{"/health"}
+			}
+			return nil
+		},
+	}
+	builds := []BuildStep{
+		{Name: "api", Context: "${{ github.workspace }}/api", Image: "reg/api:1"},
+		{Name: "ui", Context: "${{ github.workspace }}/ui", Image: "reg/ui:1"},
+		{Name: "matrix", Context: "${{ matrix.dir }}"},
+	}
+	deploys := []DeployStep{
+		{Name: "api", Image: "reg/api:1"},
+		{Name: "ui", Image: "reg/ui:1"},
+		{Name: "grpc", Image: "reg/grpc:1", HealthType: "grpc"},
+	}
+	findings := a.Analyze(builds, deploys)
+
+	want := []struct {
+		rule     string
+		severity Severity
+		service  string
+		file     string
+	}{
+		{RuleKanikoGoBuildVCS, SeverityWarning, "api", "api/Dockerfile"},
+		{RuleMissingDockerfile, SeverityError, "ui", a.Workflow},
+		{RuleUnresolvedContext, SeverityWarning, "matrix", a.Workflow},
+		{RuleHealthCheckMismatch, SeverityWarning, "api", a.Workflow},
+		{RuleMissingHealthCheck, SeverityError, "ui", a.Workflow},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for i, w := range want {
+		f := findings[i]
+		if f.RuleID != w.rule || f.Severity != w.severity || f.Service != w.service || f.File != w.file {
+			t.Errorf("finding %d = %+v, want %s/%s/%s/%s", i, f, w.rule, w.severity, w.service, w.file)
+		}
+	}
+	if findings[1].Fix == "" || findings[3].Fix != `health-check-path: "/health"` {
+		t.Errorf("missing fixes: %q, %q", findings[1].Fix, findings[3].Fix)
+	}
+}
+
+func TestCheckBuild_ReturnsDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0644)
+
+	a := &Analyzer{RepoPath: dir}
+	dockerfile, findings := a.CheckBuild(BuildStep{Name: "app", Context: "."})
+	if dockerfile != "Dockerfile" || len(findings) != 0 {
+		t.Errorf("CheckBuild = %q, %+v; want Dockerfile and no findings", dockerfile, findings)
+	}
+}
+
+func TestKanikoFindings(t *testing.T) {
+	content := "FROM node:20\nARG TARGETARCH\nRUN npm ci\nRUN poetry install\n"
+	var rules []string
+	for _, f := range KanikoFindings("web/Dockerfile", content) {
+		rules = append(rules, f.RuleID)
+		if f.File != "web/Dockerfile" || !strings.HasPrefix(f.Message, "web/Dockerfile ") || f.Fix == "" {
+			t.Errorf("malformed finding %+v", f)
+		}
+	}
+	want := []string{RuleKanikoPlatformArgs, RuleKanikoPoetryNoRoot, RuleKanikoNpmCache}
+	if strings.Join(rules, ",") != strings.Join(want, ",") {
+		t.Errorf("rules = %v, want %v", rules, want)
+	}
+}
+
+// ────────────────────────────────────────────────────────────────────────────
+// SARIF
+// ────────────────────────────────────────────────────────────────────────────
+
+func TestToSARIF(t *testing.T) {
+	log := ToSARIF([]Finding{{
+		RuleID:   RuleMissingDockerfile,
+		Severity: SeverityError,
+		Service:  "ui",
+		File:     ".github/workflows/dev-deploy.yml",
+		Message:  "build ui: no Dockerfile at ui/Dockerfile",
+		Fix:      "Create a Dockerfile",
+	}}, "kindling doctor", "1.2.3")
+
+	data, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"version":"2.1.0"`,
+		`"name":"kindling doctor"`,
+		`"ruleId":"missing_dockerfile"`,
+		`"level":"error"`,
+		`"uri":".github/workflows/dev-deploy.yml"`,
+		`"service":"ui"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("SARIF missing %s:\n%s", want, data)
+		}
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(Rules) {
+		t.Errorf("driver lists %d rules, want %d", len(run.Tool.Driver.Rules), len(Rules))
+	}
+	if msg := run.Results[0].Message.Text; !strings.HasSuffix(msg, "\nFix: Create a Dockerfile") {
+		t.Errorf("message = %q, want the fix appended", msg)
+	}
+}
+
+func TestToSARIF_NoFindings(t *testing.T) {
+	data, _ := json.Marshal(ToSARIF(nil, "kindling doctor", "dev"))
+	if !strings.Contains(string(data), `"results":[]`) {
+		t.Errorf("an empty run must still have a results array: %s", data)
+	}
+}
@@ -0,0 +1,214 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependencyInfo describes a dependency type the operator can provision
+// alongside an app. The operator fills its dependency registry from
+// [Dependencies], and the CLI and generate prompt render them, so the
+// three always agree.
+type DependencyInfo struct {
+	// Type is the DevStagingEnvironment dependency type, e.g. "postgres".
+	Type string `json:"type"`
+	// Image is the default image, without a tag.
+	Image string `json:"image"`
+	// DefaultVersion is the tag used when neither version nor image is
+	// set, so dependencies don't float on ":latest". Empty pulls the
+	// registry's latest tag.
+	DefaultVersion string `json:"defaultVersion,omitempty"`
+	// Port is the main port the dependency's Service exposes.
+	Port int32 `json:"port"`
+	// EnvVars are the env vars injected into the app, the connection URL
+	// first.
+	EnvVars []string `json:"envVars"`
+	// Example is a sample connection value, with <name> for the CR name
+	// and <password> for the generated password.
+	Example string `json:"example"`
+}
+
+// EnvVarName returns the connection URL env var injected into the app.
+func (d DependencyInfo) EnvVarName() string {
+	return d.EnvVars[0]
+}
+
+// DefaultImage returns the image and default tag, e.g. "postgres:16".
+func (d DependencyInfo) DefaultImage() string {
+	if d.DefaultVersion == "" {
+		return d.Image
+	}
+	return d.Image + ":" + d.DefaultVersion
+}
+
+// Dependencies lists every supported dependency type, in the order docs
+// and prompts present them.
+var Dependencies = []DependencyInfo{
+	{Type: "postgres", Image: "postgres", DefaultVersion: "16", Port: 5432,
+		EnvVars: []string{"DATABASE_URL"},
+		Example: "postgres://devuser:<password>@<name>-postgres:5432/devdb?sslmode=disable"},
+	{Type: "redis", Image: "redis", DefaultVersion: "7", Port: 6379,
+		EnvVars: []string{"REDIS_URL"},
+		Example: "redis://<name>-redis:6379/0"},
+	{Type: "mysql", Image: "mysql", DefaultVersion: "8.4", Port: 3306,
+		EnvVars: []string{"DATABASE_URL"},
+		Example: "mysql://devuser:<password>@<name>-mysql:3306/devdb"},
+	{Type: "mongodb", Image: "mongo", DefaultVersion: "7", Port: 27017,
+		EnvVars: []string{"MONGO_URL"},
+		Example: "mongodb://devuser:<password>@<name>-mongodb:27017"},
+	// The management tag includes the UI.
+	{Type: "rabbitmq", Image: "rabbitmq", DefaultVersion: "3-management", Port: 5672,
+		EnvVars: []string{"AMQP_URL"},
+		Example: "amqp://devuser:<password>@<name>-rabbitmq:5672"},
+	{Type: "minio", Image: "minio/minio", Port: 9000,
+		EnvVars: []string{"S3_ENDPOINT", "S3_ACCESS_KEY", "S3_SECRET_KEY"},
+		Example: "http://<name>-minio:9000, keys minioadmin"},
+	{Type: "elasticsearch", Image: "docker.elastic.co/elasticsearch/elasticsearch", DefaultVersion: "8.12.0", Port: 9200,
+		EnvVars: []string{"ELASTICSEARCH_URL"},
+		Example: "http://<name>-elasticsearch:9200"},
+	{Type: "kafka", Image: "apache/kafka", DefaultVersion: "3.9.0", Port: 9092,
+		EnvVars: []string{"KAFKA_BROKER_URL"},
+		Example: "<name>-kafka:9092"},
+	{Type: "nats", Image: "nats", DefaultVersion: "2", Port: 4222,
+		EnvVars: []string{"NATS_URL"},
+		Example: "nats://<name>-nats:4222"},
+	{Type: "memcached", Image: "memcached", DefaultVersion: "1.6", Port: 11211,
+		EnvVars: []string{"MEMCACHED_URL"},
+		Example: "<name>-memcached:11211"},
+	{Type: "cassandra", Image: "cassandra", DefaultVersion: "4.1", Port: 9042,
+		EnvVars: []string{"CASSANDRA_URL"},
+		Example: "<name>-cassandra:9042"},
+	{Type: "consul", Image: "hashicorp/consul", DefaultVersion: "1.20", Port: 8500,
+		EnvVars: []string{"CONSUL_HTTP_ADDR"},
+		Example: "http://<name>-consul:8500"},
+	{Type: "vault", Image: "hashicorp/vault", DefaultVersion: "1.18", Port: 8200,
+		EnvVars: []string{"VAULT_ADDR", "VAULT_TOKEN"},
+		Example: "http://<name>-vault:8200; the token is the dev root token"},
+	// The DOCKER_INFLUXDB_INIT_* setup is InfluxDB 2 only.
+	{Type: "influxdb", Image: "influxdb", DefaultVersion: "2.7", Port: 8086,
+		EnvVars: []string{"INFLUXDB_URL", "INFLUXDB_TOKEN", "INFLUXDB_ORG", "INFLUXDB_BUCKET"},
+		Example: "http://<name>-influxdb:8086; the token is the per-environment password"},
+	{Type: "jaeger", Image: "jaegertracing/all-in-one", DefaultVersion: "1.62.0", Port: 16686,
+		EnvVars: []string{"JAEGER_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT"},
+		Example: "http://<name>-jaeger:16686, OTLP at http://<name>-jaeger:4317"},
+	{Type: "clickhouse", Image: "clickhouse/clickhouse-server", DefaultVersion: "24.8", Port: 8123,
+		EnvVars: []string{"CLICKHOUSE_URL"},
+		Example: "http://devuser:<password>@<name>-clickhouse:8123/devdb"},
+	{Type: "cockroachdb", Image: "cockroachdb/cockroach", DefaultVersion: "latest-v24.2", Port: 26257,
+		EnvVars: []string{"DATABASE_URL"},
+		Example: "postgres://root@<name>-cockroachdb:26257/devdb?sslmode=disable"},
+	{Type: "timescaledb", Image: "timescale/timescaledb", DefaultVersion: "latest-pg16", Port: 5432,
+		EnvVars: []string{"DATABASE_URL"},
+		Example: "postgres://devuser:<password>@<name>-timescaledb:5432/devdb?sslmode=disable"},
+	{Type: "mailpit", Image: "axllent/mailpit", Port: 1025,
+		EnvVars: []string{"SMTP_URL", "SMTP_HOST", "SMTP_PORT"},
+		Example: "smtp://<name>-mailpit:1025"},
+	{Type: "qdrant", Image: "qdrant/qdrant", DefaultVersion: "v1.12.1", Port: 6333,
+		EnvVars: []string{"QDRANT_URL"},
+		Example: "http://<name>-qdrant:6333"},
+	// Weaviate publishes no "latest" tag.
+	{Type: "weaviate", Image: "semitechnologies/weaviate", DefaultVersion: "1.26.1", Port: 8080,
+		EnvVars: []string{"WEAVIATE_URL", "WEAVIATE_GRPC_URL"},
+		Example: "http://<name>-weaviate:8080"},
+	{Type: "chroma", Image: "chromadb/chroma", Port: 8000,
+		EnvVars: []string{"CHROMA_URL", "CHROMA_HOST", "CHROMA_PORT"},
+		Example: "http://<name>-chroma:8000"},
+	{Type: "localstack", Image: "localstack/localstack", DefaultVersion: "3.8", Port: 4566,
+		EnvVars: []string{"AWS_ENDPOINT_URL", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_REGION", "AWS_DEFAULT_REGION"},
+		Example: `http://<name>-localstack:4566, creds "test"`},
+	{Type: "neo4j", Image: "neo4j", DefaultVersion: "5", Port: 7687,
+		EnvVars: []string{"NEO4J_URL"},
+		Example: "bolt://neo4j:<password>@<name>-neo4j:7687"},
+	{Type: "mariadb", Image: "mariadb", DefaultVersion: "11.4", Port: 3306,
+		EnvVars: []string{"DATABASE_URL"},
+		Example: "mariadb://devuser:<password>@<name>-mariadb:3306/devdb"},
+	{Type: "sqlserver", Image: "mcr.microsoft.com/mssql/server", DefaultVersion: "2022-latest", Port: 1433,
+		EnvVars: []string{"DATABASE_URL"},
+		Example: "sqlserver://sa:<password>@<name>-sqlserver:1433?database=master"},
+	// The etcd registry publishes no "latest" tag.
+	{Type: "etcd", Image: "gcr.io/etcd-development/etcd", DefaultVersion: "v3.5.17", Port: 2379,
+		EnvVars: []string{"ETCD_ENDPOINTS"},
+		Example: "<name>-etcd:2379"},
+	// Config auto-reload needs Prometheus 3.
+	{Type: "prometheus", Image: "prom/prometheus", DefaultVersion: "v3.1.0", Port: 9090,
+		EnvVars: []string{"PROMETHEUS_URL"},
+		Example: "http://<name>-prometheus:9090; scrapes the app's /metrics"},
+	{Type: "grafana", Image: "grafana/grafana", DefaultVersion: "11.3.0", Port: 3000,
+		EnvVars: []string{"GRAFANA_URL"},
+		Example: "http://<name>-grafana:3000; prometheus datasource preset"},
+	{Type: "arangodb", Image: "arangodb", DefaultVersion: "3.12", Port: 8529,
+		EnvVars: []string{"ARANGO_URL"},
+		Example: "http://root:<password>@<name>-arangodb:8529"},
+	{Type: "couchdb", Image: "couchdb", DefaultVersion: "3.4", Port: 5984,
+		EnvVars: []string{"COUCHDB_URL"},
+		Example: "http://devuser:<password>@<name>-couchdb:5984"},
+	{Type: "pulsar", Image: "apachepulsar/pulsar", DefaultVersion: "3.3.2", Port: 6650,
+		EnvVars: []string{"PULSAR_URL", "PULSAR_ADMIN_URL"},
+		Example: "pulsar://<name>-pulsar:6650, http://<name>-pulsar:8080"},
+	{Type: "meilisearch", Image: "getmeili/meilisearch", DefaultVersion: "v1.11", Port: 7700,
+		EnvVars: []string{"MEILISEARCH_URL", "MEILISEARCH_API_KEY"},
+		Example: "http://<name>-meilisearch:7700"},
+	{Type: "typesense", Image: "typesense/typesense", DefaultVersion: "27.1", Port: 8108,
+		EnvVars: []string{"TYPESENSE_URL", "TYPESENSE_API_KEY"},
+		Example: "http://<name>-typesense:8108"},
+	// OTLP over HTTP; gRPC is an extra port.
+	{Type: "otel-collector", Image: "otel/opentelemetry-collector-contrib", DefaultVersion: "0.114.0", Port: 4318,
+		EnvVars: []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_PROTOCOL"},
+		Example: "http://<name>-otel-collector:4318, http/protobuf"},
+	{Type: "zookeeper", Image: "zookeeper", DefaultVersion: "3.9", Port: 2181,
+		EnvVars: []string{"ZOOKEEPER_URL"},
+		Example: "<name>-zookeeper:2181"},
+	{Type: "surrealdb", Image: "surrealdb/surrealdb", DefaultVersion: "v2.1.4", Port: 8000,
+		EnvVars: []string{"SURREAL_URL", "SURREAL_USER", "SURREAL_PASS"},
+		Example: "http://<name>-surrealdb:8000, user root"},
+	{Type: "rethinkdb", Image: "rethinkdb", DefaultVersion: "2.4", Port: 28015,
+		EnvVars: []string{"RETHINKDB_URL"},
+		Example: "rethinkdb://<name>-rethinkdb:28015"},
+	// Also injects ELASTICSEARCH_URL for apps on an Elasticsearch client.
+	{Type: "opensearch", Image: "opensearchproject/opensearch", DefaultVersion: "2.18.0", Port: 9200,
+		EnvVars: []string{"OPENSEARCH_URL", "ELASTICSEARCH_URL"},
+		Example: "http://<name>-opensearch:9200"},
+}
+
+// LookupDependency returns the [DependencyInfo] for a dependency type.
+func LookupDependency(depType string) (DependencyInfo, bool) {
+	for _, d := range Dependencies {
+		if d.Type == depType {
+			return d, true
+		}
+	}
+	return DependencyInfo{}, false
+}
+
+// dependencyTypeList renders every dependency type as a comma-separated
+// list, wrapped to width and indented by two spaces.
+func dependencyTypeList(width int) string {
+	var b strings.Builder
+	line := " "
+	for i, d := range Dependencies {
+		word := " " + d.Type
+		if i < len(Dependencies)-1 {
+			word += ","
+		}
+		if len(line)+len(word) > width {
+			b.WriteString(line + "\n")
+			line = " "
+		}
+		line += word
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+// dependencyEnvVarList renders the env vars injected for each dependency
+// type, one type per line.
+func dependencyEnvVarList() string {
+	var b strings.Builder
+	for i, d := range Dependencies {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "  %-14s → %s (e.g. %s)", d.Type, strings.Join(d.EnvVars, ", "), d.Example)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package ci
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+	seen := map[string]bool{}
+	for _, d := range Dependencies {
+		if seen[d.Type] {
+			t.Errorf("duplicate dependency type %q", d.Type)
+		}
+		seen[d.Type] = true
+		if d.Image == "" || d.Port == 0 || len(d.EnvVars) == 0 || d.Example == "" {
+			t.Errorf("incomplete dependency %+v", d)
+		}
+	}
+
+	pg, ok := LookupDependency("postgres")
+	if !ok || pg.DefaultImage() != "postgres:16" || pg.EnvVarName() != "DATABASE_URL" {
+		t.Errorf("LookupDependency(postgres) = %+v, %v", pg, ok)
+	}
+	if minio, _ := LookupDependency("minio"); minio.DefaultImage() != "minio/minio" {
+		t.Errorf("an unpinned image should have no tag, got %q", minio.DefaultImage())
+	}
+	if _, ok := LookupDependency("oracle"); ok {
+		t.Error("LookupDependency should not find an unsupported type")
+	}
+}
+
+func TestPromptsListEveryDependency(t *testing.T) {
+	for _, d := range Dependencies {
+		if !strings.Contains(PromptDependencyDetection, " "+d.Type) {
+			t.Errorf("PromptDependencyDetection missing %q", d.Type)
+		}
+		for _, ev := range d.EnvVars {
+			if !strings.Contains(PromptDependencyAutoInjection, ev) {
+				t.Errorf("PromptDependencyAutoInjection missing %s for %s", ev, d.Type)
+			}
+		}
+	}
+	for _, line := range strings.Split(dependencyTypeList(78), "\n") {
+		if len(line) > 78 {
+			t.Errorf("type list line longer than 78 columns: %q", line)
+		}
+	}
+}
@@ -35,15 +35,21 @@ const PromptDeployInputs = `kindling-deploy inputs:
   dependencies — Dependencies as YAML block
   ingress-host — Ingress hostname
   ingress-class — Ingress class name (default: traefik)
+  ingress-annotations — Ingress annotations as YAML block (only with ingress-host)
   health-check-path — HTTP health check path (default: /healthz)
   health-check-type — http (default), grpc, or none
+  health-check-readiness-path — HTTP path for the readiness probe only (default: health-check-path)
+  health-check-startup-path — HTTP path for a startup probe that holds off liveness until boot finishes
+  startup-failure-threshold — startup probe failures (10s apart) allowed before a restart; enables the startup probe
+  health-check-success-threshold — consecutive readiness successes before a pod gets traffic again
   replicas — Number of replicas (default: 1)
   service-type — ClusterIP, NodePort, LoadBalancer (default: ClusterIP)
   wait — Wait for deployment rollout (default: true)
 
 kindling-deploy field ordering (follow this order exactly):
-  name, image, port, ingress-host, health-check-path, health-check-type, labels, env, dependencies,
-  replicas, service-type, ingress-class, wait`
+  name, image, port, ingress-host, health-check-path, health-check-type, health-check-readiness-path,
+  health-check-startup-path, startup-failure-threshold, health-check-success-threshold, labels, env,
+  dependencies, replicas, service-type, ingress-class, ingress-annotations, wait`
 
 // PromptBuildInputs is the shared description of the kindling-build inputs.
 const PromptBuildInputs = `kindling-build inputs:
@@ -91,13 +97,31 @@ const PromptHealthChecks = `Health check guidance:
   • For HTTP services (Express, Flask, FastAPI, Gin, etc.), use the default "http" type.
   • gRPC indicators: imports of "google.golang.org/grpc", "grpc" (Python), "@grpc/grpc-js" (Node),
     "io.grpc" (Java), .proto files, protobuf codegen files (*_pb2.py, *.pb.go, *_grpc.pb.go),
-    or proto/ directories in the repo.`
+    or proto/ directories in the repo.
+- Slow-booting frameworks (Spring Boot, Quarkus, Rails, Django with heavy imports, apps that load
+  ML models) can take longer to start than liveness allows, and get killed in a restart loop
+  although the deploy itself succeeded. For these, set startup-failure-threshold (e.g. "30" for
+  Spring Boot/Rails, "60" for model-loading apps) so a startup probe holds off liveness until boot
+  finishes. Only omit it for services that start in a few seconds.
+- When the app serves separate probe routes, split them instead of reusing one path:
+  • Spring Boot actuator: health-check-path: "/actuator/health/liveness",
+    health-check-readiness-path: "/actuator/health/readiness"
+  • Routes named /livez, /readyz, /startupz (or similar): health-check-path for liveness,
+    health-check-readiness-path for readiness, health-check-startup-path for startup
+  Only use routes you found in the source. Leave health-check-success-threshold unset unless
+  readiness flaps between ready and unready.
+- In an inline DevStagingEnvironment the same settings are spec.deployment.healthCheck fields:
+  readinessPath, startupPath, startupFailureThreshold, successThreshold.`
 
 // PromptDependencyDetection is the shared rules for detecting backing services
-// from source code and dependency manifests.
-const PromptDependencyDetection = `Supported dependency types for the "dependencies" input (YAML list under the input):
-  postgres, redis, mysql, mongodb, rabbitmq, minio, elasticsearch,
-  kafka, nats, memcached, cassandra, consul, vault, influxdb, jaeger
+// from source code and dependency manifests. The type list comes from
+// [Dependencies].
+var PromptDependencyDetection = `Supported dependency types for the "dependencies" input (YAML list under the input):
+` + dependencyTypeList(78) + `
+
+Each type deploys a pinned default tag (postgres 16, redis 7, mysql 8.4,
+mongodb 7, ...). Only set "version" when the project pins a different one,
+e.g. the image tag of the matching docker-compose service.
 
 Detect which dependencies to include by analyzing imports, packages, and env var
 references across ALL common languages:
@@ -106,32 +130,101 @@ references across ALL common languages:
             "go.mongodb.org/mongo-driver" → mongodb, "github.com/streadway/amqp" → rabbitmq,
             "github.com/segmentio/kafka-go" → kafka, "github.com/nats-io/nats.go" → nats,
             "github.com/minio/minio-go" → minio, "github.com/elastic/go-elasticsearch" → elasticsearch,
-            "github.com/hashicorp/vault" → vault, "github.com/hashicorp/consul" → consul
+            "github.com/hashicorp/vault" → vault, "github.com/hashicorp/consul" → consul,
+            "github.com/ClickHouse/clickhouse-go" → clickhouse,
+            "github.com/neo4j/neo4j-go-driver" → neo4j,
+            "github.com/microsoft/go-mssqldb"/"github.com/denisenkom/go-mssqldb" → sqlserver,
+            "go.etcd.io/etcd/client" → etcd, "github.com/arangodb/go-driver" → arangodb,
+            "github.com/go-kivik/kivik" → couchdb, "github.com/apache/pulsar-client-go" → pulsar,
+            "github.com/meilisearch/meilisearch-go" → meilisearch,
+            "github.com/typesense/typesense-go" → typesense,
+            "github.com/opensearch-project/opensearch-go" → opensearch
 - Node/TS:  "pg"/"pg-promise" → postgres, "ioredis"/"redis" → redis, "mysql2" → mysql,
             "mongoose"/"mongodb" → mongodb, "amqplib" → rabbitmq, "kafkajs" → kafka,
             "nats" → nats, "memcached"/"memjs" → memcached, "@elastic/elasticsearch" → elasticsearch,
-            "minio" → minio, "cassandra-driver" → cassandra
+            "minio" → minio, "cassandra-driver" → cassandra, "@clickhouse/client" → clickhouse,
+            "neo4j-driver" → neo4j, "mssql"/"tedious" → sqlserver, "mariadb" → mariadb,
+            "etcd3" → etcd, "arangojs" → arangodb, "nano"/"couchdb" → couchdb,
+            "pulsar-client" → pulsar, "meilisearch" → meilisearch, "typesense" → typesense,
+            "@opensearch-project/opensearch" → opensearch
 - Python:   "psycopg2"/"asyncpg"/"sqlalchemy" → postgres, "redis"/"aioredis" → redis,
             "pymysql"/"mysqlclient" → mysql, "pymongo"/"motor" → mongodb,
             "pika"/"aio-pika" → rabbitmq, "kafka-python"/"confluent-kafka" → kafka,
             "nats-py" → nats, "pymemcache" → memcached, "elasticsearch" → elasticsearch,
-            "boto3"/"minio" → minio, "cassandra-driver" → cassandra, "hvac" → vault
+            "minio" → minio, "cassandra-driver" → cassandra, "hvac" → vault,
+            "clickhouse-connect"/"clickhouse-driver" → clickhouse, "neo4j"/"py2neo" → neo4j,
+            "pyodbc"/"pymssql"/"mssql-django" → sqlserver, "mariadb" → mariadb,
+            "etcd3" → etcd, "python-arango" → arangodb, "couchdb"/"couchdb3" → couchdb,
+            "pulsar-client" → pulsar, "meilisearch" → meilisearch, "typesense" → typesense,
+            "opensearch-py" → opensearch
 - Java/Kotlin: "org.postgresql" → postgres, "jedis"/"lettuce" → redis, "mysql-connector" → mysql,
             "mongo-java-driver" → mongodb, "spring-boot-starter-amqp" → rabbitmq,
             "spring-kafka" → kafka, "spring-data-elasticsearch" → elasticsearch,
-            "spring-cloud-vault" → vault, "spring-cloud-consul" → consul
+            "spring-cloud-vault" → vault, "spring-cloud-consul" → consul,
+            "clickhouse-jdbc"/"com.clickhouse" → clickhouse,
+            "org.neo4j.driver"/"spring-boot-starter-data-neo4j" → neo4j,
+            "mssql-jdbc"/"com.microsoft.sqlserver" → sqlserver,
+            "mariadb-java-client"/"org.mariadb.jdbc" → mariadb,
+            "org.opensearch.client" → opensearch
 - Rust:     "tokio-postgres"/"diesel" → postgres, "redis" → redis, "sqlx" + mysql feature → mysql,
-            "mongodb" → mongodb, "lapin" → rabbitmq, "rdkafka" → kafka
+            "mongodb" → mongodb, "lapin" → rabbitmq, "rdkafka" → kafka, "tiberius" → sqlserver
 - Ruby:     "pg" gem → postgres, "redis" gem → redis, "mysql2" gem → mysql,
-            "mongo"/"mongoid" → mongodb, "bunny" → rabbitmq, "sidekiq" → redis
+            "mongo"/"mongoid" → mongodb, "bunny" → rabbitmq, "sidekiq" → redis,
+            "tiny_tds"/"activerecord-sqlserver-adapter" → sqlserver,
+            "meilisearch"/"meilisearch-rails" → meilisearch, "typesense" → typesense
 - PHP:      "predis"/"phpredis" → redis, "doctrine/dbal" → postgres or mysql,
-            "php-amqplib" → rabbitmq, "mongodb/mongodb" → mongodb
+            "php-amqplib" → rabbitmq, "mongodb/mongodb" → mongodb, "pdo_sqlsrv"/"sqlsrv" → sqlserver,
+            "meilisearch/meilisearch-php" → meilisearch, "typesense/typesense-php" → typesense
 - C#/.NET:  "Npgsql" → postgres, "StackExchange.Redis" → redis,
             "MySqlConnector" → mysql, "MongoDB.Driver" → mongodb,
             "RabbitMQ.Client" → rabbitmq, "Confluent.Kafka" → kafka,
-            "NATS.Client" → nats, "Elasticsearch.Net" → elasticsearch
+            "NATS.Client" → nats, "Elasticsearch.Net" → elasticsearch,
+            "System.Data.SqlClient"/"Microsoft.Data.SqlClient"/
+            "Microsoft.EntityFrameworkCore.SqlServer" → sqlserver
 - Elixir:   "postgrex"/"ecto" → postgres, "redix" → redis, "amqp" → rabbitmq,
             "kafka_ex" → kafka, "mongodb_driver" → mongodb
+- Tracing: add "otel-collector" when the app uses an OpenTelemetry SDK or OTLP exporter
+  ("go.opentelemetry.io/otel", "@opentelemetry/sdk-node"/"@opentelemetry/exporter-trace-otlp-*",
+  "opentelemetry-sdk"/"opentelemetry-exporter-otlp", "io.opentelemetry", "OpenTelemetry.Exporter.*",
+  "opentelemetry" gems/crates). It injects OTEL_EXPORTER_OTLP_ENDPOINT, which the SDKs read
+  automatically. Also add "jaeger" to browse the traces; the collector forwards to it.
+- Multi-model databases: "github.com/surrealdb/surrealdb.go", "surrealdb" (npm, PyPI, crate),
+  or surrealdb/surrealdb images in docker-compose → surrealdb;
+  "gopkg.in/rethinkdb/rethinkdb-go.v6"/"github.com/rethinkdb/rethinkdb-go", "rethinkdb"/
+  "rethinkdbdash" (npm), "rethinkdb" (PyPI), "com.rethinkdb" (Java), or rethinkdb images → rethinkdb.
+- ZooKeeper: add "zookeeper" only when the app itself talks to ZooKeeper — "kazoo" (Python),
+  "node-zookeeper-client" (Node), "github.com/go-zookeeper/zk" (Go), "org.apache.curator"/
+  "curator-framework" or "org.apache.zookeeper" (Java), e.g. for Solr or distributed locks.
+  The kafka dependency runs in KRaft mode and does NOT need it, so do NOT add zookeeper just
+  because the app uses kafka or docker-compose runs a zookeeper service next to kafka.
+- Observability: add "prometheus" (and "grafana" for dashboards) only when the repo's
+  docker-compose already runs them. A metrics client library such as
+  "github.com/prometheus/client_golang" or "prom-client" alone is NOT a reason to add them.
+- Postgres-compatible databases: use "cockroachdb" when the app targets CockroachDB
+  (cockroachdb/cockroach images in docker-compose, "github.com/cockroachdb/cockroach-go",
+  "sqlalchemy-cockroachdb", "activerecord-cockroachdb-adapter", port 26257) and
+  "timescaledb" when it relies on TimescaleDB (timescale/timescaledb images,
+  "CREATE EXTENSION timescaledb", create_hypertable). Both inject DATABASE_URL and
+  work with the app's regular postgres driver, so do NOT also add postgres.
+- MySQL-compatible databases: use "mariadb" instead of "mysql" when the app targets
+  MariaDB specifically (mariadb images in docker-compose, the MariaDB connectors above).
+  It injects DATABASE_URL with a mariadb:// scheme, so do NOT also add mysql.
+- AWS SDKs ("boto3"/"aioboto3", "@aws-sdk/client-*"/"aws-sdk", "github.com/aws/aws-sdk-go-v2",
+  "software.amazon.awssdk", "aws-sdk-*" gems/crates) used for S3, SQS, SNS, DynamoDB, Kinesis,
+  Secrets Manager, etc. → localstack. Use it when the app can run against a local AWS endpoint
+  (no real account data is needed). Do NOT add it for AWS services the app only reaches
+  through a managed connector (RDS, Aurora) — see the cloud-managed rule below.
+- Full-text search: use "meilisearch" or "typesense" when their clients above are
+  imported, docker-compose runs getmeili/meilisearch or typesense/typesense, or Laravel
+  Scout is configured with either driver. They are lighter than Elasticsearch, so prefer
+  them whenever detected and do NOT also add elasticsearch for the same search index.
+- OpenSearch: use "opensearch" instead of "elasticsearch" when the app uses an OpenSearch
+  client above or docker-compose runs opensearchproject/opensearch. It also injects
+  ELASTICSEARCH_URL, so do NOT add elasticsearch as well.
+- Outgoing email over SMTP: use "mailpit" (a local SMTP server with a web inbox) when
+  the app sends mail via "nodemailer", Python "smtplib"/"aiosmtplib", Go "net/smtp"/"gomail",
+  Rails ActionMailer, Django EMAIL_HOST, Laravel MAIL_HOST, or references SMTP_HOST/SMTP_URL,
+  or docker-compose runs mailhog/mailpit.
 - docker-compose.yml service names (postgres, redis, mysql, mongo, rabbitmq, etc.)
 - Environment variable references in code (DATABASE_URL, REDIS_URL, MONGO_URL, etc.)
 
@@ -143,34 +236,21 @@ Only add a local dependency when the service genuinely connects to a local datab
 instance (e.g. via DATABASE_URL, localhost, or docker-compose-style service names).
 Examples of SDKs that should NOT trigger local dependencies:
   - google-cloud-alloydb, cloud-sql-python-connector → NOT local postgres
-  - boto3.dynamodb, @aws-sdk/client-dynamodb → NOT local mongodb
+  - boto3.dynamodb, @aws-sdk/client-dynamodb → NOT local mongodb (use localstack)
   - @azure/cosmos → NOT local mongodb
   - langchain-postgres + alloydb → NOT local postgres (uses AlloyDB connector)`
 
 // PromptDependencyAutoInjection is the shared rules about auto-injected
-// connection URLs from declared dependencies.
-const PromptDependencyAutoInjection = `CRITICAL — Dependency connection URLs are auto-injected:
+// connection URLs from declared dependencies. The per-type list comes from
+// [Dependencies].
+var PromptDependencyAutoInjection = `CRITICAL — Dependency connection URLs are auto-injected:
 When you declare a dependency in the "dependencies" input, the kindling operator
 AUTOMATICALLY injects the corresponding connection URL environment variable into the
 application container. You MUST NOT include these env vars in the "env" input — they
 will be duplicated, and any secretKeyRef will fail because no such secret exists.
 
 Auto-injected env vars by dependency type:
-  postgres       → DATABASE_URL  (e.g. postgres://devuser:devpass@<name>-postgres:5432/devdb?sslmode=disable)
-  redis          → REDIS_URL     (e.g. redis://<name>-redis:6379/0)
-  mysql          → DATABASE_URL  (e.g. mysql://devuser:devpass@<name>-mysql:3306/devdb)
-  mongodb        → MONGO_URL     (e.g. mongodb://devuser:devpass@<name>-mongodb:27017)
-  rabbitmq       → AMQP_URL      (e.g. amqp://devuser:devpass@<name>-rabbitmq:5672)
-  minio          → S3_ENDPOINT   (e.g. http://<name>-minio:9000)
-  elasticsearch  → ELASTICSEARCH_URL (e.g. http://<name>-elasticsearch:9200)
-  kafka          → KAFKA_BROKER_URL  (e.g. <name>-kafka:9092)
-  nats           → NATS_URL      (e.g. nats://<name>-nats:4222)
-  memcached      → MEMCACHED_URL (e.g. <name>-memcached:11211)
-  cassandra      → CASSANDRA_URL (e.g. <name>-cassandra:9042)
-  consul         → CONSUL_HTTP_ADDR (e.g. http://<name>-consul:8500)
-  vault          → VAULT_ADDR    (e.g. http://<name>-vault:8200)
-  influxdb       → INFLUXDB_URL  (e.g. http://<name>-influxdb:8086)
-  jaeger         → JAEGER_ENDPOINT (e.g. http://<name>-jaeger:16686)
+` + dependencyEnvVarList() + `
 
 So if you write "dependencies: postgres, redis", do NOT also write:
   env: |
@@ -323,13 +403,21 @@ to start and be usable with ZERO manual secret setup. Follow these rules:
 3. Optional external integrations should be OMITTED entirely from the env block.
    These are not needed for local dev and including them as secretKeyRef will
    cause the pod to fail with CreateContainerConfigError. Skip:
-   - Cloud storage (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, GCS_*, AZURE_STORAGE_*)
+   - Cloud storage (GCS_*, AZURE_STORAGE_*)
    - Monitoring/APM (DD_API_KEY, SENTRY_DSN, NEW_RELIC_*, DATADOG_*)
-   - Email/SMS (SMTP_*, SENDGRID_*, TWILIO_*, MAILGUN_*)
+   - Email/SMS APIs (SENDGRID_*, TWILIO_*, MAILGUN_*)
+   Plain SMTP is the exception: declare a mailpit dependency and map the app's
+   SMTP settings onto the injected SMTP_HOST/SMTP_PORT instead of omitting them.
    - OAuth providers (SLACK_CLIENT_*, GOOGLE_CLIENT_*, GITHUB_CLIENT_*, AUTH0_*)
    - Analytics (SEGMENT_*, MIXPANEL_*, AMPLITUDE_*)
    Instead, if the app has a config option to disable these features, set it.
-   For example: FILE_STORAGE=local instead of FILE_STORAGE=s3.
+   AWS is the exception: when the app uses an AWS SDK (S3, SQS, DynamoDB, ...),
+   declare a "localstack" dependency so the real code path runs, and do NOT add
+   AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY (they are injected). Only fall back
+   to a local option (e.g. FILE_STORAGE=local) when the app needs real AWS data.
+   If the app reads its endpoint from a different env var (S3_ENDPOINT,
+   AWS_S3_ENDPOINT, ...), map it with "$(AWS_ENDPOINT_URL)", and enable
+   path-style S3 addressing if the app has a setting for it.
 
 4. Only use valueFrom.secretKeyRef for credentials that are BOTH:
    (a) absolutely required for the app to start (it will crash without them), AND
@@ -341,7 +429,8 @@ to start and be usable with ZERO manual secret setup. Follow these rules:
    For each variable found:
    - Skip it if it's an auto-injected dependency URL (DATABASE_URL, REDIS_URL, etc.)
    - Skip it if it's a dependency credential (POSTGRES_PASSWORD, etc.)
-   - Skip it if it's an optional external integration (AWS_*, DD_*, SENTRY_*, etc.)
+   - Skip it if it's an optional external integration (DD_*, SENTRY_*, etc.)
+   - Skip AWS_* credentials and region when a localstack dependency is declared
    - For app secrets (SECRET_KEY, SESSION_SECRET, etc.) → set a random 64-char hex value
    - For URL vars that reference the app itself (URL, BASE_URL, APP_URL, COLLABORATION_URL,
      etc.) → set to "http://<actor>-<name>.localhost"
@@ -426,21 +515,24 @@ Agent frameworks:
 
 Vector stores:
   When vector store dependencies are detected (chromadb, pgvector, pinecone, weaviate,
-  qdrant, milvus), DEFAULT to respecting external services — the user almost certainly
-  already has a cloud-hosted vector store they're connecting to. Handle as follows:
-  - pinecone, weaviate, qdrant, milvus (cloud-hosted): surface their API keys
-    (PINECONE_API_KEY, WEAVIATE_API_KEY, QDRANT_API_KEY, MILVUS_API_KEY) as
-    secretKeyRef entries. Do NOT add local dependencies.
+  qdrant, milvus), handle them as follows:
+  - qdrant, weaviate: these run locally. Add a "qdrant" or "weaviate" dependency
+    (auto-injects QDRANT_URL / WEAVIATE_URL) instead of surfacing QDRANT_API_KEY or
+    WEAVIATE_API_KEY as secrets — the local instances need no auth.
+  - pinecone, milvus (cloud-hosted): DEFAULT to respecting external services.
+    Surface their API keys (PINECONE_API_KEY, MILVUS_API_KEY) as secretKeyRef
+    entries. Do NOT add local dependencies.
   - pgvector: do NOT auto-add a "postgres" dependency. The app likely connects to
     an external PostgreSQL with pgvector. Surface any API keys / connection env vars.
     Add a YAML comment: # NOTE: pgvector detected — add 'postgres' dependency if you
     # want a local dev replica instead of your external database.
-  - chromadb: if used as an embedded library, no extra dependency needed. If it
-    appears to run as a separate service (has its own Dockerfile), treat it as a
-    deployable service. Add a comment noting the user can run ChromaDB locally.
+  - chromadb: if used as an embedded library (PersistentClient/EphemeralClient), no
+    extra dependency needed. If the app connects over HTTP (chromadb.HttpClient,
+    CHROMA_HOST), add a "chroma" dependency (auto-injects CHROMA_URL, CHROMA_HOST,
+    CHROMA_PORT).
   - FAISS: always embedded, no dependency needed.
-  Do NOT inject local database dependencies for vector stores unless the user
-  explicitly asks for local replication.
+  Apart from qdrant, weaviate, and chroma, do NOT inject local database dependencies
+  for vector stores unless the user explicitly asks for local replication.
 
 Background workers:
   Celery workers, Kafka consumers, RabbitMQ subscribers, and async task processors
@@ -42,8 +42,9 @@ func TestPromptConstantsNonEmpty(t *testing.T) {
 func TestPromptDeployInputsContent(t *testing.T) {
 	fields := []string{
 		"name", "image", "port", "labels", "env", "dependencies",
-		"ingress-host", "health-check-path", "health-check-type",
-		"replicas", "service-type", "wait",
+		"ingress-host", "ingress-annotations", "health-check-path", "health-check-type",
+		"health-check-readiness-path", "health-check-startup-path", "startup-failure-threshold",
+		"health-check-success-threshold", "replicas", "service-type", "wait",
 	}
 	for _, f := range fields {
 		if !strings.Contains(PromptDeployInputs, f) {
@@ -57,6 +58,12 @@ func TestPromptDependencyDetectionContent(t *testing.T) {
 		"postgres", "redis", "mysql", "mongodb", "rabbitmq",
 		"minio", "elasticsearch", "kafka", "nats", "memcached",
 		"cassandra", "consul", "vault", "influxdb", "jaeger",
+		"clickhouse", "cockroachdb", "timescaledb", "mailpit",
+		"qdrant", "weaviate", "chroma", "localstack", "neo4j",
+		"mariadb", "sqlserver", "etcd", "prometheus", "grafana",
+		"arangodb", "couchdb", "pulsar", "meilisearch", "typesense",
+		"otel-collector", "zookeeper", "surrealdb", "rethinkdb",
+		"opensearch",
 	}
 	for _, d := range deps {
 		if !strings.Contains(PromptDependencyDetection, d) {
@@ -80,6 +87,8 @@ func TestPromptDependencyAutoInjectionContent(t *testing.T) {
 		"S3_ENDPOINT", "ELASTICSEARCH_URL", "KAFKA_BROKER_URL",
 		"NATS_URL", "MEMCACHED_URL", "CASSANDRA_URL",
 		"CONSUL_HTTP_ADDR", "VAULT_ADDR", "INFLUXDB_URL", "JAEGER_ENDPOINT",
+		"CLICKHOUSE_URL", "SMTP_URL", "QDRANT_URL", "WEAVIATE_URL", "CHROMA_URL",
+		"AWS_ENDPOINT_URL", "NEO4J_URL",
 	}
 	for _, ev := range envVars {
 		if !strings.Contains(PromptDependencyAutoInjection, ev) {
@@ -95,6 +104,11 @@ func TestPromptHealthChecksContent(t *testing.T) {
 			t.Errorf("PromptHealthChecks missing type %q", hcType)
 		}
 	}
+	for _, want := range []string{"startup-failure-threshold", "health-check-readiness-path", "Spring Boot", "Rails", "startupFailureThreshold"} {
+		if !strings.Contains(PromptHealthChecks, want) {
+			t.Errorf("PromptHealthChecks missing slow-boot guidance %q", want)
+		}
+	}
 }
 
 func TestPromptKanakoPatchingContent(t *testing.T) {
@@ -0,0 +1,126 @@
+package ci
+
+// SARIF 2.1.0, trimmed to the fields code-scanning tools read. See
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// SARIFLog is the top-level SARIF document.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is one tool invocation and its results.
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool identifies the analyzer that produced a run.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver names the tool and lists its rules.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes one rule.
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+// SARIFMessage is a plain-text message.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is one finding.
+type SARIFResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    SARIFMessage      `json:"message"`
+	Locations  []SARIFLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// SARIFLocation points a result at a file.
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation is a file location, relative to the repo root.
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is a file URI.
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// ToSARIF converts findings into a single-run SARIF log for a tool called
+// toolName at toolVersion. Every rule in [Rules] is listed, so uploads
+// stay comparable when a run finds nothing.
+//
+// Fixes are instructions rather than patches, and a SARIF fix object
+// needs a patch, so each fix is appended to the message and kept in the
+// result's "fix" property instead.
+func ToSARIF(findings []Finding, toolName, toolVersion string) SARIFLog {
+	rules := make([]SARIFRule, len(Rules))
+	for i, r := range Rules {
+		rules[i] = SARIFRule{ID: r.ID, ShortDescription: SARIFMessage{Text: r.Description}}
+	}
+
+	results := make([]SARIFResult, 0, len(findings))
+	for _, f := range findings {
+		res := SARIFResult{
+			RuleID:  f.RuleID,
+			Level:   string(f.Severity),
+			Message: SARIFMessage{Text: f.Message},
+		}
+		if f.File != "" {
+			res.Locations = []SARIFLocation{{
+				PhysicalLocation: SARIFPhysicalLocation{
+					ArtifactLocation: SARIFArtifactLocation{URI: f.File},
+				},
+			}}
+		}
+		props := map[string]string{}
+		if f.Fix != "" {
+			res.Message.Text += "\nFix: " + f.Fix
+			props["fix"] = f.Fix
+		}
+		if f.Service != "" {
+			props["service"] = f.Service
+		}
+		if len(props) > 0 {
+			res.Properties = props
+		}
+		results = append(results, res)
+	}
+
+	return SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []SARIFRun{{
+			Tool: SARIFTool{Driver: SARIFDriver{
+				Name:           toolName,
+				Version:        toolVersion,
+				InformationURI: "https://github.com/kindling-sh/kindling",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
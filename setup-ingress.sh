@@ -27,11 +27,20 @@ kubectl apply -f config/registry/registry.yaml
 # Configure containerd registry mirror on Kind nodes (config_path mode
 # for containerd 2.x).  This makes containerd resolve "registry:5000"
 # to localhost:5000 where the hostNetwork registry pod is listening.
+# The registry runs on the ingress-ready node, so other nodes (workers
+# from kindling init --nodes) reach it at that node's IP instead.
 REGISTRY_DIR="/etc/containerd/certs.d/registry:5000"
+REGISTRY_NODE_IP=$(kubectl get nodes -l ingress-ready=true \
+  -o jsonpath='{.items[0].status.addresses[?(@.type=="InternalIP")].address}' 2>/dev/null || true)
 for node in $(kind get nodes --name "${KIND_CLUSTER_NAME:-dev}" 2>/dev/null); do
+  registry_host="localhost"
+  if [ -n "$REGISTRY_NODE_IP" ] && \
+     [ "$(kubectl get node "$node" -o jsonpath='{.metadata.labels.ingress-ready}' 2>/dev/null)" != "true" ]; then
+    registry_host="$REGISTRY_NODE_IP"
+  fi
   docker exec "$node" mkdir -p "$REGISTRY_DIR"
   docker exec -i "$node" sh -c "cat > ${REGISTRY_DIR}/hosts.toml" <<EOF
-[host."http://localhost:5000"]
+[host."http://${registry_host}:5000"]
   capabilities = ["pull", "resolve", "push"]
 EOF
 done